@@ -0,0 +1,84 @@
+// Package audit provides a structured event stream describing every
+// provider call a Generator makes and how schema validation disposed of
+// its result, so a run can be reconstructed or investigated after the
+// fact without re-reading the raw generated dataset.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// Outcome classifies what happened to a single generation attempt.
+type Outcome string
+
+const (
+	// OutcomeGenerated means the provider call succeeded and the sample
+	// passed schema validation.
+	OutcomeGenerated Outcome = "generated"
+	// OutcomeRejected means the provider call succeeded but the sample
+	// failed schema validation.
+	OutcomeRejected Outcome = "rejected"
+	// OutcomeError means the provider call itself failed.
+	OutcomeError Outcome = "error"
+)
+
+// Event is a single structured audit record.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	Provider     string `json:"provider"`
+	ProviderType string `json:"provider_type"`
+	Model        string `json:"model"`
+	Schema       string `json:"schema"`
+
+	// PromptHash is the hex-encoded SHA-256 digest of the rendered
+	// prompt (see HashPrompt), so the prompt text itself — which may
+	// contain sensitive generation context — never has to leave the
+	// generating process to be audited.
+	PromptHash string `json:"prompt_hash"`
+
+	Usage        provider.TokenUsage `json:"usage"`
+	Latency      time.Duration       `json:"latency"`
+	FinishReason string              `json:"finish_reason,omitempty"`
+	Seed         *int64              `json:"seed,omitempty"`
+
+	// Cached marks an event whose response came from the generator's
+	// cache instead of an actual provider call this run. Usage and
+	// Latency are zeroed on these events since neither was spent/taken
+	// this run; use Cached to distinguish that from a genuinely free,
+	// instant call.
+	Cached bool `json:"cached,omitempty"`
+
+	Outcome Outcome `json:"outcome"`
+
+	// Error is the provider.Generate failure message; set only when
+	// Outcome is OutcomeError.
+	Error string `json:"error,omitempty"`
+
+	// Validation lists every field-level failure from the sample's
+	// schema.ValidationReport; set only when Outcome is OutcomeRejected.
+	// Mirrors the shape output.JSONLWriter.WriteRejection already writes
+	// to its rejection sidecar.
+	Validation []*schema.SchemaError `json:"validation,omitempty"`
+}
+
+// HashPrompt returns the hex-encoded SHA-256 digest of prompt, used to
+// populate Event.PromptHash without auditing the prompt text itself.
+func HashPrompt(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sink receives audit events as they're emitted. Implementations must be
+// safe for concurrent use, since a Generator's worker pool emits events
+// from multiple goroutines.
+type Sink interface {
+	Write(ctx context.Context, event Event) error
+	Close() error
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// JSONLSink appends each Event as one line of NDJSON to a file, opened in
+// append mode so a resumed run extends the same audit trail rather than
+// truncating it.
+type JSONLSink struct {
+	file *os.File
+	w    *bufio.Writer
+	mu   sync.Mutex
+}
+
+// NewJSONLSink opens (creating if necessary) path for appending and
+// returns a Sink that writes every Event to it as NDJSON.
+func NewJSONLSink(path string) (*JSONLSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLSink{file: file, w: bufio.NewWriterSize(file, 64*1024)}, nil
+}
+
+func (s *JSONLSink) Write(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	if _, err := s.w.WriteString("\n"); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
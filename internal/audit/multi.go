@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"context"
+	"log"
+)
+
+// MultiSink fans a single Event out to several Sinks, e.g. a JSONL file
+// alongside a webhook. A failing sink never blocks the others — audit
+// emission is best-effort by design, since losing an audit record is far
+// less costly than a generation run stalling or aborting because of it.
+type MultiSink struct {
+	sinks []Sink
+}
+
+// NewMultiSink creates a MultiSink fanning out to every given sink.
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+// Write writes event to every inner sink, logging (not returning) any
+// failure so one struggling sink doesn't stop the others from recording
+// the event.
+func (m *MultiSink) Write(ctx context.Context, event Event) error {
+	for _, s := range m.sinks {
+		if err := s.Write(ctx, event); err != nil {
+			log.Printf("audit: sink failed to write event: %v", err)
+		}
+	}
+	return nil
+}
+
+// Close closes every inner sink even if one fails, returning the first
+// error encountered.
+func (m *MultiSink) Close() error {
+	var firstErr error
+	for _, s := range m.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// StderrSink streams every Event to an io.Writer (stderr by default) as
+// NDJSON, useful for piping a run's audit trail into an external log
+// collector without configuring a file path.
+type StderrSink struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewStderrSink returns a Sink that writes every Event to os.Stderr.
+func NewStderrSink() *StderrSink {
+	return &StderrSink{w: os.Stderr}
+}
+
+func (s *StderrSink) Write(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(event)
+}
+
+func (s *StderrSink) Close() error { return nil }
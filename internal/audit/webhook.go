@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultWebhookTimeout bounds a webhook POST when the caller doesn't
+// specify one.
+const defaultWebhookTimeout = 10 * time.Second
+
+// signatureHeader carries the HMAC-SHA256 signature of the request body,
+// hex-encoded and prefixed with the algorithm name, e.g.
+// "sha256=3f786850e387550fdab836ed7e6dc881de23001b".
+const signatureHeader = "X-Kothaset-Signature"
+
+// WebhookSink POSTs each Event as a JSON body to a configured URL,
+// HMAC-SHA256-signing it with Secret so the receiver can verify the
+// request actually came from this run.
+type WebhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookSink returns a Sink that POSTs every Event to url, signed
+// with secret. A zero timeout falls back to defaultWebhookTimeout.
+func NewWebhookSink(url, secret string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookSink{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *WebhookSink) Write(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		req.Header.Set(signatureHeader, signBody(body, s.secret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("audit webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook: %s returned status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *WebhookSink) Close() error { return nil }
+
+// signBody returns the "sha256=<hex>" HMAC-SHA256 signature of body
+// keyed by secret.
+func signBody(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookSink_SignsRequest(t *testing.T) {
+	const secret = "test-secret"
+
+	var gotSignature string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(signatureHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, secret, time.Second)
+	defer sink.Close()
+
+	event := Event{Provider: "openai", Model: "gpt-5.2", Outcome: OutcomeGenerated}
+	if err := sink.Write(context.Background(), event); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(gotBody)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if gotSignature != want {
+		t.Errorf("signature = %q, want %q", gotSignature, want)
+	}
+}
+
+func TestWebhookSink_NonOKStatusIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, "secret", time.Second)
+	defer sink.Close()
+
+	if err := sink.Write(context.Background(), Event{}); err == nil {
+		t.Error("Write() error = nil, want an error for a 500 response")
+	}
+}
@@ -0,0 +1,47 @@
+// Package cache stores provider responses keyed by the request that
+// produced them, so a rerun after a crash or an unrelated config tweak
+// can skip an LLM call entirely when it already has an answer for that
+// exact prompt.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// Entry is what Cache stores per key: the provider's raw response, so a
+// hit can feed straight into schema parsing/validation exactly as if the
+// provider had just answered.
+type Entry struct {
+	Response provider.GenerationResponse `json:"response"`
+}
+
+// Cache looks up and stores Entry values by Key. Implementations must be
+// safe for concurrent use, since a Generator's worker pool may hit the
+// same key from multiple goroutines (e.g. KSamples > 1 re-requesting an
+// identical prompt). Get returns (nil, false, nil) on a miss.
+type Cache interface {
+	Get(ctx context.Context, key string) (*Entry, bool, error)
+	Put(ctx context.Context, key string, entry *Entry) error
+	Close() error
+}
+
+// Key returns the hex-encoded SHA-256 digest identifying a generation
+// request: two requests that agree on every field here are expected to
+// produce the same response, so a cached entry can stand in for a fresh
+// provider call. seed is nil for "no fixed seed", which is itself part
+// of the identity (a run without a seed must never hit a cache entry
+// written by one that pinned it).
+func Key(providerName, model, systemPrompt, prompt string, temperature, topP float64, maxTokens int, seed *int64) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%g\x00%g\x00%d\x00",
+		providerName, model, systemPrompt, prompt, temperature, topP, maxTokens)
+	if seed != nil {
+		fmt.Fprintf(h, "%d", *seed)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
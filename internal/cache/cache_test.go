@@ -0,0 +1,33 @@
+package cache
+
+import "testing"
+
+func TestKey_DeterministicForIdenticalInputs(t *testing.T) {
+	seed := int64(42)
+	a := Key("openai", "gpt-4o", "be terse", "write a haiku", 0.7, 0.9, 256, &seed)
+	b := Key("openai", "gpt-4o", "be terse", "write a haiku", 0.7, 0.9, 256, &seed)
+	if a != b {
+		t.Fatalf("Key() not deterministic: %q != %q", a, b)
+	}
+}
+
+func TestKey_DiffersOnEachField(t *testing.T) {
+	seed := int64(42)
+	base := Key("openai", "gpt-4o", "be terse", "write a haiku", 0.7, 0.9, 256, &seed)
+
+	variants := map[string]string{
+		"provider":     Key("anthropic", "gpt-4o", "be terse", "write a haiku", 0.7, 0.9, 256, &seed),
+		"model":        Key("openai", "claude-3", "be terse", "write a haiku", 0.7, 0.9, 256, &seed),
+		"systemPrompt": Key("openai", "gpt-4o", "be verbose", "write a haiku", 0.7, 0.9, 256, &seed),
+		"prompt":       Key("openai", "gpt-4o", "be terse", "write a limerick", 0.7, 0.9, 256, &seed),
+		"temperature":  Key("openai", "gpt-4o", "be terse", "write a haiku", 0.2, 0.9, 256, &seed),
+		"topP":         Key("openai", "gpt-4o", "be terse", "write a haiku", 0.7, 0.5, 256, &seed),
+		"maxTokens":    Key("openai", "gpt-4o", "be terse", "write a haiku", 0.7, 0.9, 64, &seed),
+		"seed":         Key("openai", "gpt-4o", "be terse", "write a haiku", 0.7, 0.9, 256, nil),
+	}
+	for field, variant := range variants {
+		if variant == base {
+			t.Errorf("Key() unchanged when varying %s", field)
+		}
+	}
+}
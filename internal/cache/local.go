@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var cacheBucket = []byte("cache")
+
+// LocalCache is a BoltDB-backed Cache stored as a single file, for
+// single-machine runs that don't need a cache shared across processes.
+type LocalCache struct {
+	db *bolt.DB
+}
+
+// NewLocalCache opens (creating if necessary) a BoltDB file at path as a
+// Cache backend.
+func NewLocalCache(path string) (*LocalCache, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local cache %s: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(cacheBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize local cache %s: %w", path, err)
+	}
+	return &LocalCache{db: db}, nil
+}
+
+// Get implements Cache.
+func (c *LocalCache) Get(ctx context.Context, key string) (*Entry, bool, error) {
+	var entry *Entry
+	err := c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(cacheBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		entry = &Entry{}
+		return json.Unmarshal(v, entry)
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache key %s: %w", key, err)
+	}
+	return entry, entry != nil, nil
+}
+
+// Put implements Cache.
+func (c *LocalCache) Put(ctx context.Context, key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cacheBucket).Put([]byte(key), data)
+	})
+}
+
+// Close implements Cache.
+func (c *LocalCache) Close() error {
+	return c.db.Close()
+}
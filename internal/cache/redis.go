@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a Redis-backed Cache, for sharing cached responses
+// across multiple machines or processes running the same generation.
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisCache connects to the Redis instance named by url (e.g.
+// "redis://localhost:6379/0") as a Cache backend. ttl expires every
+// entry Put writes after that long; zero keeps entries forever.
+func NewRedisCache(url string, ttl time.Duration) (*RedisCache, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis cache url: %w", err)
+	}
+	return &RedisCache{client: redis.NewClient(opts), ttl: ttl}, nil
+}
+
+// Get implements Cache.
+func (c *RedisCache) Get(ctx context.Context, key string) (*Entry, bool, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache key %s: %w", key, err)
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal cache entry: %w", err)
+	}
+	return entry, true, nil
+}
+
+// Put implements Cache.
+func (c *RedisCache) Put(ctx context.Context, key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	return c.client.Set(ctx, key, data, c.ttl).Err()
+}
+
+// Close implements Cache.
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shantoislamdev/kothaset/internal/audit"
+	"github.com/shantoislamdev/kothaset/internal/config"
+)
+
+// loadAuditSink builds the audit.Sink described by cfg.Audit, fanning
+// out to every configured backend (file, stderr, webhook) via an
+// audit.MultiSink. Returns nil, nil if auditing is disabled or no
+// backend is configured, so callers can skip SetAuditSink entirely.
+func loadAuditSink(cfg *config.Config, secrets *config.SecretsConfig) (audit.Sink, error) {
+	ac := cfg.Audit
+	if !ac.Enabled {
+		return nil, nil
+	}
+
+	var sinks []audit.Sink
+
+	if ac.File != "" {
+		if dir := filepath.Dir(ac.File); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+			}
+		}
+		sink, err := audit.NewJSONLSink(ac.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log file %s: %w", ac.File, err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if ac.Stderr {
+		sinks = append(sinks, audit.NewStderrSink())
+	}
+
+	if ac.Webhook != nil && ac.Webhook.URL != "" {
+		sinks = append(sinks, audit.NewWebhookSink(ac.Webhook.URL, secrets.AuditWebhookSecret, ac.Webhook.Timeout.Duration))
+	}
+
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return audit.NewMultiSink(sinks...), nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shantoislamdev/kothaset/internal/cache"
+	"github.com/shantoislamdev/kothaset/internal/config"
+)
+
+// loadCache builds the cache.Cache described by cfg.Cache, if enabled.
+// cacheDir is the run's resolved cache directory (the same one used for
+// checkpoints and the dedup index), used as the local backend's base
+// directory. Returns nil, nil if caching is disabled, so callers can
+// skip SetCache entirely.
+func loadCache(cfg *config.Config, cacheDir string) (cache.Cache, error) {
+	cc := cfg.Cache
+	if !cc.Enabled {
+		return nil, nil
+	}
+
+	switch cc.Backend {
+	case "redis":
+		if cfg.Global.CacheRedisURL == "" {
+			return nil, fmt.Errorf("cache backend \"redis\" requires global.cache_redis_url")
+		}
+		c, err := cache.NewRedisCache(cfg.Global.CacheRedisURL, cc.TTL.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to redis cache: %w", err)
+		}
+		return c, nil
+	case "", "local":
+		if cc.TTL.Duration > 0 {
+			fmt.Fprintln(os.Stderr, "Warning: cache.ttl has no effect on the local backend; entries are kept until the cache file is removed")
+		}
+		dir := filepath.Join(cacheDir, "cache")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+		}
+		c, err := cache.NewLocalCache(filepath.Join(dir, "cache.db"))
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	default:
+		return nil, fmt.Errorf("unsupported cache backend: %s (supported: local, redis)", cc.Backend)
+	}
+}
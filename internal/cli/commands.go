@@ -1,15 +1,16 @@
 package cli
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/shantoislamdev/kothaset/internal/metrics"
 	"github.com/shantoislamdev/kothaset/internal/provider"
 	"github.com/shantoislamdev/kothaset/internal/schema"
 	"github.com/spf13/cobra"
@@ -110,6 +111,12 @@ var validateSchemaCmd = &cobra.Command{
 	},
 }
 
+var (
+	validateStrict bool
+	validateSample int
+	validateReport string
+)
+
 var validateDatasetCmd = &cobra.Command{
 	Use:   "dataset <path>",
 	Short: "Validate an existing dataset",
@@ -117,29 +124,42 @@ var validateDatasetCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filePath := args[0]
 
-		// Check file exists
 		info, err := os.Stat(filePath)
 		if err != nil {
 			return fmt.Errorf("cannot access file: %w", err)
 		}
 
-		// Detect format from extension
 		format := detectFormat(filePath)
-		if format != "jsonl" {
-			return fmt.Errorf("unsupported format: %s (only .jsonl is currently supported)", filePath)
+		if format == "" {
+			return fmt.Errorf("unsupported format: %s (supported: .jsonl, .parquet, .json, huggingface directory)", filePath)
 		}
 
-		fmt.Printf("Validating dataset: %s\n", filePath)
-		fmt.Printf("  Format: %s\n", format)
-		fmt.Printf("  Size:   %d bytes\n", info.Size())
+		var sch schema.Schema
+		if cfg != nil && cfg.Global.Schema != "" {
+			sch, _ = schema.Get(cfg.Global.Schema)
+		}
 
-		// Read and validate based on format
-		var rowCount int
-		var parseErr error
+		if validateReport != "json" {
+			fmt.Printf("Validating dataset: %s\n", filePath)
+			fmt.Printf("  Format: %s\n", format)
+			if info.IsDir() {
+				fmt.Printf("  Kind:   directory\n")
+			} else {
+				fmt.Printf("  Size:   %d bytes\n", info.Size())
+			}
+		}
 
+		var result *datasetValidationResult
+		var parseErr error
 		switch format {
 		case "jsonl":
-			rowCount, parseErr = validateJSONL(filePath)
+			result, parseErr = validateRows(jsonlRowSource(filePath), sch, validateSample)
+		case "json":
+			result, parseErr = validateRows(jsonArrayRowSource(filePath), sch, validateSample)
+		case "parquet":
+			result, parseErr = validateRows(parquetRowSource(filePath), sch, validateSample)
+		case "huggingface":
+			result, parseErr = validateHuggingFaceDataset(filePath, sch, validateSample)
 		}
 
 		if parseErr != nil {
@@ -147,16 +167,39 @@ var validateDatasetCmd = &cobra.Command{
 			return parseErr
 		}
 
-		fmt.Printf("✓ Valid dataset\n")
-		fmt.Printf("  Rows: %d\n", rowCount)
+		if validateReport == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(result); err != nil {
+				return err
+			}
+		} else {
+			printValidationResult(result)
+		}
+
+		if validateStrict && result.FailedRows > 0 {
+			return fmt.Errorf("%d of %d rows failed validation", result.FailedRows, result.TotalRows)
+		}
 		return nil
 	},
 }
 
-// detectFormat returns the format string based on file extension
+// detectFormat returns the format string based on file extension, or
+// "huggingface" for a directory containing a HuggingFace dataset_info.json.
 func detectFormat(path string) string {
-	if hasExtension(path, ".jsonl") {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		if _, err := os.Stat(filepath.Join(path, "dataset_info.json")); err == nil {
+			return "huggingface"
+		}
+		return ""
+	}
+	switch {
+	case hasExtension(path, ".jsonl"):
 		return "jsonl"
+	case hasExtension(path, ".parquet"):
+		return "parquet"
+	case hasExtension(path, ".json"):
+		return "json"
 	}
 	return ""
 }
@@ -166,41 +209,14 @@ func hasExtension(path, ext string) bool {
 	return len(path) > len(ext) && strings.EqualFold(path[len(path)-len(ext):], ext)
 }
 
-// validateJSONL validates a JSONL file and returns row count
-func validateJSONL(path string) (int, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return 0, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	// Allow long lines (up to 10MB per line for large JSON objects)
-	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
-	count := 0
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		var obj map[string]any
-		if err := json.Unmarshal([]byte(line), &obj); err != nil {
-			return count, fmt.Errorf("line %d: invalid JSON: %w", lineNum, err)
-		}
-		count++
-	}
-	if err := scanner.Err(); err != nil {
-		return count, fmt.Errorf("read error: %w", err)
-	}
-	return count, nil
-}
-
 func init() {
 	validateCmd.AddCommand(validateConfigCmd)
 	validateCmd.AddCommand(validateSchemaCmd)
 	validateCmd.AddCommand(validateDatasetCmd)
+
+	validateDatasetCmd.Flags().BoolVar(&validateStrict, "strict", false, "exit non-zero if any row fails validation")
+	validateDatasetCmd.Flags().IntVar(&validateSample, "sample", 0, "reservoir-sample N rows instead of validating every row (0 = validate all)")
+	validateDatasetCmd.Flags().StringVar(&validateReport, "report", "text", "output format for the validation report: text or json")
 }
 
 // Schema command
@@ -274,9 +290,49 @@ var schemaShowCmd = &cobra.Command{
 	},
 }
 
+var schemaExportOutput string
+
+var schemaExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a schema's JSON Schema document",
+	Long: `Export a Draft-07 JSON Schema document describing a schema's sample
+shape - the same contract the generator enforces via ValidateSample - so
+external tools (dataset viewers, HF datasets feature inference, third-party
+validators) can validate a dataset against it without depending on KothaSet.
+
+Writes to stdout by default; use --output to write to a file instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemaName := args[0]
+
+		sch, err := schema.Get(schemaName)
+		if err != nil {
+			return fmt.Errorf("schema not found: %s\nRun 'kothaset schema list' to see available schemas", schemaName)
+		}
+
+		doc, err := sch.JSONSchema()
+		if err != nil {
+			return fmt.Errorf("failed to build JSON Schema for %s: %w", schemaName, err)
+		}
+
+		if schemaExportOutput == "" {
+			fmt.Println(string(doc))
+			return nil
+		}
+		if err := os.WriteFile(schemaExportOutput, doc, 0644); err != nil {
+			return fmt.Errorf("failed to write JSON Schema to %s: %w", schemaExportOutput, err)
+		}
+		fmt.Printf("✓ Wrote JSON Schema for %s to %s\n", schemaName, schemaExportOutput)
+		return nil
+	},
+}
+
 func init() {
 	schemaCmd.AddCommand(schemaListCmd)
 	schemaCmd.AddCommand(schemaShowCmd)
+	schemaCmd.AddCommand(schemaExportCmd)
+
+	schemaExportCmd.Flags().StringVar(&schemaExportOutput, "output", "", "file path to write the JSON Schema to (default: stdout)")
 }
 
 // Provider command
@@ -326,13 +382,16 @@ var providerTestCmd = &cobra.Command{
 						timeout = p.Timeout.Duration
 					}
 					providerCfg = &provider.Config{
-						Name:       p.Name,
-						Type:       p.Type,
-						BaseURL:    p.BaseURL,
-						APIKey:     p.APIKey,
-						MaxRetries: p.MaxRetries,
-						Timeout:    timeout,
-						Headers:    p.Headers,
+						Name:           p.Name,
+						Type:           p.Type,
+						BaseURL:        p.BaseURL,
+						APIKey:         string(p.APIKey),
+						MaxRetries:     p.MaxRetries,
+						Timeout:        timeout,
+						Headers:        p.Headers,
+						Command:        p.Command,
+						Socket:         p.Socket,
+						SystemRoleMode: provider.SystemRoleMode(p.SystemRoleMode),
 					}
 					break
 				}
@@ -360,11 +419,15 @@ var providerTestCmd = &cobra.Command{
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		stopTimer := metrics.Timer(metrics.ProviderRequestDuration.WithLabelValues(providerName, "health_check"))
 		start := time.Now()
 		if err := p.HealthCheck(ctx); err != nil {
+			stopTimer()
+			metrics.ProviderErrors.WithLabelValues(providerName, "terminal").Inc()
 			fmt.Printf("✗ Connection failed: %v\n", err)
 			return err
 		}
+		stopTimer()
 		elapsed := time.Since(start)
 
 		// Success output
@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shantoislamdev/kothaset/internal/generator"
+	"github.com/shantoislamdev/kothaset/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// maxDoctorIssues bounds how many individual issues are kept in the
+// report unless --verbose is set; the per-kind counts in doctorReport
+// still reflect every issue found.
+const maxDoctorIssues = 20
+
+// doctorReport is the machine-readable summary for `doctor --report json`,
+// and also drives the human-readable text report.
+type doctorReport struct {
+	Path          string        `json:"path"`
+	Schema        string        `json:"schema,omitempty"`
+	TotalLines    int           `json:"total_lines"`
+	ValidRows     int           `json:"valid_rows"`
+	ParseErrors   int           `json:"parse_errors"`
+	SchemaErrors  int           `json:"schema_errors"`
+	DuplicateRows int           `json:"duplicate_rows"`
+	Issues        []doctorIssue `json:"issues,omitempty"`
+
+	ProviderUsage map[string]int          `json:"provider_usage,omitempty"`
+	Checkpoint    *doctorCheckpointReport `json:"checkpoint,omitempty"`
+}
+
+type doctorIssue struct {
+	Line    int    `json:"line"`
+	Kind    string `json:"kind"` // parse_error, schema_error, duplicate
+	Message string `json:"message"`
+}
+
+// doctorCheckpointReport compares a loaded Checkpoint against what was
+// actually found in the output file. Sample.ID and SampleMetadata never
+// reach the on-disk row (output.Writer implementations serialize only
+// Sample.Fields), so this can't cross-reference per-row provider/model or
+// detect an orphan checkpoint ID against an output ID the way a richer
+// on-disk format could; it's scoped to what the two files actually carry:
+// aggregate counts and the generation Config.
+type doctorCheckpointReport struct {
+	Path            string `json:"path"`
+	Schema          string `json:"schema"`
+	SchemaMatches   bool   `json:"schema_matches"`
+	Completed       int    `json:"completed"`
+	OutputRows      int    `json:"output_rows"`
+	RowCountMatches bool   `json:"row_count_matches"`
+	TokensUsed      int    `json:"tokens_used"`
+	InputFile       string `json:"input_file,omitempty"`
+	InputFileFound  bool   `json:"input_file_found"`
+}
+
+var (
+	doctorSchemaName  string
+	doctorCheckpoint  string
+	doctorMaxErrors   int
+	doctorReportStyle string
+	doctorVerbose     bool
+	doctorFix         bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor <path>",
+	Short: "Audit a generated dataset and its checkpoint for structural problems",
+	Long: `Re-reads a JSONL dataset produced by 'generate' and reports structural
+problems: JSON parse/UTF-8 errors, rows that fail schema validation,
+duplicate rows, and (when --checkpoint is given) whether the checkpoint's
+Config and counters still line up with the output file. Exits non-zero
+once parse and schema errors together exceed --max-errors.
+
+Use --fix to rewrite the output skipping malformed lines and regenerate a
+checkpoint consistent with what's kept.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot access file: %w", err)
+		}
+
+		schemaName := doctorSchemaName
+		if schemaName == "" && cfg != nil {
+			schemaName = cfg.Global.Schema
+		}
+		var sch schema.Schema
+		if schemaName != "" {
+			s, err := schema.Get(schemaName)
+			if err != nil {
+				return fmt.Errorf("schema not found: %s\nRun 'kothaset schema list' to see available schemas", schemaName)
+			}
+			sch = s
+		}
+
+		report, kept, err := inspectJSONL(path, sch)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		report.Path = path
+		report.Schema = schemaName
+
+		var cp *generator.Checkpoint
+		if doctorCheckpoint != "" {
+			cp, err = generator.LoadCheckpoint(doctorCheckpoint)
+			if err != nil {
+				return fmt.Errorf("failed to load checkpoint: %w", err)
+			}
+			report.ProviderUsage = cp.ProviderUsage
+			report.Checkpoint = compareCheckpoint(cp, doctorCheckpoint, report.ValidRows)
+		}
+
+		if doctorReportStyle == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			printDoctorReport(report)
+		}
+
+		if doctorFix {
+			if err := fixJSONL(path, kept); err != nil {
+				return fmt.Errorf("--fix failed: %w", err)
+			}
+			if cp != nil {
+				newCp := &generator.Checkpoint{
+					Timestamp:     time.Now(),
+					SchemaVersion: 1,
+					Config:        cp.Config,
+					Completed:     len(kept),
+					TokensUsed:    cp.TokensUsed,
+					ProviderUsage: report.ProviderUsage,
+				}
+				if err := generator.SaveCheckpoint(newCp, doctorCheckpoint); err != nil {
+					return fmt.Errorf("--fix failed to regenerate checkpoint: %w", err)
+				}
+			}
+			fmt.Printf("Rewrote %s: kept %d of %d lines\n", path, len(kept), report.TotalLines)
+		}
+
+		totalErrors := report.ParseErrors + report.SchemaErrors
+		if totalErrors > doctorMaxErrors {
+			return fmt.Errorf("%d error(s) exceed --max-errors=%d", totalErrors, doctorMaxErrors)
+		}
+		return nil
+	},
+}
+
+// inspectJSONL scans path a line at a time, tolerating malformed lines
+// instead of aborting on the first one (unlike jsonlRowSource, which
+// exists for validate's all-or-nothing reads). kept returns the raw bytes
+// of every line that parsed as JSON, for --fix to rewrite.
+func inspectJSONL(path string, sch schema.Schema) (*doctorReport, [][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	report := &doctorReport{}
+	seen := make(map[string]int) // canonical row JSON -> first line number
+	var kept [][]byte
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		report.TotalLines++
+
+		if !utf8.Valid(line) {
+			report.ParseErrors++
+			addDoctorIssue(report, lineNum, "parse_error", "invalid UTF-8")
+			continue
+		}
+
+		var row map[string]any
+		if err := json.Unmarshal(line, &row); err != nil {
+			report.ParseErrors++
+			addDoctorIssue(report, lineNum, "parse_error", err.Error())
+			continue
+		}
+
+		canonical, err := json.Marshal(row)
+		if err == nil {
+			if first, dup := seen[string(canonical)]; dup {
+				report.DuplicateRows++
+				addDoctorIssue(report, lineNum, "duplicate", fmt.Sprintf("duplicate of line %d", first))
+			} else {
+				seen[string(canonical)] = lineNum
+			}
+		}
+
+		if sch != nil {
+			if err := sch.ValidateSample(&schema.Sample{Fields: row}); err != nil {
+				report.SchemaErrors++
+				addDoctorIssue(report, lineNum, "schema_error", err.Error())
+				continue
+			}
+		}
+
+		report.ValidRows++
+		kept = append(kept, append([]byte(nil), line...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return report, kept, nil
+}
+
+func addDoctorIssue(report *doctorReport, line int, kind, message string) {
+	if doctorVerbose || len(report.Issues) < maxDoctorIssues {
+		report.Issues = append(report.Issues, doctorIssue{Line: line, Kind: kind, Message: message})
+	}
+}
+
+// compareCheckpoint reports how cp's recorded Config and counters line up
+// with what inspectJSONL actually found in the output. InputFile is
+// checked for existence only: Config doesn't store a hash of it, so
+// doctor can't detect drift in its contents, only that the path it
+// recorded is gone.
+func compareCheckpoint(cp *generator.Checkpoint, path string, outputRows int) *doctorCheckpointReport {
+	r := &doctorCheckpointReport{
+		Path:            path,
+		Schema:          cp.Config.Schema,
+		Completed:       cp.Completed,
+		OutputRows:      outputRows,
+		RowCountMatches: cp.Completed == outputRows,
+		TokensUsed:      cp.TokensUsed,
+		InputFile:       cp.Config.InputFile,
+	}
+	if schemaName := doctorSchemaName; schemaName != "" {
+		r.SchemaMatches = schemaName == cp.Config.Schema
+	} else {
+		r.SchemaMatches = true
+	}
+	if cp.Config.InputFile != "" {
+		if _, err := os.Stat(cp.Config.InputFile); err == nil {
+			r.InputFileFound = true
+		}
+	}
+	return r
+}
+
+func printDoctorReport(r *doctorReport) {
+	if r.ParseErrors == 0 && r.SchemaErrors == 0 {
+		fmt.Println("✓ No structural problems found")
+	} else {
+		fmt.Printf("✗ %d parse error(s), %d schema error(s)\n", r.ParseErrors, r.SchemaErrors)
+	}
+	fmt.Printf("  Lines:      %d\n", r.TotalLines)
+	fmt.Printf("  Valid rows: %d\n", r.ValidRows)
+	if r.DuplicateRows > 0 {
+		fmt.Printf("  Duplicates: %d\n", r.DuplicateRows)
+	}
+	if len(r.Issues) > 0 {
+		fmt.Printf("  Issues (first %d):\n", len(r.Issues))
+		for _, issue := range r.Issues {
+			fmt.Printf("    line %d [%s]: %s\n", issue.Line, issue.Kind, issue.Message)
+		}
+	}
+	if len(r.ProviderUsage) > 0 {
+		fmt.Println("  Token usage by provider:")
+		for name, count := range r.ProviderUsage {
+			fmt.Printf("    %-20s %d\n", name, count)
+		}
+	}
+	if r.Checkpoint != nil {
+		c := r.Checkpoint
+		fmt.Printf("  Checkpoint: %s\n", c.Path)
+		fmt.Printf("    Schema:          %s (matches: %v)\n", c.Schema, c.SchemaMatches)
+		fmt.Printf("    Completed:       %d (output rows: %d, matches: %v)\n", c.Completed, c.OutputRows, c.RowCountMatches)
+		fmt.Printf("    Tokens used:     %d\n", c.TokensUsed)
+		if c.InputFile != "" {
+			fmt.Printf("    Input file:      %s (found: %v)\n", c.InputFile, c.InputFileFound)
+		}
+	}
+}
+
+// fixJSONL rewrites path to contain exactly the kept lines, atomically.
+func fixJSONL(path string, kept [][]byte) error {
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, line := range kept {
+		if _, err := w.Write(line); err != nil {
+			f.Close()
+			return err
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorSchemaName, "schema", "", "schema to validate rows against (default: config's global schema)")
+	doctorCmd.Flags().StringVar(&doctorCheckpoint, "checkpoint", "", "checkpoint file to cross-check against the output (default: skip checkpoint checks)")
+	doctorCmd.Flags().IntVar(&doctorMaxErrors, "max-errors", 0, "exit non-zero once parse+schema errors exceed this count")
+	doctorCmd.Flags().StringVar(&doctorReportStyle, "report", "text", "output format for the report: text or json")
+	doctorCmd.Flags().BoolVar(&doctorVerbose, "verbose", false, "report every issue instead of only the first 20")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "rewrite the output skipping malformed lines and regenerate the checkpoint")
+}
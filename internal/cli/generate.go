@@ -11,10 +11,12 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
 	"github.com/shantoislamdev/kothaset/internal/generator"
+	"github.com/shantoislamdev/kothaset/internal/logging"
 	"github.com/shantoislamdev/kothaset/internal/output"
 	"github.com/shantoislamdev/kothaset/internal/provider"
 	"github.com/shantoislamdev/kothaset/internal/schema"
@@ -45,21 +47,47 @@ Examples:
 
 var (
 	// Generate command flags
-	genSchema       string
-	genProvider     string
-	genOutput       string
-	genFormat       string
-	genCount        int
-	genWorkers      int
-	genSeed         string
-	genInputFile    string
-	genResume       string
-	genDryRun       bool
-	genModel        string
-	genTemp         float64
-	genMaxTokens    int
-	genSystemPrompt string
-	genTimeout      string
+	genSchema        string
+	genProvider      string
+	genOutput        string
+	genFormat        string
+	genCompression   string
+	genCount         int
+	genWorkers       int
+	genSeed          string
+	genInputFile     string
+	genResume        string
+	genDryRun        bool
+	genModel         string
+	genTemp          float64
+	genMaxTokens     int
+	genSystemPrompt  string
+	genTimeout       string
+	genWatch         bool
+	genWatchPoll     bool
+	genStream        bool
+	genStreamWorkers int
+	genProviders     string
+	genFallback      string
+	genProviderCool  time.Duration
+	genRateLimitRPM  int
+	genRateLimitTPM  int
+	genLogFormat     string
+	genLogFile       string
+	genLogSyslog     string
+	genKSamples      int
+	genJudgeProvider string
+	genJudgeMinScore float64
+	genDedup         bool
+	genDedupThresh   float64
+	genDedupHashes   int
+	genDedupBands    int
+	genDedupOnDup    string
+	genBudgetMaxUSD  float64
+	genBudgetPrices  []string
+	genBatchMode     bool
+	genBatchSize     int
+	genBatchMaxBytes int64
 )
 
 func init() {
@@ -71,9 +99,15 @@ func init() {
 	generateCmd.Flags().StringVarP(&genSchema, "schema", "s", "", "dataset schema (default: from config)")
 	generateCmd.Flags().StringVarP(&genProvider, "provider", "p", "", "LLM provider (default: from config)")
 	generateCmd.Flags().StringVarP(&genModel, "model", "m", "", "model to use (default: from config)")
+	generateCmd.Flags().StringVar(&genProviders, "providers", "", "comma-separated providers to route across, e.g. 'openai=3,anthropic=1,local' (weight optional, defaults to 1; overrides --provider)")
+	generateCmd.Flags().StringVar(&genFallback, "fallback", "", "routing policy across --providers: failover, round-robin, or weighted (default: failover)")
+	generateCmd.Flags().DurationVar(&genProviderCool, "provider-cooldown", 0, "how long a --providers entry that fails HealthCheck is pulled out of rotation (0 disables cooldown)")
+	generateCmd.Flags().IntVar(&genRateLimitRPM, "rate-limit-rpm", 0, "requests-per-minute cap applied to every provider, overriding .secrets.yaml (0 = use the secrets file)")
+	generateCmd.Flags().IntVar(&genRateLimitTPM, "rate-limit-tpm", 0, "tokens-per-minute cap applied to every provider, overriding .secrets.yaml (0 = use the secrets file)")
 
 	// Output format
 	generateCmd.Flags().StringVarP(&genFormat, "format", "f", "", "output format (jsonl)")
+	generateCmd.Flags().StringVar(&genCompression, "compression", "", "force a streaming codec for JSONL output (gzip, zstd, xz), overriding what --output's extension implies")
 
 	// Generation parameters
 	generateCmd.Flags().Float64Var(&genTemp, "temperature", 0.7, "sampling temperature")
@@ -81,8 +115,33 @@ func init() {
 	generateCmd.Flags().StringVar(&genSystemPrompt, "system-prompt", "", "custom system prompt")
 	generateCmd.Flags().StringVar(&genTimeout, "timeout", "", "maximum total generation time (e.g. '30m', '2h')")
 
+	// Self-consistency and LLM-as-judge quality filtering
+	generateCmd.Flags().IntVar(&genKSamples, "k-samples", 1, "generate this many candidates per sample and keep one via self-consistency (1 disables it)")
+	generateCmd.Flags().StringVar(&genJudgeProvider, "judge-provider", "", "name of a --providers entry to use as an LLM judge that scores each sample 1-5 (empty disables judging)")
+	generateCmd.Flags().Float64Var(&genJudgeMinScore, "judge-min-score", 0, "minimum average judge score (1-5) a sample must meet to be kept; requires --judge-provider")
+
+	// Near-duplicate filtering
+	generateCmd.Flags().BoolVar(&genDedup, "dedup", false, "drop near-duplicate samples using a MinHash/LSH index persisted alongside the checkpoint")
+	generateCmd.Flags().Float64Var(&genDedupThresh, "dedup-threshold", 0.8, "minimum estimated Jaccard similarity for a sample to count as a near-duplicate")
+	generateCmd.Flags().IntVar(&genDedupHashes, "dedup-num-hashes", 48, "number of MinHash functions per signature")
+	generateCmd.Flags().IntVar(&genDedupBands, "dedup-bands", 6, "number of LSH bands a signature is split into")
+	generateCmd.Flags().StringVar(&genDedupOnDup, "dedup-on-duplicate", "skip", "what to do with a near-duplicate: skip, regenerate (retry with a fresh topic), or keep")
+
+	// Cost-aware budget
+	generateCmd.Flags().Float64Var(&genBudgetMaxUSD, "budget-max-usd", 0, "stop submitting new samples once cumulative spend reaches this many dollars (0 disables the cap)")
+	generateCmd.Flags().StringSliceVar(&genBudgetPrices, "budget-price", nil, "USD cost per 1000 tokens for a provider/model, as 'provider:model=price' (repeatable); required for --budget-max-usd to track real spend")
+
 	// Concurrency and workers
 	generateCmd.Flags().IntVarP(&genWorkers, "workers", "w", 4, "number of concurrent workers")
+	generateCmd.Flags().IntVar(&genBatchSize, "batch-size", 0, "accumulate this many completed samples before flushing them to the writer together, alongside --batch-max-bytes (0 disables batching, writing each sample immediately)")
+	generateCmd.Flags().Int64Var(&genBatchMaxBytes, "batch-max-bytes", 0, "serialized-byte budget a write flush can't exceed, alongside --batch-size (0 = 4 MiB default; only takes effect once --batch-size > 1)")
+
+	// Offline batch mode
+	generateCmd.Flags().BoolVar(&genBatchMode, "batch-mode", false, "submit all samples as a single asynchronous batch job (OpenAI Batch API, Anthropic Message Batches) instead of live concurrent requests; requires a provider that supports it")
+
+	// Streaming
+	generateCmd.Flags().BoolVar(&genStream, "stream", false, "use the provider's streaming API and print partial tokens as they arrive (falls back to a plain call if the provider doesn't support streaming)")
+	generateCmd.Flags().IntVar(&genStreamWorkers, "stream-workers", 0, "max concurrent streaming calls, separate from --workers (0 = same as --workers)")
 
 	// Reproducibility
 	generateCmd.Flags().StringVar(&genSeed, "seed", "", "random seed for reproducibility (use 'random' for client-side random seeds per request)")
@@ -93,6 +152,15 @@ func init() {
 
 	// Dry run
 	generateCmd.Flags().BoolVar(&genDryRun, "dry-run", false, "validate configuration without generating")
+
+	// Hot-reload
+	generateCmd.Flags().BoolVar(&genWatch, "watch", false, "hot-reload kothaset.yaml and .secrets.yaml during this run (rate limit and provider credential changes apply without restarting)")
+	generateCmd.Flags().BoolVar(&genWatchPoll, "watch-poll", false, "use mtime polling instead of filesystem events for --watch (for network mounts or containers where inotify is unreliable)")
+
+	// Structured logging
+	generateCmd.Flags().StringVar(&genLogFormat, "log-format", "text", "structured log rendering: text or json")
+	generateCmd.Flags().StringVar(&genLogFile, "log-file", "", "also append structured logs to this file")
+	generateCmd.Flags().StringVar(&genLogSyslog, "log-syslog", "", `also forward structured logs to syslog: "local" or "tcp://host:port"`)
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
@@ -106,9 +174,31 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if genMaxTokens < 0 {
 		return fmt.Errorf("--max-tokens must be >= 0, got %d", genMaxTokens)
 	}
+	if genKSamples < 1 {
+		return fmt.Errorf("--k-samples must be >= 1, got %d", genKSamples)
+	}
+	if genJudgeMinScore != 0 && genJudgeProvider == "" {
+		return fmt.Errorf("--judge-min-score requires --judge-provider")
+	}
+	if genDedupThresh <= 0 || genDedupThresh > 1 {
+		return fmt.Errorf("--dedup-threshold must be between 0 and 1.0, got %.2f", genDedupThresh)
+	}
+	switch genDedupOnDup {
+	case "skip", "regenerate", "keep":
+	default:
+		return fmt.Errorf("--dedup-on-duplicate must be skip, regenerate, or keep, got %q", genDedupOnDup)
+	}
+	if genBudgetMaxUSD < 0 {
+		return fmt.Errorf("--budget-max-usd must be >= 0, got %.2f", genBudgetMaxUSD)
+	}
+	budgetPrices, err := parseBudgetPrices(genBudgetPrices)
+	if err != nil {
+		return err
+	}
 
 	// Load resume checkpoint early so required values can be inferred safely.
 	var resumeCheckpoint *generator.Checkpoint
+	var runID string
 	if genResume != "" {
 		cp, err := generator.LoadCheckpoint(genResume)
 		if err != nil {
@@ -126,22 +216,34 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		if genSchema == "" {
 			genSchema = cp.Config.Schema
 		}
+		var cpProvider, cpModel string
+		if len(cp.Config.Providers) > 0 {
+			cpProvider = cp.Config.Providers[0].Name
+			cpModel = cp.Config.Providers[0].Model
+		}
 		if genProvider == "" {
-			genProvider = cp.Config.Provider
+			genProvider = cpProvider
 		}
 		if genModel == "" {
-			genModel = cp.Config.Model
+			genModel = cpModel
 		}
+		if genProviders == "" && len(cp.Config.Providers) > 1 {
+			genProviders = formatProviderSpecs(cp.Config.Providers)
+		}
+		if genFallback == "" {
+			genFallback = string(cp.Config.FallbackPolicy)
+		}
+		runID = cp.Config.RunID
 
 		// Guardrails against accidentally resuming into a different run target.
 		if cmd.Flags().Changed("schema") && cp.Config.Schema != "" && genSchema != cp.Config.Schema {
 			return fmt.Errorf("resume schema mismatch: checkpoint=%s current=%s", cp.Config.Schema, genSchema)
 		}
-		if cmd.Flags().Changed("provider") && cp.Config.Provider != "" && genProvider != cp.Config.Provider {
-			return fmt.Errorf("resume provider mismatch: checkpoint=%s current=%s", cp.Config.Provider, genProvider)
+		if cmd.Flags().Changed("provider") && cpProvider != "" && genProvider != cpProvider {
+			return fmt.Errorf("resume provider mismatch: checkpoint=%s current=%s", cpProvider, genProvider)
 		}
-		if cmd.Flags().Changed("model") && cp.Config.Model != "" && genModel != cp.Config.Model {
-			return fmt.Errorf("resume model mismatch: checkpoint=%s current=%s", cp.Config.Model, genModel)
+		if cmd.Flags().Changed("model") && cpModel != "" && genModel != cpModel {
+			return fmt.Errorf("resume model mismatch: checkpoint=%s current=%s", cpModel, genModel)
 		}
 		if cmd.Flags().Changed("input") && cp.Config.InputFile != "" && genInputFile != cp.Config.InputFile {
 			return fmt.Errorf("resume input mismatch: checkpoint=%s current=%s", cp.Config.InputFile, genInputFile)
@@ -157,6 +259,10 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if runID == "" {
+		runID = uuid.New().String()
+	}
+
 	if genInputFile == "" {
 		return fmt.Errorf("input file is required (use -i/--input or --resume with a checkpoint)")
 	}
@@ -203,6 +309,11 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("provider %q not configured in .secrets.yaml: %w", providerName, err)
 	}
 
+	// --rate-limit-rpm/--rate-limit-tpm override every provider's secrets
+	// file rate limit for this run; 0 (unset) keeps the secrets value.
+	rateLimitRPM := effectiveRPM(providerCfg.RateLimit.RequestsPerMinute)
+	rateLimitTPM := effectiveTPM(providerCfg.RateLimit.TokensPerMinute)
+
 	// Get schema name from flag or config
 	schemaName := genSchema
 	if schemaName == "" {
@@ -241,6 +352,24 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		model = cfg.Global.Model
 	}
 
+	// --providers overrides the single --provider flow with one or more
+	// providers eligible for fallover/round-robin/weighted routing; every
+	// entry shares the run's --model, since the flag's weight-only syntax
+	// has no way to carry a per-provider model.
+	var providerSpecs []generator.ProviderSpec
+	if genProviders != "" {
+		specs, err := parseProviderSpecs(genProviders, model)
+		if err != nil {
+			return err
+		}
+		providerSpecs = specs
+		providerName = specs[0].Name
+	}
+	fallbackPolicy := generator.FallbackPolicy(genFallback)
+	if fallbackPolicy == "" {
+		fallbackPolicy = generator.FallbackFailover
+	}
+
 	// Get schema
 	sch, err := schema.Get(schemaName)
 	if err != nil {
@@ -251,11 +380,18 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if genDryRun {
 		fmt.Println("âœ“ Configuration valid")
 		fmt.Printf("  Schema:      %s\n", schemaName)
-		fmt.Printf("  Provider:    %s\n", providerName)
+		if len(providerSpecs) > 1 {
+			fmt.Printf("  Providers:   %s (%s)\n", genProviders, fallbackPolicy)
+		} else {
+			fmt.Printf("  Provider:    %s\n", providerName)
+		}
 		fmt.Printf("  Model:       %s\n", model)
 		fmt.Printf("  Count:       %d\n", genCount)
 		fmt.Printf("  Output:      %s\n", outputPath)
 		fmt.Printf("  Format:      %s\n", genFormat)
+		if genCompression != "" {
+			fmt.Printf("  Compression: %s\n", genCompression)
+		}
 		fmt.Printf("  Workers:     %d\n", workers)
 		fmt.Printf("  Temperature: %.2f\n", genTemp)
 		if genInputFile != "" {
@@ -272,14 +408,17 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 
 	// Create provider config for generation
 	provCfg := &provider.Config{
-		Name:       providerCfg.Name,
-		Type:       providerCfg.Type,
-		BaseURL:    providerCfg.BaseURL,
-		APIKey:     providerCfg.APIKey,
-		Model:      model,
-		MaxRetries: providerCfg.MaxRetries,
-		Timeout:    providerCfg.Timeout.Duration,
-		RateLimit:  providerCfg.RateLimit.RequestsPerMinute,
+		Name:           providerCfg.Name,
+		Type:           providerCfg.Type,
+		BaseURL:        providerCfg.BaseURL,
+		APIKey:         string(providerCfg.APIKey),
+		Model:          model,
+		MaxRetries:     providerCfg.MaxRetries,
+		Timeout:        providerCfg.Timeout.Duration,
+		RateLimit:      rateLimitRPM,
+		Command:        providerCfg.Command,
+		Socket:         providerCfg.Socket,
+		SystemRoleMode: provider.SystemRoleMode(providerCfg.SystemRoleMode),
 	}
 	if provCfg.Timeout <= 0 && cfg.Global.Timeout.Duration > 0 {
 		provCfg.Timeout = cfg.Global.Timeout.Duration
@@ -292,6 +431,36 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 	defer provider.CloseAll()
 
+	// --providers beyond the first are instantiated here and registered on
+	// gen with AddProvider once it exists, below.
+	var extraProviders []namedProviderInstance
+	if len(providerSpecs) > 1 {
+		for _, spec := range providerSpecs[1:] {
+			p, err := newProviderInstance(spec.Name, spec.Model, cfg.Global.Timeout.Duration)
+			if err != nil {
+				return err
+			}
+			extraProviders = append(extraProviders, namedProviderInstance{name: spec.Name, prov: p})
+		}
+	}
+
+	// --judge-provider is registered the same way as an extra --providers
+	// entry unless it already names one of them, so generator.Generator can
+	// look it up by name for its judge calls.
+	judgeAlreadyRegistered := genJudgeProvider == providerName
+	for _, extra := range extraProviders {
+		if extra.name == genJudgeProvider {
+			judgeAlreadyRegistered = true
+		}
+	}
+	if genJudgeProvider != "" && !judgeAlreadyRegistered {
+		p, err := newProviderInstance(genJudgeProvider, "", cfg.Global.Timeout.Duration)
+		if err != nil {
+			return fmt.Errorf("failed to create judge provider %q: %w", genJudgeProvider, err)
+		}
+		extraProviders = append(extraProviders, namedProviderInstance{name: genJudgeProvider, prov: p})
+	}
+
 	// Context and instructions from kothaset.yaml
 	userContext := cfg.Context
 	userInstruction := strings.Join(cfg.Instructions, "\n")
@@ -315,31 +484,70 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 
 	// Build generator config
 	genCfg := generator.Config{
-		NumSamples:      genCount,
-		Schema:          schemaName,
-		OutputPath:      outputPath,
-		OutputFormat:    genFormat,
-		Provider:        providerName,
-		Model:           model,
-		SystemPrompt:    genSystemPrompt,
-		Temperature:     genTemp,
-		MaxTokens:       genMaxTokens,
-		Seed:            seedPtr,    // Fixed seed sent to AI (nil if not specified)
-		RandomSeed:      randomSeed, // When true, generates new random seed per request
-		Workers:         workers,
-		RateLimit:       providerCfg.RateLimit.RequestsPerMinute,
-		MaxRetries:      3,
-		RetryDelay:      time.Second * 2,
-		CheckpointEvery: checkpointEvery,
-		CacheDir:        cacheDir,
-		ResumeFrom:      genResume,
-		InputFile:       genInputFile,
-		UserContext:     userContext,
-		UserInstruction: userInstruction,
+		NumSamples:       genCount,
+		Schema:           schemaName,
+		OutputPath:       outputPath,
+		OutputFormat:     genFormat,
+		Compression:      genCompression,
+		Providers:        providerSpecs,
+		FallbackPolicy:   fallbackPolicy,
+		ProviderCooldown: genProviderCool,
+		SystemPrompt:     genSystemPrompt,
+		Temperature:      genTemp,
+		MaxTokens:        genMaxTokens,
+		Seed:             seedPtr,    // Fixed seed sent to AI (nil if not specified)
+		RandomSeed:       randomSeed, // When true, generates new random seed per request
+		Workers:          workers,
+		BatchSize:        genBatchSize,
+		BatchMaxBytes:    genBatchMaxBytes,
+		Streaming:        genStream,
+		StreamWorkers:    genStreamWorkers,
+		RateLimit:        rateLimitRPM,
+		TPMLimit:         rateLimitTPM,
+		MaxRetries:       3,
+		RetryDelay:       time.Second * 2,
+		CheckpointEvery:  checkpointEvery,
+		CacheDir:         cacheDir,
+		ResumeFrom:       genResume,
+		InputFile:        genInputFile,
+		UserContext:      userContext,
+		UserInstruction:  userInstruction,
+		RunID:            runID,
+		KSamples:         genKSamples,
+		Judge:            generator.JudgeConfig{Provider: genJudgeProvider, MinScore: genJudgeMinScore},
+		Dedup: generator.DedupConfig{
+			Enabled:     genDedup,
+			Threshold:   genDedupThresh,
+			NumHashes:   genDedupHashes,
+			Bands:       genDedupBands,
+			OnDuplicate: genDedupOnDup,
+		},
+		Budget: generator.BudgetConfig{
+			MaxUSD: genBudgetMaxUSD,
+			Prices: budgetPrices,
+		},
+		BatchMode: genBatchMode,
 	}
 
 	// Create generator
 	gen := generator.New(genCfg, prov, sch)
+	for _, extra := range extraProviders {
+		gen.AddProvider(extra.name, extra.prov)
+	}
+	if genStream {
+		gen.SetStreamProgress(os.Stdout)
+	}
+
+	logger, closeLogger, err := logging.New(logging.Config{
+		Format: logging.Format(genLogFormat),
+		File:   genLogFile,
+		Syslog: genLogSyslog,
+	}, runID)
+	if err != nil {
+		return fmt.Errorf("failed to set up logging: %w", err)
+	}
+	defer closeLogger()
+	gen.SetLogger(logger)
 
 	// Create and set output writer
 	writer, err := output.NewWriter(genFormat, sch)
@@ -348,6 +556,26 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 	gen.SetWriter(writer)
 
+	// Wire up the audit event stream, if configured
+	auditSink, err := loadAuditSink(cfg, secrets)
+	if err != nil {
+		return fmt.Errorf("failed to set up audit sink: %w", err)
+	}
+	if auditSink != nil {
+		gen.SetAuditSink(auditSink)
+		defer auditSink.Close()
+	}
+
+	// Wire up the prompt/response cache, if configured
+	promptCache, err := loadCache(cfg, cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to set up cache: %w", err)
+	}
+	if promptCache != nil {
+		gen.SetCache(promptCache)
+		defer promptCache.Close()
+	}
+
 	// Setup sampler from input file (mandatory)
 	// Supports both file input and inline topic input.
 	sampler, err := generator.NewSampler(genInputFile)
@@ -378,12 +606,20 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		select {
 		case <-sigCh:
 			fmt.Println("\nâš  Received interrupt, saving checkpoint...")
+			logger.Info("signal shutdown")
 			cancel()
 		case <-ctx.Done():
 			// Generation finished normally or was cancelled.
 		}
 	}()
 
+	if genWatch {
+		if err := watchGeneration(ctx, gen, genWatchPoll); err != nil {
+			return fmt.Errorf("failed to start config watcher: %w", err)
+		}
+		fmt.Println("👀 Watching kothaset.yaml and .secrets.yaml for changes")
+	}
+
 	// Create progress bar
 	bar := progressbar.NewOptions(genCount,
 		progressbar.OptionEnableColorCodes(true),
@@ -455,6 +691,150 @@ func hasParentPathTraversal(path string) bool {
 	return false
 }
 
+// namedProviderInstance pairs a live provider with the name it was
+// configured under, so runGenerate can register it on a Generator via
+// AddProvider after instantiating every --providers entry.
+type namedProviderInstance struct {
+	name string
+	prov provider.Provider
+}
+
+// newProviderInstance resolves name against .secrets.yaml and creates (or
+// reuses, via provider.GetOrCreate) a provider.Provider for it, the same
+// way the primary provider is built above.
+func newProviderInstance(name, model string, globalTimeout time.Duration) (provider.Provider, error) {
+	providerCfg, err := secrets.GetProvider(name)
+	if err != nil {
+		return nil, fmt.Errorf("provider %q not configured in .secrets.yaml: %w", name, err)
+	}
+
+	provCfg := &provider.Config{
+		Name:           providerCfg.Name,
+		Type:           providerCfg.Type,
+		BaseURL:        providerCfg.BaseURL,
+		APIKey:         string(providerCfg.APIKey),
+		Model:          model,
+		MaxRetries:     providerCfg.MaxRetries,
+		Timeout:        providerCfg.Timeout.Duration,
+		RateLimit:      effectiveRPM(providerCfg.RateLimit.RequestsPerMinute),
+		Command:        providerCfg.Command,
+		Socket:         providerCfg.Socket,
+		SystemRoleMode: provider.SystemRoleMode(providerCfg.SystemRoleMode),
+	}
+	if provCfg.Timeout <= 0 && globalTimeout > 0 {
+		provCfg.Timeout = globalTimeout
+	}
+
+	prov, err := provider.GetOrCreate(provCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider %q: %w", name, err)
+	}
+	return prov, nil
+}
+
+// effectiveRPM applies the --rate-limit-rpm override, if set, to a
+// provider's .secrets.yaml requests-per-minute.
+func effectiveRPM(secretsRPM int) int {
+	if genRateLimitRPM > 0 {
+		return genRateLimitRPM
+	}
+	return secretsRPM
+}
+
+// effectiveTPM applies the --rate-limit-tpm override, if set, to a
+// provider's .secrets.yaml tokens-per-minute.
+func effectiveTPM(secretsTPM int) int {
+	if genRateLimitTPM > 0 {
+		return genRateLimitTPM
+	}
+	return secretsTPM
+}
+
+// parseProviderSpecs parses a --providers flag value ("openai=3,anthropic=1,local")
+// into ProviderSpecs. A weight is optional and defaults to 1 (ProviderSpec's
+// own zero-value convention); model is applied to every entry, since the
+// flag's syntax carries only names and weights. Each spec's own RateLimit/
+// TPMLimit is looked up from .secrets.yaml (subject to --rate-limit-rpm/
+// --rate-limit-tpm overrides) so multi-provider routing paces every
+// provider by its own configured limit instead of one shared default.
+func parseProviderSpecs(raw, model string) ([]generator.ProviderSpec, error) {
+	var specs []generator.ProviderSpec
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightStr, hasWeight := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid --providers entry %q: missing provider name", part)
+		}
+		var weight int
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weightStr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight in --providers entry %q: %w", part, err)
+			}
+			weight = w
+		}
+
+		spec := generator.ProviderSpec{Name: name, Model: model, Weight: weight}
+		if providerCfg, err := secrets.GetProvider(name); err == nil {
+			spec.RateLimit = effectiveRPM(providerCfg.RateLimit.RequestsPerMinute)
+			spec.TPMLimit = effectiveTPM(providerCfg.RateLimit.TokensPerMinute)
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("--providers must list at least one provider")
+	}
+	return specs, nil
+}
+
+// parseBudgetPrices parses --budget-price entries of the form
+// "provider:model=price" into the nested table generator.BudgetConfig.Prices
+// expects.
+func parseBudgetPrices(raw []string) (map[string]map[string]float64, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	prices := make(map[string]map[string]float64)
+	for _, entry := range raw {
+		key, priceStr, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --budget-price entry %q: want 'provider:model=price'", entry)
+		}
+		providerName, model, ok := strings.Cut(key, ":")
+		if !ok || providerName == "" || model == "" {
+			return nil, fmt.Errorf("invalid --budget-price entry %q: want 'provider:model=price'", entry)
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(priceStr), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price in --budget-price entry %q: %w", entry, err)
+		}
+		if prices[providerName] == nil {
+			prices[providerName] = make(map[string]float64)
+		}
+		prices[providerName][model] = price
+	}
+	return prices, nil
+}
+
+// formatProviderSpecs renders specs back into --providers flag syntax, used
+// to backfill the flag when resuming a multi-provider run that didn't pass
+// --providers explicitly.
+func formatProviderSpecs(specs []generator.ProviderSpec) string {
+	parts := make([]string, len(specs))
+	for i, s := range specs {
+		if s.Weight > 0 {
+			parts[i] = fmt.Sprintf("%s=%d", s.Name, s.Weight)
+		} else {
+			parts[i] = s.Name
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
 func pathsEqual(a, b string) (bool, error) {
 	aAbs, err := filepath.Abs(a)
 	if err != nil {
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/shantoislamdev/kothaset/internal/templates"
 )
 
 var initCmd = &cobra.Command{
@@ -17,59 +19,75 @@ var initCmd = &cobra.Command{
 This command creates:
   - kothaset.yaml  (public config - commit to git)
   - .secrets.yaml  (private config - gitignored)
+  - starter input files appropriate for the chosen --template
 
 Example:
   kothaset init
+  kothaset init --template chat
+  kothaset init --list-templates
   kothaset init --force  # Overwrite existing files`,
 	RunE: runInit,
 }
 
-var initForce bool
+var (
+	initForce            bool
+	initTemplate         string
+	initListTemplates    bool
+	initTemplateURL      string
+	initTemplateChecksum string
+)
 
 func init() {
 	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite existing files")
+	initCmd.Flags().StringVar(&initTemplate, "template", "instruction", "starter kit to scaffold (see --list-templates)")
+	initCmd.Flags().BoolVar(&initListTemplates, "list-templates", false, "list available templates and exit")
+	initCmd.Flags().StringVar(&initTemplateURL, "template-url", "", "fetch a template tarball (.tar.gz) from this HTTPS URL instead of a built-in template")
+	initCmd.Flags().StringVar(&initTemplateChecksum, "template-checksum", "", "expected SHA-256 (hex) of the --template-url archive; required to use --template-url")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if initListTemplates {
+		printTemplateList()
+		return nil
+	}
+
+	if initTemplateURL != "" {
+		return runInitFromRemoteTemplate()
+	}
+
+	tmpl, err := templates.Get(initTemplate)
+	if err != nil {
+		return err
+	}
+	files, err := templates.Files(tmpl.Name)
+	if err != nil {
+		return err
+	}
+
 	// Create kothaset.yaml (public config)
 	publicPath := "kothaset.yaml"
 	if _, err := os.Stat(publicPath); err == nil && !initForce {
 		return fmt.Errorf("config file already exists: %s (use --force to overwrite)", publicPath)
 	}
-
-	publicContent := `# KothaSet Configuration
-# This file is PUBLIC - safe to commit to git
-
-version: "1.0"
-
-global:
-  provider: openai
-  schema: instruction  # Available: instruction, chat, preference, classification
-  model: gpt-5.2
-  # output_dir: ./output  # Defaults to current directory
-  concurrency: 4
-
-
-# Context: Background info or persona injected into every prompt
-context: |
-  Generate high-quality training data for an AI assistant.
-  The data should be helpful, accurate, and well-formatted.
-
-# Instructions: Specific rules and guidelines for generation
-instructions:
-  - Be creative and diverse in topics and approaches
-  - Vary the style and complexity of responses
-  - Use clear and concise language
-
-logging:
-  level: info
-  format: text
-`
-
-	if err := os.WriteFile(publicPath, []byte(publicContent), 0644); err != nil {
+	if err := os.WriteFile(publicPath, files["kothaset.yaml"], 0644); err != nil {
 		return fmt.Errorf("failed to write kothaset.yaml: %w", err)
 	}
 
+	// Create the template's starter input files (topics.txt, labels.yaml, ...)
+	for name, data := range files {
+		if name == "kothaset.yaml" {
+			continue
+		}
+		if _, err := os.Stat(name); err == nil && !initForce {
+			fmt.Printf("  (skipped %s, already exists; use --force to overwrite)\n", name)
+			continue
+		}
+		if err := os.WriteFile(name, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		fmt.Printf("✓ Created %s (from template %q)\n", name, tmpl.Name)
+	}
+
 	// Create .secrets.yaml (private config)
 	secretsPath := ".secrets.yaml"
 	secretsContent := `# KothaSet Secrets
@@ -91,6 +109,31 @@ providers:
   #   base_url: https://api.deepseek.com/v1
   #   api_key: env.DEEPSEEK_API_KEY
   #   max_retries: 3
+
+  # api_key backends, for team/CI environments where committing even an
+  # env-var reference is discouraged. Run "kothaset secrets check" to
+  # confirm which backend resolves a given reference without printing it.
+  #
+  #   file:     api_key: file:/run/secrets/openai_api_key
+  #   cmd:      api_key: "cmd:op read op://vault/openai/api_key --reveal"
+  #   keychain: api_key: keychain:kothaset/openai   # via zalando/go-keyring
+  #   vault:    api_key: vault:secret/data/kothaset#openai
+  #             # or the typed form:
+  #             # api_key:
+  #             #   source: vault
+  #             #   path: secret/data/kothaset
+  #             #   key: openai
+  #   sops:     api_key: sops:.secrets.yaml.enc#providers.openai.api_key
+
+# Example: external provider plugin binary (see provider/plugin package)
+# plugins:
+#   - name: my-provider
+#     path: ~/.kothaset/plugins/my-provider
+#     args: ["--config", "my-provider.yaml"]
+
+# Example: HMAC secret for the audit.webhook sink configured in
+# kothaset.yaml, resolved the same way a provider's api_key is.
+# audit_webhook_secret: env.KOTHASET_AUDIT_WEBHOOK_SECRET
 `
 
 	if _, err := os.Stat(secretsPath); os.IsNotExist(err) || initForce {
@@ -123,6 +166,41 @@ providers:
 	return nil
 }
 
+// printTemplateList prints every built-in template's name, schema, and
+// description for `kothaset init --list-templates`.
+func printTemplateList() {
+	fmt.Println("Available templates:")
+	for _, tmpl := range templates.List() {
+		fmt.Printf("  %-28s (schema: %-14s) %s\n", tmpl.Name, tmpl.Schema, tmpl.Description)
+	}
+}
+
+// runInitFromRemoteTemplate fetches a template tarball from
+// --template-url, verifies it against --template-checksum, and
+// extracts it into the current directory in place of a built-in
+// template. The extracted files are trusted as-is (no further
+// kothaset.yaml/force handling), since a remote template's layout is up
+// to whoever published it.
+func runInitFromRemoteTemplate() error {
+	if initTemplateChecksum == "" {
+		return fmt.Errorf("--template-url requires --template-checksum (the expected SHA-256 of the archive)")
+	}
+	if !strings.HasPrefix(initTemplateURL, "https://") {
+		return fmt.Errorf("--template-url must be an https:// URL, got %q", initTemplateURL)
+	}
+
+	if err := templates.FetchRemote(initTemplateURL, initTemplateChecksum, "."); err != nil {
+		return fmt.Errorf("failed to fetch template from %s: %w", initTemplateURL, err)
+	}
+
+	if err := handleGitignore(); err != nil {
+		fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+	}
+
+	fmt.Printf("✓ Extracted template from %s\n", initTemplateURL)
+	return nil
+}
+
 // handleGitignore checks for existing .gitignore and manages KothaSet entries
 func handleGitignore() error {
 	gitignorePath := ".gitignore"
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/shantoislamdev/kothaset/internal/config"
+	"github.com/shantoislamdev/kothaset/internal/provider"
+	"github.com/shantoislamdev/kothaset/internal/provider/plugin"
+)
+
+// init registers the "grpc" provider factory so a Type: grpc entry in
+// .secrets.yaml's providers list resolves through provider.GetOrCreate
+// like any built-in provider. It can't live in provider/registry.go's
+// NewRegistry alongside openai/anthropic/deepseek: those are in-process,
+// but grpc launches or dials an out-of-process plugin.Provider, which
+// would make provider import provider/plugin and provider/plugin import
+// provider right back.
+func init() {
+	provider.RegisterFactory("grpc", plugin.NewGRPCFactory)
+}
+
+// loadPlugins launches every plugin referenced by secrets.Plugins and
+// registers the result in the global provider registry under its
+// configured name, so later provider.GetOrCreate/provider.Get calls see
+// it exactly like a built-in openai/anthropic/deepseek provider.
+func loadPlugins(secrets *config.SecretsConfig) error {
+	for _, p := range secrets.Plugins {
+		path := p.Path
+		if path == "" {
+			path = filepath.Join(plugin.DefaultDir(), p.Name)
+		}
+
+		proxy, err := plugin.Launch(path, p.Args...)
+		if err != nil {
+			return fmt.Errorf("failed to load plugin %s: %w", p.Name, err)
+		}
+
+		if err := provider.Register(p.Name, proxy); err != nil {
+			proxy.Close()
+			return fmt.Errorf("failed to register plugin %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
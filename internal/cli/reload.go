@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/shantoislamdev/kothaset/internal/config"
+	"github.com/shantoislamdev/kothaset/internal/generator"
+	"github.com/shantoislamdev/kothaset/internal/metrics"
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// watchGeneration starts hot-reload watchers for kothaset.yaml and
+// .secrets.yaml for the lifetime of ctx, applying changes to a running
+// `generate` invocation without a restart:
+//   - kothaset.yaml: only changes safe to apply live are; concurrency,
+//     model, and output settings still require a restart, so a reload is
+//     just logged for the operator. It exists here mainly so other
+//     subscribers (rate limiter, sampler weights) have a live *Config to
+//     read from going forward.
+//   - .secrets.yaml: a changed rate limit for any configured provider
+//     rebuilds that provider's own rate limiter via SetRateLimit; any
+//     other changed field (API key, base URL, timeout, headers) hot-swaps
+//     the provider client via provider.Replace.
+//
+// Both watchers only apply a reload once it parses (and, for
+// kothaset.yaml, validates) successfully, so a bad edit is logged and
+// otherwise ignored rather than taking down the run. poll forces mtime
+// polling instead of fsnotify, for filesystems where inotify is unreliable.
+func watchGeneration(ctx context.Context, gen *generator.Generator, poll bool) error {
+	cfgWatcher, err := config.Watch(ctx, cfg, config.WatchOptions{Poll: poll})
+	if err != nil {
+		return err
+	}
+	cfgWatcher.Subscribe(func(old, next *config.Config) {
+		metrics.ConfigReloads.WithLabelValues("success").Inc()
+		printInfo("kothaset.yaml reloaded; concurrency/model/output changes apply on the next generate run")
+	})
+	go drainReloadErrors("kothaset.yaml", cfgWatcher.Errors())
+
+	secretsWatcher, err := secrets.Watch(ctx)
+	if err != nil {
+		// secrets wasn't loaded from a file (e.g. DefaultSecretsConfig
+		// when .secrets.yaml doesn't exist); hot-reload isn't available.
+		return nil
+	}
+	secretsWatcher.Subscribe(func(old, next *config.SecretsConfig) {
+		updated := swapChangedProviders(gen, old, next)
+		metrics.ConfigReloads.WithLabelValues("success").Inc()
+		printInfo(".secrets.yaml reloaded, %d provider(s) updated", updated)
+	})
+	go drainReloadErrors(".secrets.yaml", secretsWatcher.Errors())
+
+	return nil
+}
+
+// drainReloadErrors logs reload failures from a watcher's Errors channel
+// until ctx is canceled and the channel's producer goroutine exits.
+func drainReloadErrors(source string, errs <-chan error) {
+	for err := range errs {
+		metrics.ConfigReloads.WithLabelValues("error").Inc()
+		printError("%s reload failed: %v", source, err)
+	}
+}
+
+// swapChangedProviders diffs old.Providers against new.Providers. A
+// changed RateLimit rebuilds that provider's own rate limiter in the
+// router (in-flight requests already past Acquire keep going; only new
+// Acquire calls see the new limit), whether or not it's gen's active
+// provider, since every configured provider gets its own limiter. Any
+// other changed field hot-swaps the registry entry via provider.Replace.
+// It returns the number of providers updated.
+func swapChangedProviders(gen *generator.Generator, old, next *config.SecretsConfig) int {
+	if old == nil || next == nil {
+		return 0
+	}
+
+	updated := 0
+	for _, np := range next.Providers {
+		op, err := old.GetProvider(np.Name)
+		if err == nil && op.BaseURL == np.BaseURL && op.APIKey == np.APIKey &&
+			op.Timeout == np.Timeout && op.RateLimit == np.RateLimit &&
+			op.Command == np.Command && op.Socket == np.Socket &&
+			op.SystemRoleMode == np.SystemRoleMode {
+			continue
+		}
+
+		if err == nil && op.RateLimit != np.RateLimit {
+			gen.SetRateLimit(np.Name, np.RateLimit.RequestsPerMinute, np.RateLimit.TokensPerMinute)
+		}
+
+		providerCfg := &provider.Config{
+			Name:           np.Name,
+			Type:           np.Type,
+			BaseURL:        np.BaseURL,
+			APIKey:         string(np.APIKey),
+			MaxRetries:     np.MaxRetries,
+			Timeout:        np.Timeout.Duration,
+			Headers:        np.Headers,
+			Command:        np.Command,
+			Socket:         np.Socket,
+			SystemRoleMode: provider.SystemRoleMode(np.SystemRoleMode),
+		}
+		if err := provider.Replace(np.Name, providerCfg); err != nil {
+			printError("failed to hot-swap provider %s: %v", np.Name, err)
+			continue
+		}
+		updated++
+	}
+	return updated
+}
@@ -2,6 +2,8 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -9,6 +11,8 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/shantoislamdev/kothaset/internal/config"
+	"github.com/shantoislamdev/kothaset/internal/metrics"
+	"github.com/shantoislamdev/kothaset/internal/telemetry"
 )
 
 var (
@@ -18,12 +22,18 @@ var (
 	BuildDate = "unknown"
 
 	// Global config instance
-	cfg *config.Config
+	cfg     *config.Config
+	secrets *config.SecretsConfig
+
+	// secretsFile is the path initConfig loaded secrets from, so the
+	// hot-reload watcher knows what to re-read.
+	secretsFile string
 
 	// Global flags
-	cfgFile string
-	verbose bool
-	quiet   bool
+	cfgFile     string
+	verbose     bool
+	quiet       bool
+	metricsAddr string
 )
 
 // rootCmd represents the base command when called without subcommands
@@ -44,19 +54,38 @@ Features:
 Example:
   kothaset generate --schema instruction --count 1000 --output dataset.jsonl`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if metricsAddr != "" {
+			go func() {
+				if err := metrics.Serve(context.Background(), metricsAddr); err != nil {
+					printError("metrics server stopped: %v", err)
+				}
+			}()
+		}
+
 		// Skip config loading for version command
 		if cmd.Name() == "version" {
 			return nil
 		}
-		return initConfig()
+		if err := initConfig(); err != nil {
+			return err
+		}
+		if err := telemetry.Init(context.Background(), cfg.Observability); err != nil {
+			return fmt.Errorf("failed to initialize telemetry: %w", err)
+		}
+		return nil
 	},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 }
 
-// Execute runs the root command
+// Execute runs the root command, shipping any buffered traces/metrics
+// (see config.ObservabilityConfig) before returning.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if shutdownErr := telemetry.Shutdown(context.Background()); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	return err
 }
 
 func init() {
@@ -64,6 +93,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default: .kothaset.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress non-essential output")
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, "metrics-addr", "", "address to serve Prometheus /metrics on (e.g. :9090); disabled if empty")
 
 	// Bind flags to viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
@@ -73,9 +103,11 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(providerCmd)
+	rootCmd.AddCommand(secretsCmd)
 }
 
 // initConfig reads in config file and ENV variables if set
@@ -85,6 +117,20 @@ func initConfig() error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+
+	secretsFile = ".secrets.yaml"
+	if loaded, err := config.LoadSecretsConfig(secretsFile); err == nil {
+		secrets = loaded
+	} else if errors.Is(err, os.ErrNotExist) {
+		secrets = config.DefaultSecretsConfig()
+	} else {
+		return fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	if err := loadPlugins(secrets); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -107,3 +153,9 @@ func IsQuiet() bool {
 func printError(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
 }
+
+// printInfo prints a non-error status message to stderr, e.g. a successful
+// hot-reload notification. Unlike printError, it doesn't prefix "Error: ".
+func printInfo(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/shantoislamdev/kothaset/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Inspect secret references in .secrets.yaml",
+	Long:  `Resolve the secret references in .secrets.yaml and report which backend handled each one.`,
+}
+
+var secretsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Resolve every referenced secret and report which backend was used",
+	Long: `Resolve every provider's api_key reference and print the backend that
+resolved it (env, file, cmd, keychain, vault, sops, aws-sm, gcp-sm, or
+plain), without printing the resolved value itself.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		descriptions, err := config.DescribeSecrets(secretsFile)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tBACKEND\tSTATUS")
+
+		failed := 0
+		for _, d := range descriptions {
+			status := "ok"
+			if !d.OK {
+				status = "FAILED: " + d.Err.Error()
+				failed++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", d.Provider, d.Backend, status)
+		}
+		w.Flush()
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d secret(s) failed to resolve", failed, len(descriptions))
+		}
+		return nil
+	},
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsCheckCmd)
+}
@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// datasetValidationResult is the machine-readable summary for `validate
+// dataset --report json`, and also drives the human-readable text report.
+type datasetValidationResult struct {
+	TotalRows  int               `json:"total_rows"`
+	ValidRows  int               `json:"valid_rows"`
+	FailedRows int               `json:"failed_rows"`
+	Sampled    bool              `json:"sampled"`
+	ErrorKinds map[string]int    `json:"error_kinds,omitempty"`
+	Errors     []datasetRowError `json:"errors,omitempty"`
+}
+
+type datasetRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// maxReportedErrors bounds how many individual row errors are kept in the
+// report; ErrorKinds still counts every failure.
+const maxReportedErrors = 20
+
+// rowSource yields raw rows (already decoded into field maps) for
+// validation. Returning (nil, io.EOF) signals the end of the dataset.
+type rowSource func() (map[string]any, error)
+
+// validateRows drains src, optionally reservoir-sampling to at most
+// sampleSize rows, and validates each row against sch if provided.
+func validateRows(src rowSource, sch schema.Schema, sampleSize int) (*datasetValidationResult, error) {
+	result := &datasetValidationResult{ErrorKinds: map[string]int{}}
+
+	var reservoir []map[string]any
+	rowNum := 0
+
+	for {
+		row, err := src()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rowNum++
+
+		if sampleSize > 0 {
+			result.Sampled = true
+			if len(reservoir) < sampleSize {
+				reservoir = append(reservoir, row)
+			} else if n := rand.Intn(rowNum); n < sampleSize {
+				reservoir[n] = row
+			}
+			continue
+		}
+
+		validateRow(result, rowNum, row, sch)
+	}
+
+	if sampleSize > 0 {
+		for i, row := range reservoir {
+			validateRow(result, i+1, row, sch)
+		}
+	}
+
+	result.TotalRows = result.ValidRows + result.FailedRows
+	return result, nil
+}
+
+func validateRow(result *datasetValidationResult, rowNum int, row map[string]any, sch schema.Schema) {
+	if sch == nil {
+		result.ValidRows++
+		return
+	}
+	sample := &schema.Sample{Fields: row}
+	err := sch.ValidateSample(sample)
+	if err == nil {
+		result.ValidRows++
+		return
+	}
+
+	result.FailedRows++
+	// ValidateSample now accumulates every field-level failure instead of
+	// stopping at the first, so ErrorKinds counts each one rather than
+	// just the one that used to win the race to return first.
+	fieldErrs := schema.NewValidationReport(err).Errors()
+	if len(fieldErrs) == 0 {
+		result.ErrorKinds["unknown"]++
+	}
+	for _, fieldErr := range fieldErrs {
+		result.ErrorKinds[errorKind(fieldErr)]++
+	}
+	if len(result.Errors) < maxReportedErrors {
+		result.Errors = append(result.Errors, datasetRowError{Row: rowNum, Message: err.Error()})
+	}
+}
+
+// errorKind buckets a *schema.SchemaError by its Field (or "unknown") so
+// the report can show counts by error kind rather than every unique
+// message.
+func errorKind(se *schema.SchemaError) string {
+	if se != nil && se.Field != "" {
+		return se.Field
+	}
+	return "unknown"
+}
+
+func printValidationResult(result *datasetValidationResult) {
+	if result.FailedRows == 0 {
+		fmt.Println("✓ Valid dataset")
+	} else {
+		fmt.Printf("✗ %d of %d rows failed validation\n", result.FailedRows, result.TotalRows)
+	}
+	if result.Sampled {
+		fmt.Printf("  Rows:   %d (reservoir-sampled)\n", result.TotalRows)
+	} else {
+		fmt.Printf("  Rows:   %d\n", result.TotalRows)
+	}
+	if len(result.ErrorKinds) > 0 {
+		fmt.Println("  Errors by kind:")
+		for kind, count := range result.ErrorKinds {
+			fmt.Printf("    %-20s %d\n", kind, count)
+		}
+		fmt.Printf("  First %d errors:\n", len(result.Errors))
+		for _, e := range result.Errors {
+			fmt.Printf("    row %d: %s\n", e.Row, e.Message)
+		}
+	}
+}
+
+// jsonlRowSource streams a JSONL file a line at a time.
+func jsonlRowSource(path string) rowSource {
+	file, err := os.Open(path)
+	if err != nil {
+		return func() (map[string]any, error) { return nil, err }
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
+
+	return func() (map[string]any, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var obj map[string]any
+			if err := json.Unmarshal([]byte(line), &obj); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("invalid JSON: %w", err)
+			}
+			return obj, nil
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read error: %w", err)
+		}
+		return nil, io.EOF
+	}
+}
+
+// jsonArrayRowSource streams a top-level JSON array of objects using
+// json.Decoder's Token API so multi-GB files don't need to be buffered in
+// memory.
+func jsonArrayRowSource(path string) rowSource {
+	file, err := os.Open(path)
+	if err != nil {
+		return func() (map[string]any, error) { return nil, err }
+	}
+	dec := json.NewDecoder(file)
+
+	tok, err := dec.Token()
+	if err != nil {
+		file.Close()
+		return func() (map[string]any, error) { return nil, fmt.Errorf("invalid JSON: %w", err) }
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		file.Close()
+		return func() (map[string]any, error) {
+			return nil, fmt.Errorf("expected top-level JSON array, got %v", tok)
+		}
+	}
+
+	return func() (map[string]any, error) {
+		if !dec.More() {
+			file.Close()
+			return nil, io.EOF
+		}
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("invalid JSON element: %w", err)
+		}
+		return obj, nil
+	}
+}
+
+// parquetRowToMap converts a row xitongsys/parquet-go's reader decoded
+// from the file's footer schema into a field-name-keyed map. With a nil
+// schema handler (as parquetRowSource passes), the reader always
+// synthesizes an anonymous struct rather than a map - one exported field
+// per column, named by uppercasing the column's first letter - so this
+// reverses that naming to match the lowercase field names
+// internal/output.ParquetWriter wrote the column under.
+func parquetRowToMap(row reflect.Value) (map[string]any, error) {
+	if row.Kind() == reflect.Ptr {
+		row = row.Elem()
+	}
+	if row.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unexpected parquet row type %s", row.Kind())
+	}
+
+	t := row.Type()
+	obj := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if name == "" {
+			continue
+		}
+		field := row.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				obj[strings.ToLower(name[:1])+name[1:]] = nil
+				continue
+			}
+			field = field.Elem()
+		}
+		obj[strings.ToLower(name[:1])+name[1:]] = field.Interface()
+	}
+	return obj, nil
+}
+
+// parquetRowSource streams rows from a Parquet file using the schema
+// embedded in its footer, matching the rows ParquetWriter produces in
+// internal/output.
+func parquetRowSource(path string) rowSource {
+	fr, err := local.NewLocalFileReader(path)
+	if err != nil {
+		return func() (map[string]any, error) { return nil, fmt.Errorf("failed to open parquet file: %w", err) }
+	}
+
+	pr, err := reader.NewParquetReader(fr, nil, 4)
+	if err != nil {
+		fr.Close()
+		return func() (map[string]any, error) { return nil, fmt.Errorf("failed to read parquet footer: %w", err) }
+	}
+
+	total := int(pr.GetNumRows())
+	read := 0
+
+	return func() (map[string]any, error) {
+		if read >= total {
+			pr.ReadStop()
+			fr.Close()
+			return nil, io.EOF
+		}
+		rows, err := pr.ReadByNumber(1)
+		if err != nil {
+			pr.ReadStop()
+			fr.Close()
+			return nil, fmt.Errorf("failed to read parquet row: %w", err)
+		}
+		read++
+		if len(rows) == 0 {
+			pr.ReadStop()
+			fr.Close()
+			return nil, io.EOF
+		}
+		obj, err := parquetRowToMap(reflect.ValueOf(rows[0]))
+		if err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+}
+
+// validateHuggingFaceDataset validates a HuggingFace-style directory
+// dataset (dataset_info.json + sharded Parquet part files under train/) by
+// concatenating every shard's rows.
+func validateHuggingFaceDataset(dir string, sch schema.Schema, sampleSize int) (*datasetValidationResult, error) {
+	var shards []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && hasExtension(path, ".parquet") {
+			shards = append(shards, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk dataset directory: %w", err)
+	}
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("no Parquet shards found under %s", dir)
+	}
+
+	shardIdx := 0
+	var current rowSource
+	var src rowSource
+	src = func() (map[string]any, error) {
+		for {
+			if current == nil {
+				if shardIdx >= len(shards) {
+					return nil, io.EOF
+				}
+				current = parquetRowSource(shards[shardIdx])
+				shardIdx++
+			}
+			row, err := current()
+			if err == io.EOF {
+				current = nil
+				continue
+			}
+			return row, err
+		}
+	}
+	return validateRows(src, sch, sampleSize)
+}
@@ -22,8 +22,28 @@ type Config struct {
 	// Logging configuration
 	Logging LoggingConfig `yaml:"logging,omitempty" json:"logging,omitempty"`
 
+	// Audit configures the structured audit event stream emitted for
+	// every provider call and schema validation outcome during
+	// generation (see internal/audit).
+	Audit AuditConfig `yaml:"audit,omitempty" json:"audit,omitempty"`
+
+	// Cache configures the prompt/response cache that lets a rerun skip
+	// identical provider calls entirely (see internal/cache).
+	Cache CacheConfig `yaml:"cache,omitempty" json:"cache,omitempty"`
+
+	// Observability configures OpenTelemetry trace/metric export for a
+	// generation run (see internal/telemetry). Disabled by default since
+	// most runs are happy with the existing stderr warnings and
+	// Prometheus metrics (see Audit, and --metrics-addr).
+	Observability ObservabilityConfig `yaml:"observability,omitempty" json:"observability,omitempty"`
+
 	// Named profiles for quick switching (optional)
 	Profiles map[string]Profile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+
+	// path is the file this Config was loaded from, recorded so Watch can
+	// find it again; empty for a Config built without a config file (e.g.
+	// the defaults-only Config returned by Load when none is found).
+	path string
 }
 
 // GlobalConfig contains global settings
@@ -43,6 +63,11 @@ type GlobalConfig struct {
 	// CacheDir is the directory for caching (optional, defaults to .kothaset/)
 	CacheDir string `yaml:"cache_dir,omitempty" json:"cache_dir,omitempty"`
 
+	// CacheRedisURL is the Redis connection string (e.g.
+	// "redis://localhost:6379/0") used when CacheConfig.Backend is
+	// "redis". Ignored by the local backend, which uses CacheDir instead.
+	CacheRedisURL string `yaml:"cache_redis_url,omitempty" json:"cache_redis_url,omitempty"`
+
 	// Concurrency is the default number of concurrent workers
 	Concurrency int `yaml:"concurrency" json:"concurrency"`
 
@@ -52,7 +77,9 @@ type GlobalConfig struct {
 	// MaxTokens is the default max tokens per response (0 = unlimited/model default)
 	MaxTokens int `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`
 
-	// OutputFormat is the default output format (jsonl, parquet, hf)
+	// OutputFormat is the default output format (jsonl, parquet, hf).
+	// Multiple formats can be combined with a comma (e.g. "jsonl,parquet")
+	// to fan a single generation run out to several writers at once.
 	OutputFormat string `yaml:"output_format,omitempty" json:"output_format,omitempty"`
 }
 
@@ -60,6 +87,37 @@ type GlobalConfig struct {
 type SecretsConfig struct {
 	// Providers contains provider configurations with credentials
 	Providers []ProviderConfig `yaml:"providers" json:"providers"`
+
+	// Plugins lists external provider plugin binaries to launch at
+	// startup, each registering a provider under its own Name alongside
+	// the built-in openai/anthropic/deepseek providers.
+	Plugins []PluginConfig `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+
+	// AuditWebhookSecret is the HMAC signing secret for the audit webhook
+	// sink configured under audit.webhook in kothaset.yaml. Resolved the
+	// same way a provider's api_key is: a literal value, the
+	// "env.VAR_NAME" shorthand, or a "scheme:value" secret reference.
+	AuditWebhookSecret string `yaml:"audit_webhook_secret,omitempty" json:"audit_webhook_secret,omitempty"`
+
+	// path is the file this SecretsConfig was loaded from, recorded so
+	// Watch can find it again; empty for a SecretsConfig built without
+	// LoadSecretsConfig (e.g. DefaultSecretsConfig).
+	path string
+}
+
+// PluginConfig references an external provider plugin binary, launched
+// via provider/plugin.Launch and registered under Name.
+type PluginConfig struct {
+	// Name is the provider name this plugin registers as; reference it
+	// from global.provider or a profile just like a built-in provider.
+	Name string `yaml:"name" json:"name"`
+
+	// Path is the plugin executable. Leave empty to discover it by Name
+	// in provider/plugin.DefaultDir (~/.kothaset/plugins/<name>).
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// Args are extra arguments passed to the plugin binary on launch.
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
 }
 
 // ProviderConfig contains LLM provider settings (in .secrets.yaml)
@@ -73,12 +131,22 @@ type ProviderConfig struct {
 	// BaseURL is the API base URL (for custom endpoints)
 	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
 
-	// APIKey is the API key (can be a secret reference)
-	APIKey string `yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	// APIKey is the API key. Accepts a plain value, the "env.FOO" /
+	// "scheme:value" secret-reference shorthand (see resolveAPIKey), or
+	// the typed { source, path, key } mapping form.
+	APIKey APIKeyRef `yaml:"api_key,omitempty" json:"api_key,omitempty"`
 
 	// Headers are additional HTTP headers
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 
+	// Command and Socket configure a Type: grpc provider (see
+	// provider/plugin.NewGRPCFactory): Command launches a binary (plus
+	// args, shell-split like a cmd: secret reference) as a subprocess;
+	// Socket dials an already-running gRPC provider directly. Exactly one
+	// should be set.
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+	Socket  string `yaml:"socket,omitempty" json:"socket,omitempty"`
+
 	// Timeout for requests to this provider
 	Timeout Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 
@@ -87,6 +155,12 @@ type ProviderConfig struct {
 
 	// RateLimit configuration
 	RateLimit RateLimitConfig `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+
+	// SystemRoleMode configures how a Type: openai provider sends system
+	// content to models that reject the system role (OpenAI's o1 family):
+	// "auto" (default), "system", "user-prefix", "developer", or "drop".
+	// See provider.SystemRoleMode's constants. Ignored by other types.
+	SystemRoleMode string `yaml:"system_role_mode,omitempty" json:"system_role_mode,omitempty"`
 }
 
 // RateLimitConfig defines rate limiting settings
@@ -140,6 +214,82 @@ type GenerationConfig struct {
 	SystemPrompt string `yaml:"system_prompt,omitempty" json:"system_prompt,omitempty"`
 }
 
+// AuditConfig configures the structured audit event stream. Disabled by
+// default since most runs don't need a structured audit trail on top of
+// the usual progress output and checkpoints.
+type AuditConfig struct {
+	// Enabled turns on audit event emission.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// File is a JSONL path audit events are appended to (e.g.
+	// ".kothaset/audit.jsonl"). Empty disables the file sink.
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+
+	// Stderr additionally streams every audit event to stderr as NDJSON.
+	Stderr bool `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+
+	// Webhook optionally POSTs every audit event as NDJSON to an HTTP
+	// endpoint, HMAC-signed with a secret from .secrets.yaml.
+	Webhook *AuditWebhookConfig `yaml:"webhook,omitempty" json:"webhook,omitempty"`
+}
+
+// CacheConfig configures the prompt/response cache that lets a rerun
+// skip identical provider calls entirely (see internal/cache). Disabled
+// by default since most runs generate fresh samples every time.
+type CacheConfig struct {
+	// Enabled turns on cache lookups and writes.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Backend selects the storage backend: "local" (a BoltDB file under
+	// GlobalConfig.CacheDir) or "redis" (GlobalConfig.CacheRedisURL).
+	// Defaults to "local".
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// TTL expires cache entries after the given duration. Zero (the
+	// default) keeps entries forever; set this on a shared long-lived
+	// Redis backend so stale prompts don't accumulate indefinitely.
+	TTL Duration `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+}
+
+// ObservabilityConfig configures OpenTelemetry export for a generation
+// run's traces and metrics (see internal/telemetry). Disabled by default;
+// set Enabled and OTLPEndpoint to ship spans/metrics to a collector (e.g.
+// Tempo/Jaeger for traces, Prometheus via an OTLP-to-Prometheus collector
+// for metrics) without any code changes.
+type ObservabilityConfig struct {
+	// Enabled turns on span/metric emission for the run.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// OTLPEndpoint is the collector's OTLP/gRPC endpoint (e.g.
+	// "localhost:4317"). Required when Enabled is true.
+	OTLPEndpoint string `yaml:"otlp_endpoint,omitempty" json:"otlp_endpoint,omitempty"`
+
+	// OTLPHeaders are extra headers sent with every OTLP export (e.g. an
+	// API key for a hosted collector), the same "env.VAR_NAME" /
+	// "scheme:value" secret-reference shorthand as ProviderConfig.APIKey
+	// is not applied here - these are plain values, since they're read
+	// from the public kothaset.yaml rather than .secrets.yaml.
+	OTLPHeaders map[string]string `yaml:"otlp_headers,omitempty" json:"otlp_headers,omitempty"`
+
+	// SampleRate is the fraction of traces to sample, from 0 (none) to 1
+	// (all). Defaults to 1 (always sample) when unset, since generation
+	// runs are low-volume enough that full tracing rarely costs anything.
+	SampleRate float64 `yaml:"sample_rate,omitempty" json:"sample_rate,omitempty"`
+
+	// ServiceName overrides the resource's service.name attribute.
+	// Defaults to "kothaset" when unset.
+	ServiceName string `yaml:"service_name,omitempty" json:"service_name,omitempty"`
+}
+
+// AuditWebhookConfig configures the HMAC-signed webhook audit sink.
+type AuditWebhookConfig struct {
+	// URL is the endpoint every audit event is POSTed to.
+	URL string `yaml:"url" json:"url"`
+
+	// Timeout bounds each webhook POST. Defaults to 10s if zero.
+	Timeout Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
 // LoggingConfig contains logging settings
 type LoggingConfig struct {
 	// Level is the log level (debug, info, warn, error)
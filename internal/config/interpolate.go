@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// interpolationMaxDepth bounds how many "${ref:...}" hops a single
+// expression can chain through before resolution gives up, so a cycle
+// (a references b which references a) fails fast instead of recursing
+// forever.
+const interpolationMaxDepth = 16
+
+// interpolationPattern matches a single ${...} expression anywhere within
+// a string value, so "https://${env:HOST|localhost}/v1" interpolates just
+// the host portion.
+var interpolationPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// interpolateConfig walks every string field reachable from cfg and
+// expands "${...}" references in place, consul-template style. It runs as
+// a two-pass resolver: the first pass snapshots every field's raw value
+// into a dotted-path map (e.g. "global.model"), and the second pass
+// expands references against that snapshot in deterministic (sorted
+// path) order, so repeated runs of the same config don't depend on Go's
+// randomized map iteration. In addition to the existing secret schemes
+// (env, file, vault, ...), it understands "${ref:dotted.path}" to reuse
+// another already-parsed value, and a "|default" suffix on any scheme to
+// fall back to a literal when resolution fails. ProviderConfig.APIKey is
+// excluded (see apiKeyRefType): it's resolved exclusively, and cached,
+// through resolveSecrets/resolveAPIKey.
+func interpolateConfig(cfg *Config) error {
+	fields := collectStringFields(reflect.ValueOf(cfg).Elem(), "")
+
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values[f.path] = f.get()
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].path < fields[j].path })
+
+	for _, f := range fields {
+		resolved, err := renderString(f.get(), values, map[string]bool{f.path: true}, 0)
+		if err != nil {
+			return fmt.Errorf("config interpolation failed at %s: %w", f.path, err)
+		}
+		f.set(resolved)
+		values[f.path] = resolved
+	}
+	return nil
+}
+
+// stringField is a settable string leaf discovered while walking the
+// config tree, addressed by its dotted path.
+type stringField struct {
+	path string
+	get  func() string
+	set  func(string)
+}
+
+// apiKeyRefType identifies ProviderConfig.APIKey's field type, so
+// collectStringFields can skip it: resolveSecrets/resolveAPIKey own
+// resolving it exclusively, through cachedResolve, so a Vault/AWS/GCP
+// secret backend isn't hit again on every reload. If this generic walker
+// resolved it too, it would do so uncached and leave resolveAPIKey with
+// an already-plaintext value to no-op on.
+var apiKeyRefType = reflect.TypeOf(APIKeyRef(""))
+
+// collectStringFields recursively finds every string field reachable from
+// v, naming each by its yaml tag joined with dots (struct fields), its
+// index (slices), or its key (string-keyed maps).
+func collectStringFields(v reflect.Value, prefix string) []stringField {
+	var out []stringField
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() && v.Type() != apiKeyRefType {
+			out = append(out, stringField{
+				path: prefix,
+				get:  func() string { return v.String() },
+				set:  func(s string) { v.SetString(s) },
+			})
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+			name := yamlFieldName(field)
+			if name == "-" {
+				continue
+			}
+			childPrefix := name
+			if prefix != "" {
+				childPrefix = prefix + "." + name
+			}
+			out = append(out, collectStringFields(v.Field(i), childPrefix)...)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, collectStringFields(v.Index(i), fmt.Sprintf("%s.%d", prefix, i))...)
+		}
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		for _, key := range v.MapKeys() {
+			key := key
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+
+			nested := collectStringFields(elem, prefix+"."+key.String())
+			for _, nf := range nested {
+				nf := nf
+				origSet := nf.set
+				nf.set = func(s string) {
+					origSet(s)
+					v.SetMapIndex(key, elem)
+				}
+				out = append(out, nf)
+			}
+		}
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			out = append(out, collectStringFields(v.Elem(), prefix)...)
+		}
+	}
+
+	return out
+}
+
+// yamlFieldName returns the name a struct field is addressed by in a
+// dotted interpolation path, taken from its yaml tag (falling back to the
+// lower-cased Go field name if untagged).
+func yamlFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("yaml")
+	if tag == "" {
+		return strings.ToLower(field.Name)
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// renderString expands every ${...} expression found in raw against
+// values. visited holds the dotted paths already on the current
+// resolution chain, so a ${ref:...} cycle is reported instead of
+// recursing forever; depth counts the ${ref:...} hops taken so far.
+func renderString(raw string, values map[string]string, visited map[string]bool, depth int) (string, error) {
+	if depth > interpolationMaxDepth {
+		return "", fmt.Errorf("interpolation depth exceeded %d hops (possible cycle)", interpolationMaxDepth)
+	}
+
+	var resolveErr error
+	result := interpolationPattern.ReplaceAllStringFunc(raw, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		resolved, err := resolveExpr(match[2:len(match)-1], values, visited, depth)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return resolved
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return result, nil
+}
+
+// resolveExpr resolves a single "scheme:value" or "scheme:value|default"
+// expression (the part between ${ and }).
+func resolveExpr(expr string, values map[string]string, visited map[string]bool, depth int) (string, error) {
+	scheme, rest, ok := strings.Cut(expr, ":")
+	if !ok {
+		return "", fmt.Errorf("invalid interpolation expression %q", expr)
+	}
+	value, def, hasDefault := strings.Cut(rest, "|")
+
+	if scheme == "ref" {
+		return resolveRef(value, def, hasDefault, values, visited, depth)
+	}
+
+	resolved, err := resolveWithScheme(SecretType(scheme), value)
+	if err != nil {
+		if hasDefault {
+			return def, nil
+		}
+		return "", err
+	}
+	return resolved, nil
+}
+
+// resolveRef looks up path in values (a snapshot of every config field's
+// raw value) and recursively renders it, so a chain like
+// base_url: ${ref:global.default_base_url} where default_base_url itself
+// contains ${env:...} resolves fully.
+func resolveRef(path, def string, hasDefault bool, values map[string]string, visited map[string]bool, depth int) (string, error) {
+	value, ok := values[path]
+	if !ok {
+		if hasDefault {
+			return def, nil
+		}
+		return "", fmt.Errorf("ref %q not found in config", path)
+	}
+	if visited[path] {
+		return "", fmt.Errorf("interpolation cycle detected at ref %q", path)
+	}
+
+	visited[path] = true
+	defer delete(visited, path)
+	return renderString(value, values, visited, depth+1)
+}
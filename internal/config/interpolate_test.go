@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateConfig_Ref(t *testing.T) {
+	cfg := &Config{
+		Global:  GlobalConfig{Schema: "instruction"},
+		Context: "Use the ${ref:global.schema} schema",
+	}
+
+	if err := interpolateConfig(cfg); err != nil {
+		t.Fatalf("interpolateConfig() error = %v", err)
+	}
+
+	want := "Use the instruction schema"
+	if cfg.Context != want {
+		t.Errorf("Context = %q, want %q", cfg.Context, want)
+	}
+}
+
+func TestInterpolateConfig_EnvDefault(t *testing.T) {
+	os.Unsetenv("KOTHASET_TEST_INTERP_MISSING")
+
+	cfg := &Config{
+		Global: GlobalConfig{OutputFormat: "${env:KOTHASET_TEST_INTERP_MISSING|jsonl}"},
+	}
+
+	if err := interpolateConfig(cfg); err != nil {
+		t.Fatalf("interpolateConfig() error = %v", err)
+	}
+	if cfg.Global.OutputFormat != "jsonl" {
+		t.Errorf("OutputFormat = %q, want jsonl", cfg.Global.OutputFormat)
+	}
+}
+
+func TestInterpolateConfig_CycleDetected(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			Provider: "${ref:global.schema}",
+			Schema:   "${ref:global.provider}",
+		},
+	}
+
+	err := interpolateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestInterpolateConfig_Profiles(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{Provider: "openai"},
+		Profiles: map[string]Profile{
+			"default": {Provider: "${ref:global.provider}"},
+		},
+	}
+
+	if err := interpolateConfig(cfg); err != nil {
+		t.Fatalf("interpolateConfig() error = %v", err)
+	}
+	if got := cfg.Profiles["default"].Provider; got != "openai" {
+		t.Errorf("Profiles[default].Provider = %q, want openai", got)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/shantoislamdev/kothaset/internal/output"
 )
 
 // Load loads configuration from multiple sources with proper precedence
@@ -60,11 +63,16 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if err := interpolateConfig(cfg); err != nil {
+		return nil, err
+	}
+
 	// Resolve any secret references
 	if err := resolveSecrets(cfg); err != nil {
 		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
 	}
 
+	cfg.path = configFilePath
 	return cfg, nil
 }
 
@@ -80,13 +88,66 @@ func LoadFromFile(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if err := interpolateConfig(cfg); err != nil {
+		return nil, err
+	}
+
 	if err := resolveSecrets(cfg); err != nil {
 		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
 	}
 
+	cfg.path = path
 	return cfg, nil
 }
 
+// LoadSecretsConfig loads the private .secrets.yaml file containing
+// provider credentials and resolves any secret references it contains.
+func LoadSecretsConfig(path string) (*SecretsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	secrets := DefaultSecretsConfig()
+	secrets.Providers = nil
+	if err := yaml.Unmarshal(data, secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+
+	for i := range secrets.Providers {
+		p := &secrets.Providers[i]
+		apiKey, err := resolveAPIKey(p)
+		if err != nil {
+			// Don't fail the whole load on a missing key; it will surface
+			// when the provider is actually used.
+			continue
+		}
+		p.APIKey = APIKeyRef(apiKey)
+	}
+
+	if secrets.AuditWebhookSecret != "" {
+		// As with provider API keys, don't fail the whole load if this
+		// can't be resolved yet; it will surface when the webhook sink
+		// is actually constructed.
+		if resolved, err := ResolveSecretValue(secrets.AuditWebhookSecret); err == nil {
+			secrets.AuditWebhookSecret = resolved
+		}
+	}
+
+	secrets.path = path
+	return secrets, nil
+}
+
+// GetProvider returns the provider configuration by name.
+func (s *SecretsConfig) GetProvider(name string) (*ProviderConfig, error) {
+	for i := range s.Providers {
+		if s.Providers[i].Name == name {
+			return &s.Providers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("provider not found: %s", name)
+}
+
 // SaveToFile saves configuration to a file
 func SaveToFile(cfg *Config, path string) error {
 	data, err := yaml.Marshal(cfg)
@@ -148,6 +209,10 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Global.OutputFormat != "" && !output.IsSupportedFormat(c.Global.OutputFormat) {
+		return fmt.Errorf("unsupported output_format: %s (supported: %s)", c.Global.OutputFormat, strings.Join(output.SupportedFormats(), ", "))
+	}
+
 	return nil
 }
 
@@ -1,9 +1,29 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/getsops/sops/v3/decrypt"
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
 )
 
 // SecretType defines how a secret is stored/retrieved
@@ -16,14 +36,150 @@ const (
 	SecretTypeFile SecretType = "file"
 	// SecretTypePlain is a plain text secret (not recommended)
 	SecretTypePlain SecretType = "plain"
+	// SecretTypeCmd retrieves secret from the stdout of a command
+	SecretTypeCmd SecretType = "cmd"
+	// SecretTypeVault retrieves secret from a HashiCorp Vault KV v2 path
+	SecretTypeVault SecretType = "vault"
+	// SecretTypeAWSSM retrieves secret from AWS Secrets Manager
+	SecretTypeAWSSM SecretType = "aws-sm"
+	// SecretTypeGCPSM retrieves secret from Google Cloud Secret Manager
+	SecretTypeGCPSM SecretType = "gcp-sm"
+	// SecretTypeKeychain retrieves secret from the OS credential store
+	// (macOS Keychain, Windows Credential Manager, or Secret Service on
+	// Linux) via zalando/go-keyring.
+	SecretTypeKeychain SecretType = "keychain"
+	// SecretTypeSOPS retrieves secret from a field of an age/GPG-encrypted
+	// file decrypted in place via getsops/sops.
+	SecretTypeSOPS SecretType = "sops"
 )
 
+// APIKeyRef holds a provider's api_key value. It accepts either the plain
+// "env.FOO" / "vault:secret/data/x#key" shorthand already understood by
+// resolveAPIKey, or the typed YAML mapping form
+// { source: vault, path: secret/data/kothaset, key: openai }, which
+// UnmarshalYAML normalizes to the same "scheme:path#key" shorthand so the
+// rest of the resolver dispatch never needs to know which form was used.
+type APIKeyRef string
+
+// typedSecretRef is the typed YAML mapping form of an APIKeyRef.
+type typedSecretRef struct {
+	Source string `yaml:"source"`
+	Path   string `yaml:"path"`
+	Key    string `yaml:"key,omitempty"`
+}
+
+// canonical renders a typedSecretRef as the "scheme:path#key" shorthand
+// resolveAPIKey's Priority 1 dispatch already understands.
+func (t typedSecretRef) canonical() string {
+	ref := t.Source + ":" + t.Path
+	if t.Key != "" {
+		ref += "#" + t.Key
+	}
+	return ref
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, accepting either a bare string
+// or the typed mapping form.
+func (r *APIKeyRef) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*r = APIKeyRef(s)
+		return nil
+	}
+
+	var typed typedSecretRef
+	if err := unmarshal(&typed); err != nil {
+		return fmt.Errorf("api_key must be a string or a { source, path, key } mapping: %w", err)
+	}
+	*r = APIKeyRef(typed.canonical())
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (r APIKeyRef) MarshalYAML() (interface{}, error) {
+	return string(r), nil
+}
+
 // SecretRef represents a reference to a secret value
 type SecretRef struct {
 	Type  SecretType `yaml:"$type,omitempty" json:"$type,omitempty"`
 	Value string     `yaml:"$value,omitempty" json:"$value,omitempty"`
 }
 
+// SecretResolver fetches the value referenced by the scheme-specific part
+// of a secret reference (the text after "scheme:").
+type SecretResolver interface {
+	Resolve(value string) (string, error)
+}
+
+// SecretResolverFunc adapts a plain function to a SecretResolver.
+type SecretResolverFunc func(value string) (string, error)
+
+// Resolve implements SecretResolver.
+func (f SecretResolverFunc) Resolve(value string) (string, error) { return f(value) }
+
+// secretResolvers is keyed by scheme prefix. Registered here so new backends
+// (and tests) can be plugged in without touching the resolveAPIKey dispatch
+// logic itself.
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[SecretType]SecretResolver{
+		SecretTypeEnv:      SecretResolverFunc(resolveEnvSecret),
+		SecretTypeFile:     SecretResolverFunc(resolveFileSecret),
+		SecretTypePlain:    SecretResolverFunc(func(value string) (string, error) { return value, nil }),
+		SecretTypeCmd:      SecretResolverFunc(resolveCmdSecret),
+		SecretTypeVault:    SecretResolverFunc(resolveVaultSecret),
+		SecretTypeAWSSM:    SecretResolverFunc(resolveAWSSMSecret),
+		SecretTypeGCPSM:    SecretResolverFunc(resolveGCPSMSecret),
+		SecretTypeKeychain: SecretResolverFunc(resolveKeychainSecret),
+		SecretTypeSOPS:     SecretResolverFunc(resolveSOPSSecret),
+	}
+)
+
+// RegisterSecretResolver registers (or overrides) the resolver used for a
+// scheme prefix. Primarily used by tests to stub out network-backed
+// resolvers such as vault/aws-sm/gcp-sm.
+func RegisterSecretResolver(scheme SecretType, resolver SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[scheme] = resolver
+}
+
+// secretCacheTTL bounds how long a resolved secret is reused before the
+// backend is hit again. A Vault lease or cmd: invocation is expensive
+// enough that re-resolving it for every provider construction in a long
+// generation run would otherwise hammer the backend.
+const secretCacheTTL = 5 * time.Minute
+
+type secretCacheEntry struct {
+	value    string
+	resolved time.Time
+}
+
+var (
+	secretCacheMu sync.Mutex
+	secretCache   = map[string]secretCacheEntry{}
+)
+
+func cachedResolve(cacheKey string, resolve func() (string, error)) (string, error) {
+	secretCacheMu.Lock()
+	if entry, ok := secretCache[cacheKey]; ok && time.Since(entry.resolved) < secretCacheTTL {
+		secretCacheMu.Unlock()
+		return entry.value, nil
+	}
+	secretCacheMu.Unlock()
+
+	value, err := resolve()
+	if err != nil {
+		return "", err
+	}
+
+	secretCacheMu.Lock()
+	secretCache[cacheKey] = secretCacheEntry{value: value, resolved: time.Now()}
+	secretCacheMu.Unlock()
+	return value, nil
+}
+
 // resolveSecrets resolves all secret references in the configuration
 func resolveSecrets(cfg *Config) error {
 	for i := range cfg.Providers {
@@ -36,19 +192,41 @@ func resolveSecrets(cfg *Config) error {
 			// They will be validated when the provider is used
 			continue
 		}
-		p.APIKey = apiKey
+		p.APIKey = APIKeyRef(apiKey)
 	}
 	return nil
 }
 
+// envDotPrefix is the terser "env.VAR_NAME" convention used throughout
+// the default config and generated .secrets.yaml scaffold as shorthand
+// for the "env:VAR_NAME" scheme reference. resolveAPIKey and
+// ResolveSecretValue treat the two identically.
+const envDotPrefix = "env."
+
 // resolveAPIKey resolves the API key for a provider
 func resolveAPIKey(p *ProviderConfig) (string, error) {
-	// Priority 1: Direct API key value
-	if p.APIKey != "" && !isSecretRef(p.APIKey) {
-		return p.APIKey, nil
+	key := string(p.APIKey)
+
+	// Priority 1: scheme-prefixed reference, e.g. "vault:secret/data/openai#api_key"
+	if scheme, value, ok := splitScheme(key); ok {
+		return cachedResolve(p.Name+":"+key, func() (string, error) {
+			return resolveWithScheme(scheme, value)
+		})
+	}
+
+	// Priority 1.5: "env.VAR_NAME" shorthand for "env:VAR_NAME"
+	if value, ok := strings.CutPrefix(key, envDotPrefix); ok {
+		return cachedResolve(p.Name+":"+key, func() (string, error) {
+			return resolveWithScheme(SecretTypeEnv, value)
+		})
+	}
+
+	// Priority 2: Direct API key value
+	if key != "" && !isSecretRef(key) {
+		return key, nil
 	}
 
-	// Priority 2: Environment variable reference
+	// Priority 3: Environment variable reference
 	if p.APIKeyEnv != "" {
 		if value := os.Getenv(p.APIKeyEnv); value != "" {
 			return value, nil
@@ -65,12 +243,14 @@ func resolveAPIKey(p *ProviderConfig) (string, error) {
 		}
 	}
 
-	// Priority 3: Parse secret reference in APIKey field
-	if p.APIKey != "" && isSecretRef(p.APIKey) {
-		return resolveSecretRef(p.APIKey)
+	// Priority 4: Legacy ${type:value} secret reference in APIKey field
+	if key != "" && isSecretRef(key) {
+		return cachedResolve(p.Name+":"+key, func() (string, error) {
+			return resolveSecretRef(key)
+		})
 	}
 
-	// Priority 4: Default environment variable based on provider type
+	// Priority 5: Default environment variable based on provider type
 	defaultEnvVars := map[string]string{
 		"openai":    "OPENAI_API_KEY",
 		"anthropic": "ANTHROPIC_API_KEY",
@@ -85,12 +265,57 @@ func resolveAPIKey(p *ProviderConfig) (string, error) {
 	return "", fmt.Errorf("no API key found for provider %s", p.Name)
 }
 
-// isSecretRef checks if a string looks like a secret reference
+// ResolveSecretValue resolves a bare secret value string using the same
+// conventions as a provider's api_key: a "scheme:value" reference (e.g.
+// "vault:secret/data/x#key"), the "env.VAR_NAME" shorthand, a legacy
+// "${scheme:value}" reference, or (if none of those match) the literal
+// value itself. Used wherever a single secret string needs resolving
+// outside the ProviderConfig.APIKey/APIKeyEnv machinery, e.g. the audit
+// webhook's HMAC signing secret.
+func ResolveSecretValue(raw string) (string, error) {
+	if scheme, value, ok := splitScheme(raw); ok {
+		return cachedResolve(raw, func() (string, error) {
+			return resolveWithScheme(scheme, value)
+		})
+	}
+	if value, ok := strings.CutPrefix(raw, envDotPrefix); ok {
+		return cachedResolve(raw, func() (string, error) {
+			return resolveWithScheme(SecretTypeEnv, value)
+		})
+	}
+	if raw != "" && isSecretRef(raw) {
+		return cachedResolve(raw, func() (string, error) {
+			return resolveSecretRef(raw)
+		})
+	}
+	return raw, nil
+}
+
+// isSecretRef checks if a string looks like a legacy ${type:value} secret reference
 func isSecretRef(s string) bool {
 	return strings.HasPrefix(s, "${") && strings.HasSuffix(s, "}")
 }
 
-// resolveSecretRef resolves a secret reference string
+// splitScheme splits a bare "scheme:value" reference, returning ok=false
+// unless scheme is a registered SecretResolver. This keeps raw API keys
+// that happen to contain a colon (none do today, but custom headers might)
+// from being misinterpreted as secret references.
+func splitScheme(s string) (scheme SecretType, value string, ok bool) {
+	idx := strings.Index(s, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+	candidate := SecretType(s[:idx])
+	secretResolversMu.RLock()
+	_, registered := secretResolvers[candidate]
+	secretResolversMu.RUnlock()
+	if !registered {
+		return "", "", false
+	}
+	return candidate, s[idx+1:], true
+}
+
+// resolveSecretRef resolves a legacy secret reference string.
 // Format: ${type:value}
 // Examples:
 //   - ${env:MY_API_KEY}
@@ -104,44 +329,409 @@ func resolveSecretRef(ref string) (string, error) {
 		return "", fmt.Errorf("invalid secret reference format: %s", ref)
 	}
 
-	secretType := SecretType(parts[0])
-	value := parts[1]
+	return resolveWithScheme(SecretType(parts[0]), parts[1])
+}
+
+// resolveWithScheme dispatches to the resolver registered for scheme and
+// audit-logs the (masked) result so secret access is traceable to a backend.
+func resolveWithScheme(scheme SecretType, value string) (string, error) {
+	secretResolversMu.RLock()
+	resolver, ok := secretResolvers[scheme]
+	secretResolversMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown secret type: %s", scheme)
+	}
+	resolved, err := resolver.Resolve(value)
+	if err != nil {
+		return "", err
+	}
+	MaskSecret(resolved, scheme)
+	return resolved, nil
+}
+
+func resolveEnvSecret(value string) (string, error) {
+	envValue := os.Getenv(value)
+	if envValue == "" {
+		return "", fmt.Errorf("environment variable not set: %s", value)
+	}
+	return envValue, nil
+}
+
+func resolveFileSecret(value string) (string, error) {
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// resolveCmdSecret runs a command and returns its trimmed stdout. The value
+// is split on whitespace, so "cmd:op read op://vault/item --reveal" maps to
+// exec.Command("op", "read", "op://vault/item", "--reveal").
+func resolveCmdSecret(value string) (string, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty cmd: secret reference")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("cmd secret %q failed: %w (stderr: %s)", value, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// resolveKeychainSecret resolves "service/account" against the OS
+// credential store (macOS Keychain, Windows Credential Manager, or Secret
+// Service on Linux) via zalando/go-keyring.
+func resolveKeychainSecret(value string) (string, error) {
+	service, account, ok := strings.Cut(value, "/")
+	if !ok {
+		return "", fmt.Errorf("keychain secret reference must be service/account, got %q", value)
+	}
+	secret, err := keyring.Get(service, account)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keychain secret %s/%s: %w", service, account, err)
+	}
+	return secret, nil
+}
+
+// resolveSOPSSecret resolves "path/to/file.enc[#dotted.key]" by decrypting
+// an age/GPG-encrypted file with getsops/sops. The decrypted document's
+// format is inferred from the file extension; omit the #key suffix to use
+// the whole decrypted plaintext as the secret.
+func resolveSOPSSecret(value string) (string, error) {
+	path, key, hasKey := strings.Cut(value, "#")
+	if path == "" {
+		return "", fmt.Errorf("empty sops secret reference")
+	}
+
+	plaintext, err := decrypt.File(path, sopsFormat(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt sops file %s: %w", path, err)
+	}
+	if !hasKey {
+		return strings.TrimSpace(string(plaintext)), nil
+	}
+
+	fields := map[string]any{}
+	if err := yaml.Unmarshal(plaintext, &fields); err != nil {
+		return "", fmt.Errorf("decrypted sops file %s is not valid YAML/JSON: %w", path, err)
+	}
+	value, err := lookupYAMLPath(fields, key)
+	if err != nil {
+		return "", fmt.Errorf("sops file %s: %w", path, err)
+	}
+	return value, nil
+}
+
+// sopsFormat maps a file extension to the format name sops/decrypt expects,
+// defaulting to "yaml" since that's the format .secrets.yaml.enc uses.
+func sopsFormat(path string) string {
+	switch filepath.Ext(path) {
+	case ".json":
+		return "json"
+	case ".env":
+		return "dotenv"
+	default:
+		return "yaml"
+	}
+}
 
-	switch secretType {
-	case SecretTypeEnv:
-		envValue := os.Getenv(value)
-		if envValue == "" {
-			return "", fmt.Errorf("environment variable not set: %s", value)
+// lookupYAMLPath walks a dotted path (e.g. "openai.api_key") through a
+// decoded YAML/JSON document.
+func lookupYAMLPath(doc map[string]any, dotted string) (string, error) {
+	var cur any = doc
+	parts := strings.Split(dotted, ".")
+	for i, part := range parts {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("key %q is not an object", strings.Join(parts[:i], "."))
+		}
+		cur, ok = m[part]
+		if !ok {
+			return "", fmt.Errorf("no key %q", dotted)
 		}
-		return envValue, nil
+	}
+	str, ok := cur.(string)
+	if !ok {
+		return "", fmt.Errorf("key %q is not a string", dotted)
+	}
+	return str, nil
+}
 
-	case SecretTypeFile:
-		data, err := os.ReadFile(value)
-		if err != nil {
-			return "", fmt.Errorf("failed to read secret file: %w", err)
+// resolveVaultSecret resolves "mount/path#field" against a HashiCorp Vault
+// KV v2 engine using VAULT_ADDR/VAULT_TOKEN.
+func resolveVaultSecret(value string) (string, error) {
+	mountPath, field, ok := strings.Cut(value, "#")
+	if !ok {
+		return "", fmt.Errorf("vault secret reference must be mount/path#field, got %q", value)
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		token = readVaultTokenFile()
+	}
+	if token == "" {
+		return "", fmt.Errorf("VAULT_TOKEN not set and ~/.vault-token not found")
+	}
+
+	mount, path, ok := strings.Cut(mountPath, "/")
+	if !ok {
+		return "", fmt.Errorf("vault secret reference must be mount/path#field, got %q", value)
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(addr, "/"), mount, path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault request to %s returned status %d", url, resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := jsonDecode(resp.Body, &body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	raw, ok := body.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %q", mountPath, field)
+	}
+	str, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s field %q is not a string", mountPath, field)
+	}
+	return str, nil
+}
+
+// readVaultTokenFile returns the contents of ~/.vault-token, the location
+// the Vault CLI writes a token to after `vault login`. Returns "" if the
+// file can't be read, leaving the caller to report a combined error.
+func readVaultTokenFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// resolveAWSSMSecret resolves "<secret-id>[?region=...&version-id=...&version-stage=...][#json_key]"
+// against AWS Secrets Manager. secret-id is a secret name or ARN; json_key
+// selects a field from the secret's JSON payload (omit to use the raw
+// secret string).
+func resolveAWSSMSecret(value string) (string, error) {
+	ref, jsonKey, _ := strings.Cut(value, "#")
+
+	secretID, rawQuery, _ := strings.Cut(ref, "?")
+	if secretID == "" {
+		return "", fmt.Errorf("empty aws-sm secret reference")
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid aws-sm query %q: %w", rawQuery, err)
+	}
+
+	secret, err := fetchAWSSecret(secretID, query.Get("region"), query.Get("version"), query.Get("version-id"))
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch AWS secret %s: %w", secretID, err)
+	}
+	if jsonKey == "" {
+		return secret, nil
+	}
+
+	fields, err := jsonDecodeMap(secret)
+	if err != nil {
+		return "", fmt.Errorf("AWS secret %s is not a JSON object: %w", secretID, err)
+	}
+	raw, ok := fields[jsonKey]
+	if !ok {
+		return "", fmt.Errorf("AWS secret %s has no key %q", secretID, jsonKey)
+	}
+	str, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("AWS secret %s key %q is not a string", secretID, jsonKey)
+	}
+	return str, nil
+}
+
+// resolveGCPSMSecret resolves a fully-qualified GCP Secret Manager version
+// name, e.g. "projects/foo/secrets/openai/versions/latest", using the
+// default application credentials.
+func resolveGCPSMSecret(value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("empty gcp-sm secret reference")
+	}
+
+	ctx := context.Background()
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCP Secret Manager client: %w", err)
+	}
+	defer client.Close()
+
+	resp, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: value})
+	if err != nil {
+		return "", fmt.Errorf("failed to access GCP secret %s: %w", value, err)
+	}
+	return string(resp.Payload.Data), nil
+}
+
+// SecretDescription summarizes how a provider's api_key reference
+// resolved, without exposing the resolved value itself.
+type SecretDescription struct {
+	Provider string
+	Backend  SecretType
+	OK       bool
+	Err      error
+}
+
+// DescribeSecrets re-reads the secrets file at path (rather than reusing an
+// already-loaded SecretsConfig, whose APIKey fields have been overwritten
+// with resolved values) and reports, per provider, which backend its
+// api_key reference resolves through and whether resolution succeeds. It
+// never returns the resolved value. Used by `kothaset secrets check`.
+func DescribeSecrets(path string) ([]SecretDescription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	raw := DefaultSecretsConfig()
+	raw.Providers = nil
+	if err := yaml.Unmarshal(data, raw); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+
+	descriptions := make([]SecretDescription, 0, len(raw.Providers))
+	for i := range raw.Providers {
+		p := &raw.Providers[i]
+		desc := SecretDescription{Provider: p.Name, Backend: secretBackend(p)}
+		if _, err := resolveAPIKey(p); err != nil {
+			desc.Err = err
+		} else {
+			desc.OK = true
 		}
-		return strings.TrimSpace(string(data)), nil
+		descriptions = append(descriptions, desc)
+	}
+	return descriptions, nil
+}
 
-	case SecretTypePlain:
-		return value, nil
+// secretBackend identifies which backend a provider's api_key reference
+// would dispatch to, mirroring resolveAPIKey's priority order without
+// actually resolving (and thus without needing the backend to succeed).
+func secretBackend(p *ProviderConfig) SecretType {
+	key := string(p.APIKey)
 
-	default:
-		return "", fmt.Errorf("unknown secret type: %s", secretType)
+	if scheme, _, ok := splitScheme(key); ok {
+		return scheme
+	}
+	if _, ok := strings.CutPrefix(key, envDotPrefix); ok {
+		return SecretTypeEnv
+	}
+	if key != "" && !isSecretRef(key) {
+		return SecretTypePlain
 	}
+	if p.APIKeyEnv != "" {
+		return SecretTypeEnv
+	}
+	if key != "" && isSecretRef(key) {
+		if parts := strings.SplitN(key[2:len(key)-1], ":", 2); len(parts) == 2 {
+			return SecretType(parts[0])
+		}
+	}
+	return SecretTypeEnv
 }
 
-// ResolveSecret is a public helper to resolve a single secret reference
+// ResolveSecret is a public helper to resolve a single secret reference.
+// It understands both the bare "scheme:value" form and the legacy
+// "${scheme:value}" form.
 func ResolveSecret(ref string) (string, error) {
+	if scheme, value, ok := splitScheme(ref); ok {
+		return resolveWithScheme(scheme, value)
+	}
 	if !isSecretRef(ref) {
 		return ref, nil
 	}
 	return resolveSecretRef(ref)
 }
 
-// MaskSecret returns a masked version of a secret for display
-func MaskSecret(secret string) string {
-	if len(secret) <= 8 {
-		return "********"
+// MaskSecret returns a masked version of a secret for display, and logs an
+// audit line naming the backend that resolved it (never the value) so
+// secret access shows up in operator logs. This is the only sanctioned way
+// to log a resolved secret value.
+func MaskSecret(secret string, backend SecretType) string {
+	masked := "********"
+	if len(secret) > 8 {
+		masked = secret[:4] + "..." + secret[len(secret)-4:]
+	}
+	log.Printf("secret resolved backend=%s value=%s", backend, masked)
+	return masked
+}
+
+// fetchAWSSecret retrieves the raw secret string for secretID from AWS
+// Secrets Manager using the default credential chain. region, version, and
+// versionID are optional and, if empty, fall through to AWS defaults.
+func fetchAWSSecret(secretID, region, version, versionID string) (string, error) {
+	opts := []func(*awsconfig.LoadOptions) error{}
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := secretsmanager.NewFromConfig(awsCfg)
+
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)}
+	if version != "" {
+		input.VersionStage = aws.String(version)
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	out, err := client.GetSecretValue(context.Background(), input)
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	return string(out.SecretBinary), nil
+}
+
+func jsonDecode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func jsonDecodeMap(s string) (map[string]any, error) {
+	var m map[string]any
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil, err
 	}
-	return secret[:4] + "..." + secret[len(secret)-4:]
+	return m, nil
 }
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestResolveAPIKey(t *testing.T) {
@@ -73,7 +76,7 @@ func TestResolveAPIKey(t *testing.T) {
 			cfg := &ProviderConfig{
 				Name:   "test-provider",
 				Type:   tt.provType,
-				APIKey: tt.apiKey,
+				APIKey: APIKeyRef(tt.apiKey),
 			}
 
 			got, err := resolveAPIKey(cfg)
@@ -101,6 +104,29 @@ func TestResolveAPIKey(t *testing.T) {
 	}
 }
 
+func TestResolveAPIKey_CustomResolver(t *testing.T) {
+	const fakeScheme = SecretType("fake")
+	RegisterSecretResolver(fakeScheme, SecretResolverFunc(func(value string) (string, error) {
+		if value != "my-secret-path" {
+			return "", fmt.Errorf("unexpected value: %s", value)
+		}
+		return "resolved-by-fake", nil
+	}))
+
+	cfg := &ProviderConfig{
+		Name:   "fake-provider",
+		APIKey: "fake:my-secret-path",
+	}
+
+	got, err := resolveAPIKey(cfg)
+	if err != nil {
+		t.Fatalf("resolveAPIKey() unexpected error = %v", err)
+	}
+	if got != "resolved-by-fake" {
+		t.Errorf("resolveAPIKey() = %v, want resolved-by-fake", got)
+	}
+}
+
 func TestLoadSecretsConfig_WithEnv(t *testing.T) {
 	os.Setenv("TEST_KEY", "my-secret-key")
 	defer os.Unsetenv("TEST_KEY")
@@ -131,6 +157,62 @@ providers:
 	}
 }
 
+func TestAPIKeyRef_UnmarshalYAML_TypedForm(t *testing.T) {
+	var secrets SecretsConfig
+	content := []byte(`
+providers:
+  - name: "test-provider"
+    type: "openai"
+    api_key:
+      source: vault
+      path: secret/data/kothaset
+      key: openai
+`)
+	if err := yaml.Unmarshal(content, &secrets); err != nil {
+		t.Fatalf("yaml.Unmarshal failed: %v", err)
+	}
+
+	want := APIKeyRef("vault:secret/data/kothaset#openai")
+	if got := secrets.Providers[0].APIKey; got != want {
+		t.Errorf("APIKey = %q, want %q", got, want)
+	}
+}
+
+func TestDescribeSecrets(t *testing.T) {
+	os.Setenv("TEST_DESCRIBE_KEY", "secret-value")
+	defer os.Unsetenv("TEST_DESCRIBE_KEY")
+
+	tmpDir := t.TempDir()
+	secretsPath := filepath.Join(tmpDir, ".secrets.yaml")
+	content := []byte(`
+providers:
+  - name: "good-provider"
+    type: "openai"
+    api_key: "env.TEST_DESCRIBE_KEY"
+  - name: "bad-provider"
+    type: "openai"
+    api_key: "env.MISSING_DESCRIBE_KEY"
+`)
+	if err := os.WriteFile(secretsPath, content, 0644); err != nil {
+		t.Fatalf("failed to write temp secrets: %v", err)
+	}
+
+	descriptions, err := DescribeSecrets(secretsPath)
+	if err != nil {
+		t.Fatalf("DescribeSecrets() error = %v", err)
+	}
+	if len(descriptions) != 2 {
+		t.Fatalf("expected 2 descriptions, got %d", len(descriptions))
+	}
+
+	if descriptions[0].Backend != SecretTypeEnv || !descriptions[0].OK {
+		t.Errorf("good-provider = %+v, want backend=env, ok=true", descriptions[0])
+	}
+	if descriptions[1].Backend != SecretTypeEnv || descriptions[1].OK {
+		t.Errorf("bad-provider = %+v, want backend=env, ok=false", descriptions[1])
+	}
+}
+
 func TestMaskSecret(t *testing.T) {
 	tests := []struct {
 		input string
@@ -142,7 +224,7 @@ func TestMaskSecret(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		if got := MaskSecret(tt.input); got != tt.want {
+		if got := MaskSecret(tt.input, SecretTypeEnv); got != tt.want {
 			t.Errorf("MaskSecret(%s) = %s, want %s", tt.input, got, tt.want)
 		}
 	}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shantoislamdev/kothaset/internal/fswatch"
+)
+
+// SecretsWatcher keeps a SecretsConfig in sync with the .secrets.yaml it
+// was loaded from, so a rotated API key lands in a long-running generation
+// job without a restart. Current is safe to call from any goroutine while
+// Watch's background loop swaps in newly parsed values.
+type SecretsWatcher struct {
+	inner *fswatch.Watcher[SecretsConfig]
+}
+
+// Watch starts watching the file s was loaded from for changes and returns
+// a SecretsWatcher whose Current method always reflects the latest
+// successfully parsed SecretsConfig, with API keys re-resolved the same
+// way LoadSecretsConfig resolves them. It returns an error if s was not
+// loaded from a file. The watcher stops when ctx is canceled.
+func (s *SecretsConfig) Watch(ctx context.Context) (*SecretsWatcher, error) {
+	if s.path == "" {
+		return nil, fmt.Errorf("config: cannot watch a secrets config that was not loaded from a file")
+	}
+
+	path := s.path
+	inner, err := fswatch.Start(ctx, path, "config: secrets", s, func() (*SecretsConfig, error) {
+		next, err := LoadSecretsConfig(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload %s: %w", path, err)
+		}
+		return next, nil
+	}, fswatch.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretsWatcher{inner: inner}, nil
+}
+
+// Current returns the most recently loaded SecretsConfig. Callers should
+// re-fetch it on each use rather than caching the pointer, so they see a
+// rotated credential as soon as it lands.
+func (w *SecretsWatcher) Current() *SecretsConfig {
+	return w.inner.Current()
+}
+
+// Subscribe registers fn to be called, with the previous and newly loaded
+// SecretsConfig, every time a reload succeeds. fn is called synchronously
+// from the watcher's goroutine, so it must not block.
+func (w *SecretsWatcher) Subscribe(fn func(old, new *SecretsConfig)) {
+	w.inner.Subscribe(fn)
+}
+
+// Errors returns the channel parse failures are sent to. A failed reload
+// leaves Current unchanged, so a broken edit never takes down in-flight
+// workers. The channel is buffered by one and never closed; a reader that
+// falls behind just sees the latest error on its next receive.
+func (w *SecretsWatcher) Errors() <-chan error {
+	return w.inner.Errors()
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSecretsConfig_Watch_RequiresFile(t *testing.T) {
+	secrets := DefaultSecretsConfig()
+	if _, err := secrets.Watch(context.Background()); err == nil {
+		t.Fatal("expected error watching a SecretsConfig not loaded from a file")
+	}
+}
+
+func TestSecretsConfig_Watch_Reloads(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".secrets.yaml")
+
+	initial := "providers:\n  - name: openai\n    type: openai\n    api_key: sk-initial\n"
+	if err := os.WriteFile(path, []byte(initial), 0644); err != nil {
+		t.Fatalf("failed to write secrets file: %v", err)
+	}
+
+	secrets, err := LoadSecretsConfig(path)
+	if err != nil {
+		t.Fatalf("LoadSecretsConfig() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := secrets.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	rotated := "providers:\n  - name: openai\n    type: openai\n    api_key: sk-rotated\n"
+	if err := os.WriteFile(path, []byte(rotated), 0644); err != nil {
+		t.Fatalf("failed to rewrite secrets file: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		p, err := w.Current().GetProvider("openai")
+		if err == nil && p.APIKey == "sk-rotated" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("secrets watcher did not pick up rotated api key in time")
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shantoislamdev/kothaset/internal/fswatch"
+)
+
+// ConfigChange describes a successfully applied hot-reload of kothaset.yaml.
+type ConfigChange struct {
+	Old *Config
+	New *Config
+}
+
+// WatchOptions controls how a Watcher detects changes to its source file.
+type WatchOptions struct {
+	// Poll forces mtime polling instead of fsnotify, for filesystems
+	// (network mounts, some container overlays) where inotify events are
+	// unreliable or unavailable.
+	Poll bool
+
+	// PollInterval is how often Poll mode re-stats the file. Defaults to
+	// fswatch's own poll interval (2s) when zero.
+	PollInterval time.Duration
+}
+
+// Watcher watches the file a Config was loaded from and keeps an
+// atomically-swapped copy available via Current, so a long-running
+// generate job can pick up a concurrency, model, or prompt change without
+// restarting. Unlike context.Watcher and SecretsWatcher, a reload here
+// re-runs Validate and is only swapped in once it passes, so a typo in
+// kothaset.yaml never takes down an in-flight run.
+type Watcher struct {
+	inner   *fswatch.Watcher[Config]
+	changes chan ConfigChange
+}
+
+// Watch starts watching the file cfg was loaded from (Load/LoadFromFile
+// record this internally) for changes. On each change it re-runs Load and
+// Validate; only a fully successful, valid parse is swapped in. The
+// watcher stops when ctx is canceled.
+func Watch(ctx context.Context, cfg *Config, opts WatchOptions) (*Watcher, error) {
+	if cfg.path == "" {
+		return nil, fmt.Errorf("config: cannot watch a config that was not loaded from a file")
+	}
+
+	path := cfg.path
+	w := &Watcher{changes: make(chan ConfigChange, 1)}
+
+	inner, err := fswatch.Start(ctx, path, "config", cfg, func() (*Config, error) {
+		next, err := Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload %s: %w", path, err)
+		}
+		if err := next.Validate(); err != nil {
+			return nil, fmt.Errorf("reloaded %s failed validation: %w", path, err)
+		}
+		return next, nil
+	}, fswatch.Options{Poll: opts.Poll, PollInterval: opts.PollInterval})
+	if err != nil {
+		return nil, err
+	}
+	w.inner = inner
+
+	w.inner.Subscribe(func(old, next *Config) {
+		select {
+		case w.changes <- ConfigChange{Old: old, New: next}:
+		default:
+		}
+	})
+
+	return w, nil
+}
+
+// Current returns the most recently applied, validated Config. Callers
+// should re-fetch it on each use rather than caching the pointer, so they
+// see a reload as soon as it lands.
+func (w *Watcher) Current() *Config {
+	return w.inner.Current()
+}
+
+// Subscribe registers fn to be called, with the previous and newly applied
+// Config, every time a reload succeeds. fn is called synchronously from
+// the watcher's goroutine, so it must not block; fan work out to its own
+// goroutine if it needs to.
+func (w *Watcher) Subscribe(fn func(old, new *Config)) {
+	w.inner.Subscribe(fn)
+}
+
+// Changes returns a channel that receives a ConfigChange after each
+// successful reload. The channel is buffered by one and never closed; a
+// reader that falls behind just sees the latest change on its next
+// receive.
+func (w *Watcher) Changes() <-chan ConfigChange {
+	return w.changes
+}
+
+// Errors returns the channel reload failures (parse or validation errors)
+// are sent to. A failed reload leaves Current unchanged. The channel is
+// buffered by one and never closed.
+func (w *Watcher) Errors() <-chan error {
+	return w.inner.Errors()
+}
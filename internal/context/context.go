@@ -16,6 +16,11 @@ type Config struct {
 
 	// Instruction provides additional generation instructions (free-form paragraph)
 	Instruction string `yaml:"instruction"`
+
+	// path is the file this Config was loaded from, recorded so Watch can
+	// find it again; empty for a Config built without LoadFromFile (e.g.
+	// the empty default returned by Load when no context file exists).
+	path string
 }
 
 // searchPaths defines where to look for context.yaml
@@ -52,6 +57,7 @@ func LoadFromFile(path string) (*Config, error) {
 	// Trim whitespace from both fields
 	cfg.Context = strings.TrimSpace(cfg.Context)
 	cfg.Instruction = strings.TrimSpace(cfg.Instruction)
+	cfg.path = path
 
 	return cfg, nil
 }
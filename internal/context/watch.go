@@ -0,0 +1,57 @@
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shantoislamdev/kothaset/internal/fswatch"
+)
+
+// Watcher keeps a Config in sync with the context.yaml it was loaded from,
+// so a long-running generation job picks up an edited instruction
+// paragraph without restarting. Current is safe to call from any goroutine
+// while Watch's background loop swaps in newly parsed values.
+type Watcher struct {
+	inner *fswatch.Watcher[Config]
+}
+
+// Watch starts watching the file c was loaded from for changes and returns
+// a Watcher whose Current method always reflects the latest successfully
+// parsed Config. It returns an error if c was not loaded from a file (e.g.
+// the empty Config returned by Load when no context.yaml exists). The
+// watcher stops when ctx is canceled.
+func (c *Config) Watch(ctx context.Context) (*Watcher, error) {
+	if c.path == "" {
+		return nil, fmt.Errorf("context: cannot watch a config that was not loaded from a file")
+	}
+
+	path := c.path
+	inner, err := fswatch.Start(ctx, path, "context", c, func() (*Config, error) {
+		next, err := LoadFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload %s: %w", path, err)
+		}
+		return next, nil
+	}, fswatch.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{inner: inner}, nil
+}
+
+// Current returns the most recently loaded Config. Callers should re-fetch
+// it on each use rather than caching the pointer, so they see a rotated
+// value as soon as it lands.
+func (w *Watcher) Current() *Config {
+	return w.inner.Current()
+}
+
+// Errors returns the channel parse failures are sent to. A failed reload
+// leaves Current unchanged, so a broken edit never takes down in-flight
+// workers; it is up to the consumer to decide whether an error is worth
+// surfacing. The channel is buffered by one and never closed; a reader
+// that falls behind just sees the latest error on its next receive.
+func (w *Watcher) Errors() <-chan error {
+	return w.inner.Errors()
+}
@@ -0,0 +1,251 @@
+// Package fswatch implements the debounced-reload/Subscribe/Errors
+// machinery shared by context.Watcher, config.Watcher, and
+// config.SecretsWatcher: each keeps an atomically-swapped value in sync
+// with the file it was loaded from, watching it via fsnotify (or an mtime
+// poll loop, for filesystems where inotify is unreliable) and reloading
+// through a caller-supplied func whenever it changes.
+package fswatch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultDebounce coalesces the burst of events an editor's write-then-rename
+// save produces into a single reload.
+const defaultDebounce = 200 * time.Millisecond
+
+// defaultPollInterval is how often Poll mode re-stats the watched file.
+const defaultPollInterval = 2 * time.Second
+
+// Options controls how a Watcher detects and coalesces changes to its
+// source file.
+type Options struct {
+	// Debounce coalesces a burst of fsnotify events into a single reload.
+	// Defaults to 200ms when zero.
+	Debounce time.Duration
+
+	// Poll forces mtime polling instead of fsnotify, for filesystems
+	// (network mounts, some container overlays) where inotify events are
+	// unreliable or unavailable.
+	Poll bool
+
+	// PollInterval is how often Poll mode re-stats the file. Defaults to
+	// 2s when zero.
+	PollInterval time.Duration
+}
+
+// Watcher keeps a *T in sync with the file it was loaded from, so a
+// long-running generation job picks up an edited config/context/secrets
+// file without restarting. Current is safe to call from any goroutine
+// while the background loop swaps in newly reloaded values.
+type Watcher[T any] struct {
+	path      string
+	errPrefix string
+	reload    func() (*T, error)
+
+	current atomic.Pointer[T]
+
+	subsMu sync.Mutex
+	subs   []func(old, next *T)
+
+	errs chan error
+}
+
+// Start begins watching path for changes and returns a Watcher whose
+// Current always reflects the latest value a successful call to reload
+// returned. initial seeds Current before any reload runs. errPrefix labels
+// the errors Start and the watcher's Errors channel produce (e.g.
+// "context", "config", "config: secrets"), matching the caller's own
+// error-wrapping convention. The watcher stops when ctx is canceled.
+func Start[T any](ctx context.Context, path, errPrefix string, initial *T, reload func() (*T, error), opts Options) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		path:      path,
+		errPrefix: errPrefix,
+		reload:    reload,
+		errs:      make(chan error, 1),
+	}
+	w.current.Store(initial)
+
+	if opts.Poll {
+		interval := opts.PollInterval
+		if interval <= 0 {
+			interval = defaultPollInterval
+		}
+		go w.runPoll(ctx, interval)
+		return w, nil
+	}
+
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to start watcher: %w", errPrefix, err)
+	}
+	dir := filepath.Dir(path)
+	if err := fsw.Add(dir); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("%s: failed to watch %s: %w", errPrefix, dir, err)
+	}
+
+	go w.run(ctx, fsw, debounce)
+	return w, nil
+}
+
+// Current returns the most recently reloaded value. Callers should
+// re-fetch it on each use rather than caching the pointer, so they see a
+// rotated value as soon as it lands.
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// Subscribe registers fn to be called, with the previous and newly
+// reloaded value, every time a reload succeeds. fn is called synchronously
+// from the watcher's goroutine, so it must not block; fan work out to its
+// own goroutine if it needs to.
+func (w *Watcher[T]) Subscribe(fn func(old, next *T)) {
+	w.subsMu.Lock()
+	w.subs = append(w.subs, fn)
+	w.subsMu.Unlock()
+}
+
+// Errors returns the channel reload failures are sent to. A failed reload
+// leaves Current unchanged, so a broken edit never takes down in-flight
+// workers; it is up to the consumer to decide whether an error is worth
+// surfacing. The channel is buffered by one and never closed; a reader
+// that falls behind just sees the latest error on its next receive.
+func (w *Watcher[T]) Errors() <-chan error {
+	return w.errs
+}
+
+// run watches fsw for events on w.path until ctx is canceled, debouncing
+// bursts (an editor's write+rename save trips multiple events) before
+// reloading.
+func (w *Watcher[T]) run(ctx context.Context, fsw *fsnotify.Watcher, debounce time.Duration) {
+	defer fsw.Close()
+
+	reload := make(chan struct{}, 1)
+	var timer *time.Timer
+
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(w.path) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+
+			if timer == nil {
+				timer = time.AfterFunc(debounce, func() {
+					select {
+					case reload <- struct{}{}:
+					default:
+					}
+				})
+			} else {
+				timer.Reset(debounce)
+			}
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			w.reportError(fmt.Errorf("%s: watcher error: %w", w.errPrefix, err))
+
+		case <-reload:
+			w.doReload()
+		}
+	}
+}
+
+// runPoll is the fallback path for filesystems where fsnotify is
+// unreliable: it re-stats w.path on a timer and reloads whenever the mtime
+// advances.
+func (w *Watcher[T]) runPoll(ctx context.Context, interval time.Duration) {
+	lastMod, err := modTime(w.path)
+	if err != nil {
+		w.reportError(fmt.Errorf("%s: failed to stat %s: %w", w.errPrefix, w.path, err))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mod, err := modTime(w.path)
+			if err != nil {
+				w.reportError(fmt.Errorf("%s: failed to stat %s: %w", w.errPrefix, w.path, err))
+				continue
+			}
+			if mod.Equal(lastMod) {
+				continue
+			}
+			lastMod = mod
+			w.doReload()
+		}
+	}
+}
+
+// doReload calls w.reload and, on success, swaps it in and notifies
+// subscribers; on failure it reports the error and leaves Current
+// unchanged.
+func (w *Watcher[T]) doReload() {
+	old := w.current.Load()
+
+	next, err := w.reload()
+	if err != nil {
+		w.reportError(fmt.Errorf("%s: %w", w.errPrefix, err))
+		return
+	}
+
+	w.current.Store(next)
+	log.Printf("%s: reloaded %s", w.errPrefix, w.path)
+
+	w.subsMu.Lock()
+	subs := make([]func(old, next *T), len(w.subs))
+	copy(subs, w.subs)
+	w.subsMu.Unlock()
+	for _, sub := range subs {
+		sub(old, next)
+	}
+}
+
+func (w *Watcher[T]) reportError(err error) {
+	select {
+	case w.errs <- err:
+	default:
+	}
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
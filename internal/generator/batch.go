@@ -0,0 +1,290 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/shantoislamdev/kothaset/internal/metrics"
+	"github.com/shantoislamdev/kothaset/internal/output"
+	"github.com/shantoislamdev/kothaset/internal/provider"
+	"github.com/shantoislamdev/kothaset/internal/telemetry"
+)
+
+// pendingBatchRequest is the prompt/topic/seed bookkeeping runBatch keeps
+// alongside each submitted provider.BatchRequest, keyed by its CustomID, so
+// a downloaded provider.BatchResult can still be run through
+// finishFromResponse (which needs the original prompt for its audit event
+// and the topic for schema.SampleMetadata) once results come back.
+type pendingBatchRequest struct {
+	prompt string
+	topic  string
+	seed   *int64
+}
+
+// runBatch is Run's Config.BatchMode path: instead of a live worker pool
+// making one Generate call per sample, it builds every remaining sample's
+// prompt up front, submits them as a single asynchronous
+// provider.BatchProvider job, polls it to completion, and feeds the
+// downloaded results through the same finishFromResponse pipeline
+// (ParseResponse/ValidateSample/dedup) the live path uses before writing.
+//
+// Batch mode is scoped to a single provider (g.provider) rather than
+// g.router: a batch job has no per-request failover equivalent, so
+// multi-provider routing, streaming, self-consistency (Config.KSamples),
+// and Generator.cache don't apply here. g.budget, if configured, is still
+// charged per successful result so Result.SpentUSD reports correctly, but
+// it does not gate submission - a batch is submitted as a whole, so there
+// is no per-sample point to stop early on budget the way the live path can.
+//
+// A resumed run (g.batchJobID already set from Checkpoint.BatchJobID) skips
+// SubmitBatch and instead rebuilds the prompts for the job's original
+// index range (Checkpoint.BatchRange, not baseCompleted..baseCompleted+
+// remaining): a batch job's results can complete out of order, so after a
+// crash mid-result-processing baseCompleted no longer lines up with which
+// indices that job actually covers. Checkpoint.BatchAppliedIDs then lets the
+// result loop skip whichever of that range a prior attempt already applied,
+// rather than re-deriving "already done" from a shifted index range.
+// Rebuilding prompts by re-sampling rather than persisting the original
+// requests relies on Config.Sampler being deterministic per index, the same
+// assumption the live path's own resume support already makes.
+func (g *Generator) runBatch(ctx context.Context, startTime time.Time, baseCompleted, remaining int) (*Result, error) {
+	batchProv, ok := g.provider.(provider.BatchProvider)
+	if !ok {
+		return nil, fmt.Errorf("batch mode requires a provider implementing provider.BatchProvider, got %s", g.provider.Name())
+	}
+
+	rangeStart, rangeCount := baseCompleted, remaining
+	if g.batchJobID != "" {
+		rangeStart, rangeCount = g.batchRangeStart, g.batchRangeCount
+	}
+
+	// Build every sample in the job's range up front, streaming from the
+	// sampler, regardless of whether this run is submitting a fresh job or
+	// resuming one already in flight: resuming still needs this mapping to
+	// attribute downloaded results back to a topic/prompt/seed.
+	pending := make(map[string]pendingBatchRequest, rangeCount)
+	var batchReqs []provider.BatchRequest
+	for i := 0; i < rangeCount; i++ {
+		idx := rangeStart + i
+		prompt, topic, req, err := g.buildRequest(ctx, idx)
+		if err != nil {
+			atomic.AddInt32(&g.failed, 1)
+			fmt.Fprintf(os.Stderr, "⚠ Sample failed: %v\n", err)
+			continue
+		}
+		customID := strconv.Itoa(idx)
+		pending[customID] = pendingBatchRequest{prompt: prompt, topic: topic, seed: req.Seed}
+		batchReqs = append(batchReqs, provider.BatchRequest{CustomID: customID, Request: req})
+	}
+
+	if g.batchJobID == "" {
+		if len(batchReqs) == 0 {
+			return g.buildResult(startTime, false), nil
+		}
+		jobID, err := batchProv.SubmitBatch(ctx, batchReqs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to submit batch: %w", err)
+		}
+		g.batchJobID = jobID
+		g.batchRangeStart = rangeStart
+		g.batchRangeCount = rangeCount
+		g.logEvent("batch submitted", "job_id", jobID, "requests", len(batchReqs))
+		// Checkpoint immediately so a crash right after submission resumes
+		// by polling this job instead of submitting a duplicate one.
+		if err := g.saveCheckpoint(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save checkpoint after batch submission: %v\n", err)
+		}
+	} else {
+		g.logEvent("resuming batch job", "job_id", g.batchJobID)
+	}
+
+	status, err := g.pollBatch(ctx, batchProv)
+	if err != nil {
+		return nil, err
+	}
+	g.logEvent("batch finished", "job_id", g.batchJobID, "state", status.State, "completed", status.CompletedCount, "failed", status.FailedCount, "total", status.TotalCount)
+
+	results, err := batchProv.FetchBatchResults(ctx, g.batchJobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch batch %s results: %w", g.batchJobID, err)
+	}
+
+	// Results from a CustomID outside pending (unknown to this job) or
+	// already in g.batchApplied (a prior attempt at this same job already
+	// applied it, before a crash partway through this very loop) are
+	// skipped, rather than inferring "already done" from an index range -
+	// out-of-order completion means the two don't reliably line up.
+	var writeErr error
+	checkpointCounter := 0
+	for _, r := range results {
+		p, known := pending[r.CustomID]
+		if !known || g.batchResultApplied(r.CustomID) {
+			continue
+		}
+		if r.Err != nil {
+			atomic.AddInt32(&g.failed, 1)
+			metrics.SamplesGenerated.WithLabelValues(g.config.Schema, g.provider.Name(), "error").Inc()
+			fmt.Fprintf(os.Stderr, "⚠ Sample failed: %v\n", r.Err)
+		} else {
+			wr := g.finishFromResponse(ctx, r.Response, g.provider, g.provider.Name(), g.provider.Model(), p.prompt, p.seed, p.topic, false)
+			if werr := g.applyBatchResult(ctx, wr); werr != nil && writeErr == nil {
+				writeErr = werr
+			}
+		}
+		g.markBatchResultApplied(r.CustomID)
+		g.reportProgress(startTime)
+
+		// Checkpoint periodically, the same as the live path's collector,
+		// so a crash partway through a large batch's results only replays
+		// the results applied since the last flush, not the whole job.
+		checkpointCounter++
+		if g.config.CheckpointEvery > 0 && checkpointCounter >= g.config.CheckpointEvery {
+			if err := g.writer.Sync(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to sync output: %v\n", err)
+			}
+			if err := g.saveCheckpoint(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save checkpoint: %v\n", err)
+			}
+			checkpointCounter = 0
+		}
+	}
+
+	if err := g.saveCheckpoint(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save final checkpoint: %v\n", err)
+	}
+
+	result := g.buildResult(startTime, false)
+	if writeErr != nil {
+		return result, fmt.Errorf("generation completed with write errors: %w", writeErr)
+	}
+	return result, nil
+}
+
+// batchResultApplied reports whether customID, from the current
+// g.batchJobID, was already applied by a prior attempt at this same job
+// (see Checkpoint.BatchAppliedIDs).
+func (g *Generator) batchResultApplied(customID string) bool {
+	g.batchAppliedMu.Lock()
+	defer g.batchAppliedMu.Unlock()
+	return g.batchApplied[customID]
+}
+
+// markBatchResultApplied records that customID has been run through
+// applyBatchResult (or failed), so a resumed run's result loop skips it.
+func (g *Generator) markBatchResultApplied(customID string) {
+	g.batchAppliedMu.Lock()
+	defer g.batchAppliedMu.Unlock()
+	if g.batchApplied == nil {
+		g.batchApplied = make(map[string]bool)
+	}
+	g.batchApplied[customID] = true
+}
+
+// applyBatchResult records one finishFromResponse outcome the same way
+// Run's collector goroutine does for a live workerResult: writing a success
+// to g.writer, counting duplicates/failures, and charging g.budget. Returns
+// the writer error, if any, so the caller can surface it the way Run does.
+func (g *Generator) applyBatchResult(ctx context.Context, result *workerResult) error {
+	if result.err != nil && result.duplicate {
+		atomic.AddInt32(&g.duplicatesFound, 1)
+		metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "duplicate").Inc()
+		telemetry.IncDuplicate(ctx, g.config.Schema, result.provider)
+		return nil
+	}
+	if result.err != nil {
+		atomic.AddInt32(&g.failed, 1)
+		metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "error").Inc()
+		telemetry.IncFailure(ctx, g.config.Schema, result.provider)
+		fmt.Fprintf(os.Stderr, "⚠ Sample failed: %v\n", result.err)
+
+		if g.config.EmitRejectionSidecar && result.report != nil {
+			if rw, ok := g.writer.(output.RejectionWriter); ok {
+				if err := rw.WriteRejection(result.sample, result.report); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠ Failed to write rejection sidecar: %v\n", err)
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := g.writer.Write(result.sample); err != nil {
+		atomic.AddInt32(&g.failed, 1)
+		metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "error").Inc()
+		telemetry.IncFailure(ctx, g.config.Schema, result.provider)
+		fmt.Fprintf(os.Stderr, "⚠ Write failed: %v\n", err)
+		return err
+	}
+
+	atomic.AddInt32(&g.completed, 1)
+	atomic.AddInt64(&g.tokensUsed, int64(result.tokens))
+	if g.budget != nil && result.tokens > 0 {
+		g.budget.Charge(result.provider, result.model, result.tokens)
+	}
+	if result.duplicateKept {
+		atomic.AddInt32(&g.duplicatesFound, 1)
+	}
+	metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "success").Inc()
+	metrics.SampleTokens.WithLabelValues(g.config.Schema, "output").Observe(float64(result.tokens))
+	telemetry.IncSuccess(ctx, g.config.Schema, result.provider)
+	telemetry.RecordSampleTokens(ctx, g.config.Schema, result.tokens)
+	return nil
+}
+
+// defaultBatchPollBase is batchPollInterval's base delay outside of tests.
+const defaultBatchPollBase = 5 * time.Second
+
+// batchPollIntervalCap is the maximum delay batchPollInterval backs off to.
+const batchPollIntervalCap = 60 * time.Second
+
+// batchPollInterval returns the delay before the (attempt+1)th PollBatch
+// call, doubling from g.batchPollBase (or defaultBatchPollBase outside
+// tests) and capped at batchPollIntervalCap. Unlike retryDelay's
+// per-request backoff, a batch job's turnaround is measured in minutes to
+// hours, so there's no point polling faster than this even on the first
+// attempt; g.batchPollBase exists only so tests don't have to wait out a
+// real multi-second sleep per poll.
+func (g *Generator) batchPollInterval(attempt int) time.Duration {
+	base := g.batchPollBase
+	if base <= 0 {
+		base = defaultBatchPollBase
+	}
+	delay := base
+	for i := 0; i < attempt; i++ {
+		delay *= 2
+		if delay >= batchPollIntervalCap {
+			return batchPollIntervalCap
+		}
+	}
+	return delay
+}
+
+// pollBatch polls jobID with batchPollInterval backoff until it reaches a
+// terminal state, returning the completed status or an error for any
+// non-completed terminal state (failed/expired/cancelled) or a context
+// cancellation.
+func (g *Generator) pollBatch(ctx context.Context, batchProv provider.BatchProvider) (provider.BatchJobStatus, error) {
+	for attempt := 0; ; attempt++ {
+		status, err := batchProv.PollBatch(ctx, g.batchJobID)
+		if err != nil {
+			return provider.BatchJobStatus{}, fmt.Errorf("failed to poll batch %s: %w", g.batchJobID, err)
+		}
+		g.logEvent("batch poll", "job_id", g.batchJobID, "state", status.State, "completed", status.CompletedCount, "failed", status.FailedCount, "total", status.TotalCount)
+
+		switch status.State {
+		case provider.BatchStateCompleted:
+			return status, nil
+		case provider.BatchStateFailed, provider.BatchStateExpired, provider.BatchStateCancelled:
+			return provider.BatchJobStatus{}, fmt.Errorf("batch %s ended in state %s", g.batchJobID, status.State)
+		}
+
+		select {
+		case <-ctx.Done():
+			return provider.BatchJobStatus{}, ctx.Err()
+		case <-time.After(g.batchPollInterval(attempt)):
+		}
+	}
+}
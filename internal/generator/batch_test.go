@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+func TestGenerator_Run_BatchMode_Success(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 3
+	cfg.BatchMode = true
+
+	prov := &MockBatchProvider{
+		MockProvider: MockProvider{Response: `{"instruction": "this is a long enough instruction", "output": "this is a long enough output"}`},
+		JobID:        "batch-123",
+		PollStates: []provider.BatchJobStatus{
+			{State: provider.BatchStateInProgress, TotalCount: 3},
+			{State: provider.BatchStateCompleted, CompletedCount: 3, TotalCount: 3},
+		},
+	}
+	s := schema.NewInstructionSchema()
+	gen := New(cfg, prov, s)
+	gen.batchPollBase = time.Millisecond
+	gen.SetSampler(&MockSampler{Topic: "test-topic"})
+	writer := &MockWriter{}
+	gen.SetWriter(writer)
+
+	res, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+	if res.SuccessCount != 3 {
+		t.Errorf("expected 3 successes, got %d", res.SuccessCount)
+	}
+	if len(writer.Samples) != 3 {
+		t.Errorf("expected 3 written samples, got %d", len(writer.Samples))
+	}
+	if len(prov.Submitted) != 3 {
+		t.Errorf("expected 3 submitted requests, got %d", len(prov.Submitted))
+	}
+	if prov.FetchCalls != 1 {
+		t.Errorf("expected 1 FetchBatchResults call, got %d", prov.FetchCalls)
+	}
+}
+
+func TestGenerator_Run_BatchMode_UnsupportedProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 1
+	cfg.BatchMode = true
+
+	prov := &MockProvider{Response: `{}`}
+	s := schema.NewInstructionSchema()
+	gen := New(cfg, prov, s)
+	gen.SetSampler(&MockSampler{Topic: "test-topic"})
+	gen.SetWriter(&MockWriter{})
+
+	if _, err := gen.Run(context.Background()); err == nil {
+		t.Fatal("expected an error for a provider that doesn't implement provider.BatchProvider")
+	}
+}
+
+func TestGenerator_Run_BatchMode_JobFails(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 2
+	cfg.BatchMode = true
+
+	prov := &MockBatchProvider{
+		MockProvider: MockProvider{Response: `{}`},
+		JobID:        "batch-456",
+		PollStates:   []provider.BatchJobStatus{{State: provider.BatchStateFailed}},
+	}
+	s := schema.NewInstructionSchema()
+	gen := New(cfg, prov, s)
+	gen.SetSampler(&MockSampler{Topic: "test-topic"})
+	gen.SetWriter(&MockWriter{})
+
+	if _, err := gen.Run(context.Background()); err == nil {
+		t.Fatal("expected an error when the batch job ends in a failed state")
+	}
+}
+
+func TestGenerator_Run_BatchMode_ResumeSkipsResubmission(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 2
+	cfg.BatchMode = true
+
+	prov := &MockBatchProvider{
+		MockProvider: MockProvider{Response: `{"instruction": "this is a long enough instruction", "output": "this is a long enough output"}`},
+		JobID:        "batch-789",
+		PollStates:   []provider.BatchJobStatus{{State: provider.BatchStateCompleted, CompletedCount: 2, TotalCount: 2}},
+	}
+	s := schema.NewInstructionSchema()
+	gen := New(cfg, prov, s)
+	gen.SetSampler(&MockSampler{Topic: "test-topic"})
+	gen.SetWriter(&MockWriter{})
+	gen.batchJobID = "batch-789" // simulate a resume that already recorded a job ID
+
+	res, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+	if len(prov.Submitted) != 0 {
+		t.Errorf("expected resume to skip SubmitBatch, but %d requests were submitted", len(prov.Submitted))
+	}
+	if res.SuccessCount != 2 {
+		t.Errorf("expected 2 successes, got %d", res.SuccessCount)
+	}
+}
@@ -0,0 +1,75 @@
+package generator
+
+import "sync"
+
+// BudgetConfig configures a hard USD spending cap for a run. A zero MaxUSD
+// disables it, so a Generator with no Budget configured never stops early
+// regardless of how much a run would otherwise cost.
+type BudgetConfig struct {
+	// MaxUSD is the cumulative spend, across every provider call the run
+	// makes, at which Generator.Run stops submitting new samples.
+	MaxUSD float64 `yaml:"max_usd,omitempty" json:"max_usd,omitempty"`
+
+	// Prices gives the USD cost per 1000 tokens for provider/model pairs a
+	// run might route through. The outer key is the provider name matching
+	// ProviderSpec.Name; the inner key is the model. A pair missing from
+	// this table is treated as free, so Budget only enforces a cap for
+	// providers the caller bothered to price.
+	Prices map[string]map[string]float64 `yaml:"prices,omitempty" json:"prices,omitempty"`
+}
+
+// Budget tracks cumulative USD spend across a run against
+// BudgetConfig.MaxUSD, pricing each charge from BudgetConfig.Prices. Safe
+// for concurrent use, since a Generator's worker pool charges it from
+// multiple goroutines.
+type Budget struct {
+	mu       sync.Mutex
+	cfg      BudgetConfig
+	spentUSD float64
+}
+
+// NewBudget creates a Budget enforcing cfg.
+func NewBudget(cfg BudgetConfig) *Budget {
+	return &Budget{cfg: cfg}
+}
+
+// priceFor returns the USD-per-1000-tokens rate for providerName/model, or
+// 0 if BudgetConfig.Prices has no entry for it.
+func (b *Budget) priceFor(providerName, model string) float64 {
+	models, ok := b.cfg.Prices[providerName]
+	if !ok {
+		return 0
+	}
+	return models[model]
+}
+
+// Charge prices tokens spent through providerName/model and adds the
+// result to the running total, returning the USD cost it added.
+func (b *Budget) Charge(providerName, model string, tokens int) float64 {
+	cost := b.priceFor(providerName, model) * float64(tokens) / 1000
+	if cost == 0 {
+		return 0
+	}
+	b.mu.Lock()
+	b.spentUSD += cost
+	b.mu.Unlock()
+	return cost
+}
+
+// Exceeded reports whether cumulative spend has reached MaxUSD. Always
+// false when MaxUSD <= 0 (no cap configured).
+func (b *Budget) Exceeded() bool {
+	if b.cfg.MaxUSD <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spentUSD >= b.cfg.MaxUSD
+}
+
+// Spent returns cumulative USD spend so far.
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spentUSD
+}
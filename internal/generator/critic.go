@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// JudgeConfig configures the optional LLM-as-judge quality filter. Provider
+// names a provider.Provider already registered on the Generator (via New
+// or AddProvider) that scores each sample against a rubric; MinScore is the
+// average score (1-5) a sample must meet or exceed to be written. A zero
+// value (empty Provider) disables judging entirely.
+type JudgeConfig struct {
+	Provider string  `yaml:"provider,omitempty" json:"provider,omitempty"`
+	MinScore float64 `yaml:"min_score,omitempty" json:"min_score,omitempty"`
+}
+
+// judgeRubricScores is the shape the judge provider is asked to return: a
+// 1-5 score on each rubric axis, parsed straight out of its JSON response.
+type judgeRubricScores struct {
+	Correctness float64 `json:"correctness"`
+	Relevance   float64 `json:"relevance"`
+	Format      float64 `json:"format"`
+}
+
+func (s judgeRubricScores) average() float64 {
+	return (s.Correctness + s.Relevance + s.Format) / 3
+}
+
+// judgeSample scores sample 1-5 on correctness, relevance, and format using
+// Config.Judge.Provider, returning the map recorded at
+// Sample.Metadata.Custom["judge"] (the three axes plus their average).
+func (g *Generator) judgeSample(ctx context.Context, sample *schema.Sample) (map[string]any, error) {
+	jp, ok := g.providers[g.config.Judge.Provider]
+	if !ok {
+		return nil, fmt.Errorf("judge provider %q is not registered", g.config.Judge.Provider)
+	}
+
+	fieldsJSON, err := json.Marshal(sample.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sample for judging: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Score the following generated sample on three axes, each from 1 (worst) to 5 (best):\n")
+	sb.WriteString("- correctness: is the content factually and logically sound?\n")
+	sb.WriteString("- relevance: does it actually address the prompt/topic it was generated for?\n")
+	sb.WriteString("- format: does it follow the expected structure and is it well-formed?\n\n")
+	sb.WriteString("Sample:\n")
+	sb.Write(fieldsJSON)
+	sb.WriteString("\n\nRespond with ONLY a JSON object: {\"correctness\": <1-5>, \"relevance\": <1-5>, \"format\": <1-5>}")
+
+	resp, err := jp.Generate(ctx, provider.GenerationRequest{
+		Messages: []provider.Message{{Role: "user", Content: sb.String()}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("judge provider call failed: %w", err)
+	}
+
+	raw := strings.TrimSpace(resp.Content)
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(raw, "```")
+	raw = strings.TrimSpace(raw)
+
+	var scores judgeRubricScores
+	if err := json.Unmarshal([]byte(raw), &scores); err != nil {
+		return nil, fmt.Errorf("failed to parse judge response: %w", err)
+	}
+
+	return map[string]any{
+		"correctness": scores.Correctness,
+		"relevance":   scores.Relevance,
+		"format":      scores.Format,
+		"average":     scores.average(),
+	}, nil
+}
+
+// selectCandidate implements the self-consistency vote across candidates
+// generated for the same sample index (Config.KSamples): the majority
+// label for classification-style schemas, or the highest judge-scored
+// candidate for everything else. It returns the winning candidate plus any
+// judge scores already computed while choosing it, so applyJudge doesn't
+// pay for a second judge call on the same sample.
+func (g *Generator) selectCandidate(ctx context.Context, candidates []*workerResult) (*workerResult, map[string]any) {
+	if g.schema.Style() == schema.StyleClassification {
+		return selectByMajorityLabel(candidates), nil
+	}
+
+	if g.config.Judge.Provider == "" {
+		// Open-ended self-consistency has no signal to rank candidates on
+		// without a judge, so just keep the first one.
+		return candidates[0], nil
+	}
+
+	best := candidates[0]
+	var bestScores map[string]any
+	bestAvg := -1.0
+	for _, c := range candidates {
+		scores, err := g.judgeSample(ctx, c.sample)
+		if err != nil {
+			continue
+		}
+		if avg, _ := scores["average"].(float64); avg > bestAvg {
+			bestAvg = avg
+			best = c
+			bestScores = scores
+		}
+	}
+	return best, bestScores
+}
+
+// classificationKey returns a stable string identifying a classification
+// sample's predicted label(s), used to group self-consistency candidates
+// for majority vote across ClassificationSchema's single/multi/hierarchical
+// modes.
+func classificationKey(sample *schema.Sample) string {
+	if path, ok := sample.Fields["path"].([]string); ok {
+		return strings.Join(path, ">")
+	}
+	if labels, ok := sample.Fields["labels"].([]string); ok {
+		sorted := append([]string(nil), labels...)
+		sort.Strings(sorted)
+		return strings.Join(sorted, ",")
+	}
+	return sample.GetString("label")
+}
+
+func selectByMajorityLabel(candidates []*workerResult) *workerResult {
+	counts := make(map[string]int, len(candidates))
+	for _, c := range candidates {
+		counts[classificationKey(c.sample)]++
+	}
+
+	best := candidates[0]
+	bestCount := 0
+	for _, c := range candidates {
+		if n := counts[classificationKey(c.sample)]; n > bestCount {
+			bestCount = n
+			best = c
+		}
+	}
+	return best
+}
+
+// applyJudge scores result's sample with Config.Judge (if configured) and
+// turns it into an error result if the score falls below
+// Config.Judge.MinScore, so the collector drops it the same way it drops a
+// failed validation. precomputed, if non-nil, is reused instead of calling
+// the judge again (selectCandidate already scores the winning candidate for
+// open-ended self-consistency). A judge call failure is logged and the
+// sample passes through unscored rather than being dropped, since a broken
+// judge shouldn't silently fail an otherwise good run.
+func (g *Generator) applyJudge(ctx context.Context, result *workerResult, precomputed map[string]any) *workerResult {
+	if g.config.Judge.Provider == "" || result.err != nil {
+		return result
+	}
+
+	scores := precomputed
+	if scores == nil {
+		var err error
+		scores, err = g.judgeSample(ctx, result.sample)
+		if err != nil {
+			g.logEvent("judge failed", "provider", g.config.Judge.Provider, "error", err.Error())
+			return result
+		}
+	}
+
+	if result.sample.Metadata.Custom == nil {
+		result.sample.Metadata.Custom = make(map[string]any)
+	}
+	result.sample.Metadata.Custom["judge"] = scores
+
+	if avg, _ := scores["average"].(float64); g.config.Judge.MinScore > 0 && avg < g.config.Judge.MinScore {
+		return &workerResult{
+			sample:   result.sample,
+			err:      fmt.Errorf("sample rejected by judge: score %.2f below min_score %.2f", avg, g.config.Judge.MinScore),
+			provider: result.provider,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+func TestGenerator_JudgeSample(t *testing.T) {
+	prov := &MockProvider{Response: `{"correctness": 4, "relevance": 5, "format": 3}`}
+	judge := &MockProvider{Response: `{"correctness": 4, "relevance": 5, "format": 3}`}
+	gen := New(DefaultConfig(), prov, schema.NewInstructionSchema())
+	gen.AddProvider("judge", judge)
+	gen.config.Judge = JudgeConfig{Provider: "judge"}
+
+	scores, err := gen.judgeSample(context.Background(), &schema.Sample{Fields: map[string]any{"instruction": "do X", "output": "did X"}})
+	if err != nil {
+		t.Fatalf("judgeSample failed: %v", err)
+	}
+	if avg, _ := scores["average"].(float64); avg != 4 {
+		t.Errorf("expected average 4, got %v", scores["average"])
+	}
+	if judge.Calls != 1 {
+		t.Errorf("expected 1 judge call, got %d", judge.Calls)
+	}
+}
+
+func TestGenerator_JudgeSample_UnregisteredProvider(t *testing.T) {
+	prov := &MockProvider{Response: "{}"}
+	gen := New(DefaultConfig(), prov, schema.NewInstructionSchema())
+	gen.config.Judge = JudgeConfig{Provider: "missing"}
+
+	if _, err := gen.judgeSample(context.Background(), &schema.Sample{Fields: map[string]any{}}); err == nil {
+		t.Error("expected an error for an unregistered judge provider")
+	}
+}
+
+func TestGenerator_ApplyJudge_DropsBelowMinScore(t *testing.T) {
+	prov := &MockProvider{}
+	judge := &MockProvider{Response: `{"correctness": 2, "relevance": 2, "format": 2}`}
+	gen := New(DefaultConfig(), prov, schema.NewInstructionSchema())
+	gen.AddProvider("judge", judge)
+	gen.config.Judge = JudgeConfig{Provider: "judge", MinScore: 3}
+
+	result := &workerResult{sample: &schema.Sample{Fields: map[string]any{"instruction": "x", "output": "y"}}}
+	out := gen.applyJudge(context.Background(), result, nil)
+	if out.err == nil {
+		t.Fatal("expected sample to be rejected by the judge")
+	}
+	if result.sample.Metadata.Custom["judge"] == nil {
+		t.Error("expected judge scores to be recorded on the sample even when rejected")
+	}
+}
+
+func TestGenerator_ApplyJudge_KeepsAboveMinScore(t *testing.T) {
+	prov := &MockProvider{}
+	judge := &MockProvider{Response: `{"correctness": 5, "relevance": 4, "format": 5}`}
+	gen := New(DefaultConfig(), prov, schema.NewInstructionSchema())
+	gen.AddProvider("judge", judge)
+	gen.config.Judge = JudgeConfig{Provider: "judge", MinScore: 3}
+
+	result := &workerResult{sample: &schema.Sample{Fields: map[string]any{"instruction": "x", "output": "y"}}}
+	out := gen.applyJudge(context.Background(), result, nil)
+	if out.err != nil {
+		t.Fatalf("expected sample to survive judging, got error: %v", out.err)
+	}
+	scores, ok := result.sample.Metadata.Custom["judge"].(map[string]any)
+	if !ok {
+		t.Fatal("expected judge scores recorded on sample metadata")
+	}
+	if avg, _ := scores["average"].(float64); avg < 3 {
+		t.Errorf("expected average >= 3, got %v", avg)
+	}
+}
+
+func TestGenerator_ApplyJudge_NoJudgeConfigured(t *testing.T) {
+	prov := &MockProvider{}
+	gen := New(DefaultConfig(), prov, schema.NewInstructionSchema())
+
+	result := &workerResult{sample: &schema.Sample{Fields: map[string]any{"instruction": "x", "output": "y"}}}
+	out := gen.applyJudge(context.Background(), result, nil)
+	if out != result {
+		t.Error("expected applyJudge to pass the result through unchanged when no judge is configured")
+	}
+}
+
+func TestGenerator_SelectCandidate_MajorityLabel(t *testing.T) {
+	prov := &MockProvider{}
+	gen := New(DefaultConfig(), prov, schema.NewClassificationSchema(schema.ClassificationConfig{}))
+
+	candidates := []*workerResult{
+		{sample: &schema.Sample{Fields: map[string]any{"label": "positive"}}},
+		{sample: &schema.Sample{Fields: map[string]any{"label": "negative"}}},
+		{sample: &schema.Sample{Fields: map[string]any{"label": "positive"}}},
+	}
+
+	winner, scores := gen.selectCandidate(context.Background(), candidates)
+	if winner.sample.GetString("label") != "positive" {
+		t.Errorf("expected majority label %q, got %q", "positive", winner.sample.GetString("label"))
+	}
+	if scores != nil {
+		t.Error("expected no judge scores for classification majority vote")
+	}
+}
+
+func TestGenerator_SelectCandidate_OpenEndedPicksHighestJudgeScore(t *testing.T) {
+	prov := &MockProvider{}
+	judge := &MockProvider{Response: `{"correctness": 5, "relevance": 5, "format": 5}`}
+	gen := New(DefaultConfig(), prov, schema.NewInstructionSchema())
+	gen.AddProvider("judge", judge)
+	gen.config.Judge = JudgeConfig{Provider: "judge"}
+
+	candidates := []*workerResult{
+		{sample: &schema.Sample{Fields: map[string]any{"instruction": "a", "output": "b"}}},
+		{sample: &schema.Sample{Fields: map[string]any{"instruction": "c", "output": "d"}}},
+	}
+
+	winner, scores := gen.selectCandidate(context.Background(), candidates)
+	if winner == nil {
+		t.Fatal("expected a winning candidate")
+	}
+	if scores == nil {
+		t.Error("expected precomputed judge scores for the winner")
+	}
+	if judge.Calls != len(candidates) {
+		t.Errorf("expected one judge call per candidate, got %d", judge.Calls)
+	}
+}
+
+func TestGenerator_SelectCandidate_OpenEndedNoJudgeKeepsFirst(t *testing.T) {
+	prov := &MockProvider{}
+	gen := New(DefaultConfig(), prov, schema.NewInstructionSchema())
+
+	first := &workerResult{sample: &schema.Sample{Fields: map[string]any{"instruction": "a", "output": "b"}}}
+	candidates := []*workerResult{first, {sample: &schema.Sample{Fields: map[string]any{"instruction": "c", "output": "d"}}}}
+
+	winner, scores := gen.selectCandidate(context.Background(), candidates)
+	if winner != first {
+		t.Error("expected the first candidate to be kept when no judge is configured")
+	}
+	if scores != nil {
+		t.Error("expected no precomputed scores without a judge")
+	}
+}
+
+func TestGenerator_Run_SelfConsistency_MajorityLabel(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Schema = "classification"
+	cfg.NumSamples = 1
+	cfg.Workers = 1
+	cfg.KSamples = 3
+
+	prov := &MockProvider{Response: `{"text": "a movie review", "label": "positive"}`}
+	gen := New(cfg, prov, schema.NewClassificationSchema(schema.ClassificationConfig{}))
+	gen.SetSampler(&MockSampler{Topic: "movies"})
+	writer := &MockWriter{}
+	gen.SetWriter(writer)
+
+	res, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+	if res.SuccessCount != 1 {
+		t.Fatalf("expected 1 success, got %d", res.SuccessCount)
+	}
+	if prov.Calls != cfg.KSamples {
+		t.Errorf("expected %d provider calls (one per candidate), got %d", cfg.KSamples, prov.Calls)
+	}
+	if len(writer.Samples) != 1 || writer.Samples[0].GetString("label") != "positive" {
+		t.Error("expected the single majority-label sample to be written")
+	}
+}
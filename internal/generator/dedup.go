@@ -0,0 +1,343 @@
+package generator
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const dedupShingleSize = 5
+
+// DedupConfig configures near-duplicate filtering across a run (see
+// Deduper). A zero value (Enabled false) disables it entirely.
+type DedupConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Threshold is the minimum estimated Jaccard similarity, over 0-1, for
+	// a sample to be considered a near-duplicate of a previously accepted
+	// one. Defaults to 0.8.
+	Threshold float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+
+	// NumHashes is K, the number of MinHash functions in a signature.
+	// Defaults to 48.
+	NumHashes int `yaml:"num_hashes,omitempty" json:"num_hashes,omitempty"`
+
+	// Bands is B, the number of LSH bands a signature is split into
+	// (R = NumHashes / Bands rows per band). Defaults to 6, which together
+	// with the NumHashes default gives (1/B)^(1/R) ≈ 0.8, matching the
+	// default Threshold. NumHashes is rounded up to a multiple of Bands.
+	Bands int `yaml:"bands,omitempty" json:"bands,omitempty"`
+
+	// OnDuplicate selects what a Generator does when CheckAndAdd reports a
+	// near-duplicate: "skip" (the default) drops the sample and moves on to
+	// the next index; "regenerate" asks the sampler for a fresh topic and
+	// retries the same index, up to maxDuplicateRegenerateAttempts, before
+	// falling back to skip; "keep" writes the sample anyway. All three still
+	// increment Result.DuplicatesFound.
+	OnDuplicate string `yaml:"on_duplicate,omitempty" json:"on_duplicate,omitempty"`
+}
+
+// Deduper filters near-duplicate samples via MinHash signatures over
+// character shingles of a schema's primary text field, indexed with banded
+// LSH so a candidate lookup doesn't have to scan every previously accepted
+// signature. Safe for concurrent use.
+type Deduper struct {
+	mu sync.Mutex
+
+	cfg  DedupConfig
+	rows int
+
+	// aSeeds/bSeeds hold one independent seed pair per MinHash function,
+	// derived deterministically from the function's index (see
+	// seedForIndex) so a resumed run rebuilds the exact same hash family
+	// without needing to persist it.
+	aSeeds []uint64
+	bSeeds []uint64
+
+	// buckets maps a "band index:band hash" key to the sample IDs whose
+	// signature hashed into it, for O(1) candidate lookup per band.
+	buckets map[string][]string
+
+	// signatures holds every accepted sample's MinHash signature, keyed by
+	// sample ID, so a candidate's estimated Jaccard similarity can be
+	// recomputed against it.
+	signatures map[string][]uint64
+}
+
+// NewDeduper creates a Deduper, filling in defaults for any unset
+// DedupConfig numeric field.
+func NewDeduper(cfg DedupConfig) *Deduper {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 0.8
+	}
+	bands := cfg.Bands
+	if bands <= 0 {
+		bands = 6
+	}
+	numHashes := cfg.NumHashes
+	if numHashes <= 0 {
+		numHashes = 48
+	}
+	rows := (numHashes + bands - 1) / bands
+	if rows < 1 {
+		rows = 1
+	}
+	numHashes = rows * bands
+	cfg.Bands = bands
+	cfg.NumHashes = numHashes
+	if cfg.OnDuplicate == "" {
+		cfg.OnDuplicate = "skip"
+	}
+
+	aSeeds := make([]uint64, numHashes)
+	bSeeds := make([]uint64, numHashes)
+	for i := 0; i < numHashes; i++ {
+		aSeeds[i], bSeeds[i] = seedForIndex(i)
+	}
+
+	return &Deduper{
+		cfg:        cfg,
+		rows:       rows,
+		aSeeds:     aSeeds,
+		bSeeds:     bSeeds,
+		buckets:    make(map[string][]string),
+		signatures: make(map[string][]uint64),
+	}
+}
+
+// splitmix64 is a fast, well-mixed 64-bit hash used both to derive
+// independent MinHash seeds from a small integer index and as the MinHash
+// functions themselves.
+func splitmix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
+
+// seedForIndex derives the (a, b) seed pair for the i-th MinHash function.
+func seedForIndex(i int) (a, b uint64) {
+	x := uint64(i)*2 + 1
+	return splitmix64(x), splitmix64(x ^ 0x9E3779B97F4A7C15)
+}
+
+// shingleHash hashes a single n-gram shingle to a uint64 with FNV-1a.
+func shingleHash(shingle string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(shingle))
+	return h.Sum64()
+}
+
+// shingles splits text into overlapping dedupShingleSize-rune n-grams
+// (lowercased), returning their hashes as a set. Text shorter than the
+// shingle size is treated as a single shingle.
+func shingles(text string) map[uint64]struct{} {
+	runes := []rune(strings.ToLower(text))
+	set := make(map[uint64]struct{})
+	if len(runes) <= dedupShingleSize {
+		set[shingleHash(string(runes))] = struct{}{}
+		return set
+	}
+	for i := 0; i+dedupShingleSize <= len(runes); i++ {
+		set[shingleHash(string(runes[i:i+dedupShingleSize]))] = struct{}{}
+	}
+	return set
+}
+
+// signature computes text's MinHash signature against d's hash family.
+func (d *Deduper) signature(text string) []uint64 {
+	sig := make([]uint64, len(d.aSeeds))
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for sh := range shingles(text) {
+		for i := range sig {
+			h := splitmix64(sh^d.aSeeds[i]) ^ d.bSeeds[i]
+			if h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// estimateJaccard reports the fraction of matching rows between two
+// equal-length MinHash signatures, the standard unbiased estimator of the
+// Jaccard similarity of the sets they were built from.
+func estimateJaccard(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	matches := 0
+	for i := range a {
+		if a[i] == b[i] {
+			matches++
+		}
+	}
+	return float64(matches) / float64(len(a))
+}
+
+// bucketKey hashes the band-th band (d.rows consecutive signature values)
+// of sig into a bucket key, prefixed with the band index so bands never
+// collide with each other.
+func (d *Deduper) bucketKey(band int, sig []uint64) string {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	start := band * d.rows
+	for _, v := range sig[start : start+d.rows] {
+		binary.BigEndian.PutUint64(buf, v)
+		_, _ = h.Write(buf)
+	}
+	return fmt.Sprintf("%d:%x", band, h.Sum64())
+}
+
+// CheckAndAdd computes text's MinHash signature and checks it against every
+// candidate sharing an LSH bucket with it. If a candidate's estimated
+// Jaccard similarity meets the configured Threshold, text is reported as a
+// near-duplicate and left out of the index. Otherwise id's signature is
+// inserted so later calls can match against it.
+func (d *Deduper) CheckAndAdd(id, text string) bool {
+	sig := d.signature(text)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	checked := make(map[string]bool)
+	for band := 0; band < d.cfg.Bands; band++ {
+		key := d.bucketKey(band, sig)
+		for _, candID := range d.buckets[key] {
+			if checked[candID] {
+				continue
+			}
+			checked[candID] = true
+			if estimateJaccard(sig, d.signatures[candID]) >= d.cfg.Threshold {
+				return true
+			}
+		}
+	}
+
+	d.signatures[id] = sig
+	for band := 0; band < d.cfg.Bands; band++ {
+		key := d.bucketKey(band, sig)
+		d.buckets[key] = append(d.buckets[key], id)
+	}
+	return false
+}
+
+// insert adds a signature that was already accepted (used to rebuild the
+// LSH index from a persisted dedupSnapshot, where every signature is known
+// good and doesn't need re-checking against itself).
+func (d *Deduper) insert(id string, sig []uint64) {
+	d.signatures[id] = sig
+	for band := 0; band < d.cfg.Bands; band++ {
+		key := d.bucketKey(band, sig)
+		d.buckets[key] = append(d.buckets[key], id)
+	}
+}
+
+// dedupSnapshot is the on-disk shape of a persisted Deduper, saved
+// alongside the run's checkpoint so --resume continues deduping against
+// every sample accepted so far.
+type dedupSnapshot struct {
+	Config     DedupConfig         `json:"config"`
+	Signatures map[string][]uint64 `json:"signatures"`
+}
+
+// getDedupPath returns the path for the dedup index file next to the
+// checkpoint for outputPath/dir, mirroring getCheckpointPath.
+func getDedupPath(outputPath, dir string) string {
+	return getCheckpointPath(outputPath, dir) + ".dedup"
+}
+
+// Save persists d's signatures to path, atomically like SaveCheckpoint.
+func (d *Deduper) Save(path string) error {
+	d.mu.Lock()
+	snap := dedupSnapshot{
+		Config:     d.cfg,
+		Signatures: make(map[string][]uint64, len(d.signatures)),
+	}
+	for id, sig := range d.signatures {
+		snap.Signatures[id] = sig
+	}
+	d.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// LoadDeduper loads a Deduper previously persisted with Save, rebuilding
+// its LSH index from the saved signatures.
+func LoadDeduper(path string) (*Deduper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap dedupSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+
+	d := NewDeduper(snap.Config)
+	for id, sig := range snap.Signatures {
+		d.insert(id, sig)
+	}
+	return d, nil
+}
+
+// dedupPrimaryField maps a registered schema name to the field in
+// Sample.Fields that dedup should shingle, matching whichever field best
+// represents "the text" for that schema's style.
+var dedupPrimaryField = map[string]string{
+	"instruction":    "instruction",
+	"chat":           "conversations",
+	"preference":     "prompt",
+	"kto":            "prompt",
+	"ranked":         "prompt",
+	"classification": "text",
+}
+
+// dedupText returns the text CheckAndAdd should shingle for sample, given
+// the name of the schema that produced it. Falls back to a handful of
+// common field names for a schema not in dedupPrimaryField, and returns ""
+// (meaning: skip dedup for this sample) if none are present.
+func dedupText(schemaName string, fields map[string]any) string {
+	field, ok := dedupPrimaryField[schemaName]
+	if !ok {
+		for _, candidate := range []string{"text", "instruction", "prompt"} {
+			if _, present := fields[candidate]; present {
+				field = candidate
+				break
+			}
+		}
+	}
+	if field == "" {
+		return ""
+	}
+	v, present := fields[field]
+	if !present {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+func TestDeduper_CheckAndAdd_FlagsNearDuplicates(t *testing.T) {
+	d := NewDeduper(DedupConfig{Threshold: 0.8})
+
+	text := "The quick brown fox jumps over the lazy dog near the riverbank."
+	if dup := d.CheckAndAdd("a", text); dup {
+		t.Fatal("first occurrence should not be a duplicate")
+	}
+
+	nearDup := text + " Extra."
+	if dup := d.CheckAndAdd("b", nearDup); !dup {
+		t.Error("expected a near-identical text to be flagged as a duplicate")
+	}
+
+	distinct := "A completely different sentence about something else entirely, unrelated to foxes."
+	if dup := d.CheckAndAdd("c", distinct); dup {
+		t.Error("expected an unrelated text not to be flagged as a duplicate")
+	}
+}
+
+func TestDeduper_SaveLoad_RoundTrips(t *testing.T) {
+	d := NewDeduper(DedupConfig{Threshold: 0.8, NumHashes: 48, Bands: 6})
+	d.CheckAndAdd("a", "The quick brown fox jumps over the lazy dog.")
+	d.CheckAndAdd("b", "A totally unrelated sentence about spaceships and planets.")
+
+	path := filepath.Join(t.TempDir(), "run.checkpoint.dedup")
+	if err := d.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadDeduper(path)
+	if err != nil {
+		t.Fatalf("LoadDeduper failed: %v", err)
+	}
+
+	if dup := loaded.CheckAndAdd("c", "The quick brown fox jumps over the lazy dog!"); !dup {
+		t.Error("expected the reloaded index to still flag a near-duplicate of a previously accepted sample")
+	}
+}
+
+func TestDedupText_UsesSchemaPrimaryField(t *testing.T) {
+	if got := dedupText("classification", map[string]any{"text": "hello", "label": "x"}); got != "hello" {
+		t.Errorf("expected classification primary field text, got %q", got)
+	}
+	if got := dedupText("instruction", map[string]any{"instruction": "do X"}); got != "do X" {
+		t.Errorf("expected instruction primary field instruction, got %q", got)
+	}
+	if got := dedupText("unknown-schema", map[string]any{"prompt": "hi"}); got != "hi" {
+		t.Errorf("expected fallback to a common field name, got %q", got)
+	}
+	if got := dedupText("unknown-schema", map[string]any{"other": "x"}); got != "" {
+		t.Errorf("expected empty string when no known field is present, got %q", got)
+	}
+}
+
+func TestGenerator_Run_Dedup_DropsNearDuplicates(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 3
+	cfg.Workers = 1
+	cfg.Dedup = DedupConfig{Enabled: true, Threshold: 0.8}
+
+	prov := &MockProvider{Response: `{"instruction": "write a short poem about the ocean at dawn", "output": "waves glitter under the rising sun"}`}
+	gen := New(cfg, prov, schema.NewInstructionSchema())
+	gen.SetSampler(&MockSampler{Topic: "ocean"})
+	writer := &MockWriter{}
+	gen.SetWriter(writer)
+
+	res, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+	if res.DuplicatesFound != 2 {
+		t.Errorf("expected 2 duplicates found, got %d", res.DuplicatesFound)
+	}
+	if res.SuccessCount != 1 {
+		t.Errorf("expected 1 success, got %d", res.SuccessCount)
+	}
+	if len(writer.Samples) != 1 {
+		t.Errorf("expected 1 written sample, got %d", len(writer.Samples))
+	}
+}
+
+func TestGenerator_Run_Dedup_OnDuplicateKeep_WritesDuplicatesAnyway(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 3
+	cfg.Workers = 1
+	cfg.Dedup = DedupConfig{Enabled: true, Threshold: 0.8, OnDuplicate: "keep"}
+
+	prov := &MockProvider{Response: `{"instruction": "write a short poem about the ocean at dawn", "output": "waves glitter under the rising sun"}`}
+	gen := New(cfg, prov, schema.NewInstructionSchema())
+	gen.SetSampler(&MockSampler{Topic: "ocean"})
+	writer := &MockWriter{}
+	gen.SetWriter(writer)
+
+	res, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+	if res.DuplicatesFound != 2 {
+		t.Errorf("expected 2 duplicates found, got %d", res.DuplicatesFound)
+	}
+	if res.SuccessCount != 3 {
+		t.Errorf("expected all 3 samples kept as successes, got %d", res.SuccessCount)
+	}
+	if len(writer.Samples) != 3 {
+		t.Errorf("expected 3 written samples, got %d", len(writer.Samples))
+	}
+}
+
+func TestGenerator_Run_Dedup_OnDuplicateRegenerate_RetriesBeforeGivingUp(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 2
+	cfg.Workers = 1
+	cfg.Dedup = DedupConfig{Enabled: true, Threshold: 0.8, OnDuplicate: "regenerate"}
+
+	// MockProvider always answers with the same text regardless of prompt,
+	// so every regenerate retry for the second sample is still a duplicate
+	// of the first; this confirms the retry loop runs to its cap rather
+	// than giving up after a single attempt.
+	prov := &MockProvider{Response: `{"instruction": "write a short poem about the ocean at dawn", "output": "waves glitter under the rising sun"}`}
+	gen := New(cfg, prov, schema.NewInstructionSchema())
+	gen.SetSampler(&MockSampler{Topic: "ocean"})
+	writer := &MockWriter{}
+	gen.SetWriter(writer)
+
+	res, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+	if res.DuplicatesFound != 1 {
+		t.Errorf("expected 1 duplicate found, got %d", res.DuplicatesFound)
+	}
+	if res.SuccessCount != 1 {
+		t.Errorf("expected 1 success, got %d", res.SuccessCount)
+	}
+	wantCalls := 1 + maxDuplicateRegenerateAttempts // first sample's success + every regenerate attempt for the second
+	if prov.Calls != wantCalls {
+		t.Errorf("provider Calls = %d, want %d (regenerate should retry up to the cap)", prov.Calls, wantCalls)
+	}
+	// Every call, including the discarded duplicate retries, cost real
+	// provider tokens (MockProvider.Generate always returns 10); none of
+	// that spend should vanish from TotalTokens.
+	wantTokens := wantCalls * 10
+	if res.TotalTokens != wantTokens {
+		t.Errorf("TotalTokens = %d, want %d (discarded regenerate attempts still cost tokens)", res.TotalTokens, wantTokens)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -16,15 +18,49 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shantoislamdev/kothaset/internal/audit"
+	"github.com/shantoislamdev/kothaset/internal/cache"
+	"github.com/shantoislamdev/kothaset/internal/metrics"
 	"github.com/shantoislamdev/kothaset/internal/output"
 	"github.com/shantoislamdev/kothaset/internal/provider"
 	"github.com/shantoislamdev/kothaset/internal/schema"
+	"github.com/shantoislamdev/kothaset/internal/telemetry"
 )
 
-const cacheDir = ".kothaset"
+const defaultCacheDir = ".kothaset"
+
+// checkpointVersion guards against loading a checkpoint written by an
+// incompatible future Checkpoint/Config shape; LoadCheckpoint doesn't
+// enforce it itself (a zero value just means "written before this field
+// existed"), but Run's resume path can use it to reject anything newer
+// than what this binary understands.
+const checkpointVersion = 1
+
+// sameOutputPath reports whether a and b refer to the same file once
+// resolved to absolute, cleaned paths.
+func sameOutputPath(a, b string) (bool, error) {
+	aAbs, err := filepath.Abs(a)
+	if err != nil {
+		return false, err
+	}
+	bAbs, err := filepath.Abs(b)
+	if err != nil {
+		return false, err
+	}
+	return filepath.Clean(aAbs) == filepath.Clean(bAbs), nil
+}
+
+// getCheckpointPath returns the path for the checkpoint file inside dir
+// (the configured cache directory, or defaultCacheDir if unset).
+func getCheckpointPath(outputPath, dir string) string {
+	if dir == "" {
+		dir = defaultCacheDir
+	}
 
-// getCheckpointPath returns the path for the checkpoint file in the cache directory
-func getCheckpointPath(outputPath string) string {
 	// Use absolute path to avoid collisions between same-named files in different dirs
 	absPath, err := filepath.Abs(outputPath)
 	if err != nil {
@@ -35,7 +71,7 @@ func getCheckpointPath(outputPath string) string {
 	safeName := strings.ReplaceAll(absPath, string(filepath.Separator), "_")
 	safeName = strings.ReplaceAll(safeName, ":", "_")
 	checkpointFile := safeName + ".checkpoint"
-	return filepath.Join(cacheDir, checkpointFile)
+	return filepath.Join(dir, checkpointFile)
 }
 
 // Config contains all settings for dataset generation
@@ -46,9 +82,42 @@ type Config struct {
 	OutputPath   string `yaml:"output_path" json:"output_path"`
 	OutputFormat string `yaml:"output_format" json:"output_format"` // jsonl, json
 
-	// Provider
-	Provider string `yaml:"provider" json:"provider"`
-	Model    string `yaml:"model" json:"model"`
+	// Compression forces the streaming codec OutputPath's JSONL writer
+	// wraps its output in ("gzip", "zstd", or "xz"), overriding the codec
+	// OutputPath's own extension would otherwise imply (see
+	// output.DetectCompression). Empty leaves extension-based detection in
+	// place, so naming OutputPath "dataset.jsonl.gz" is enough on its own.
+	//
+	// "xz" has a weaker crash-recovery guarantee than "gzip"/"zstd": its
+	// writer can't flush mid-stream (see output.flusher), so a periodic
+	// output.Writer.Sync at a CheckpointEvery boundary can't push a
+	// partially-written xz frame to disk the way it can for gzip/zstd -
+	// only a clean Close does. A crash between checkpoints can leave an
+	// .xz output truncated, which CountJSONLRecords will then fail to
+	// decode on resume.
+	Compression string `yaml:"compression,omitempty" json:"compression,omitempty"`
+
+	// Providers lists the provider/model pairs eligible to serve requests.
+	// A single entry behaves like a plain fixed-provider config; more than
+	// one enables fallover/round-robin/weighted routing per
+	// FallbackPolicy. New's caller must register a live provider.Provider
+	// for every entry's Name via Generator.AddProvider (the provider
+	// passed to New itself covers the first one).
+	Providers []ProviderSpec `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// FallbackPolicy selects how Providers is consulted when more than one
+	// entry is eligible. Defaults to FallbackFailover.
+	FallbackPolicy FallbackPolicy `yaml:"fallback_policy,omitempty" json:"fallback_policy,omitempty"`
+
+	// ProviderCooldown is how long a provider that fails HealthCheck
+	// after a retryable error is pulled out of rotation before becoming
+	// eligible again. Zero disables cooldown, even with multiple
+	// Providers configured.
+	ProviderCooldown time.Duration `yaml:"provider_cooldown,omitempty" json:"provider_cooldown,omitempty"`
+
+	// CacheDir is where checkpoints are written, mirroring
+	// config.GlobalConfig.CacheDir. Defaults to defaultCacheDir when unset.
+	CacheDir string `yaml:"cache_dir,omitempty" json:"cache_dir,omitempty"`
 
 	// Generation parameters
 	SystemPrompt string  `yaml:"system_prompt,omitempty" json:"system_prompt,omitempty"`
@@ -56,15 +125,79 @@ type Config struct {
 	MaxTokens    int     `yaml:"max_tokens" json:"max_tokens"`
 	TopP         float64 `yaml:"top_p,omitempty" json:"top_p,omitempty"`
 
+	// KSamples, when > 1, generates that many independent candidates per
+	// sample and keeps one via self-consistency instead of writing every
+	// candidate: the majority-voted label for classification-style schemas,
+	// or the highest Judge-scored candidate for everything else (with no
+	// Judge configured, open-ended self-consistency has no signal to rank
+	// candidates on, so the first one is kept). <= 1 disables it and
+	// generates a single candidate, today's behavior.
+	KSamples int `yaml:"k_samples,omitempty" json:"k_samples,omitempty"`
+
+	// Judge optionally scores every kept sample 1-5 on rubric axes via a
+	// second provider and drops it if the score falls short; see
+	// JudgeConfig. A zero value disables judging.
+	Judge JudgeConfig `yaml:"judge,omitempty" json:"judge,omitempty"`
+
+	// Dedup optionally filters near-duplicate samples across the run (and,
+	// via CacheDir, across resumes of it) using MinHash/LSH; see
+	// DedupConfig. A zero value disables it.
+	Dedup DedupConfig `yaml:"dedup,omitempty" json:"dedup,omitempty"`
+
+	// Budget optionally caps cumulative USD spend across the run, stopping
+	// new generation once reached; see BudgetConfig. A zero value (MaxUSD
+	// <= 0) disables it.
+	Budget BudgetConfig `yaml:"budget,omitempty" json:"budget,omitempty"`
+
 	// Reproducibility
 	Seed          *int64 `yaml:"seed,omitempty" json:"seed,omitempty"`
 	RandomSeed    bool   `yaml:"random_seed,omitempty" json:"random_seed,omitempty"` // Generate new random seed per request
 	Deterministic bool   `yaml:"deterministic" json:"deterministic"`
 
 	// Concurrency
-	Workers   int `yaml:"workers" json:"workers"`
-	BatchSize int `yaml:"batch_size" json:"batch_size"`
-	RateLimit int `yaml:"rate_limit" json:"rate_limit"`
+	Workers int `yaml:"workers" json:"workers"`
+
+	// BatchSize caps how many completed samples the collector accumulates
+	// before flushing them to the writer as a group, alongside BatchMaxBytes
+	// - whichever bound is hit first cuts the batch (see splitWriteBatch).
+	// <= 0 (the default) disables batching: every sample is written as soon
+	// as it completes, the generator's original behavior.
+	BatchSize int `yaml:"batch_size,omitempty" json:"batch_size,omitempty"`
+
+	// BatchMaxBytes caps a write flush's total serialized size, alongside
+	// BatchSize. <= 0 defaults to defaultBatchMaxBytes (4 MiB). Only takes
+	// effect once BatchSize > 1; with batching disabled there's never more
+	// than one sample in a "batch" to weigh against it.
+	BatchMaxBytes int64 `yaml:"batch_max_bytes,omitempty" json:"batch_max_bytes,omitempty"`
+
+	RateLimit      int `yaml:"rate_limit" json:"rate_limit"`
+	RateLimitBurst int `yaml:"rate_limit_burst,omitempty" json:"rate_limit_burst,omitempty"`
+
+	// BatchMode, if true, bypasses the live worker pool entirely and
+	// submits every remaining sample's prompt as a single asynchronous job
+	// through provider.BatchProvider (OpenAI's Batch API, Anthropic's
+	// Message Batches API), polling it to completion instead of making one
+	// Generate call per sample. Requires g.provider to implement
+	// provider.BatchProvider; Run returns an error otherwise. Streaming,
+	// self-consistency (KSamples), the response cache, and multi-provider
+	// routing don't apply in this mode - see runBatch.
+	BatchMode bool `yaml:"batch_mode,omitempty" json:"batch_mode,omitempty"`
+
+	// TPMLimit paces tokens-per-minute the same way RateLimit paces
+	// requests-per-minute; <= 0 disables it. Like RateLimit, it's the
+	// default for any ProviderSpec that doesn't set its own TPMLimit.
+	TPMLimit int `yaml:"tpm_limit,omitempty" json:"tpm_limit,omitempty"`
+
+	// Streaming, if true, uses Provider.GenerateStream instead of
+	// Generate for providers that support it (SupportsStreaming),
+	// falling back to a plain Generate call otherwise.
+	Streaming bool `yaml:"streaming,omitempty" json:"streaming,omitempty"`
+
+	// StreamWorkers caps how many streaming calls may be in flight at
+	// once, separately from Workers. Streaming calls hold their socket
+	// open far longer than a batch call, so mixing them under one limit
+	// can starve batch throughput. Defaults to Workers if unset.
+	StreamWorkers int `yaml:"stream_workers,omitempty" json:"stream_workers,omitempty"`
 
 	// Resilience
 	MaxRetries      int           `yaml:"max_retries" json:"max_retries"`
@@ -81,6 +214,20 @@ type Config struct {
 	// Context from context.yaml (free-form paragraphs)
 	UserContext     string `yaml:"user_context,omitempty" json:"user_context,omitempty"`
 	UserInstruction string `yaml:"user_instruction,omitempty" json:"user_instruction,omitempty"`
+
+	// EmitRejectionSidecar records every sample that fails schema
+	// validation, along with its full field-by-field ValidationReport,
+	// to the writer's rejection sidecar if it implements
+	// output.RejectionWriter. Off by default since most runs don't need
+	// it and it costs an extra write per rejection.
+	EmitRejectionSidecar bool `yaml:"emit_rejection_sidecar,omitempty" json:"emit_rejection_sidecar,omitempty"`
+
+	// RunID tags every structured log record (see SetLogger) and is
+	// persisted to Checkpoint so log lines can be correlated with the
+	// checkpoint a run produced. Callers generate one per invocation and
+	// backfill it from the checkpoint's own RunID on resume, the same way
+	// --providers and --fallback are backfilled.
+	RunID string `yaml:"run_id,omitempty" json:"run_id,omitempty"`
 }
 
 // DefaultConfig returns sensible defaults
@@ -106,6 +253,20 @@ type Result struct {
 	DuplicatesFound int `json:"duplicates_found"`
 	TotalTokens     int `json:"total_tokens"`
 
+	// CacheHits is how many samples were served from Generator.cache
+	// instead of a provider call. CachedTokens is the sum of those hits'
+	// original Usage.TotalTokens, i.e. the tokens this run avoided
+	// billing; it is not included in TotalTokens.
+	CacheHits    int `json:"cache_hits,omitempty"`
+	CachedTokens int `json:"cached_tokens,omitempty"`
+
+	// SpentUSD is cumulative spend as priced by Config.Budget.Prices,
+	// zero when Config.Budget is unset. StoppedOnBudget is true when Run
+	// stopped submitting new samples because Config.Budget.MaxUSD was
+	// reached, rather than because every sample was attempted.
+	SpentUSD        float64 `json:"spent_usd,omitempty"`
+	StoppedOnBudget bool    `json:"stopped_on_budget,omitempty"`
+
 	Duration       time.Duration `json:"duration"`
 	OutputPath     string        `json:"output_path"`
 	CheckpointPath string        `json:"checkpoint_path,omitempty"`
@@ -129,17 +290,47 @@ type ProgressCallback func(Progress)
 
 // Generator orchestrates dataset generation
 type Generator struct {
-	config   Config
+	config Config
+	// provider is the default/primary provider, passed to New. It also
+	// covers the single-provider case, where Config.Providers is empty.
 	provider provider.Provider
-	schema   schema.Schema
-	sampler  Sampler
-	// Request limiter used to enforce provider RPM limits.
-	rateLimiter *RateLimiter
+	// providers holds every provider instance available for routing,
+	// keyed by Name(); populated with provider under its own name by New
+	// and extended by AddProvider for any other entries in
+	// Config.Providers.
+	providers map[string]provider.Provider
+	// router dispatches each request to one of providers per
+	// Config.FallbackPolicy; built once at the start of Run.
+	router *providerRouter
+
+	// providerUsageMu guards providerUsage, an aggregate count of
+	// completed samples per provider name, surfaced in Checkpoint so a
+	// resumed run keeps attribution across providers without needing to
+	// store per-sample state (each written sample already carries its own
+	// provider in schema.SampleMetadata).
+	providerUsageMu sync.Mutex
+	providerUsage   map[string]int
+
+	schema  schema.Schema
+	sampler Sampler
+
+	// deduper, if Config.Dedup.Enabled, filters near-duplicate samples
+	// across the run; built once at the start of Run, loading a persisted
+	// index from CacheDir on resume.
+	deduper *Deduper
+
+	// budget, if Config.Budget.MaxUSD > 0, tracks cumulative USD spend and
+	// tells Run when to stop submitting new samples; built once at the
+	// start of Run.
+	budget *Budget
 
 	// State - only store counts, not samples (to prevent memory leaks)
-	completed  int32
-	failed     int32
-	tokensUsed int64
+	completed       int32
+	failed          int32
+	duplicatesFound int32
+	tokensUsed      int64
+	cacheHits       int32
+	cachedTokens    int64
 
 	// Callbacks
 	onProgress ProgressCallback
@@ -147,20 +338,81 @@ type Generator struct {
 	// Output
 	writer output.Writer
 
+	// auditSink, if set, receives one audit.Event per provider.Generate
+	// attempt made by generateSample. Nil by default since most runs
+	// don't need a structured audit trail.
+	auditSink audit.Sink
+
+	// streamProgress, if set, receives partial tokens as they arrive
+	// during a streaming generation (see Config.Streaming). Nil by
+	// default since most runs are non-interactive.
+	streamProgress io.Writer
+
+	// logger, if set, receives one structured record per request start,
+	// retry, rate-limit wait, and checkpoint flush (see internal/logging).
+	// Callers tag it with run_id themselves (logging.New does this) so it
+	// correlates with Config.RunID, persisted in Checkpoint. Nil by
+	// default; a run that wants these events sets it via SetLogger
+	// before Run.
+	logger *slog.Logger
+
+	// cache, if set, is consulted before every provider call in
+	// generateCandidate; a hit skips the call entirely and is recorded
+	// separately in Result.CachedTokens instead of Result.TotalTokens.
+	// Nil by default since most runs generate fresh samples every time.
+	cache cache.Cache
+
 	// Test hook for retry jitter
 	randFloat func() float64
+
+	// batchJobID is the provider.BatchProvider job ID submitted by runBatch
+	// (see Config.BatchMode), persisted via saveCheckpoint so a resumed run
+	// can poll the existing job instead of resubmitting. Empty outside
+	// batch mode.
+	batchJobID string
+
+	// batchRangeStart/batchRangeCount are the sample-index range
+	// batchJobID was submitted for (see Checkpoint.BatchRange); fixed once
+	// the job is submitted and unaffected by baseCompleted advancing as
+	// results are applied.
+	batchRangeStart int
+	batchRangeCount int
+
+	// batchAppliedMu guards batchApplied, the set of BatchRequest.CustomID
+	// values from batchJobID already applied via finishFromResponse, so a
+	// resumed run can skip re-applying a result from a prior attempt at the
+	// same job instead of relying on index ranges, which a batch job's
+	// out-of-order completion can't guarantee line up with baseCompleted.
+	batchAppliedMu sync.Mutex
+	batchApplied   map[string]bool
+
+	// Test hook for batchPollInterval's base delay; zero means use
+	// defaultBatchPollBase.
+	batchPollBase time.Duration
 }
 
-// New creates a new generator
+// New creates a new generator. prov is the default/primary provider; for
+// multi-provider routing (see Config.Providers), call AddProvider for
+// every other entry before Run.
 func New(cfg Config, prov provider.Provider, sch schema.Schema) *Generator {
 	return &Generator{
-		config:    cfg,
-		provider:  prov,
-		schema:    sch,
-		randFloat: rand.Float64,
+		config:        cfg,
+		provider:      prov,
+		providers:     map[string]provider.Provider{prov.Name(): prov},
+		providerUsage: make(map[string]int),
+		schema:        sch,
+		randFloat:     rand.Float64,
 	}
 }
 
+// AddProvider registers an additional named provider instance for
+// multi-provider routing. The provider passed to New is already
+// registered under its own Name(); call AddProvider once per remaining
+// entry in Config.Providers before Run.
+func (g *Generator) AddProvider(name string, prov provider.Provider) {
+	g.providers[name] = prov
+}
+
 // SetProgressCallback sets the progress callback
 func (g *Generator) SetProgressCallback(cb ProgressCallback) {
 	g.onProgress = cb
@@ -176,22 +428,157 @@ func (g *Generator) SetWriter(w output.Writer) {
 	g.writer = w
 }
 
+// SetAuditSink sets the sink that receives a structured audit.Event for
+// every provider.Generate attempt. Leave unset to disable audit events.
+func (g *Generator) SetAuditSink(s audit.Sink) {
+	g.auditSink = s
+}
+
+// effectiveCompression returns the codec OutputPath is (or will be)
+// compressed with - Config.Compression if set, else whatever OutputPath's
+// own extension implies - or "" if the output isn't a (possibly
+// compressed) JSONL file at all, in which case CountJSONLRecords wouldn't
+// know how to make sense of it.
+func (g *Generator) effectiveCompression() string {
+	if g.config.OutputFormat != "" && g.config.OutputFormat != "jsonl" {
+		return ""
+	}
+	if g.config.Compression != "" {
+		return g.config.Compression
+	}
+	return output.DetectCompression(g.config.OutputPath)
+}
+
+// SetCache sets the cache consulted before every provider call. Leave
+// unset to always call the provider.
+func (g *Generator) SetCache(c cache.Cache) {
+	g.cache = c
+}
+
+// SetStreamProgress sets the writer that receives partial tokens as
+// they arrive during a streaming generation (see Config.Streaming). Pass
+// nil to disable forwarding.
+func (g *Generator) SetStreamProgress(w io.Writer) {
+	g.streamProgress = w
+}
+
+// SetLogger sets the structured logger that receives one record per
+// request start, retry, rate-limit wait, and checkpoint flush. Leave
+// unset to disable these events entirely.
+func (g *Generator) SetLogger(logger *slog.Logger) {
+	g.logger = logger
+}
+
+// logEvent writes msg to g.logger with attrs plus the run's run_id, a
+// no-op when no logger is set.
+func (g *Generator) logEvent(msg string, attrs ...any) {
+	if g.logger == nil {
+		return
+	}
+	g.logger.Info(msg, attrs...)
+}
+
+// SetRateLimit replaces name's rate limiter with one configured for the
+// given requests-per-minute and tokens-per-minute values (either <= 0
+// disables that dimension). The previous limiter is not closed until the
+// swap completes, so a worker already blocked in Acquire on it keeps
+// waiting for a token rather than getting cut off. Safe to call
+// concurrently with Run; calling it before Run has built the router (and
+// for a name not among Config.Providers) has no effect.
+func (g *Generator) SetRateLimit(name string, requestsPerMinute, tokensPerMinute int) {
+	if g.router == nil {
+		return
+	}
+	g.router.SetLimit(name, requestsPerMinute, g.config.RateLimitBurst, tokensPerMinute, 0)
+}
+
 // Run executes the generation process
-func (g *Generator) Run(ctx context.Context) (*Result, error) {
+func (g *Generator) Run(ctx context.Context) (result *Result, err error) {
 	startTime := time.Now()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	ctx, span := telemetry.Tracer().Start(ctx, "kothaset.generate.run", trace.WithAttributes(
+		attribute.String("kothaset.schema", g.config.Schema),
+		attribute.Int("kothaset.num_samples", g.config.NumSamples),
+		attribute.Bool("kothaset.batch_mode", g.config.BatchMode),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if result != nil {
+			span.SetAttributes(
+				attribute.Int("kothaset.success_count", result.SuccessCount),
+				attribute.Int("kothaset.failed_count", result.FailedCount),
+				attribute.Int("kothaset.duplicates_found", result.DuplicatesFound),
+				attribute.Int64("kothaset.total_tokens", int64(result.TotalTokens)),
+			)
+		}
+		span.End()
+	}()
+
 	// Load checkpoint if resuming
 	if g.config.ResumeFrom != "" {
 		checkpoint, err := LoadCheckpoint(g.config.ResumeFrom)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load checkpoint: %w", err)
 		}
+		if checkpoint.SchemaVersion > checkpointVersion {
+			return nil, fmt.Errorf("checkpoint was written by a newer version of kothaset (schema version %d > %d)", checkpoint.SchemaVersion, checkpointVersion)
+		}
+		if checkpoint.Config.Schema != "" && checkpoint.Config.Schema != g.config.Schema {
+			return nil, fmt.Errorf("resume schema mismatch: checkpoint=%s current=%s", checkpoint.Config.Schema, g.config.Schema)
+		}
+		if checkpoint.Config.OutputPath != "" {
+			same, err := sameOutputPath(checkpoint.Config.OutputPath, g.config.OutputPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare checkpoint output path: %w", err)
+			}
+			if !same {
+				return nil, fmt.Errorf("resume output mismatch: checkpoint=%s current=%s", checkpoint.Config.OutputPath, g.config.OutputPath)
+			}
+		}
+
+		// A compressed output can't be seeked into the way a plain JSONL
+		// file's byte offset can, so instead of trusting Checkpoint.Completed
+		// outright, stream-decompress the file we're about to append to and
+		// count its records. A mismatch means the output file and the
+		// checkpoint drifted apart (e.g. the output file was edited, or
+		// replaced, between runs) and resuming would silently duplicate or
+		// lose records.
+		if codec := g.effectiveCompression(); codec != "" {
+			if count, err := output.CountJSONLRecords(g.config.OutputPath, codec); err == nil {
+				if count != int(checkpoint.Completed) {
+					return nil, fmt.Errorf("resume output mismatch: output file %s has %d records, checkpoint expects %d completed", g.config.OutputPath, count, checkpoint.Completed)
+				}
+			} else if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to validate resumed output file: %w", err)
+			}
+		}
+
+		if checkpoint.Completed > g.config.NumSamples {
+			return nil, fmt.Errorf("resume count mismatch: checkpoint has %d completed samples, which exceeds the requested %d", checkpoint.Completed, g.config.NumSamples)
+		}
+
 		// Resume from checkpoint count - samples already written to output file
 		atomic.StoreInt32(&g.completed, int32(checkpoint.Completed))
 		atomic.StoreInt32(&g.failed, int32(checkpoint.Failed))
 		atomic.StoreInt64(&g.tokensUsed, int64(checkpoint.TokensUsed))
+		g.providerUsageMu.Lock()
+		for name, count := range checkpoint.ProviderUsage {
+			g.providerUsage[name] = count
+		}
+		g.providerUsageMu.Unlock()
+		g.batchJobID = checkpoint.BatchJobID
+		g.batchRangeStart = checkpoint.BatchRangeStart
+		g.batchRangeCount = checkpoint.BatchRangeCount
+		if len(checkpoint.BatchAppliedIDs) > 0 {
+			g.batchApplied = make(map[string]bool, len(checkpoint.BatchAppliedIDs))
+			for _, id := range checkpoint.BatchAppliedIDs {
+				g.batchApplied[id] = true
+			}
+		}
 	}
 
 	// Open output writer if not already set
@@ -204,6 +591,63 @@ func (g *Generator) Run(ctx context.Context) (*Result, error) {
 		return nil, fmt.Errorf("sampler not set: input file is mandatory")
 	}
 
+	// Build the provider router. A single-provider Config (the common
+	// case) gets an implicit one-entry spec built from the default
+	// provider passed to New, so the rest of Run never needs to branch on
+	// whether routing is in play.
+	specs := g.config.Providers
+	if len(specs) == 0 {
+		specs = []ProviderSpec{{Name: g.provider.Name(), Model: g.provider.Model()}}
+	}
+	router, err := newProviderRouter(specs, g.providers, g.config.FallbackPolicy, g.config.ProviderCooldown, routerLimits{
+		RPM:   g.config.RateLimit,
+		Burst: g.config.RateLimitBurst,
+		TPM:   g.config.TPMLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.router = router
+	defer g.router.Close()
+
+	// Build the deduper. On resume, pick up the persisted index so
+	// near-duplicate checks still account for every sample accepted in
+	// prior runs; otherwise start from an empty one.
+	if g.config.Dedup.Enabled {
+		dedupPath := getDedupPath(g.config.OutputPath, g.config.CacheDir)
+		if g.config.ResumeFrom != "" {
+			if d, err := LoadDeduper(dedupPath); err == nil {
+				g.deduper = d
+			}
+		}
+		if g.deduper == nil {
+			g.deduper = NewDeduper(g.config.Dedup)
+		}
+	}
+
+	// Build the budget tracker, if a cap is configured.
+	if g.config.Budget.MaxUSD > 0 {
+		g.budget = NewBudget(g.config.Budget)
+	}
+
+	// Force a compression codec on the writer if one was configured
+	// explicitly, rather than leaving it to the writer's own
+	// extension-based detection (see output.CompressionWriter). A writer
+	// that doesn't implement CompressionWriter at all (e.g. MultiWriter
+	// fanning out to multiple formats, or a binary format like Parquet
+	// that compresses internally) can't honor this, so fail loudly rather
+	// than silently generating an uncompressed output the user didn't ask
+	// for.
+	if g.config.Compression != "" {
+		cw, ok := g.writer.(output.CompressionWriter)
+		if !ok {
+			return nil, fmt.Errorf("output format %q does not support --compression", g.config.OutputFormat)
+		}
+		if err := cw.SetCompression(g.config.Compression); err != nil {
+			return nil, fmt.Errorf("failed to configure output compression: %w", err)
+		}
+	}
+
 	// Open output - use append mode when resuming to preserve existing data
 	if g.config.ResumeFrom != "" {
 		if err := g.writer.OpenAppend(g.config.OutputPath); err != nil {
@@ -220,11 +664,24 @@ func (g *Generator) Run(ctx context.Context) (*Result, error) {
 	baseCompleted := int(atomic.LoadInt32(&g.completed))
 	remaining := g.config.NumSamples - baseCompleted
 
-	// Create worker pool
-	pool := NewWorkerPool(g.config.Workers)
-	g.rateLimiter = NewRateLimiter(g.config.RateLimit)
-	defer g.rateLimiter.Close()
+	if g.config.BatchMode {
+		return g.runBatch(ctx, startTime, baseCompleted, remaining)
+	}
 
+	// Create worker pool. Streaming calls get their own sub-limit
+	// (defaulting to Workers if unset) so they can't starve batch calls.
+	streamWorkers := g.config.StreamWorkers
+	if streamWorkers <= 0 {
+		streamWorkers = g.config.Workers
+	}
+	pool := NewWorkerPoolWithStreamLimit(g.config.Workers, streamWorkers)
+	workKind := WorkBatch
+	// Sized against the default/primary provider; a routed provider that
+	// doesn't support streaming just falls back to a plain Generate call
+	// for its requests (see generateSample).
+	if g.config.Streaming && g.provider.SupportsStreaming() {
+		workKind = WorkStream
+	}
 	// Submit work
 	resultBuffer := g.config.Workers * 2
 	if resultBuffer < 1 {
@@ -236,54 +693,223 @@ func (g *Generator) Run(ctx context.Context) (*Result, error) {
 	var writeErr error
 	collectorDone := make(chan struct{})
 
+	// Batching bounds for the write flush below (see writebatch.go). Both
+	// default to a single item, so a config that never sets BatchSize writes
+	// one sample at a time exactly as before.
+	batchMaxCount := g.effectiveBatchSize()
+	batchMaxBytes := g.effectiveBatchMaxBytes()
+	var pending []*workerResult
+	var pendingBytes int64
+
+	// advanceProgress reports progress and ticks the checkpoint counter for
+	// one processed result, regardless of whether it was written, a
+	// duplicate, or a failure - shared by flush (for writes) and the
+	// collector loop below (for duplicate/failed results, which never reach
+	// flush) so the two paths can't drift out of sync.
+	advanceProgress := func() {
+		g.reportProgress(startTime)
+
+		checkpointCounter++
+		if g.config.CheckpointEvery > 0 && checkpointCounter >= g.config.CheckpointEvery {
+			// Sync to physical storage before checkpointing for crash-safe durability
+			if err := g.writer.Sync(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to sync output: %v\n", err)
+			}
+			if err := g.saveCheckpoint(); err != nil {
+				// Log but don't fail
+				fmt.Fprintf(os.Stderr, "Warning: failed to save checkpoint: %v\n", err)
+			}
+			checkpointCounter = 0
+		}
+	}
+
+	// writerBatch is g.writer's optional BatchWriter capability: writing a
+	// batch through it amortizes one flush/syscall across every sample in
+	// the group instead of paying it per sample (see output.BatchWriter),
+	// which is the whole point of batching. A writer that doesn't implement
+	// it (or a single-item batch, the no-batching default) just gets one
+	// Write call per sample.
+	writerBatch, _ := g.writer.(output.BatchWriter)
+
+	// flush writes batch to g.writer in order, only advancing g.completed
+	// (and therefore Checkpoint.Completed, via saveCheckpoint) for samples
+	// that actually land on disk. If a write fails partway through, the
+	// failed item and everything queued after it in batch are returned
+	// unflushed rather than discarded, leaving their failed/completed
+	// bookkeeping to the caller - once flush has failed once, nothing it
+	// returns will ever be retried (see the writeErr != nil branch below),
+	// so the caller accounts for all of it as failed in one place instead
+	// of flush double-counting just the first item.
+	flush := func(batch []*workerResult) (rescued []*workerResult) {
+		written := len(batch)
+		var writeFailErr error
+
+		if writerBatch != nil && len(batch) > 1 {
+			samples := make([]*schema.Sample, len(batch))
+			for i, result := range batch {
+				samples[i] = result.sample
+			}
+			written, writeFailErr = writerBatch.WriteBatch(samples)
+		} else {
+			for i, result := range batch {
+				if err := g.writer.Write(result.sample); err != nil {
+					written, writeFailErr = i, err
+					break
+				}
+			}
+		}
+
+		for _, result := range batch[:written] {
+			atomic.AddInt32(&g.completed, 1)
+			atomic.AddInt64(&g.tokensUsed, int64(result.tokens))
+			if g.budget != nil && result.tokens > 0 {
+				g.budget.Charge(result.provider, result.model, result.tokens)
+			}
+			if result.duplicateKept {
+				atomic.AddInt32(&g.duplicatesFound, 1)
+			}
+			if result.cached {
+				atomic.AddInt32(&g.cacheHits, 1)
+				atomic.AddInt64(&g.cachedTokens, int64(result.cachedTokens))
+			}
+			metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "success").Inc()
+			metrics.SampleTokens.WithLabelValues(g.config.Schema, "output").Observe(float64(result.tokens))
+			telemetry.IncSuccess(ctx, g.config.Schema, result.provider)
+			telemetry.RecordSampleTokens(ctx, g.config.Schema, result.tokens)
+			if result.cached {
+				telemetry.IncCacheHit(ctx, g.config.Schema, result.provider)
+			}
+
+			advanceProgress()
+		}
+
+		if writeFailErr != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Write failed: %v\n", writeFailErr)
+			if writeErr == nil {
+				writeErr = writeFailErr
+				cancel()
+			}
+			return batch[written:]
+		}
+		return nil
+	}
+
 	// Always start collector so workers can never block forever on results sends.
 	go func() {
 		defer close(collectorDone)
 		for result := range results {
-			if result.err != nil {
+			if result.regenerateWastedTokens > 0 {
+				// Already charged to the budget per-attempt in generateCandidate,
+				// against each discarded attempt's own provider/model; only the
+				// token total is still owed here.
+				atomic.AddInt64(&g.tokensUsed, int64(result.regenerateWastedTokens))
+			}
+			if result.err != nil && result.duplicate {
+				atomic.AddInt32(&g.duplicatesFound, 1)
+				if result.cached {
+					atomic.AddInt32(&g.cacheHits, 1)
+					atomic.AddInt64(&g.cachedTokens, int64(result.cachedTokens))
+				}
+				metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "duplicate").Inc()
+				telemetry.IncDuplicate(ctx, g.config.Schema, result.provider)
+			} else if result.err != nil {
 				atomic.AddInt32(&g.failed, 1)
+				metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "error").Inc()
+				telemetry.IncFailure(ctx, g.config.Schema, result.provider)
 				// Log the error so failures are not silently swallowed
 				fmt.Fprintf(os.Stderr, "⚠ Sample failed: %v\n", result.err)
-			} else {
-				// Write to output immediately - don't store in memory to prevent memory leaks
-				if err := g.writer.Write(result.sample); err != nil {
+
+				if g.config.EmitRejectionSidecar && result.report != nil {
+					if rw, ok := g.writer.(output.RejectionWriter); ok {
+						if err := rw.WriteRejection(result.sample, result.report); err != nil {
+							fmt.Fprintf(os.Stderr, "⚠ Failed to write rejection sidecar: %v\n", err)
+						}
+					}
+				}
+			} else if writeErr != nil {
+				// A prior flush already hit a write error and cancelled the
+				// run; the writer broke and isn't expected to recover
+				// mid-run, so don't keep queuing newly-completed samples
+				// behind it - count each as failed outright, the same as
+				// the pre-batching collector did for every post-error
+				// result.
+				atomic.AddInt32(&g.failed, 1)
+				metrics.SamplesGenerated.WithLabelValues(g.config.Schema, result.provider, "error").Inc()
+				continue
+			} else if batchMaxCount <= 1 {
+				// Batching disabled (the default config): flush this one
+				// sample immediately rather than routing it through
+				// pending/splitWriteBatch, preserving the original
+				// one-write-per-sample cost for the common case.
+				for _, r := range flush([]*workerResult{result}) {
 					atomic.AddInt32(&g.failed, 1)
-					fmt.Fprintf(os.Stderr, "⚠ Write failed: %v\n", err)
-					if writeErr == nil {
-						writeErr = err
-						cancel()
+					metrics.SamplesGenerated.WithLabelValues(g.config.Schema, r.provider, "error").Inc()
+				}
+				continue
+			} else {
+				// Accumulate rather than writing immediately, so a run of
+				// samples can be flushed together once a count or byte
+				// bound is hit (see writebatch.go). pendingBytes tracks the
+				// accumulated serialized size incrementally so this check
+				// doesn't re-marshal the whole slice on every append.
+				pending = append(pending, result)
+				pendingBytes += result.cachedJSONSize()
+				if len(pending) >= batchMaxCount || pendingBytes >= batchMaxBytes {
+					batch, rest, batchBytes := splitWriteBatch(pending, batchMaxCount, batchMaxBytes)
+					pending = append(flush(batch), rest...)
+					if writeErr != nil {
+						// This flush just failed; nothing left in pending
+						// will be attempted again, so account every one of
+						// them as failed now instead of leaving them to
+						// vanish from both SuccessCount and FailedCount.
+						for _, r := range pending {
+							atomic.AddInt32(&g.failed, 1)
+							metrics.SamplesGenerated.WithLabelValues(g.config.Schema, r.provider, "error").Inc()
+						}
+						pending = nil
+						pendingBytes = 0
+					} else {
+						// flush(batch) succeeded in full (returned nil), so
+						// pending is now just rest; its bytes are whatever
+						// wasn't claimed by the flushed batch.
+						pendingBytes -= batchBytes
 					}
-					continue
 				}
-
-				atomic.AddInt32(&g.completed, 1)
-				atomic.AddInt64(&g.tokensUsed, int64(result.tokens))
+				continue
 			}
 
-			// Update progress
-			g.reportProgress(startTime)
+			// Duplicate/failed results never reach flush, so advance
+			// progress/checkpoint for them here instead.
+			advanceProgress()
+		}
 
-			// Checkpoint
-			checkpointCounter++
-			if g.config.CheckpointEvery > 0 && checkpointCounter >= g.config.CheckpointEvery {
-				// Sync to physical storage before checkpointing for crash-safe durability
-				if err := g.writer.Sync(); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to sync output: %v\n", err)
-				}
-				if err := g.saveCheckpoint(); err != nil {
-					// Log but don't fail
-					fmt.Fprintf(os.Stderr, "Warning: failed to save checkpoint: %v\n", err)
-				}
-				checkpointCounter = 0
+		// Results channel is closed; make one last attempt to land whatever
+		// didn't hit a batch bound (or was rescued from a failed flush) -
+		// there's no more work coming in to wait for, and a writer that just
+		// failed is unlikely to recover mid-run.
+		if writeErr == nil && len(pending) > 0 {
+			for _, r := range flush(pending) {
+				atomic.AddInt32(&g.failed, 1)
+				metrics.SamplesGenerated.WithLabelValues(g.config.Schema, r.provider, "error").Inc()
 			}
 		}
 	}()
 
+	stoppedOnBudget := false
 loop:
 	for i := 0; i < remaining; i++ {
+		if g.budget != nil && g.budget.Exceeded() {
+			// Clean shutdown: stop submitting new work, but let whatever's
+			// already in flight finish and write normally rather than
+			// cancelling them mid-request.
+			stoppedOnBudget = true
+			g.logEvent("budget exceeded, stopping new generation", "spent_usd", g.budget.Spent(), "max_usd", g.config.Budget.MaxUSD)
+			break loop
+		}
+
 		// Acquire a worker slot *before* spawning the goroutine
 		// This provides backpressure and prevents spawning millions of goroutines
-		if err := pool.Acquire(ctx); err != nil {
+		if err := pool.AcquireTyped(ctx, workKind); err != nil {
 			break loop
 		}
 
@@ -292,7 +918,7 @@ loop:
 
 		go func(idx int) {
 			defer wg.Done()
-			defer pool.Release()
+			defer pool.ReleaseTyped(workKind)
 
 			result := g.generateSample(ctx, idx)
 			results <- result
@@ -311,19 +937,7 @@ loop:
 		fmt.Fprintf(os.Stderr, "Warning: failed to save final checkpoint: %v\n", err)
 	}
 
-	duration := time.Since(startTime)
-	tokens := int(atomic.LoadInt64(&g.tokensUsed))
-
-	result := &Result{
-		TotalSamples: g.config.NumSamples,
-		SuccessCount: int(atomic.LoadInt32(&g.completed)),
-		FailedCount:  int(atomic.LoadInt32(&g.failed)),
-		TotalTokens:  tokens,
-
-		Duration:   duration,
-		OutputPath: g.config.OutputPath,
-	}
-
+	result = g.buildResult(startTime, stoppedOnBudget)
 	if writeErr != nil {
 		return result, fmt.Errorf("generation completed with write errors: %w", writeErr)
 	}
@@ -331,10 +945,96 @@ loop:
 	return result, nil
 }
 
+// buildResult assembles a Result from the atomic counters Run/runBatch
+// accumulate over the course of a run, shared so both the live worker-pool
+// path and Config.BatchMode's path report the same shape.
+func (g *Generator) buildResult(startTime time.Time, stoppedOnBudget bool) *Result {
+	result := &Result{
+		TotalSamples:    g.config.NumSamples,
+		SuccessCount:    int(atomic.LoadInt32(&g.completed)),
+		FailedCount:     int(atomic.LoadInt32(&g.failed)),
+		DuplicatesFound: int(atomic.LoadInt32(&g.duplicatesFound)),
+		TotalTokens:     int(atomic.LoadInt64(&g.tokensUsed)),
+		CacheHits:       int(atomic.LoadInt32(&g.cacheHits)),
+		CachedTokens:    int(atomic.LoadInt64(&g.cachedTokens)),
+		StoppedOnBudget: stoppedOnBudget,
+
+		Duration:   time.Since(startTime),
+		OutputPath: g.config.OutputPath,
+	}
+	if g.budget != nil {
+		result.SpentUSD = g.budget.Spent()
+	}
+	return result
+}
+
 type workerResult struct {
 	sample *schema.Sample
 	tokens int
 	err    error
+
+	// report is set only when err came from a failed ValidateSample, so
+	// the collector can hand the full multi-field breakdown to an
+	// output.RejectionWriter instead of just the combined error string.
+	report *schema.ValidationReport
+
+	// provider is the Name() of whichever provider g.router selected to
+	// serve this sample, used for the collector's metrics labels instead
+	// of a single fixed g.config.Provider now that a run may route across
+	// several.
+	provider string
+
+	// model is the configured ProviderSpec.Model for whichever request
+	// actually produced tokens billed to this result (empty for results
+	// with nothing billed), used to price Generator.budget charges. This is
+	// deliberately the model name the caller configured (and so can also
+	// price via --budget-price), not resp.Model: some providers echo back a
+	// resolved snapshot id for an alias model name, which wouldn't match a
+	// price keyed on the alias.
+	model string
+
+	// duplicate is set when err came from the Deduper rejecting the sample
+	// as a near-duplicate, so the collector counts it in DuplicatesFound
+	// instead of FailedCount.
+	duplicate bool
+
+	// duplicateKept is set when the Deduper flagged the sample as a
+	// near-duplicate but Config.Dedup.OnDuplicate is "keep", so err is nil
+	// (the sample is written like any other) but the collector still counts
+	// it in DuplicatesFound.
+	duplicateKept bool
+
+	// cached is set when sample came from Generator.cache instead of a
+	// provider call; the collector counts it in CacheHits and attributes
+	// cachedTokens to CachedTokens instead of tokens to TotalTokens.
+	cached       bool
+	cachedTokens int
+
+	// regenerateWastedTokens is the token cost of every near-duplicate
+	// attempt generateCandidate discarded and retried while
+	// Config.Dedup.OnDuplicate is "regenerate", before arriving at this
+	// result. The collector always adds it to TotalTokens, regardless of
+	// whether this result itself is a success or a final duplicate, since
+	// the provider was genuinely billed for those discarded attempts.
+	regenerateWastedTokens int
+
+	// jsonSize caches sampleJSONSize(sample) the first time the write
+	// batcher weighs this result against a byte budget (see writebatch.go),
+	// so a result that's re-examined across multiple splitWriteBatch calls
+	// (held back once, then reconsidered in the next flush) isn't
+	// re-marshaled each time.
+	jsonSize         int64
+	jsonSizeComputed bool
+}
+
+// cachedJSONSize returns sampleJSONSize(r.sample), computing and caching it
+// on r the first time it's asked for.
+func (r *workerResult) cachedJSONSize() int64 {
+	if !r.jsonSizeComputed {
+		r.jsonSize = sampleJSONSize(r.sample)
+		r.jsonSizeComputed = true
+	}
+	return r.jsonSize
 }
 
 // generateRandomSeed creates a cryptographically secure random seed
@@ -380,8 +1080,122 @@ func (g *Generator) retryDelay(attempt int, err error) time.Duration {
 	return time.Duration(float64(delay) * factor)
 }
 
+// generateSample produces the final workerResult for a sample index,
+// applying self-consistency (Config.KSamples) and the judge-based quality
+// filter (Config.Judge) on top of generateCandidate's single-shot
+// generation.
 func (g *Generator) generateSample(ctx context.Context, index int) *workerResult {
-	// Build prompt options
+	ctx, span := telemetry.Tracer().Start(ctx, "kothaset.generate.sample", trace.WithAttributes(
+		attribute.Int("kothaset.sample_index", index),
+	))
+	defer span.End()
+
+	k := g.config.KSamples
+	if k < 1 {
+		k = 1
+	}
+
+	var candidates []*workerResult
+	var lastFailed *workerResult
+	for i := 0; i < k; i++ {
+		candidate := g.generateCandidate(ctx, index)
+		if candidate.err != nil {
+			lastFailed = candidate
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	if len(candidates) == 0 {
+		span.RecordError(lastFailed.err)
+		span.SetStatus(codes.Error, lastFailed.err.Error())
+		return lastFailed
+	}
+
+	winner, precomputedScores := candidates[0], map[string]any(nil)
+	if len(candidates) > 1 {
+		winner, precomputedScores = g.selectCandidate(ctx, candidates)
+	}
+	result := g.applyJudge(ctx, winner, precomputedScores)
+	span.SetAttributes(attribute.Int("kothaset.tokens_used", result.tokens))
+	if result.err != nil {
+		span.RecordError(result.err)
+		span.SetStatus(codes.Error, result.err.Error())
+	}
+	return result
+}
+
+// charsPerTokenEstimate is a rough English-text chars-per-token ratio, used
+// by estimateRequestTokens to size a request's prompt side before the
+// response is known. It doesn't need to be precise: the TPM bucket reserves
+// a little more or less than the true cost, and generateCandidate's Report
+// call trues it up against the provider's own usage count afterward.
+const charsPerTokenEstimate = 4
+
+// estimateRequestTokens estimates req's total token cost (prompt + response)
+// for pacing the TPM bucket before the actual cost is known: req.MaxTokens
+// for the response side, plus a chars-per-token heuristic over the prompt
+// (SystemPrompt and every Message's Content) for the request side, since a
+// long prompt can cost as much as the response it's asking for.
+func estimateRequestTokens(req provider.GenerationRequest) int {
+	chars := len(req.SystemPrompt)
+	for _, m := range req.Messages {
+		chars += len(m.Content)
+	}
+	return req.MaxTokens + chars/charsPerTokenEstimate
+}
+
+// maxDuplicateRegenerateAttempts bounds how many times generateCandidate
+// re-samples a topic and retries after a near-duplicate when
+// Config.Dedup.OnDuplicate is "regenerate", so a sampler stuck returning
+// the same handful of topics can't loop forever.
+const maxDuplicateRegenerateAttempts = 3
+
+// generateCandidate runs one full prompt/generate/parse/validate pass for
+// a sample index, retrying across providers per Config.Providers. This is
+// what generateSample calls once (k_samples <= 1) or Config.KSamples times
+// to build the candidate pool for self-consistency.
+//
+// When Config.Dedup.OnDuplicate is "regenerate", a near-duplicate result
+// doesn't return immediately: it asks the sampler for a fresh topic and
+// retries the same index, up to maxDuplicateRegenerateAttempts, falling
+// back to the "skip" result if every retry is also a duplicate.
+func (g *Generator) generateCandidate(ctx context.Context, index int) *workerResult {
+	if g.deduper == nil || g.config.Dedup.OnDuplicate != "regenerate" {
+		return g.generateCandidateAttempt(ctx, index)
+	}
+
+	var result *workerResult
+	var wastedTokens int
+	for attempt := 0; attempt < maxDuplicateRegenerateAttempts; attempt++ {
+		result = g.generateCandidateAttempt(ctx, index)
+		if !result.duplicate {
+			break
+		}
+		// The discarded attempt still spent real provider tokens (unless it
+		// was itself a cache hit); fold that into whatever result this
+		// index ultimately returns so a regenerate retry's cost isn't
+		// silently dropped from Result.TotalTokens. Charge the budget here,
+		// against this attempt's own provider/model, rather than letting the
+		// collector charge it later against whichever provider the final
+		// attempt happened to use: a discarded attempt may have been routed
+		// to a different, differently-priced provider than the one that
+		// eventually succeeded.
+		wastedTokens += result.tokens
+		if g.budget != nil {
+			g.budget.Charge(result.provider, result.model, result.tokens)
+		}
+		g.logEvent("duplicate, regenerating", "sample_id", index, "attempt", attempt)
+	}
+	result.regenerateWastedTokens = wastedTokens
+	return result
+}
+
+// buildRequest samples a topic and renders index's prompt into a
+// provider.GenerationRequest, the same way generateCandidateAttempt does
+// before it starts routing/retrying - factored out so runBatch can build
+// every remaining sample's request up front without duplicating the
+// prompt/seed construction logic.
+func (g *Generator) buildRequest(ctx context.Context, index int) (prompt, topic string, req provider.GenerationRequest, err error) {
 	opts := schema.PromptOptions{
 		Variables:       g.config.Variables,
 		UserContext:     g.config.UserContext,
@@ -390,16 +1204,16 @@ func (g *Generator) generateSample(ctx context.Context, index int) *workerResult
 
 	// Get topic from sampler if available
 	if g.sampler != nil {
-		topic, err := g.sampler.Sample(ctx, index)
+		t, err := g.sampler.Sample(ctx, index)
 		if err == nil {
-			opts.Topic = topic
+			opts.Topic = t
 		}
 	}
 
 	// Generate prompt
-	prompt, err := g.schema.GeneratePrompt(ctx, opts)
+	prompt, err = g.schema.GeneratePrompt(ctx, opts)
 	if err != nil {
-		return &workerResult{err: fmt.Errorf("failed to generate prompt: %w", err)}
+		return "", "", provider.GenerationRequest{}, fmt.Errorf("failed to generate prompt: %w", err)
 	}
 
 	// Determine seed for this request
@@ -413,8 +1227,7 @@ func (g *Generator) generateSample(ctx context.Context, index int) *workerResult
 		requestSeed = g.config.Seed
 	}
 
-	// Build request
-	req := provider.GenerationRequest{
+	req = provider.GenerationRequest{
 		Messages: []provider.Message{
 			{Role: "user", Content: prompt},
 		},
@@ -424,41 +1237,208 @@ func (g *Generator) generateSample(ctx context.Context, index int) *workerResult
 		TopP:         g.config.TopP,
 		Seed:         requestSeed,
 	}
+	return prompt, opts.Topic, req, nil
+}
 
-	// Execute with retries
+// generateCandidateAttempt is the single-shot body generateCandidate wraps
+// with duplicate-triggered retries.
+func (g *Generator) generateCandidateAttempt(ctx context.Context, index int) *workerResult {
+	prompt, topic, req, err := g.buildRequest(ctx, index)
+	if err != nil {
+		return &workerResult{err: err}
+	}
+	requestSeed := req.Seed
+
+	// Execute with retries, routing each attempt through g.router so a
+	// retry after a retryable error fails over to a different configured
+	// provider instead of hitting the same one again.
+	tried := make(map[string]bool)
 	var resp *provider.GenerationResponse
 	var lastErr error
+	var activeSpec ProviderSpec
+	var activeProvider provider.Provider
+	var cacheKey string
+	var cacheHit bool
+	// skipDelay bypasses the backoff below for an attempt that's failing
+	// over to a different, presumably healthy provider entry rather than
+	// retrying the one that just failed, where a delay only slows down
+	// what should be an immediate switch.
+	skipDelay := false
 	for attempt := 0; attempt <= g.config.MaxRetries; attempt++ {
-		if attempt > 0 {
+		if attempt > 0 && !skipDelay {
 			delay := g.retryDelay(attempt, lastErr)
+			if span := trace.SpanFromContext(ctx); span.IsRecording() {
+				span.AddEvent("kothaset.retry", trace.WithAttributes(
+					attribute.Int("kothaset.attempt", attempt),
+					attribute.Float64("kothaset.retry_delay_seconds", delay.Seconds()),
+					attribute.String("kothaset.error_class", telemetry.ErrorClass(lastErr)),
+				))
+			}
 			select {
 			case <-ctx.Done():
 				return &workerResult{err: ctx.Err()}
 			case <-time.After(delay):
 			}
 		}
+		skipDelay = false
+
+		activeSpec, activeProvider = g.router.Select(tried)
+		tried[activeSpec.Name] = true
+		useStreaming := g.config.Streaming && activeProvider.SupportsStreaming()
+
+		// Only attach the schema's JSON Schema when the selected provider
+		// can actually use it; everyone else keeps relying on the prompt's
+		// own "respond with ONLY the JSON object" instructions.
+		attemptReq := req
+		if activeProvider.SupportsStructuredOutput() {
+			attemptReq.ResponseSchema = g.schema.ResponseJSONSchema()
+		}
 
-		if err := g.rateLimiter.Wait(ctx); err != nil {
+		// Self-consistency (Config.KSamples > 1) depends on each candidate
+		// for the same index being an independent completion; since every
+		// candidate builds an identical cache key, consulting the cache
+		// here would turn all of them into copies of the first, silently
+		// defeating it. Only cache the single-candidate case.
+		if g.cache != nil && g.config.KSamples <= 1 {
+			cacheKey = cache.Key(activeSpec.Name, activeSpec.Model, attemptReq.SystemPrompt, prompt, attemptReq.Temperature, attemptReq.TopP, attemptReq.MaxTokens, requestSeed)
+			if entry, ok, err := g.cache.Get(ctx, cacheKey); err != nil {
+				g.logEvent("cache lookup failed", "sample_id", index, "provider", activeSpec.Name, "error", err.Error())
+			} else if ok {
+				cached := entry.Response
+				resp, lastErr, cacheHit = &cached, nil, true
+				g.logEvent("cache hit", "sample_id", index, "provider", activeSpec.Name, "attempt", attempt)
+				break
+			}
+		}
+
+		estimatedTokens := estimateRequestTokens(attemptReq)
+		waitStart := time.Now()
+		rateToken, err := g.router.Acquire(ctx, activeSpec.Name, estimatedTokens)
+		if err != nil {
 			return &workerResult{err: err}
 		}
+		if waited := time.Since(waitStart); waited > 0 {
+			g.logEvent("rate limit wait", "sample_id", index, "provider", activeSpec.Name, "attempt", attempt, "latency_ms", waited.Milliseconds())
+		}
+
+		g.logEvent("request start",
+			"sample_id", index,
+			"provider", activeSpec.Name,
+			"model", activeSpec.Model,
+			"attempt", attempt,
+		)
+
+		metrics.GenerationInFlight.Inc()
+		stopTimer := metrics.Timer(metrics.ProviderRequestDuration.WithLabelValues(activeSpec.Name, "generate"))
+		reqStart := time.Now()
+		if useStreaming {
+			var stream <-chan provider.StreamChunk
+			stream, lastErr = activeProvider.GenerateStream(ctx, attemptReq)
+			if lastErr == nil {
+				agg := &StreamAggregator{Progress: g.streamProgress}
+				resp, lastErr = agg.Aggregate(stream)
+			}
+		} else {
+			resp, lastErr = activeProvider.Generate(ctx, attemptReq)
+		}
+		stopTimer()
+		metrics.GenerationInFlight.Dec()
+		telemetry.RecordRequestLatency(ctx, activeSpec.Name, time.Since(reqStart))
 
-		resp, lastErr = g.provider.Generate(ctx, req)
 		if lastErr == nil {
+			g.router.Report(activeSpec.Name, estimatedTokens, resp.Usage.TotalTokens)
+			g.router.RecordSuccess(activeSpec.Name)
+			if span := trace.SpanFromContext(ctx); span.IsRecording() {
+				span.SetAttributes(
+					attribute.String("kothaset.provider", activeSpec.Name),
+					attribute.String("kothaset.model", resp.Model),
+					attribute.String("kothaset.topic", topic),
+					attribute.Int("kothaset.attempt", attempt),
+				)
+			}
+			if g.cache != nil && cacheKey != "" {
+				if err := g.cache.Put(ctx, cacheKey, &cache.Entry{Response: *resp}); err != nil {
+					g.logEvent("cache store failed", "sample_id", index, "provider", activeSpec.Name, "error", err.Error())
+				}
+			}
+			g.logEvent("request done",
+				"sample_id", index,
+				"provider", activeSpec.Name,
+				"model", resp.Model,
+				"attempt", attempt,
+				"latency_ms", time.Since(reqStart).Milliseconds(),
+				"tokens_prompt", resp.Usage.PromptTokens,
+				"tokens_completion", resp.Usage.CompletionTokens,
+			)
 			break
 		}
 
+		if provider.IsServerError(lastErr) {
+			rateToken.Release()
+		}
+
+		if provider.IsRateLimitError(lastErr) {
+			g.router.RecordRateLimit(activeSpec.Name)
+			// A rate limit means this entry specifically is out of room
+			// right now; move on to the next configured entry without
+			// waiting out the usual backoff first.
+			skipDelay = true
+		}
+
+		if retryAfter := provider.GetRetryAfter(lastErr); retryAfter > 0 {
+			g.router.Pause(activeSpec.Name, time.Now().Add(time.Duration(retryAfter)*time.Second))
+		}
+
 		if !provider.IsRetryableError(lastErr) {
+			metrics.ProviderErrors.WithLabelValues(activeSpec.Name, "terminal").Inc()
+			if len(tried) < g.router.EligibleCount() {
+				// Another configured provider/model entry hasn't been tried
+				// yet for this sample; a non-retryable error from just one
+				// entry (e.g. its specific model was decommissioned) isn't
+				// a reason to give up on the whole chain.
+				g.logEvent("non-retryable error, failing over to next provider",
+					"sample_id", index,
+					"provider", activeSpec.Name,
+					"attempt", attempt,
+					"error", lastErr.Error(),
+				)
+				skipDelay = true
+				continue
+			}
+			g.emitAuditEvent(ctx, activeProvider, prompt, requestSeed, nil, lastErr, nil, false)
 			return &workerResult{err: lastErr}
 		}
+		metrics.ProviderErrors.WithLabelValues(activeSpec.Name, "retryable").Inc()
+		g.router.RecordFailure(ctx, activeSpec.Name, activeProvider)
+		g.logEvent("retry",
+			"sample_id", index,
+			"provider", activeSpec.Name,
+			"attempt", attempt,
+			"error", lastErr.Error(),
+		)
 	}
 
 	if lastErr != nil {
+		g.emitAuditEvent(ctx, activeProvider, prompt, requestSeed, nil, lastErr, nil, false)
 		return &workerResult{err: lastErr}
 	}
 
+	return g.finishFromResponse(ctx, resp, activeProvider, activeSpec.Name, activeSpec.Model, prompt, requestSeed, topic, cacheHit)
+}
+
+// finishFromResponse runs resp through ParseResponse, ValidateSample, and
+// the Deduper, and builds the resulting workerResult - the shared tail end
+// of both the live generateCandidateAttempt path and runBatch's downloaded
+// batch results, so a sample is held to the same parse/validate/dedup
+// pipeline regardless of how its GenerationResponse was obtained.
+// prov/specName/specModel attribute the result for metrics/budget/
+// provider-usage bookkeeping; prompt/requestSeed/topic/cacheHit only feed
+// the audit event.
+func (g *Generator) finishFromResponse(ctx context.Context, resp *provider.GenerationResponse, prov provider.Provider, specName, specModel, prompt string, requestSeed *int64, topic string, cacheHit bool) *workerResult {
 	// Parse response
 	sample, err := g.schema.ParseResponse(resp.Content)
 	if err != nil {
+		g.emitAuditEvent(ctx, prov, prompt, requestSeed, resp, fmt.Errorf("failed to parse response: %w", err), nil, cacheHit)
 		return &workerResult{err: fmt.Errorf("failed to parse response: %w", err)}
 	}
 
@@ -466,22 +1446,131 @@ func (g *Generator) generateSample(ctx context.Context, index int) *workerResult
 	sample.ID = uuid.New().String()
 	sample.Metadata = schema.SampleMetadata{
 		GeneratedAt: time.Now(),
-		Provider:    g.provider.Name(),
+		Provider:    specName,
 		Model:       resp.Model,
 		Temperature: g.config.Temperature,
 		TokensUsed:  resp.Usage.TotalTokens,
 		Latency:     resp.Latency,
-		Topic:       opts.Topic,
+		Topic:       topic,
 	}
 
 	// Validate
 	if err := g.schema.ValidateSample(sample); err != nil {
-		return &workerResult{err: fmt.Errorf("sample validation failed: %w", err)}
+		report := schema.NewValidationReport(err)
+		g.emitAuditEvent(ctx, prov, prompt, requestSeed, resp, nil, report, cacheHit)
+		return &workerResult{
+			sample:   sample,
+			err:      fmt.Errorf("sample validation failed: %w", err),
+			report:   report,
+			provider: specName,
+		}
 	}
 
+	// Dedup - between ParseResponse/ValidateSample and the writer's Write
+	if g.deduper != nil {
+		if text := dedupText(g.config.Schema, sample.Fields); text != "" {
+			if g.deduper.CheckAndAdd(sample.ID, text) {
+				g.emitAuditEvent(ctx, prov, prompt, requestSeed, resp, nil, nil, cacheHit)
+				tokens, cachedTokens := resp.Usage.TotalTokens, 0
+				if cacheHit {
+					tokens, cachedTokens = 0, resp.Usage.TotalTokens
+				}
+				if g.config.Dedup.OnDuplicate == "keep" {
+					return &workerResult{
+						sample:        sample,
+						tokens:        tokens,
+						provider:      specName,
+						model:         specModel,
+						duplicateKept: true,
+						cached:        cacheHit,
+						cachedTokens:  cachedTokens,
+					}
+				}
+				return &workerResult{
+					sample:       sample,
+					tokens:       tokens,
+					err:          fmt.Errorf("sample is a near-duplicate (threshold %.2f)", g.config.Dedup.Threshold),
+					duplicate:    true,
+					provider:     specName,
+					model:        specModel,
+					cached:       cacheHit,
+					cachedTokens: cachedTokens,
+				}
+			}
+		}
+	}
+
+	g.providerUsageMu.Lock()
+	g.providerUsage[specName]++
+	g.providerUsageMu.Unlock()
+
+	g.emitAuditEvent(ctx, prov, prompt, requestSeed, resp, nil, nil, cacheHit)
+	tokens, cachedTokens := resp.Usage.TotalTokens, 0
+	if cacheHit {
+		// A cache hit didn't cost this run anything; attribute its
+		// original cost to CachedTokens instead of TotalTokens.
+		tokens, cachedTokens = 0, resp.Usage.TotalTokens
+	}
 	return &workerResult{
-		sample: sample,
-		tokens: resp.Usage.TotalTokens,
+		sample:       sample,
+		tokens:       tokens,
+		provider:     specName,
+		model:        specModel,
+		cached:       cacheHit,
+		cachedTokens: cachedTokens,
+	}
+}
+
+// emitAuditEvent builds and writes an audit.Event for a single
+// generateSample attempt, if an audit sink is set. prov is whichever
+// provider g.router selected for this attempt (nil when the attempt
+// failed before a provider was ever selected, e.g. prompt generation).
+// sampleErr classifies the event as audit.OutcomeError; otherwise a
+// non-nil report classifies it as audit.OutcomeRejected; otherwise
+// audit.OutcomeGenerated. resp may be nil when the provider call itself
+// failed before returning one. cached is true when resp came from
+// Generator.cache instead of an actual provider call this run, in which
+// case event.Cached is set and Usage/Latency are left zero so the audit
+// log doesn't double-count tokens or time that this run never actually
+// spent; Result.CachedTokens accounts for the avoided cost separately.
+func (g *Generator) emitAuditEvent(ctx context.Context, prov provider.Provider, prompt string, seed *int64, resp *provider.GenerationResponse, sampleErr error, report *schema.ValidationReport, cached bool) {
+	if g.auditSink == nil {
+		return
+	}
+
+	event := audit.Event{
+		Timestamp:  time.Now(),
+		Schema:     string(g.schema.Style()),
+		PromptHash: audit.HashPrompt(prompt),
+		Seed:       seed,
+		Cached:     cached,
+	}
+	if prov != nil {
+		event.Provider = prov.Name()
+		event.ProviderType = prov.Type()
+	}
+	if resp != nil {
+		event.Model = resp.Model
+		event.FinishReason = resp.FinishReason
+		if !cached {
+			event.Usage = resp.Usage
+			event.Latency = resp.Latency
+		}
+	}
+
+	switch {
+	case sampleErr != nil:
+		event.Outcome = audit.OutcomeError
+		event.Error = sampleErr.Error()
+	case report != nil:
+		event.Outcome = audit.OutcomeRejected
+		event.Validation = report.Errors()
+	default:
+		event.Outcome = audit.OutcomeGenerated
+	}
+
+	if err := g.auditSink.Write(ctx, event); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Failed to write audit event: %v\n", err)
 	}
 }
 
@@ -516,30 +1605,96 @@ func (g *Generator) reportProgress(startTime time.Time) {
 }
 
 func (g *Generator) saveCheckpoint() error {
+	g.providerUsageMu.Lock()
+	usage := make(map[string]int, len(g.providerUsage))
+	for name, count := range g.providerUsage {
+		usage[name] = count
+	}
+	g.providerUsageMu.Unlock()
+
+	g.batchAppliedMu.Lock()
+	appliedIDs := make([]string, 0, len(g.batchApplied))
+	for id := range g.batchApplied {
+		appliedIDs = append(appliedIDs, id)
+	}
+	g.batchAppliedMu.Unlock()
+
 	cp := &Checkpoint{
-		Timestamp:  time.Now(),
-		Config:     g.config,
-		Completed:  int(atomic.LoadInt32(&g.completed)),
-		Failed:     int(atomic.LoadInt32(&g.failed)),
-		TokensUsed: int(atomic.LoadInt64(&g.tokensUsed)),
+		Timestamp:       time.Now(),
+		SchemaVersion:   checkpointVersion,
+		Config:          g.config,
+		Completed:       int(atomic.LoadInt32(&g.completed)),
+		Failed:          int(atomic.LoadInt32(&g.failed)),
+		TokensUsed:      int(atomic.LoadInt64(&g.tokensUsed)),
+		ProviderUsage:   usage,
+		BatchJobID:      g.batchJobID,
+		BatchRangeStart: g.batchRangeStart,
+		BatchRangeCount: g.batchRangeCount,
+		BatchAppliedIDs: appliedIDs,
 	}
 
-	return SaveCheckpoint(cp, getCheckpointPath(g.config.OutputPath))
+	path := getCheckpointPath(g.config.OutputPath, g.config.CacheDir)
+	if err := SaveCheckpoint(cp, path); err != nil {
+		return err
+	}
+	if g.deduper != nil {
+		if err := g.deduper.Save(getDedupPath(g.config.OutputPath, g.config.CacheDir)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save dedup index: %v\n", err)
+		}
+	}
+	g.logEvent("checkpoint flush",
+		"path", path,
+		"completed", cp.Completed,
+		"failed", cp.Failed,
+		"tokens_used", cp.TokensUsed,
+	)
+	return nil
 }
 
 // Checkpoint represents saved generation state
 type Checkpoint struct {
-	Timestamp  time.Time `json:"timestamp"`
-	Config     Config    `json:"config"`
-	Completed  int       `json:"completed"`
-	Failed     int       `json:"failed"`
-	TokensUsed int       `json:"tokens_used"`
+	Timestamp time.Time `json:"timestamp"`
+	// SchemaVersion is checkpointVersion at the time this checkpoint was
+	// written; zero means it predates this field. Run rejects resuming
+	// from a checkpoint newer than the running binary understands.
+	SchemaVersion int    `json:"schema_version"`
+	Config        Config `json:"config"`
+	Completed     int    `json:"completed"`
+	Failed        int    `json:"failed"`
+	TokensUsed    int    `json:"tokens_used"`
+
+	// ProviderUsage counts completed samples per provider name across all
+	// providers a multi-provider run routed through. Per-sample
+	// attribution doesn't need to live here too: each written sample
+	// already carries its own provider/model in schema.SampleMetadata.
+	ProviderUsage map[string]int `json:"provider_usage,omitempty"`
+
+	// BatchJobID is the provider.BatchProvider job ID submitted by a
+	// Config.BatchMode run, if any. A resumed run with this set skips
+	// SubmitBatch entirely and polls the existing job instead of
+	// resubmitting every remaining sample as a new one.
+	BatchJobID string `json:"batch_job_id,omitempty"`
+
+	// BatchRange is the [start, start+count) sample-index range BatchJobID
+	// was submitted for, fixed at submission time. A resumed run rebuilds
+	// this exact range's prompts regardless of how far Completed has since
+	// advanced mid-job, since a batch job's results can complete out of
+	// order and so don't line up with a shifted baseCompleted..NumSamples
+	// range the way the live path's resume does.
+	BatchRangeStart int `json:"batch_range_start,omitempty"`
+	BatchRangeCount int `json:"batch_range_count,omitempty"`
+
+	// BatchAppliedIDs are the provider.BatchRequest.CustomID values from
+	// BatchJobID already run through finishFromResponse and written (or
+	// counted as failed/duplicate), so a resumed run doesn't reapply a
+	// result a prior attempt at this same job already accounted for.
+	BatchAppliedIDs []string `json:"batch_applied_ids,omitempty"`
 }
 
 // SaveCheckpoint saves a checkpoint to disk
 func SaveCheckpoint(cp *Checkpoint, path string) error {
-	// Ensure cache directory exists
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+	// Ensure the checkpoint's directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
@@ -102,6 +102,47 @@ func TestGenerator_Run_ProviderError(t *testing.T) {
 	}
 }
 
+func TestGenerator_Run_RateLimitThrottlesAndRecovers(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 1
+	cfg.MaxRetries = 3
+	cfg.RetryDelay = time.Millisecond
+	cfg.RateLimit = 600 // 10 req/s, so Throttle's halving is observable
+	cfg.RateLimitBurst = 10
+
+	prov := &MockProvider{
+		FailTimes: 2,
+		FailErr:   provider.NewRateLimitError("slow down", 0),
+		Response:  `{"instruction": "this is a long enough instruction", "output": "this is a long enough output"}`,
+	}
+	s := schema.NewInstructionSchema()
+	gen := New(cfg, prov, s)
+	gen.SetSampler(&MockSampler{Topic: "test-topic"})
+	gen.SetWriter(&MockWriter{})
+
+	ctx := context.Background()
+	res, err := gen.Run(ctx)
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+	if res.SuccessCount != 1 {
+		t.Fatalf("expected 1 success after recovering from rate limit errors, got %d", res.SuccessCount)
+	}
+	if prov.Calls != 3 {
+		t.Fatalf("expected 3 calls (2 rate-limited + 1 success), got %d", prov.Calls)
+	}
+
+	// Two rate-limit responses should have halved refillRate twice.
+	l := gen.router.limiterFor("mock")
+	if l == nil {
+		t.Fatal("expected a limiter registered for the mock provider")
+	}
+	wantRate := l.requests.ceiling * aimdDecreaseFactor * aimdDecreaseFactor
+	if got := l.requests.refillRate; got != wantRate {
+		t.Fatalf("refillRate after two rate-limit responses = %v, want %v", got, wantRate)
+	}
+}
+
 func TestGenerator_ProgressCallback(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.NumSamples = 2
@@ -244,6 +285,89 @@ func TestGenerator_Run_ResumeOutputMismatch(t *testing.T) {
 	}
 }
 
+func TestGenerator_Run_SetsWriterCompression(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := DefaultConfig()
+	cfg.NumSamples = 1
+	cfg.Schema = "instruction"
+	cfg.OutputPath = filepath.Join(tmpDir, "out.jsonl")
+	cfg.Compression = "zstd"
+
+	gen := New(cfg, &MockProvider{Response: `{"instruction":"this is long enough","output":"this is long enough output"}`}, schema.NewInstructionSchema())
+	gen.SetSampler(&MockSampler{Topic: "topic"})
+	writer := &MockWriter{}
+	gen.SetWriter(writer)
+
+	if _, err := gen.Run(context.Background()); err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+
+	if writer.SetCompressionCalls != 1 {
+		t.Fatalf("SetCompressionCalls = %d, want 1", writer.SetCompressionCalls)
+	}
+	if writer.CompressionSet != "zstd" {
+		t.Fatalf("CompressionSet = %q, want %q", writer.CompressionSet, "zstd")
+	}
+}
+
+// noCompressionWriter implements output.Writer but not
+// output.CompressionWriter, mirroring a format (e.g. MultiWriter, Parquet)
+// that can't honor Config.Compression.
+type noCompressionWriter struct{}
+
+func (w *noCompressionWriter) Open(path string) error            { return nil }
+func (w *noCompressionWriter) OpenAppend(path string) error      { return nil }
+func (w *noCompressionWriter) Write(sample *schema.Sample) error { return nil }
+func (w *noCompressionWriter) Flush() error                      { return nil }
+func (w *noCompressionWriter) Sync() error                       { return nil }
+func (w *noCompressionWriter) Close() error                      { return nil }
+func (w *noCompressionWriter) Format() string                    { return "mock" }
+
+func TestGenerator_Run_CompressionUnsupportedByWriter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := DefaultConfig()
+	cfg.NumSamples = 1
+	cfg.Schema = "instruction"
+	cfg.OutputPath = filepath.Join(tmpDir, "out.jsonl")
+	cfg.Compression = "gzip"
+
+	gen := New(cfg, &MockProvider{Response: `{"instruction":"this is long enough","output":"this is long enough output"}`}, schema.NewInstructionSchema())
+	gen.SetSampler(&MockSampler{Topic: "topic"})
+	gen.SetWriter(&noCompressionWriter{})
+
+	_, err := gen.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected an error when the configured writer doesn't support compression")
+	}
+	if !strings.Contains(err.Error(), "does not support --compression") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenerator_Run_NoCompressionConfigured_DoesNotCallSetCompression(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := DefaultConfig()
+	cfg.NumSamples = 1
+	cfg.Schema = "instruction"
+	cfg.OutputPath = filepath.Join(tmpDir, "out.jsonl")
+
+	gen := New(cfg, &MockProvider{Response: `{"instruction":"this is long enough","output":"this is long enough output"}`}, schema.NewInstructionSchema())
+	gen.SetSampler(&MockSampler{Topic: "topic"})
+	writer := &MockWriter{}
+	gen.SetWriter(writer)
+
+	if _, err := gen.Run(context.Background()); err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+
+	if writer.SetCompressionCalls != 0 {
+		t.Fatalf("SetCompressionCalls = %d, want 0", writer.SetCompressionCalls)
+	}
+}
+
 func TestGenerator_Run_ResumeCompletedExceedsRequested(t *testing.T) {
 	tmpDir := t.TempDir()
 	checkpointPath := filepath.Join(tmpDir, "resume.checkpoint")
@@ -317,6 +441,7 @@ func TestGenerator_Run_WriteError_Graceful(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.NumSamples = 10
 	cfg.Workers = 4
+	cfg.BatchSize = 4 // exercise flush/rescue across a multi-item batch, not just single writes
 
 	prov := &MockProvider{Response: `{"instruction":"this is long enough","output":"this is long enough output"}`}
 	writer := &MockWriter{FailOnWrite: true, FailAfter: 3}
@@ -342,6 +467,57 @@ func TestGenerator_Run_WriteError_Graceful(t *testing.T) {
 	}
 }
 
+func TestGenerator_Run_BatchFlushesOnByteCapNotJustCount(t *testing.T) {
+	// Learn one sample's serialized size first, so the byte cap below can be
+	// set to fit exactly two samples - independent of the schema's exact
+	// JSON encoding.
+	probeCfg := DefaultConfig()
+	probeCfg.NumSamples = 1
+	probeCfg.Workers = 1
+	probeWriter := &MockWriter{}
+	probeGen := New(probeCfg, &MockProvider{Response: `{"instruction":"this is long enough","output":"this is long enough output"}`}, schema.NewInstructionSchema())
+	probeGen.SetSampler(&MockSampler{Topic: "test"})
+	probeGen.SetWriter(probeWriter)
+	if _, err := probeGen.Run(context.Background()); err != nil {
+		t.Fatalf("probe run failed: %v", err)
+	}
+	if len(probeWriter.Samples) != 1 {
+		t.Fatalf("expected 1 probe sample, got %d", len(probeWriter.Samples))
+	}
+	unitSize := sampleJSONSize(probeWriter.Samples[0])
+
+	// BatchSize is large enough that the count bound would never trip
+	// before NumSamples is exhausted; only BatchMaxBytes (fitting exactly
+	// two samples) should force a flush. FailAfter=2 means the writer fails
+	// on the third real Write call - if the byte cap (not BatchSize) is
+	// what's cutting batches at 2, exactly 2 samples land before the error.
+	cfg := DefaultConfig()
+	cfg.NumSamples = 6
+	cfg.Workers = 1
+	cfg.BatchSize = 1000
+	cfg.BatchMaxBytes = unitSize * 2
+
+	prov := &MockProvider{Response: `{"instruction":"this is long enough","output":"this is long enough output"}`}
+	writer := &MockWriter{FailOnWrite: true, FailAfter: 2}
+	gen := New(cfg, prov, schema.NewInstructionSchema())
+	gen.SetSampler(&MockSampler{Topic: "test"})
+	gen.SetWriter(writer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := gen.Run(ctx)
+	if err == nil {
+		t.Fatal("expected write error, got nil")
+	}
+	if res == nil {
+		t.Fatal("expected partial result on write error, got nil")
+	}
+	if res.SuccessCount != 2 {
+		t.Fatalf("expected exactly 2 successes from the byte-capped first batch, got %d", res.SuccessCount)
+	}
+}
+
 func TestGenerator_Run_Cancellation_NoPanic(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.NumSamples = 100
@@ -404,6 +580,61 @@ func TestGenerator_Run_ExponentialBackoff(t *testing.T) {
 	}
 }
 
+func TestEstimateRequestTokens(t *testing.T) {
+	req := provider.GenerationRequest{
+		SystemPrompt: strings.Repeat("x", 40), // 10 tokens
+		Messages: []provider.Message{
+			{Role: "user", Content: strings.Repeat("y", 80)}, // 20 tokens
+		},
+		MaxTokens: 100,
+	}
+	if got, want := estimateRequestTokens(req), 130; got != want {
+		t.Fatalf("estimateRequestTokens() = %d, want %d", got, want)
+	}
+}
+
+func TestGenerator_Run_CacheHitSkipsProviderCall(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumSamples = 3
+	cfg.Workers = 1 // serialize so the second/third samples can hit the first's cache entry
+
+	prov := &MockProvider{Response: `{"instruction": "this is a long enough instruction", "output": "this is a long enough output"}`}
+	s := schema.NewInstructionSchema()
+	gen := New(cfg, prov, s)
+
+	gen.SetSampler(&MockSampler{Topic: "same-topic-every-time"})
+	gen.SetWriter(&MockWriter{})
+	mc := &MockCache{}
+	gen.SetCache(mc)
+
+	res, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Generator.Run failed: %v", err)
+	}
+
+	if res.SuccessCount != 3 {
+		t.Fatalf("SuccessCount = %d, want 3", res.SuccessCount)
+	}
+	// Every sample asks the same sampler for the same topic, which makes
+	// an identical prompt every time; only the first should ever reach
+	// the provider.
+	if prov.Calls != 1 {
+		t.Fatalf("provider Calls = %d, want 1 (other two should be cache hits)", prov.Calls)
+	}
+	if res.CacheHits != 2 {
+		t.Fatalf("CacheHits = %d, want 2", res.CacheHits)
+	}
+	if res.CachedTokens != 20 { // 2 hits * MockProvider's fixed 10 tokens
+		t.Fatalf("CachedTokens = %d, want 20", res.CachedTokens)
+	}
+	if res.TotalTokens != 10 { // only the one real call is billed
+		t.Fatalf("TotalTokens = %d, want 10", res.TotalTokens)
+	}
+	if mc.Puts != 1 {
+		t.Fatalf("cache Puts = %d, want 1", mc.Puts)
+	}
+}
+
 func TestGetCheckpointPath_UsesFullPath(t *testing.T) {
 	p1 := getCheckpointPath(filepath.Join("one", "dataset.jsonl"), defaultCacheDir)
 	p2 := getCheckpointPath(filepath.Join("two", "dataset.jsonl"), defaultCacheDir)
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/shantoislamdev/kothaset/internal/cache"
 	"github.com/shantoislamdev/kothaset/internal/provider"
 	"github.com/shantoislamdev/kothaset/internal/schema"
 )
@@ -17,11 +18,27 @@ type MockProvider struct {
 	Response   string
 	Calls      int
 	mu         sync.Mutex
+
+	// SupportsStream, if true, makes SupportsStreaming report true and
+	// GenerateStream emit Response as a single chunk followed by Done.
+	SupportsStream bool
+
+	// SupportsStructured, if true, makes SupportsStructuredOutput report
+	// true.
+	SupportsStructured bool
+
+	// FailTimes and FailErr, if FailTimes > 0, make Generate return FailErr
+	// for the first FailTimes calls before falling through to the normal
+	// success (or ShouldFail) response, e.g. to simulate a provider that
+	// rate-limits for a while and then recovers.
+	FailTimes int
+	FailErr   error
 }
 
 func (m *MockProvider) Generate(ctx context.Context, req provider.GenerationRequest) (*provider.GenerationResponse, error) {
 	m.mu.Lock()
 	m.Calls++
+	calls := m.Calls
 	m.mu.Unlock()
 
 	if m.Delay > 0 {
@@ -32,6 +49,10 @@ func (m *MockProvider) Generate(ctx context.Context, req provider.GenerationRequ
 		}
 	}
 
+	if m.FailTimes > 0 && calls <= m.FailTimes {
+		return nil, m.FailErr
+	}
+
 	if m.ShouldFail {
 		return nil, fmt.Errorf("mock provider error")
 	}
@@ -46,13 +67,113 @@ func (m *MockProvider) Generate(ctx context.Context, req provider.GenerationRequ
 	}, nil
 }
 
-func (m *MockProvider) Name() string                          { return "mock" }
-func (m *MockProvider) Type() string                          { return "mock" }
-func (m *MockProvider) Model() string                         { return "mock-model" }
-func (m *MockProvider) SupportsStreaming() bool               { return false }
-func (m *MockProvider) Validate() error                       { return nil }
-func (m *MockProvider) HealthCheck(ctx context.Context) error { return nil }
-func (m *MockProvider) Close() error                          { return nil }
+// GenerateStream emits Response as a single content chunk followed by a
+// Done chunk, honoring ShouldFail/Delay the same way Generate does.
+func (m *MockProvider) GenerateStream(ctx context.Context, req provider.GenerationRequest) (<-chan provider.StreamChunk, error) {
+	m.mu.Lock()
+	m.Calls++
+	m.mu.Unlock()
+
+	if m.ShouldFail {
+		return nil, fmt.Errorf("mock provider error")
+	}
+
+	ch := make(chan provider.StreamChunk, 2)
+	go func() {
+		defer close(ch)
+		if m.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				ch <- provider.StreamChunk{Done: true, Error: ctx.Err()}
+				return
+			case <-time.After(m.Delay):
+			}
+		}
+		ch <- provider.StreamChunk{Content: m.Response}
+		ch <- provider.StreamChunk{Done: true, FinishReason: "stop", Usage: &provider.TokenUsage{TotalTokens: 10}}
+	}()
+	return ch, nil
+}
+
+func (m *MockProvider) Name() string                   { return "mock" }
+func (m *MockProvider) Type() string                   { return "mock" }
+func (m *MockProvider) Model() string                  { return "mock-model" }
+func (m *MockProvider) SupportsStreaming() bool        { return m.SupportsStream }
+func (m *MockProvider) SupportsStructuredOutput() bool { return m.SupportsStructured }
+func (m *MockProvider) Validate() error                { return nil }
+func (m *MockProvider) HealthCheck(ctx context.Context) error {
+	if m.ShouldFail {
+		return fmt.Errorf("mock provider unhealthy")
+	}
+	return nil
+}
+func (m *MockProvider) Close() error { return nil }
+
+// MockBatchProvider implements provider.Provider and provider.BatchProvider
+// for testing Config.BatchMode: SubmitBatch records the requests it was
+// given and returns JobID; PollBatch replays PollStates in order (sticking
+// on the last one once exhausted); FetchBatchResults returns Results as-is,
+// or synthesizes one success BatchResult per submitted CustomID if Results
+// is unset.
+type MockBatchProvider struct {
+	MockProvider
+
+	JobID      string
+	PollStates []provider.BatchJobStatus
+	PollErr    error
+	Results    []provider.BatchResult
+	SubmitErr  error
+
+	mu         sync.Mutex
+	Submitted  []provider.BatchRequest
+	PollCalls  int
+	FetchCalls int
+}
+
+func (m *MockBatchProvider) SubmitBatch(ctx context.Context, requests []provider.BatchRequest) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SubmitErr != nil {
+		return "", m.SubmitErr
+	}
+	m.Submitted = append(m.Submitted, requests...)
+	return m.JobID, nil
+}
+
+func (m *MockBatchProvider) PollBatch(ctx context.Context, jobID string) (provider.BatchJobStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.PollErr != nil {
+		return provider.BatchJobStatus{}, m.PollErr
+	}
+	idx := m.PollCalls
+	if idx >= len(m.PollStates) {
+		idx = len(m.PollStates) - 1
+	}
+	m.PollCalls++
+	return m.PollStates[idx], nil
+}
+
+func (m *MockBatchProvider) FetchBatchResults(ctx context.Context, jobID string) ([]provider.BatchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.FetchCalls++
+	if m.Results != nil {
+		return m.Results, nil
+	}
+	results := make([]provider.BatchResult, len(m.Submitted))
+	for i, req := range m.Submitted {
+		results[i] = provider.BatchResult{
+			CustomID: req.CustomID,
+			Response: &provider.GenerationResponse{
+				Content: m.Response,
+				Model:   "mock-model",
+				Usage:   provider.TokenUsage{TotalTokens: 10},
+			},
+		}
+	}
+	return results, nil
+}
 
 // MockWriter implements output.Writer for testing.
 type MockWriter struct {
@@ -66,6 +187,27 @@ type MockWriter struct {
 	OpenCalls   int
 	OpenAppends int
 	mu          sync.Mutex
+
+	// CompressionSet records the codec the generator last passed to
+	// SetCompression, and SetCompressionCalls how many times it was called,
+	// so tests can assert the generator wires output.CompressionWriter
+	// without needing a real compressor. SetCompressionErr, if set, makes
+	// SetCompression fail like a real writer rejecting an unknown codec.
+	CompressionSet      string
+	SetCompressionCalls int
+	SetCompressionErr   error
+}
+
+// SetCompression implements output.CompressionWriter.
+func (w *MockWriter) SetCompression(codec string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.SetCompressionCalls++
+	if w.SetCompressionErr != nil {
+		return w.SetCompressionErr
+	}
+	w.CompressionSet = codec
+	return nil
 }
 
 func (w *MockWriter) Open(path string) error {
@@ -115,6 +257,36 @@ func (w *MockWriter) Close() error {
 }
 func (w *MockWriter) Format() string { return "mock" }
 
+// MockCache implements cache.Cache in memory, for testing generateCandidate's
+// cache lookup/store without a real local or Redis backend.
+type MockCache struct {
+	mu      sync.Mutex
+	entries map[string]*cache.Entry
+	Gets    int
+	Puts    int
+}
+
+func (c *MockCache) Get(ctx context.Context, key string) (*cache.Entry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Gets++
+	entry, ok := c.entries[key]
+	return entry, ok, nil
+}
+
+func (c *MockCache) Put(ctx context.Context, key string, entry *cache.Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Puts++
+	if c.entries == nil {
+		c.entries = make(map[string]*cache.Entry)
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *MockCache) Close() error { return nil }
+
 // MockSampler implements Sampler for testing
 type MockSampler struct {
 	Topic string
@@ -9,84 +9,268 @@ import (
 
 var errRateLimiterClosed = errors.New("rate limiter closed")
 
-// RateLimiter enforces requests-per-minute limits for provider calls.
+// AIMD tuning for RateLimiter.Throttle/ReportSuccess: a rate-limit response
+// halves the current rate immediately; every aimdSuccessThreshold
+// consecutive non-rate-limited responses since then climbs it back up by
+// aimdIncreaseFraction of the configured ceiling, until it's fully
+// recovered. aimdMinRateFraction floors the decrease so a provider that's
+// rate-limiting hard is still retried occasionally instead of being
+// throttled to a standstill.
+const (
+	aimdDecreaseFactor   = 0.5
+	aimdIncreaseFraction = 0.1
+	aimdSuccessThreshold = 5
+	aimdMinRateFraction  = 0.1
+)
+
+// RateLimiter enforces requests-per-minute limits for provider calls using a
+// token bucket: tokens refill continuously at refillRate per second up to a
+// configurable burst capacity, so callers can spend a short burst of
+// requests back-to-back instead of being paced to exactly one at a time.
+// refillRate starts at ceiling (requestsPerMinute/60) and adapts within
+// [ceiling*aimdMinRateFraction, ceiling] via Throttle/ReportSuccess (AIMD).
 type RateLimiter struct {
-	tokens    chan struct{}
-	ticker    *time.Ticker
+	mu            sync.Mutex
+	tokens        float64
+	capacity      float64
+	refillRate    float64 // tokens per second, adapted by Throttle/ReportSuccess
+	ceiling       float64 // configured tokens per second, the AIMD upper bound
+	successStreak int
+	lastRefill    time.Time
+
 	done      chan struct{}
 	closeOnce sync.Once
 	disabled  bool
 }
 
-// NewRateLimiter creates a new rate limiter for the given requests per minute.
-// Values <= 0 disable throttling.
-func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+// NewRateLimiter creates a new rate limiter for the given requests per
+// minute and burst capacity (the number of requests that may be spent
+// immediately before refill catches up). Values <= 0 for requestsPerMinute
+// disable throttling; burst <= 0 is treated as 1.
+func NewRateLimiter(requestsPerMinute, burst int) *RateLimiter {
 	if requestsPerMinute <= 0 {
 		return &RateLimiter{disabled: true}
 	}
-
-	interval := time.Minute / time.Duration(requestsPerMinute)
-	if interval <= 0 {
-		interval = time.Nanosecond
+	if burst <= 0 {
+		burst = 1
 	}
 
-	rl := &RateLimiter{
-		tokens: make(chan struct{}, 1),
-		ticker: time.NewTicker(interval),
-		done:   make(chan struct{}),
+	rate := float64(requestsPerMinute) / 60.0
+	return &RateLimiter{
+		tokens:     float64(burst),
+		capacity:   float64(burst),
+		refillRate: rate,
+		ceiling:    rate,
+		lastRefill: time.Now(),
+		done:       make(chan struct{}),
 	}
+}
 
-	// Allow one request immediately.
-	rl.tokens <- struct{}{}
-
-	go func() {
-		for {
-			select {
-			case <-rl.done:
-				return
-			case <-rl.ticker.C:
-				select {
-				case rl.tokens <- struct{}{}:
-				default:
-				}
-			}
-		}
-	}()
+// RateLimitToken is returned by Wait for the cost it reserved. Release
+// refunds that cost to the limiter; callers should do so when the request
+// the token paced for failed with a server error (5xx), since that's not a
+// sign the caller is asking too fast, and charging for it anyway would
+// throttle an already-struggling provider even harder than the error
+// itself warrants.
+type RateLimitToken struct {
+	limiter *RateLimiter
+	cost    int
+}
 
-	return rl
+// Release returns the token's cost to its limiter. Safe to call on a nil
+// token (the no-op Wait returns when the limiter is disabled).
+func (t *RateLimitToken) Release() {
+	if t == nil || t.limiter == nil {
+		return
+	}
+	t.limiter.Refund(t.cost)
 }
 
-// Wait blocks until a request token is available or context is canceled.
-func (r *RateLimiter) Wait(ctx context.Context) error {
+// Wait blocks until n tokens (1 if n is omitted) are available or context is
+// canceled, returning a token whose Release gives the spent cost back. Pass
+// n to account for requests that cost more than one token, e.g. long-context
+// calls a provider weights more heavily.
+func (r *RateLimiter) Wait(ctx context.Context, n ...int) (*RateLimitToken, error) {
 	if r == nil || r.disabled {
-		return nil
+		return nil, nil
+	}
+
+	cost := 1
+	if len(n) > 0 && n[0] > 0 {
+		cost = n[0]
 	}
 
-	// Fast-path to make close behavior deterministic even if a token is buffered.
+	// Fast-path to make close behavior deterministic even if tokens are available.
 	select {
 	case <-r.done:
-		return errRateLimiterClosed
+		return nil, errRateLimiterClosed
 	default:
 	}
 
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-r.done:
-		return errRateLimiterClosed
-	case <-r.tokens:
-		return nil
+	for {
+		wait, ok := r.reserve(cost)
+		if ok {
+			return &RateLimitToken{limiter: r, cost: cost}, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-r.done:
+			timer.Stop()
+			return nil, errRateLimiterClosed
+		case <-timer.C:
+		}
+	}
+}
+
+// Throttle applies the multiplicative-decrease half of AIMD after a
+// rate-limit response: it drains the bucket to empty and halves
+// refillRate, floored at aimdMinRateFraction of ceiling, and resets the
+// success streak ReportSuccess tracks. Throttle only adjusts the
+// steady-state rate; it doesn't sleep out the response's Retry-After
+// itself, since callers already do that (see providerRouter.Pause and
+// Generator.retryDelay).
+func (r *RateLimiter) Throttle() {
+	if r == nil || r.disabled {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = 0
+	r.successStreak = 0
+	r.refillRate *= aimdDecreaseFactor
+	if floor := r.ceiling * aimdMinRateFraction; r.refillRate < floor {
+		r.refillRate = floor
+	}
+}
+
+// ReportSuccess records a non-rate-limited response for the additive
+// half of AIMD: every aimdSuccessThreshold consecutive calls, refillRate
+// climbs back toward ceiling by aimdIncreaseFraction of it. Throttle
+// resets the streak this counts.
+func (r *RateLimiter) ReportSuccess() {
+	if r == nil || r.disabled {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.refillRate >= r.ceiling {
+		r.successStreak = 0
+		return
 	}
+
+	r.successStreak++
+	if r.successStreak >= aimdSuccessThreshold {
+		r.successStreak = 0
+		r.refillRate += r.ceiling * aimdIncreaseFraction
+		if r.refillRate > r.ceiling {
+			r.refillRate = r.ceiling
+		}
+	}
+}
+
+// refillLocked adds tokens for elapsed time since lastRefill, capped at
+// capacity. Callers must hold mu.
+func (r *RateLimiter) refillLocked() {
+	now := time.Now()
+	if elapsed := now.Sub(r.lastRefill).Seconds(); elapsed > 0 {
+		r.tokens += elapsed * r.refillRate
+		if r.tokens > r.capacity {
+			r.tokens = r.capacity
+		}
+		r.lastRefill = now
+	}
+}
+
+// reserve refills the bucket for elapsed time and, if cost tokens are
+// available, spends them and returns (0, true). Otherwise it returns how
+// long the caller should wait before trying again.
+func (r *RateLimiter) reserve(cost int) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refillLocked()
+
+	if r.tokens >= float64(cost) {
+		r.tokens -= float64(cost)
+		return 0, true
+	}
+
+	deficit := float64(cost) - r.tokens
+	return time.Duration(deficit / r.refillRate * float64(time.Second)), false
+}
+
+// Refund returns n tokens to the bucket, capped at capacity. It's for
+// callers that reserved a cost estimate via Wait up front (e.g. a
+// provider's max_tokens) and later learned the actual cost was lower;
+// giving the difference back avoids under-spending the budget on calls
+// that finish early.
+func (r *RateLimiter) Refund(n int) {
+	if r == nil || r.disabled || n <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens += float64(n)
+	if r.tokens > r.capacity {
+		r.tokens = r.capacity
+	}
+}
+
+// Consume charges n additional tokens against the bucket without waiting,
+// for callers that reserved a cost estimate via Wait up front and later
+// learned the actual cost was higher; unlike reserve, it never blocks, so
+// tokens may go negative, which simply delays the next Wait until refill
+// has made up the difference. n <= 0 is a no-op.
+func (r *RateLimiter) Consume(n int) {
+	if r == nil || r.disabled || n <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refillLocked()
+	r.tokens -= float64(n)
+}
+
+// RateLimiterStats reports a RateLimiter's current bucket state, for
+// surfacing in progress output (e.g. "42/100 tokens available").
+type RateLimiterStats struct {
+	Available float64
+	Capacity  float64
+}
+
+// Stats refills the bucket for elapsed time and returns its current state.
+// Returns the zero value for a nil or disabled limiter.
+func (r *RateLimiter) Stats() RateLimiterStats {
+	if r == nil || r.disabled {
+		return RateLimiterStats{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refillLocked()
+	return RateLimiterStats{Available: r.tokens, Capacity: r.capacity}
 }
 
-// Close stops the limiter ticker and unblocks pending waiters.
+// Close stops the limiter and unblocks pending waiters.
 func (r *RateLimiter) Close() {
 	if r == nil || r.disabled {
 		return
 	}
 
 	r.closeOnce.Do(func() {
-		r.ticker.Stop()
 		close(r.done)
 	})
 }
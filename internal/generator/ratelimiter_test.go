@@ -8,12 +8,12 @@ import (
 )
 
 func TestRateLimiter_Basic(t *testing.T) {
-	rl := NewRateLimiter(1200) // 1 token every 50ms
+	rl := NewRateLimiter(1200, 1) // 1 token every 50ms, burst 1
 	defer rl.Close()
 
 	start := time.Now()
 	for i := 0; i < 3; i++ {
-		if err := rl.Wait(context.Background()); err != nil {
+		if _, err := rl.Wait(context.Background()); err != nil {
 			t.Fatalf("unexpected wait error: %v", err)
 		}
 	}
@@ -24,31 +24,76 @@ func TestRateLimiter_Basic(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_Burst(t *testing.T) {
+	rl := NewRateLimiter(60, 5) // 1 token/sec, burst of 5
+	defer rl.Close()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := rl.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected wait error: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected the full burst to be spent immediately, elapsed=%v", elapsed)
+	}
+
+	// The bucket is now empty; a 6th request should be throttled.
+	if _, err := rl.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Fatalf("expected the 6th request to wait for refill, elapsed=%v", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitCost(t *testing.T) {
+	rl := NewRateLimiter(600, 10) // 10 tokens/sec, burst of 10
+	defer rl.Close()
+
+	start := time.Now()
+	if _, err := rl.Wait(context.Background(), 8); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected the 8-token spend to fit in the burst, elapsed=%v", elapsed)
+	}
+
+	// Only 2 tokens remain; spending 5 more should block for refill.
+	if _, err := rl.Wait(context.Background(), 5); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Fatalf("expected the second spend to wait for refill, elapsed=%v", elapsed)
+	}
+}
+
 func TestRateLimiter_ContextCancellation(t *testing.T) {
-	rl := NewRateLimiter(1) // 1 token per minute
+	rl := NewRateLimiter(1, 1) // 1 token per minute, burst 1
 	defer rl.Close()
 
 	// Consume the immediate token so next wait blocks.
-	if err := rl.Wait(context.Background()); err != nil {
+	if _, err := rl.Wait(context.Background()); err != nil {
 		t.Fatalf("unexpected wait error: %v", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	err := rl.Wait(ctx)
+	_, err := rl.Wait(ctx)
 	if !errors.Is(err, context.Canceled) {
 		t.Fatalf("expected context.Canceled, got %v", err)
 	}
 }
 
 func TestRateLimiter_NoOp(t *testing.T) {
-	rl := NewRateLimiter(0)
+	rl := NewRateLimiter(0, 1)
 	defer rl.Close()
 
 	start := time.Now()
 	for i := 0; i < 100; i++ {
-		if err := rl.Wait(context.Background()); err != nil {
+		if _, err := rl.Wait(context.Background()); err != nil {
 			t.Fatalf("unexpected wait error: %v", err)
 		}
 	}
@@ -59,12 +104,181 @@ func TestRateLimiter_NoOp(t *testing.T) {
 }
 
 func TestRateLimiter_Close(t *testing.T) {
-	rl := NewRateLimiter(1)
+	rl := NewRateLimiter(1, 1)
 	rl.Close()
 	rl.Close() // idempotent
 
-	err := rl.Wait(context.Background())
+	_, err := rl.Wait(context.Background())
 	if !errors.Is(err, errRateLimiterClosed) {
 		t.Fatalf("expected errRateLimiterClosed, got %v", err)
 	}
 }
+
+func TestRateLimiter_ThrottleHalvesRate(t *testing.T) {
+	rl := NewRateLimiter(600, 1) // 10 tokens/sec
+	defer rl.Close()
+
+	rl.Throttle()
+	if got, want := rl.refillRate, 5.0; got != want {
+		t.Fatalf("refillRate after one Throttle = %v, want %v", got, want)
+	}
+
+	rl.Throttle()
+	if got, want := rl.refillRate, 2.5; got != want {
+		t.Fatalf("refillRate after two Throttles = %v, want %v", got, want)
+	}
+}
+
+func TestRateLimiter_ThrottleFloorsAtMinFraction(t *testing.T) {
+	rl := NewRateLimiter(600, 1) // 10 tokens/sec ceiling
+	defer rl.Close()
+
+	for i := 0; i < 10; i++ {
+		rl.Throttle()
+	}
+
+	if floor := rl.ceiling * aimdMinRateFraction; rl.refillRate < floor {
+		t.Fatalf("refillRate = %v fell below floor %v", rl.refillRate, floor)
+	}
+}
+
+func TestRateLimiter_ReportSuccessClimbsBackToCeiling(t *testing.T) {
+	rl := NewRateLimiter(600, 1) // 10 tokens/sec ceiling
+	defer rl.Close()
+
+	rl.Throttle()
+	if rl.refillRate >= rl.ceiling {
+		t.Fatalf("refillRate = %v should have dropped below ceiling %v after Throttle", rl.refillRate, rl.ceiling)
+	}
+
+	// aimdSuccessThreshold-1 reports shouldn't move the rate yet.
+	for i := 0; i < aimdSuccessThreshold-1; i++ {
+		rl.ReportSuccess()
+	}
+	if rl.refillRate != rl.ceiling/2 {
+		t.Fatalf("refillRate moved before success threshold: got %v, want %v", rl.refillRate, rl.ceiling/2)
+	}
+
+	rl.ReportSuccess()
+	if rl.refillRate <= rl.ceiling/2 {
+		t.Fatalf("refillRate did not climb after success threshold: got %v", rl.refillRate)
+	}
+
+	// Enough further successes should fully recover to ceiling, capped there.
+	for i := 0; i < 10*aimdSuccessThreshold; i++ {
+		rl.ReportSuccess()
+	}
+	if rl.refillRate != rl.ceiling {
+		t.Fatalf("refillRate did not recover to ceiling: got %v, want %v", rl.refillRate, rl.ceiling)
+	}
+}
+
+func TestRateLimitToken_ReleaseRefundsCost(t *testing.T) {
+	rl := NewRateLimiter(60, 1) // 1 token/sec, burst 1
+	defer rl.Close()
+
+	tok, err := rl.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	tok.Release()
+
+	// The released token should make a second Wait succeed immediately.
+	start := time.Now()
+	if _, err := rl.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected released token to let the next Wait through immediately, elapsed=%v", elapsed)
+	}
+}
+
+func TestRateLimiter_ConsumeChargesExtraTokens(t *testing.T) {
+	rl := NewRateLimiter(60, 10) // 1 token/sec, burst 10
+	defer rl.Close()
+
+	if _, err := rl.Wait(context.Background(), 4); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	// 6 tokens remain; consuming 5 more should leave only 1.
+	rl.Consume(5)
+
+	start := time.Now()
+	if _, err := rl.Wait(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected the last remaining token to be spent immediately, elapsed=%v", elapsed)
+	}
+
+	// The bucket should now be empty (or negative): a further spend waits.
+	if _, err := rl.Wait(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Fatalf("expected Consume to leave no spare capacity, elapsed=%v", elapsed)
+	}
+}
+
+func TestRateLimiter_ConsumeNilOrZeroIsNoop(t *testing.T) {
+	var rl *RateLimiter
+	rl.Consume(5) // must not panic
+
+	disabled := NewRateLimiter(0, 1)
+	defer disabled.Close()
+	disabled.Consume(5) // must not panic
+
+	live := NewRateLimiter(60, 10)
+	defer live.Close()
+	before := live.Stats().Available
+	live.Consume(0)
+	if after := live.Stats().Available; after != before {
+		t.Fatalf("Consume(0) changed available tokens: before=%v after=%v", before, after)
+	}
+}
+
+func TestRateLimiter_Stats(t *testing.T) {
+	rl := NewRateLimiter(60, 10) // 1 token/sec, burst 10
+	defer rl.Close()
+
+	stats := rl.Stats()
+	if stats.Capacity != 10 {
+		t.Fatalf("Stats().Capacity = %v, want 10", stats.Capacity)
+	}
+	if stats.Available != 10 {
+		t.Fatalf("Stats().Available = %v, want 10 (full burst)", stats.Available)
+	}
+
+	if _, err := rl.Wait(context.Background(), 4); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if stats := rl.Stats(); stats.Available > 6 {
+		t.Fatalf("Stats().Available = %v, want <= 6 after spending 4", stats.Available)
+	}
+}
+
+func TestRateLimiter_StatsNilOrDisabledIsZero(t *testing.T) {
+	var rl *RateLimiter
+	if stats := rl.Stats(); stats != (RateLimiterStats{}) {
+		t.Fatalf("nil Stats() = %+v, want zero value", stats)
+	}
+
+	disabled := NewRateLimiter(0, 1)
+	defer disabled.Close()
+	if stats := disabled.Stats(); stats != (RateLimiterStats{}) {
+		t.Fatalf("disabled Stats() = %+v, want zero value", stats)
+	}
+}
+
+func TestRateLimitToken_ReleaseNilIsNoop(t *testing.T) {
+	var tok *RateLimitToken
+	tok.Release() // must not panic
+
+	disabled := NewRateLimiter(0, 1)
+	defer disabled.Close()
+	tok, err := disabled.Wait(context.Background())
+	if err != nil || tok != nil {
+		t.Fatalf("disabled limiter Wait = (%v, %v), want (nil, nil)", tok, err)
+	}
+	tok.Release() // must not panic
+}
@@ -0,0 +1,439 @@
+package generator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// topicPool is RCU-style storage for a topic list that refreshes in the
+// background: a refresh builds an entirely new slice and swaps it into an
+// atomic pointer, so Sample never blocks on (or behind) a refresh in
+// progress. Embedded by HTTPSampler and ConsulSampler.
+type topicPool struct {
+	topics      atomic.Pointer[[]string]
+	lastRefresh atomic.Int64 // UnixNano; 0 = never refreshed
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+func newTopicPool(seed int64) *topicPool {
+	p := &topicPool{rand: rand.New(rand.NewSource(seed))}
+	empty := []string{}
+	p.topics.Store(&empty)
+	return p
+}
+
+// sample picks a uniformly random topic from the current snapshot.
+func (p *topicPool) sample() (string, error) {
+	topics := *p.topics.Load()
+	if len(topics) == 0 {
+		return "", fmt.Errorf("generator: no topics available yet")
+	}
+
+	p.randMu.Lock()
+	i := p.rand.Intn(len(topics))
+	p.randMu.Unlock()
+	return topics[i], nil
+}
+
+// swap installs next as the current topic snapshot and records the
+// refresh time, logging how the pool size changed.
+func (p *topicPool) swap(source string, next []string) {
+	old := *p.topics.Load()
+	p.topics.Store(&next)
+	p.lastRefresh.Store(time.Now().UnixNano())
+	log.Printf("generator: %s refreshed: %d topics (%+d)", source, len(next), len(next)-len(old))
+}
+
+// LastRefresh returns when the topic pool last changed, or the zero Time
+// if it has never been refreshed.
+func (p *topicPool) LastRefresh() time.Time {
+	nano := p.lastRefresh.Load()
+	if nano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nano)
+}
+
+// HTTPSamplerOptions configures an HTTPSampler.
+type HTTPSamplerOptions struct {
+	// Headers are sent on every request (e.g. Authorization, API keys).
+	Headers map[string]string
+
+	// Selector is a dot-separated path into the decoded JSON response
+	// naming the array of topic strings, e.g. "data.topics". Empty means
+	// the top-level response is itself the array.
+	Selector string
+
+	// Interval is how often to re-fetch. Defaults to 60s.
+	Interval time.Duration
+
+	// Client is the HTTP client used for requests. Defaults to a client
+	// with a 10s timeout.
+	Client *http.Client
+
+	// Seed seeds the sampler's PRNG. A random seed is used when zero.
+	Seed int64
+}
+
+// HTTPSampler fetches its topic pool from a JSON HTTP endpoint and
+// refreshes it on an interval, Prometheus-target-discovery style: a
+// background goroutine re-fetches, and Sample always reads the latest
+// swapped-in snapshot instead of blocking on the fetch.
+type HTTPSampler struct {
+	*topicPool
+
+	url      string
+	selector string
+	headers  map[string]string
+	interval time.Duration
+	client   *http.Client
+
+	etag         string
+	lastModified string
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewHTTPSampler creates an HTTPSampler and performs an initial fetch
+// before returning, so a misconfigured URL or selector fails fast instead
+// of surfacing during generation.
+func NewHTTPSampler(rawURL string, opts HTTPSamplerOptions) (*HTTPSampler, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 60 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+
+	s := &HTTPSampler{
+		topicPool: newTopicPool(seed),
+		url:       rawURL,
+		selector:  opts.Selector,
+		headers:   opts.Headers,
+		interval:  opts.Interval,
+		client:    opts.Client,
+		done:      make(chan struct{}),
+	}
+
+	if err := s.Refresh(context.Background()); err != nil {
+		return nil, fmt.Errorf("generator: initial fetch of %s failed: %w", rawURL, err)
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// Sample returns a topic from the most recently fetched snapshot.
+func (s *HTTPSampler) Sample(ctx context.Context, index int) (string, error) {
+	return s.sample()
+}
+
+// Refresh re-fetches the topic list immediately, using ETag/
+// If-Modified-Since to skip the body entirely when nothing changed.
+func (s *HTTPSampler) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	if s.lastModified != "" {
+		req.Header.Set("If-Modified-Since", s.lastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("generator: fetching %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("generator: fetching %s: unexpected status %s", s.url, resp.Status)
+	}
+
+	var decoded any
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return fmt.Errorf("generator: decoding response from %s: %w", s.url, err)
+	}
+
+	topics, err := selectStrings(decoded, s.selector)
+	if err != nil {
+		return fmt.Errorf("generator: %s: %w", s.url, err)
+	}
+
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+	s.swap("http sampler "+s.url, topics)
+	return nil
+}
+
+// Close stops the background refresh goroutine.
+func (s *HTTPSampler) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+func (s *HTTPSampler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.Refresh(context.Background()); err != nil {
+				log.Printf("generator: http sampler refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// selectStrings walks a dot-separated path of object keys into v and
+// returns the array found there as a []string. An empty selector expects
+// v itself to be the array.
+func selectStrings(v any, selector string) ([]string, error) {
+	cur := v
+	if selector != "" {
+		for _, part := range strings.Split(selector, ".") {
+			m, ok := cur.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("selector %q: expected an object at %q, got %T", selector, part, cur)
+			}
+			next, ok := m[part]
+			if !ok {
+				return nil, fmt.Errorf("selector %q: key %q not found", selector, part)
+			}
+			cur = next
+		}
+	}
+
+	arr, ok := cur.([]any)
+	if !ok {
+		return nil, fmt.Errorf("selector %q: expected an array of strings, got %T", selector, cur)
+	}
+	topics := make([]string, 0, len(arr))
+	for _, item := range arr {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("selector %q: expected a string array element, got %T", selector, item)
+		}
+		topics = append(topics, str)
+	}
+	return topics, nil
+}
+
+// ConsulSamplerOptions configures a ConsulSampler.
+type ConsulSamplerOptions struct {
+	// Token is sent as X-Consul-Token when set.
+	Token string
+
+	// Client is the HTTP client used for requests. Defaults to a client
+	// with no timeout, since blocking queries intentionally hang for up
+	// to WaitTime; each request instead gets its own context deadline.
+	Client *http.Client
+
+	// WaitTime bounds how long a blocking query may hang waiting for a
+	// change before Consul returns the unchanged value. Defaults to 5m.
+	WaitTime time.Duration
+
+	// Seed seeds the sampler's PRNG. A random seed is used when zero.
+	Seed int64
+}
+
+type consulKVEntry struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+// ConsulSampler walks a Consul KV prefix, treating each key's decoded
+// value as one topic, and keeps the pool current with Consul's blocking
+// query mechanism (?index=<X>&wait=<Y>) instead of naive polling: a
+// request only returns once the KV prefix actually changes or WaitTime
+// elapses.
+type ConsulSampler struct {
+	*topicPool
+
+	addr     string
+	prefix   string
+	token    string
+	client   *http.Client
+	waitTime time.Duration
+
+	lastIndex atomic.Uint64
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewConsulSampler creates a ConsulSampler and performs an initial,
+// non-blocking fetch before returning, so a bad address or prefix fails
+// fast instead of surfacing during generation.
+func NewConsulSampler(addr, prefix string, opts ConsulSamplerOptions) (*ConsulSampler, error) {
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+	if opts.WaitTime <= 0 {
+		opts.WaitTime = 5 * time.Minute
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+
+	s := &ConsulSampler{
+		topicPool: newTopicPool(seed),
+		addr:      strings.TrimRight(addr, "/"),
+		prefix:    strings.TrimLeft(prefix, "/"),
+		token:     opts.Token,
+		client:    opts.Client,
+		waitTime:  opts.WaitTime,
+		done:      make(chan struct{}),
+	}
+
+	if err := s.Refresh(context.Background()); err != nil {
+		return nil, fmt.Errorf("generator: initial consul kv fetch of %s failed: %w", prefix, err)
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// Sample returns a topic from the most recently fetched snapshot.
+func (s *ConsulSampler) Sample(ctx context.Context, index int) (string, error) {
+	return s.sample()
+}
+
+// Refresh queries Consul's KV API for s.prefix. Once an initial index has
+// been observed, subsequent calls use it as a blocking query so Refresh
+// only returns promptly when the prefix actually changed.
+func (s *ConsulSampler) Refresh(ctx context.Context) error {
+	index := s.lastIndex.Load()
+	blocking := index > 0
+
+	q := url.Values{}
+	q.Set("recurse", "true")
+	if blocking {
+		q.Set("index", strconv.FormatUint(index, 10))
+		q.Set("wait", s.waitTime.String())
+	}
+	reqURL := fmt.Sprintf("%s/v1/kv/%s?%s", s.addr, s.prefix, q.Encode())
+
+	reqCtx := ctx
+	if blocking {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, s.waitTime+30*time.Second)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	if s.token != "" {
+		req.Header.Set("X-Consul-Token", s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if blocking && errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+			// Long-poll timed out with nothing changed; not an error.
+			return nil
+		}
+		return fmt.Errorf("generator: consul kv query for %s failed: %w", s.prefix, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		s.swap("consul sampler "+s.prefix, nil)
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("generator: consul kv query for %s failed: unexpected status %s", s.prefix, resp.Status)
+	}
+
+	var entries []consulKVEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return fmt.Errorf("generator: decoding consul kv response for %s: %w", s.prefix, err)
+	}
+
+	if raw := resp.Header.Get("X-Consul-Index"); raw != "" {
+		if next, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			s.lastIndex.Store(next)
+		}
+	}
+
+	topics := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		value, err := base64.StdEncoding.DecodeString(entry.Value)
+		if err != nil {
+			log.Printf("generator: consul sampler: skipping key %s: %v", entry.Key, err)
+			continue
+		}
+		if topic := strings.TrimSpace(string(value)); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+
+	s.swap("consul sampler "+s.prefix, topics)
+	return nil
+}
+
+// Close stops the background refresh goroutine.
+func (s *ConsulSampler) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+func (s *ConsulSampler) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-s.done
+		cancel()
+	}()
+	defer cancel()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		if err := s.Refresh(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("generator: consul sampler refresh failed: %v", err)
+			select {
+			case <-s.done:
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+	"github.com/shantoislamdev/kothaset/internal/telemetry"
+)
+
+// FallbackPolicy selects which configured provider handles the next
+// request when Config.Providers lists more than one.
+type FallbackPolicy string
+
+const (
+	// FallbackFailover always prefers the first eligible provider in
+	// Config.Providers order, only moving on to the next one once the
+	// preferred provider is cooling down. This is the default, and the
+	// only policy that applies when a single provider is configured.
+	FallbackFailover FallbackPolicy = "failover"
+
+	// FallbackRoundRobin cycles through the eligible providers in order,
+	// one per request.
+	FallbackRoundRobin FallbackPolicy = "round-robin"
+
+	// FallbackWeighted picks an eligible provider at random, weighted by
+	// ProviderSpec.Weight.
+	FallbackWeighted FallbackPolicy = "weighted"
+)
+
+// ProviderSpec names one provider/model pair eligible for routing. A
+// Generator must have a live provider.Provider registered under Name via
+// New/AddProvider for every spec it's given. Weight is only consulted
+// under FallbackWeighted; it's treated as 1 when unset or <= 0.
+type ProviderSpec struct {
+	Name   string `yaml:"name" json:"name"`
+	Model  string `yaml:"model,omitempty" json:"model,omitempty"`
+	Weight int    `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// RateLimit and RateLimitBurst pace requests-per-minute for just this
+	// provider, independent of however many workers route through it. <= 0
+	// falls back to the default passed to newProviderRouter (Config.RateLimit
+	// /Config.RateLimitBurst), so a spec built without these (e.g. the
+	// single-provider case) keeps today's behavior.
+	RateLimit      int `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	RateLimitBurst int `yaml:"rate_limit_burst,omitempty" json:"rate_limit_burst,omitempty"`
+
+	// TPMLimit and TPMBurst pace tokens-per-minute the same way RateLimit
+	// paces requests; <= 0 disables the tokens-per-minute dimension for
+	// this provider.
+	TPMLimit int `yaml:"tpm_limit,omitempty" json:"tpm_limit,omitempty"`
+	TPMBurst int `yaml:"tpm_burst,omitempty" json:"tpm_burst,omitempty"`
+}
+
+// providerLimiter paces one provider's requests-per-minute and
+// tokens-per-minute independently; either dimension is a no-op bucket
+// (NewRateLimiter with <= 0) when unconfigured.
+type providerLimiter struct {
+	requests *RateLimiter
+	tokens   *RateLimiter
+}
+
+func newProviderLimiter(rpm, rpmBurst, tpm, tpmBurst int) *providerLimiter {
+	return &providerLimiter{
+		requests: NewRateLimiter(rpm, rpmBurst),
+		tokens:   NewRateLimiter(tpm, tpmBurst),
+	}
+}
+
+// providerLimiterToken bundles the request- and (if spent) token-bucket
+// reservations a single providerLimiter.wait call made, so Release gives
+// both back together.
+type providerLimiterToken struct {
+	req *RateLimitToken
+	tok *RateLimitToken
+}
+
+// Release returns both reservations to their buckets; callers should do
+// so when the request they paced for fails with a server error. Safe to
+// call on a nil token.
+func (t *providerLimiterToken) Release() {
+	if t == nil {
+		return
+	}
+	t.req.Release()
+	t.tok.Release()
+}
+
+// wait blocks until both the request bucket and, if estimatedTokens > 0,
+// the token bucket have room, returning a token covering both
+// reservations so the caller can Release them if the request it paced
+// for fails with a server error. If the token-bucket wait fails after
+// the request bucket already granted its token, that token is released
+// rather than spent, since the request never actually went out.
+func (l *providerLimiter) wait(ctx context.Context, estimatedTokens int) (*providerLimiterToken, error) {
+	reqTok, err := l.requests.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if estimatedTokens > 0 {
+		tokTok, err := l.tokens.Wait(ctx, estimatedTokens)
+		if err != nil {
+			reqTok.Release()
+			return nil, err
+		}
+		return &providerLimiterToken{req: reqTok, tok: tokTok}, nil
+	}
+	return &providerLimiterToken{req: reqTok}, nil
+}
+
+func (l *providerLimiter) close() {
+	l.requests.Close()
+	l.tokens.Close()
+}
+
+// providerRouter selects which configured provider handles each request.
+// A provider that fails its HealthCheck after a retryable Generate error
+// is pulled out of rotation for cooldown before it's eligible again.
+type providerRouter struct {
+	specs     []ProviderSpec
+	providers map[string]provider.Provider
+	policy    FallbackPolicy
+	cooldown  time.Duration
+
+	mu          sync.Mutex
+	nextRR      int
+	coolingDown map[string]time.Time
+	limiters    map[string]*providerLimiter
+	pauseUntil  map[string]time.Time
+
+	randFloat func() float64
+}
+
+// routerLimits seeds the rate/token buckets for any ProviderSpec that
+// doesn't set its own RateLimit/RateLimitBurst/TPMLimit/TPMBurst, mirroring
+// Config's generator-wide defaults.
+type routerLimits struct {
+	RPM      int
+	Burst    int
+	TPM      int
+	TPMBurst int
+}
+
+// newProviderRouter validates that specs and providers agree (a live
+// provider.Provider registered for every spec name) and returns a router
+// ready for Select. policy defaults to FallbackFailover when empty.
+func newProviderRouter(specs []ProviderSpec, providers map[string]provider.Provider, policy FallbackPolicy, cooldown time.Duration, defaults routerLimits) (*providerRouter, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("generator: at least one provider must be configured")
+	}
+	for _, s := range specs {
+		if _, ok := providers[s.Name]; !ok {
+			return nil, fmt.Errorf("generator: no provider instance registered for %q (call Generator.AddProvider)", s.Name)
+		}
+	}
+	if policy == "" {
+		policy = FallbackFailover
+	}
+
+	limiters := make(map[string]*providerLimiter, len(specs))
+	for _, s := range specs {
+		rpm, burst, tpm, tpmBurst := s.RateLimit, s.RateLimitBurst, s.TPMLimit, s.TPMBurst
+		if rpm <= 0 {
+			rpm = defaults.RPM
+		}
+		if burst <= 0 {
+			burst = defaults.Burst
+		}
+		if tpm <= 0 {
+			tpm = defaults.TPM
+		}
+		if tpmBurst <= 0 {
+			tpmBurst = defaults.TPMBurst
+		}
+		limiter := newProviderLimiter(rpm, burst, tpm, tpmBurst)
+		limiters[s.Name] = limiter
+		// Best-effort: a gauge registration failure (e.g. observability
+		// disabled, or a duplicate instrument name) shouldn't block routing.
+		_ = telemetry.RegisterRateLimiterGauge(s.Name, func() (available, capacity float64) {
+			stats := limiter.requests.Stats()
+			return stats.Available, stats.Capacity
+		})
+	}
+
+	return &providerRouter{
+		specs:       specs,
+		providers:   providers,
+		policy:      policy,
+		cooldown:    cooldown,
+		coolingDown: make(map[string]time.Time),
+		limiters:    limiters,
+		pauseUntil:  make(map[string]time.Time),
+		randFloat:   rand.Float64,
+	}, nil
+}
+
+// eligible returns the specs not currently cooling down, in Config.Providers
+// order. If every spec is cooling down, it returns the full list instead of
+// an empty one, since stalling generation entirely is worse than retrying a
+// provider that might have recovered.
+func (r *providerRouter) eligible() []ProviderSpec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var out []ProviderSpec
+	for _, s := range r.specs {
+		if until, ok := r.coolingDown[s.Name]; ok && now.Before(until) {
+			continue
+		}
+		out = append(out, s)
+	}
+	if len(out) == 0 {
+		return r.specs
+	}
+	return out
+}
+
+// Select returns the provider/model that should serve the next request.
+// skip excludes providers already tried earlier in the same sample's retry
+// loop, so a retry fails over to a different provider instead of hitting
+// the same one again; if every candidate has been skipped, skip is
+// ignored rather than reporting no eligible provider.
+func (r *providerRouter) Select(skip map[string]bool) (ProviderSpec, provider.Provider) {
+	candidates := r.eligible()
+	if len(skip) > 0 {
+		var filtered []ProviderSpec
+		for _, s := range candidates {
+			if !skip[s.Name] {
+				filtered = append(filtered, s)
+			}
+		}
+		if len(filtered) > 0 {
+			candidates = filtered
+		}
+	}
+
+	var chosen ProviderSpec
+	switch r.policy {
+	case FallbackRoundRobin:
+		r.mu.Lock()
+		chosen = candidates[r.nextRR%len(candidates)]
+		r.nextRR++
+		r.mu.Unlock()
+	case FallbackWeighted:
+		chosen = r.pickWeighted(candidates)
+	default: // FallbackFailover
+		chosen = candidates[0]
+	}
+	return chosen, r.providers[chosen.Name]
+}
+
+func (r *providerRouter) pickWeighted(candidates []ProviderSpec) ProviderSpec {
+	total := 0
+	for _, s := range candidates {
+		total += weightOf(s)
+	}
+
+	target := r.randFloat() * float64(total)
+	acc := 0.0
+	for _, s := range candidates {
+		acc += float64(weightOf(s))
+		if target < acc {
+			return s
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+func weightOf(s ProviderSpec) int {
+	if s.Weight <= 0 {
+		return 1
+	}
+	return s.Weight
+}
+
+// EligibleCount returns how many provider/model entries Select currently has
+// to choose from (excluding ones cooling down), so a retry loop can tell
+// whether every candidate has already been tried for this request (see
+// Select's skip parameter) before giving up. Using the eligible count rather
+// than the total configured specs keeps this in sync with what skip is
+// actually filtered against: if a cooling-down spec made one candidate
+// unreachable, trying the rest shouldn't also require cycling back through it.
+func (r *providerRouter) EligibleCount() int {
+	return len(r.eligible())
+}
+
+// RecordFailure runs a quick HealthCheck against the provider that just
+// returned a retryable error and, if it also fails, pulls it out of
+// rotation for Cooldown. A router with Cooldown <= 0 never cools a
+// provider down, even on a failed HealthCheck.
+func (r *providerRouter) RecordFailure(ctx context.Context, name string, prov provider.Provider) {
+	if r.cooldown <= 0 {
+		return
+	}
+	if err := prov.HealthCheck(ctx); err != nil {
+		r.mu.Lock()
+		r.coolingDown[name] = time.Now().Add(r.cooldown)
+		r.mu.Unlock()
+	}
+}
+
+// Acquire paces a request to name: it first waits out any pause set by
+// Pause (e.g. a 429's Retry-After deadline), then blocks on name's
+// request and, if estimatedTokens > 0, token buckets. Acquire is safe to
+// call from every worker goroutine concurrently; each provider has its
+// own independent buckets, so pacing one provider never blocks another.
+// The returned token's Release should be called if the request it paced
+// for turns out to fail with a server error, so a provider having an
+// outage isn't throttled on top of it.
+func (r *providerRouter) Acquire(ctx context.Context, name string, estimatedTokens int) (*providerLimiterToken, error) {
+	if until, paused := r.pauseDeadline(name); paused {
+		timer := time.NewTimer(time.Until(until))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	l := r.limiterFor(name)
+	if l == nil {
+		return nil, nil
+	}
+	return l.wait(ctx, estimatedTokens)
+}
+
+// RecordRateLimit applies the multiplicative-decrease half of AIMD to
+// name's request bucket after a rate-limit response (see
+// RateLimiter.Throttle), so the router backs off even if Pause's
+// Retry-After deadline is short or the provider omits one.
+func (r *providerRouter) RecordRateLimit(name string) {
+	if l := r.limiterFor(name); l != nil {
+		l.requests.Throttle()
+	}
+}
+
+// RecordSuccess records a non-rate-limited response for name's request
+// bucket, the additive half of AIMD (see RateLimiter.ReportSuccess).
+func (r *providerRouter) RecordSuccess(name string) {
+	if l := r.limiterFor(name); l != nil {
+		l.requests.ReportSuccess()
+	}
+}
+
+// Report trues up name's token bucket once a request's actual usage is
+// known: Acquire already spent estimatedTokens, so if the request used
+// fewer, the difference is refunded; if it used more (the estimate was
+// too low), the shortfall is additionally consumed instead of being let
+// through for free.
+func (r *providerRouter) Report(name string, estimatedTokens, actualTokens int) {
+	l := r.limiterFor(name)
+	if l == nil {
+		return
+	}
+	switch diff := estimatedTokens - actualTokens; {
+	case diff > 0:
+		l.tokens.Refund(diff)
+	case diff < 0:
+		l.tokens.Consume(-diff)
+	}
+}
+
+// Pause stops Acquire from letting name's requests through until until,
+// e.g. after a response carrying a Retry-After header. A zero until
+// clears any existing pause.
+func (r *providerRouter) Pause(name string, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until.IsZero() {
+		delete(r.pauseUntil, name)
+		return
+	}
+	r.pauseUntil[name] = until
+}
+
+// SetLimit hot-swaps name's rate limiter, e.g. from a reloaded secrets
+// file or a --rate-limit-rpm/--rate-limit-tpm override. The old limiter
+// isn't closed until after the swap, mirroring RateLimiter's own
+// replace-then-close pattern (see Generator.SetRateLimit), so a worker
+// already blocked in Acquire on it isn't cut off mid-wait.
+func (r *providerRouter) SetLimit(name string, rpm, rpmBurst, tpm, tpmBurst int) {
+	next := newProviderLimiter(rpm, rpmBurst, tpm, tpmBurst)
+	r.mu.Lock()
+	old := r.limiters[name]
+	r.limiters[name] = next
+	r.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+}
+
+// Close releases every provider's rate limiters. Call once Run is done
+// with the router.
+func (r *providerRouter) Close() {
+	r.mu.Lock()
+	limiters := r.limiters
+	r.mu.Unlock()
+	for _, l := range limiters {
+		l.close()
+	}
+}
+
+func (r *providerRouter) pauseDeadline(name string) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	until, ok := r.pauseUntil[name]
+	if !ok {
+		return time.Time{}, false
+	}
+	if !time.Now().Before(until) {
+		delete(r.pauseUntil, name)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+func (r *providerRouter) limiterFor(name string) *providerLimiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.limiters[name]
+}
@@ -0,0 +1,243 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// toProviderMap widens a map of *MockProvider to the provider.Provider
+// interface map newProviderRouter expects.
+func toProviderMap(m map[string]*MockProvider) map[string]provider.Provider {
+	out := make(map[string]provider.Provider, len(m))
+	for name, p := range m {
+		out[name] = p
+	}
+	return out
+}
+
+func TestProviderRouter_FailoverPrefersFirst(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a"}, {Name: "b"}}
+	providers := map[string]*MockProvider{"a": {}, "b": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	spec, _ := r.Select(nil)
+	if spec.Name != "a" {
+		t.Errorf("Select() = %q, want %q", spec.Name, "a")
+	}
+}
+
+func TestProviderRouter_FailoverSkipsTried(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a"}, {Name: "b"}}
+	providers := map[string]*MockProvider{"a": {}, "b": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	spec, _ := r.Select(map[string]bool{"a": true})
+	if spec.Name != "b" {
+		t.Errorf("Select(skip a) = %q, want %q", spec.Name, "b")
+	}
+}
+
+func TestProviderRouter_RoundRobinCycles(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a"}, {Name: "b"}}
+	providers := map[string]*MockProvider{"a": {}, "b": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackRoundRobin, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		spec, _ := r.Select(nil)
+		got = append(got, spec.Name)
+	}
+	want := []string{"a", "b", "a", "b"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("round-robin sequence = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestProviderRouter_WeightedRespectsWeight(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a", Weight: 3}, {Name: "b", Weight: 1}}
+	providers := map[string]*MockProvider{"a": {}, "b": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackWeighted, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	// A deterministic randFloat just below 3/4 should land on "a" (its
+	// 0-3 slice of the 0-4 weighted range); just above should land on "b".
+	r.randFloat = func() float64 { return 0.74 }
+	if spec, _ := r.Select(nil); spec.Name != "a" {
+		t.Errorf("Select() at 0.74 = %q, want %q", spec.Name, "a")
+	}
+	r.randFloat = func() float64 { return 0.76 }
+	if spec, _ := r.Select(nil); spec.Name != "b" {
+		t.Errorf("Select() at 0.76 = %q, want %q", spec.Name, "b")
+	}
+}
+
+func TestProviderRouter_CooldownPullsFailingProviderOutOfRotation(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a"}, {Name: "b"}}
+	unhealthy := &MockProvider{ShouldFail: true}
+	providers := map[string]*MockProvider{"a": unhealthy, "b": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 20*time.Millisecond, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	r.RecordFailure(context.Background(), "a", unhealthy)
+
+	spec, _ := r.Select(nil)
+	if spec.Name != "b" {
+		t.Fatalf("Select() after cooldown = %q, want %q", spec.Name, "b")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	spec, _ = r.Select(nil)
+	if spec.Name != "a" {
+		t.Fatalf("Select() after cooldown elapsed = %q, want %q", spec.Name, "a")
+	}
+}
+
+func TestProviderRouter_MissingProviderInstanceErrors(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a"}, {Name: "b"}}
+	providers := map[string]*MockProvider{"a": {}}
+	if _, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{}); err == nil {
+		t.Fatal("expected an error for a spec with no registered provider instance")
+	}
+}
+
+func TestProviderRouter_AcquireUsesPerProviderLimit(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a", RateLimit: 60, RateLimitBurst: 1}, {Name: "b"}}
+	providers := map[string]*MockProvider{"a": {}, "b": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	if _, err := r.Acquire(context.Background(), "a", 0); err != nil {
+		t.Fatalf("first Acquire(a) error = %v", err)
+	}
+	// "a"'s single-request burst is spent; a second immediate Acquire
+	// must block, while "b" (no RateLimit set, so the default of 0
+	// disables throttling) is unaffected.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.Acquire(ctx, "a", 0); err == nil {
+		t.Fatal("second Acquire(a) should have blocked past the burst")
+	}
+	if _, err := r.Acquire(context.Background(), "b", 0); err != nil {
+		t.Fatalf("Acquire(b) error = %v, want nil (unthrottled)", err)
+	}
+}
+
+func TestProviderRouter_PauseBlocksUntilDeadline(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a"}}
+	providers := map[string]*MockProvider{"a": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	r.Pause("a", time.Now().Add(30*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := r.Acquire(ctx, "a", 0); err == nil {
+		t.Fatal("Acquire(a) should have blocked on the pause deadline")
+	}
+
+	if _, err := r.Acquire(context.Background(), "a", 0); err != nil {
+		t.Fatalf("Acquire(a) after pause elapsed error = %v", err)
+	}
+}
+
+func TestProviderRouter_RecordRateLimitThrottlesNamedProvider(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a", RateLimit: 600, RateLimitBurst: 1}, {Name: "b", RateLimit: 600, RateLimitBurst: 1}}
+	providers := map[string]*MockProvider{"a": {}, "b": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	r.RecordRateLimit("a")
+
+	la, lb := r.limiterFor("a"), r.limiterFor("b")
+	if got, want := la.requests.refillRate, la.requests.ceiling*aimdDecreaseFactor; got != want {
+		t.Fatalf("\"a\" refillRate = %v, want %v", got, want)
+	}
+	if lb.requests.refillRate != lb.requests.ceiling {
+		t.Fatalf("\"b\" refillRate = %v, should be unaffected by \"a\"'s rate limit", lb.requests.refillRate)
+	}
+
+	for i := 0; i < aimdSuccessThreshold; i++ {
+		r.RecordSuccess("a")
+	}
+	if la.requests.refillRate <= la.requests.ceiling*aimdDecreaseFactor {
+		t.Fatalf("\"a\" refillRate did not climb after RecordSuccess: got %v", la.requests.refillRate)
+	}
+}
+
+func TestProviderRouter_ReportRefundsUnusedTokens(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a", TPMLimit: 60, TPMBurst: 100}}
+	providers := map[string]*MockProvider{"a": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	if _, err := r.Acquire(context.Background(), "a", 100); err != nil {
+		t.Fatalf("Acquire(a, 100) error = %v", err)
+	}
+	// The full burst was spent on a 100-token estimate; reporting that
+	// only 10 tokens were actually used should refund the other 90,
+	// leaving enough for another 90-token Acquire without waiting.
+	r.Report("a", 100, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.Acquire(ctx, "a", 90); err != nil {
+		t.Fatalf("Acquire(a, 90) after refund error = %v", err)
+	}
+}
+
+func TestProviderRouter_ReportConsumesUnderestimatedTokens(t *testing.T) {
+	specs := []ProviderSpec{{Name: "a", TPMLimit: 60, TPMBurst: 100}}
+	providers := map[string]*MockProvider{"a": {}}
+	r, err := newProviderRouter(specs, toProviderMap(providers), FallbackFailover, 0, routerLimits{})
+	if err != nil {
+		t.Fatalf("newProviderRouter() error = %v", err)
+	}
+
+	if _, err := r.Acquire(context.Background(), "a", 50); err != nil {
+		t.Fatalf("Acquire(a, 50) error = %v", err)
+	}
+	// The estimate (50) undershot the actual usage (90); the extra 40
+	// should be charged against the bucket too, leaving only 10 of the
+	// original 100-token burst.
+	r.Report("a", 50, 90)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.Acquire(ctx, "a", 10); err != nil {
+		t.Fatalf("Acquire(a, 10) after underestimate error = %v", err)
+	}
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+	if _, err := r.Acquire(ctx2, "a", 10); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire(a, 10) after bucket exhausted error = %v, want DeadlineExceeded", err)
+	}
+}
@@ -3,6 +3,7 @@ package generator
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"math/rand"
 	"os"
 	"strings"
@@ -124,12 +125,16 @@ var defaultCategories = []string{
 	"general knowledge",
 }
 
-// CompositeSampler combines multiple samplers
+// CompositeSampler combines multiple samplers, picking among them by
+// weight with Vose's alias method: after an O(n) setup pass, Sample draws
+// in O(1) instead of doing a cumulative-weight scan on every call.
 type CompositeSampler struct {
 	samplers []Sampler
-	weights  []float64
-	rand     *rand.Rand
-	mu       sync.Mutex
+
+	mu    sync.Mutex
+	rand  *rand.Rand
+	prob  []float64
+	alias []int
 }
 
 // NewCompositeSampler creates a sampler that randomly picks from multiple sources
@@ -141,25 +146,117 @@ func NewCompositeSampler(samplers []Sampler, weights []float64, seed int64) *Com
 			weights[i] = 1.0 / float64(len(samplers))
 		}
 	}
+	prob, alias := buildAliasTable(weights)
 	return &CompositeSampler{
 		samplers: samplers,
-		weights:  weights,
 		rand:     rand.New(rand.NewSource(seed)),
+		prob:     prob,
+		alias:    alias,
 	}
 }
 
-// Sample picks a sampler and returns its sample
+// SetWeights replaces the weights used to pick among the composed
+// samplers and rebuilds the alias table. Safe to call concurrently with
+// Sample.
+func (s *CompositeSampler) SetWeights(weights []float64) error {
+	if len(weights) != len(s.samplers) {
+		return fmt.Errorf("generator: expected %d weights, got %d", len(s.samplers), len(weights))
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return fmt.Errorf("generator: weights must sum to a positive value")
+	}
+
+	prob, alias := buildAliasTable(weights)
+
+	s.mu.Lock()
+	s.prob, s.alias = prob, alias
+	s.mu.Unlock()
+	return nil
+}
+
+// Sample draws a sampler index via the alias table and returns its sample.
 func (s *CompositeSampler) Sample(ctx context.Context, index int) (string, error) {
 	s.mu.Lock()
-	r := s.rand.Float64()
+	i := s.rand.Intn(len(s.prob))
+	coin := s.rand.Float64()
+	prob := s.prob[i]
+	alias := s.alias[i]
 	s.mu.Unlock()
 
-	var cumulative float64
-	for i, w := range s.weights {
-		cumulative += w
-		if r < cumulative {
-			return s.samplers[i].Sample(ctx, index)
+	if coin < prob {
+		return s.samplers[i].Sample(ctx, index)
+	}
+	return s.samplers[alias].Sample(ctx, index)
+}
+
+// buildAliasTable builds Vose's alias method tables for n weights in O(n):
+// each weight is scaled so the average is 1, then entries below average
+// ("small") are repeatedly paired off against entries above average
+// ("large"), donating their shortfall onto the paired large entry until
+// every entry is accounted for. weights need not already sum to 1.
+func buildAliasTable(weights []float64) (prob []float64, alias []int) {
+	n := len(weights)
+	prob = make([]float64, n)
+	alias = make([]int, n)
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	scaled := make([]float64, n)
+	if total <= 0 {
+		// Degenerate input (e.g. all-zero weights): fall back to uniform
+		// rather than dividing by zero.
+		for i := range scaled {
+			scaled[i] = 1
+		}
+	} else {
+		for i, w := range weights {
+			scaled[i] = w / total * float64(n)
+		}
+	}
+
+	small := make([]int, 0, n)
+	large := make([]int, 0, n)
+	for i, p := range scaled {
+		if p < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
 		}
 	}
-	return s.samplers[len(s.samplers)-1].Sample(ctx, index)
+
+	for len(small) > 0 && len(large) > 0 {
+		sm := small[len(small)-1]
+		small = small[:len(small)-1]
+		lg := large[len(large)-1]
+		large = large[:len(large)-1]
+
+		prob[sm] = scaled[sm]
+		alias[sm] = lg
+
+		scaled[lg] -= 1 - scaled[sm]
+		if scaled[lg] < 1 {
+			small = append(small, lg)
+		} else {
+			large = append(large, lg)
+		}
+	}
+
+	// Any entries left over are ~1 up to floating point error; treat them
+	// as certain outcomes with no alias needed.
+	for _, i := range large {
+		prob[i] = 1
+	}
+	for _, i := range small {
+		prob[i] = 1
+	}
+
+	return prob, alias
 }
@@ -82,6 +82,52 @@ func TestNewSampler_PermissionError(t *testing.T) {
 	}
 }
 
+func TestCompositeSampler_WeightedDistribution(t *testing.T) {
+	counts := map[string]int{}
+	record := func(name string) *RandomSampler {
+		r := NewRandomSampler(1)
+		r.SetCategories([]string{name})
+		return r
+	}
+
+	s := NewCompositeSampler([]Sampler{record("a"), record("b"), record("c")}, []float64{0.7, 0.2, 0.1}, 42)
+
+	ctx := context.Background()
+	const trials = 20000
+	for i := 0; i < trials; i++ {
+		topic, err := s.Sample(ctx, i)
+		if err != nil {
+			t.Fatalf("Sample failed: %v", err)
+		}
+		counts[topic]++
+	}
+
+	got := float64(counts["a"]) / trials
+	if got < 0.65 || got > 0.75 {
+		t.Fatalf("expected ~0.7 of samples to be %q, got %.3f", "a", got)
+	}
+}
+
+func TestCompositeSampler_SetWeights(t *testing.T) {
+	record := func(name string) *RandomSampler {
+		r := NewRandomSampler(1)
+		r.SetCategories([]string{name})
+		return r
+	}
+
+	s := NewCompositeSampler([]Sampler{record("a"), record("b")}, []float64{0.5, 0.5}, 7)
+
+	if err := s.SetWeights([]float64{1, 1}); err != nil {
+		t.Fatalf("SetWeights failed: %v", err)
+	}
+	if err := s.SetWeights([]float64{1}); err == nil {
+		t.Fatal("expected error for mismatched weight count")
+	}
+	if err := s.SetWeights([]float64{0, 0}); err == nil {
+		t.Fatal("expected error for all-zero weights")
+	}
+}
+
 func TestTopics_DefensiveCopy(t *testing.T) {
 	s := &FileSampler{topics: []string{"A", "B"}}
 
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// StreamAggregator assembles the chan provider.StreamChunk returned by
+// Provider.GenerateStream into a single GenerationResponse, the same
+// shape Generate returns directly, so generateSample can treat a
+// streaming call and a batch call identically past this point.
+type StreamAggregator struct {
+	// Progress, if set, receives each chunk's Content delta as it
+	// arrives (not the accumulated total), letting a caller render
+	// incremental output for a TUI. Nil disables forwarding.
+	Progress io.Writer
+}
+
+// Aggregate drains ch, concatenating Content and taking FinishReason,
+// Usage, and RateLimit from the final (Done) chunk. It returns the first
+// chunk's Error, if any, and stops draining at that point.
+func (a *StreamAggregator) Aggregate(ch <-chan provider.StreamChunk) (*provider.GenerationResponse, error) {
+	start := time.Now()
+	var content strings.Builder
+	resp := &provider.GenerationResponse{}
+
+	var toolOrder []int
+	toolCalls := map[int]*provider.ToolCall{}
+
+	for chunk := range ch {
+		if chunk.Error != nil {
+			return nil, chunk.Error
+		}
+		if chunk.Content != "" {
+			content.WriteString(chunk.Content)
+			if a.Progress != nil {
+				io.WriteString(a.Progress, chunk.Content)
+			}
+		}
+		if d := chunk.ToolCallDelta; d != nil {
+			call, ok := toolCalls[d.Index]
+			if !ok {
+				call = &provider.ToolCall{}
+				toolCalls[d.Index] = call
+				toolOrder = append(toolOrder, d.Index)
+			}
+			if d.ID != "" {
+				call.ID = d.ID
+			}
+			if d.Name != "" {
+				call.Name = d.Name
+			}
+			call.Arguments += d.Arguments
+		}
+		if chunk.Done {
+			resp.FinishReason = chunk.FinishReason
+			if chunk.Usage != nil {
+				resp.Usage = *chunk.Usage
+			}
+			if chunk.RateLimit != nil {
+				resp.RateLimit = chunk.RateLimit
+			}
+		}
+	}
+
+	resp.Content = content.String()
+	resp.Latency = time.Since(start)
+	for _, idx := range toolOrder {
+		resp.ToolCalls = append(resp.ToolCalls, *toolCalls[idx])
+	}
+	return resp, nil
+}
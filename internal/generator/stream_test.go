@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+func TestStreamAggregator_Aggregate(t *testing.T) {
+	ch := make(chan provider.StreamChunk, 3)
+	ch <- provider.StreamChunk{Content: "Hello, "}
+	ch <- provider.StreamChunk{Content: "world!"}
+	ch <- provider.StreamChunk{Done: true, FinishReason: "stop", Usage: &provider.TokenUsage{TotalTokens: 7}}
+	close(ch)
+
+	var progress strings.Builder
+	agg := &StreamAggregator{Progress: &progress}
+
+	resp, err := agg.Aggregate(ch)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if resp.Content != "Hello, world!" {
+		t.Errorf("Content = %q, want %q", resp.Content, "Hello, world!")
+	}
+	if resp.FinishReason != "stop" {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, "stop")
+	}
+	if resp.Usage.TotalTokens != 7 {
+		t.Errorf("Usage.TotalTokens = %d, want 7", resp.Usage.TotalTokens)
+	}
+	if progress.String() != "Hello, world!" {
+		t.Errorf("Progress forwarded %q, want %q", progress.String(), "Hello, world!")
+	}
+}
+
+func TestStreamAggregator_Aggregate_AccumulatesToolCallDeltas(t *testing.T) {
+	ch := make(chan provider.StreamChunk, 5)
+	ch <- provider.StreamChunk{ToolCallDelta: &provider.ToolCallDelta{Index: 0, ID: "call_1", Name: "get_weather"}}
+	ch <- provider.StreamChunk{ToolCallDelta: &provider.ToolCallDelta{Index: 0, Arguments: `{"city":`}}
+	ch <- provider.StreamChunk{ToolCallDelta: &provider.ToolCallDelta{Index: 0, Arguments: `"Dhaka"}`}}
+	ch <- provider.StreamChunk{Done: true, FinishReason: "tool_calls"}
+	close(ch)
+
+	agg := &StreamAggregator{}
+	resp, err := agg.Aggregate(ch)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("ToolCalls = %d, want 1", len(resp.ToolCalls))
+	}
+	got := resp.ToolCalls[0]
+	if got.ID != "call_1" || got.Name != "get_weather" || got.Arguments != `{"city":"Dhaka"}` {
+		t.Errorf("ToolCalls[0] = %+v, want {call_1 get_weather {\"city\":\"Dhaka\"}}", got)
+	}
+}
+
+func TestStreamAggregator_Aggregate_PropagatesRateLimit(t *testing.T) {
+	ch := make(chan provider.StreamChunk, 2)
+	ch <- provider.StreamChunk{Content: "hi"}
+	ch <- provider.StreamChunk{Done: true, FinishReason: "stop", RateLimit: &provider.RateLimitInfo{RemainingRequests: 42, RemainingTokens: 1000}}
+	close(ch)
+
+	agg := &StreamAggregator{}
+	resp, err := agg.Aggregate(ch)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if resp.RateLimit == nil || resp.RateLimit.RemainingRequests != 42 || resp.RateLimit.RemainingTokens != 1000 {
+		t.Errorf("RateLimit = %+v, want {RemainingRequests:42 RemainingTokens:1000}", resp.RateLimit)
+	}
+}
+
+func TestStreamAggregator_Aggregate_PropagatesChunkError(t *testing.T) {
+	wantErr := errors.New("boom")
+	ch := make(chan provider.StreamChunk, 1)
+	ch <- provider.StreamChunk{Error: wantErr}
+	close(ch)
+
+	agg := &StreamAggregator{}
+	if _, err := agg.Aggregate(ch); !errors.Is(err, wantErr) {
+		t.Fatalf("Aggregate() error = %v, want %v", err, wantErr)
+	}
+}
@@ -2,43 +2,103 @@ package generator
 
 import "context"
 
-// WorkerPool manages concurrent workers using a semaphore pattern
+// WorkKind distinguishes the two call shapes AcquireTyped balances.
+// Streaming calls hold their HTTP socket open for the duration of the
+// whole response instead of returning immediately, so mixing them with
+// batch calls under a single semaphore lets a handful of slow streams
+// starve the pool of batch throughput.
+type WorkKind int
+
+const (
+	// WorkBatch is a plain, non-streaming Generate call.
+	WorkBatch WorkKind = iota
+	// WorkStream is a GenerateStream call, held until its Done chunk arrives.
+	WorkStream
+)
+
+// WorkerPool manages concurrent workers using a semaphore pattern.
+// Batch and streaming calls draw from separate sub-limits so a burst of
+// long-lived streaming calls can't starve batch throughput; see
+// AcquireTyped.
 type WorkerPool struct {
-	sem chan struct{}
+	sem       chan struct{}
+	streamSem chan struct{}
 }
 
-// NewWorkerPool creates a new worker pool with the given concurrency limit
+// NewWorkerPool creates a new worker pool with the given concurrency
+// limit, shared identically between batch and streaming calls.
 func NewWorkerPool(size int) *WorkerPool {
+	return NewWorkerPoolWithStreamLimit(size, size)
+}
+
+// NewWorkerPoolWithStreamLimit creates a pool whose batch calls may use
+// up to size concurrent slots and whose streaming calls are held to the
+// separate streamLimit sub-limit.
+func NewWorkerPoolWithStreamLimit(size, streamLimit int) *WorkerPool {
 	if size <= 0 {
 		size = 1
 	}
+	if streamLimit <= 0 {
+		streamLimit = 1
+	}
 	return &WorkerPool{
-		sem: make(chan struct{}, size),
+		sem:       make(chan struct{}, size),
+		streamSem: make(chan struct{}, streamLimit),
 	}
 }
 
-// Acquire acquires a worker slot (blocks if pool is full).
-// Returns when the context is canceled while waiting.
+// Acquire acquires a batch worker slot (blocks if the pool is full).
+// Returns when the context is canceled while waiting. Equivalent to
+// AcquireTyped(ctx, WorkBatch).
 func (p *WorkerPool) Acquire(ctx context.Context) error {
+	return p.AcquireTyped(ctx, WorkBatch)
+}
+
+// AcquireTyped acquires a worker slot from the sub-limit matching kind.
+func (p *WorkerPool) AcquireTyped(ctx context.Context, kind WorkKind) error {
+	sem := p.sem
+	if kind == WorkStream {
+		sem = p.streamSem
+	}
 	select {
-	case p.sem <- struct{}{}:
+	case sem <- struct{}{}:
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 }
 
-// Release releases a worker slot
+// Release releases a batch worker slot. Equivalent to
+// ReleaseTyped(WorkBatch).
 func (p *WorkerPool) Release() {
-	<-p.sem
+	p.ReleaseTyped(WorkBatch)
 }
 
-// Size returns the pool size
+// ReleaseTyped releases a worker slot back to the sub-limit matching kind.
+func (p *WorkerPool) ReleaseTyped(kind WorkKind) {
+	sem := p.sem
+	if kind == WorkStream {
+		sem = p.streamSem
+	}
+	<-sem
+}
+
+// Size returns the batch pool size
 func (p *WorkerPool) Size() int {
 	return cap(p.sem)
 }
 
-// Active returns the number of active workers
+// Active returns the number of active batch workers
 func (p *WorkerPool) Active() int {
 	return len(p.sem)
 }
+
+// StreamSize returns the streaming sub-limit
+func (p *WorkerPool) StreamSize() int {
+	return cap(p.streamSem)
+}
+
+// ActiveStreams returns the number of active streaming workers
+func (p *WorkerPool) ActiveStreams() int {
+	return len(p.streamSem)
+}
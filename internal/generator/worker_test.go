@@ -83,3 +83,25 @@ func TestWorkerPool_ContextCancellation(t *testing.T) {
 		t.Fatal("Acquire did not return after context cancellation")
 	}
 }
+
+func TestWorkerPool_AcquireTyped_SeparateSubLimits(t *testing.T) {
+	pool := NewWorkerPoolWithStreamLimit(2, 1)
+
+	// Fill the streaming sub-limit; a batch acquire must still succeed
+	// immediately since the two sub-limits are independent.
+	if err := pool.AcquireTyped(context.Background(), WorkStream); err != nil {
+		t.Fatalf("unexpected stream acquire error: %v", err)
+	}
+	defer pool.ReleaseTyped(WorkStream)
+
+	if err := pool.AcquireTyped(context.Background(), WorkBatch); err != nil {
+		t.Fatalf("unexpected batch acquire error: %v", err)
+	}
+	defer pool.ReleaseTyped(WorkBatch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.AcquireTyped(ctx, WorkStream); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a second stream acquire to block until timeout, got %v", err)
+	}
+}
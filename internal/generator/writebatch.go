@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"encoding/json"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// defaultBatchMaxBytes is the serialized-byte budget a write flush can't
+// exceed when Config.BatchMaxBytes is unset.
+const defaultBatchMaxBytes = 4 * 1024 * 1024 // 4 MiB
+
+// effectiveBatchSize returns the count bound a write flush can't exceed: 1
+// when Config.BatchSize is unset, matching the generator's historical
+// one-write-per-sample behavior.
+func (g *Generator) effectiveBatchSize() int {
+	if g.config.BatchSize <= 0 {
+		return 1
+	}
+	return g.config.BatchSize
+}
+
+// effectiveBatchMaxBytes returns the serialized-byte bound a write flush
+// can't exceed: defaultBatchMaxBytes when Config.BatchMaxBytes is unset.
+func (g *Generator) effectiveBatchMaxBytes() int64 {
+	if g.config.BatchMaxBytes <= 0 {
+		return defaultBatchMaxBytes
+	}
+	return g.config.BatchMaxBytes
+}
+
+// sampleJSONSize returns the serialized size JSONLWriter.Write would give
+// sample on disk, used to weigh it against a write batch's byte budget.
+func sampleJSONSize(sample *schema.Sample) int64 {
+	data, err := json.Marshal(sample.Fields)
+	if err != nil {
+		// A sample that fails to marshal here will fail identically at
+		// actual write time; report it as weightless so it doesn't stall
+		// batching, and let Write surface the real error.
+		return 0
+	}
+	return int64(len(data)) + 1 // +1 for the trailing newline Write adds
+}
+
+// splitWriteBatch walks pending in order, accumulating each item's
+// cachedJSONSize, and returns the longest prefix whose count is <= maxCount
+// and whose total size is <= maxBytes, along with whatever's left over to
+// carry into the next flush and the prefix's total size (batchBytes), so a
+// caller already tracking pending's running byte total can subtract it back
+// out instead of re-summing rest. A single item whose own size already
+// exceeds maxBytes is still returned alone as the batch (rather than held
+// back forever) so one oversized sample can't stall the batcher.
+func splitWriteBatch(pending []*workerResult, maxCount int, maxBytes int64) (batch, rest []*workerResult, batchBytes int64) {
+	if len(pending) == 0 {
+		return nil, nil, 0
+	}
+
+	var size int64
+	cut := len(pending)
+	for i, result := range pending {
+		itemSize := result.cachedJSONSize()
+		if i > 0 && (i >= maxCount || size+itemSize > maxBytes) {
+			cut = i
+			break
+		}
+		size += itemSize
+	}
+
+	return pending[:cut], pending[cut:], size
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+func sampleResult(fieldValue string) *workerResult {
+	return &workerResult{sample: &schema.Sample{Fields: map[string]any{"value": fieldValue}}}
+}
+
+func TestSplitWriteBatch_CutByCount(t *testing.T) {
+	pending := []*workerResult{sampleResult("a"), sampleResult("b"), sampleResult("c")}
+
+	batch, rest, batchBytes := splitWriteBatch(pending, 2, defaultBatchMaxBytes)
+
+	if len(batch) != 2 {
+		t.Fatalf("expected batch of 2, got %d", len(batch))
+	}
+	if len(rest) != 1 {
+		t.Fatalf("expected 1 item left over, got %d", len(rest))
+	}
+	if wantBytes := sampleJSONSize(pending[0].sample) + sampleJSONSize(pending[1].sample); batchBytes != wantBytes {
+		t.Fatalf("expected batchBytes %d, got %d", wantBytes, batchBytes)
+	}
+}
+
+func TestSplitWriteBatch_CutByByteBudget(t *testing.T) {
+	// Each sample serializes to the same size; pick a byte budget that fits
+	// two but not three, well under the count bound, so the byte cap (not
+	// the count cap) is what ends the batch.
+	pending := []*workerResult{sampleResult("xxxx"), sampleResult("xxxx"), sampleResult("xxxx")}
+	oneSize := sampleJSONSize(pending[0].sample)
+
+	batch, rest, batchBytes := splitWriteBatch(pending, 10, oneSize*2)
+
+	if len(batch) != 2 {
+		t.Fatalf("expected byte cap to cut batch at 2 items, got %d", len(batch))
+	}
+	if len(rest) != 1 {
+		t.Fatalf("expected 1 item held back for the next flush, got %d", len(rest))
+	}
+	if batchBytes != oneSize*2 {
+		t.Fatalf("expected batchBytes %d, got %d", oneSize*2, batchBytes)
+	}
+}
+
+func TestSplitWriteBatch_OversizedItemAlone(t *testing.T) {
+	pending := []*workerResult{sampleResult("this-is-a-much-longer-value-than-the-budget-allows")}
+
+	batch, rest, _ := splitWriteBatch(pending, 10, 1)
+
+	if len(batch) != 1 {
+		t.Fatalf("expected the oversized item to still go out alone, got batch of %d", len(batch))
+	}
+	if len(rest) != 0 {
+		t.Fatalf("expected nothing held back, got %d", len(rest))
+	}
+}
+
+func TestSplitWriteBatch_Empty(t *testing.T) {
+	batch, rest, batchBytes := splitWriteBatch(nil, 10, defaultBatchMaxBytes)
+	if batch != nil || rest != nil {
+		t.Fatalf("expected nil, nil for empty input, got %v, %v", batch, rest)
+	}
+	if batchBytes != 0 {
+		t.Fatalf("expected batchBytes 0, got %d", batchBytes)
+	}
+}
+
+func TestEffectiveBatchSize_DefaultsToOne(t *testing.T) {
+	g := &Generator{config: Config{}}
+	if got := g.effectiveBatchSize(); got != 1 {
+		t.Fatalf("expected default batch size 1, got %d", got)
+	}
+
+	g.config.BatchSize = 5
+	if got := g.effectiveBatchSize(); got != 5 {
+		t.Fatalf("expected configured batch size 5, got %d", got)
+	}
+}
+
+func TestEffectiveBatchMaxBytes_DefaultsToConstant(t *testing.T) {
+	g := &Generator{config: Config{}}
+	if got := g.effectiveBatchMaxBytes(); got != defaultBatchMaxBytes {
+		t.Fatalf("expected default %d, got %d", defaultBatchMaxBytes, got)
+	}
+
+	g.config.BatchMaxBytes = 1024
+	if got := g.effectiveBatchMaxBytes(); got != 1024 {
+		t.Fatalf("expected configured 1024, got %d", got)
+	}
+}
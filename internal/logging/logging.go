@@ -0,0 +1,162 @@
+// Package logging provides structured, run-correlated logging for
+// generate and provider events (request start, retry, rate-limit wait,
+// checkpoint flush, signal shutdown), routed through a pluggable
+// log/slog handler so the destination and rendering are independent of
+// the call sites that emit events. It deliberately stays on slog rather
+// than pulling in a third-party logger: stdlib already covers every
+// sink this package needs (file, syslog, stdout) and every format
+// (text, json).
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"strings"
+)
+
+// Format selects how log records are rendered.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// Config selects where log records go and how they're rendered. The
+// zero value logs text-formatted records to stdout.
+type Config struct {
+	// Format is FormatText or FormatJSON. Defaults to FormatText.
+	Format Format
+
+	// File, if set, appends records to this path in addition to stdout.
+	File string
+
+	// Syslog, if set, also forwards records to a syslog daemon: "local"
+	// dials the local syslog socket, "tcp://host:port" or
+	// "udp://host:port" dials a remote one.
+	Syslog string
+}
+
+// New builds a *slog.Logger that writes to every destination Config
+// names (stdout always, plus File and/or Syslog when set), tagged with
+// run_id so records can be correlated with the checkpoint file a run
+// produced. The returned close func flushes and closes any files or
+// network connections opened for File/Syslog; callers should defer it.
+func New(cfg Config, runID string) (*slog.Logger, func() error, error) {
+	opts := &slog.HandlerOptions{}
+	newHandler := func(w io.Writer) slog.Handler {
+		if cfg.Format == FormatJSON {
+			return slog.NewJSONHandler(w, opts)
+		}
+		return slog.NewTextHandler(w, opts)
+	}
+
+	handlers := []slog.Handler{newHandler(os.Stdout)}
+	var closers []func() error
+
+	if cfg.File != "" {
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("logging: failed to open log file: %w", err)
+		}
+		handlers = append(handlers, newHandler(f))
+		closers = append(closers, f.Close)
+	}
+
+	if cfg.Syslog != "" {
+		w, err := dialSyslog(cfg.Syslog)
+		if err != nil {
+			for _, c := range closers {
+				c()
+			}
+			return nil, nil, fmt.Errorf("logging: failed to dial syslog: %w", err)
+		}
+		handlers = append(handlers, newHandler(w))
+		closers = append(closers, w.Close)
+	}
+
+	var handler slog.Handler = handlers[0]
+	if len(handlers) > 1 {
+		handler = newMultiHandler(handlers...)
+	}
+
+	closeFn := func() error {
+		var firstErr error
+		for _, c := range closers {
+			if err := c(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	return slog.New(handler).With("run_id", runID), closeFn, nil
+}
+
+// dialSyslog opens a syslog connection per target: "local" for the local
+// syslog socket, or a "tcp://host:port"/"udp://host:port" URL for a
+// remote syslog daemon.
+func dialSyslog(target string) (*syslog.Writer, error) {
+	const priority = syslog.LOG_INFO | syslog.LOG_USER
+	if target == "local" {
+		return syslog.New(priority, "kothaset")
+	}
+	network, addr, ok := strings.Cut(target, "://")
+	if !ok {
+		return nil, fmt.Errorf(`invalid --log-syslog %q: want "local" or "tcp://host:port"`, target)
+	}
+	return syslog.Dial(network, addr, priority, "kothaset")
+}
+
+// multiHandler fans a single record out to several slog.Handlers, e.g. a
+// JSON file alongside stdout. A failing handler never blocks the others;
+// its error is reported to stderr rather than returned, mirroring
+// audit.MultiSink's best-effort fan-out.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+func newMultiHandler(handlers ...slog.Handler) *multiHandler {
+	return &multiHandler{handlers: handlers}
+}
+
+func (m *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	for _, h := range m.handlers {
+		if !h.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, record.Clone()); err != nil {
+			fmt.Fprintf(os.Stderr, "logging: handler failed to write record: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: out}
+}
+
+func (m *multiHandler) WithGroup(name string) slog.Handler {
+	out := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		out[i] = h.WithGroup(name)
+	}
+	return &multiHandler{handlers: out}
+}
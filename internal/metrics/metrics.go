@@ -0,0 +1,108 @@
+// Package metrics exposes Prometheus instrumentation for generation,
+// provider, and output activity so long-running jobs can be observed with
+// live dashboards and alerts.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry is the Prometheus registry KothaSet registers its collectors
+// against. A dedicated registry (rather than the global default) keeps
+// metrics scoped to a single process instance and testable in isolation.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// SamplesGenerated counts generated samples by schema, provider, and
+	// outcome (status is "success" or "error").
+	SamplesGenerated = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kothaset_samples_generated_total",
+		Help: "Total number of samples generated, labeled by schema, provider, and status.",
+	}, []string{"schema", "provider", "status"})
+
+	// ProviderRequestDuration tracks latency of provider calls, labeled by
+	// provider name and call type (e.g. "generate", "health_check").
+	ProviderRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kothaset_provider_request_duration_seconds",
+		Help:    "Duration of provider requests in seconds, labeled by provider and request type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider", "type"})
+
+	// ProviderErrors counts provider failures, labeled by provider name and
+	// error kind ("retryable" or "terminal").
+	ProviderErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kothaset_provider_errors_total",
+		Help: "Total number of provider errors, labeled by provider and error kind.",
+	}, []string{"provider", "kind"})
+
+	// GenerationInFlight tracks the number of generation requests currently
+	// in progress across all workers.
+	GenerationInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "kothaset_generation_in_flight",
+		Help: "Number of generation requests currently in flight.",
+	})
+
+	// SampleTokens summarizes token counts of validated samples, labeled by
+	// schema and field, to spot runaway verbosity or truncation.
+	SampleTokens = factory.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       "kothaset_sample_tokens",
+		Help:       "Token counts of validated sample fields, labeled by schema and field.",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, []string{"schema", "field"})
+
+	// ConfigReloads counts hot-reload attempts of kothaset.yaml/.secrets.yaml,
+	// labeled by outcome ("success" or "error").
+	ConfigReloads = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kothaset_config_reload_total",
+		Help: "Total number of config hot-reload attempts, labeled by status.",
+	}, []string{"status"})
+)
+
+// Timer starts a timer for a Prometheus Observer (e.g. a Histogram or
+// Summary) and returns a func to defer that records the elapsed duration.
+//
+//	stop := metrics.Timer(ProviderRequestDuration.WithLabelValues("openai", "generate"))
+//	defer stop()
+func Timer(observer prometheus.Observer) func() {
+	start := time.Now()
+	return func() {
+		observer.Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler returns the HTTP handler for the /metrics endpoint.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Serve starts an HTTP server exposing /metrics on addr. It blocks until
+// the context is canceled or the server fails, and is intended to be run
+// in its own goroutine from the root command when --metrics-addr is set.
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return srv.Close()
+	case err := <-errCh:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,172 @@
+package output
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression names a streaming codec a Writer can wrap its output in.
+// The zero value ("") means uncompressed.
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+	CompressionXZ   = "xz"
+)
+
+// CompressionWriter is implemented by writers that can stream their output
+// through a compression codec - currently JSONLWriter. It's checked with an
+// optional type assertion (see generator.Generator.Run), the same pattern
+// as RejectionWriter, since binary formats like Parquet already compress
+// internally (see ParquetWriter.SetCompression) and have no use for this.
+type CompressionWriter interface {
+	// SetCompression sets the codec future Open/OpenAppend calls wrap the
+	// output file in. Must be called before Open/OpenAppend. An empty
+	// codec leaves the writer's own extension-based detection in place.
+	SetCompression(codec string) error
+}
+
+// DetectCompression returns the codec implied by path's extension
+// (".gz" -> gzip, ".zst" -> zstd, ".xz" -> xz), or CompressionNone if path
+// doesn't end in a recognized compressed extension.
+func DetectCompression(path string) string {
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		return CompressionGzip
+	case strings.HasSuffix(path, ".zst"):
+		return CompressionZstd
+	case strings.HasSuffix(path, ".xz"):
+		return CompressionXZ
+	default:
+		return CompressionNone
+	}
+}
+
+// validCompression reports whether codec is a codec newCompressWriter/
+// newDecompressReader know how to handle.
+func validCompression(codec string) bool {
+	switch codec {
+	case CompressionNone, CompressionGzip, CompressionZstd, CompressionXZ:
+		return true
+	default:
+		return false
+	}
+}
+
+// flusher is implemented by gzip.Writer and zstd.Encoder, letting Sync
+// push a compressed frame's buffered bytes to the underlying file without
+// finalizing the stream the way Close would. xz.Writer has no equivalent -
+// an xz stream can only be flushed by closing it, so Sync on an xz output
+// only flushes the bufio layer in front of it (see JSONLWriter.Sync).
+type flusher interface {
+	Flush() error
+}
+
+// newCompressWriter wraps w in codec's streaming compressor. Closing the
+// returned writer finalizes the compressed frame/stream; it does not close
+// w itself.
+func newCompressWriter(codec string, w io.Writer) (io.WriteCloser, error) {
+	switch codec {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	case CompressionXZ:
+		return xz.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported compression codec: %s", codec)
+	}
+}
+
+// newDecompressReader wraps r in codec's streaming decompressor, for
+// reading back a file newCompressWriter produced (e.g. to validate a
+// resumed output file's record count; see CountJSONLRecords).
+func newDecompressReader(codec string, r io.Reader) (io.ReadCloser, error) {
+	switch codec {
+	case CompressionNone:
+		return io.NopCloser(r), nil
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zstdReadCloser{dec}, nil
+	case CompressionXZ:
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression codec: %s", codec)
+	}
+}
+
+// nopWriteCloser adapts an io.Writer (e.g. the *os.File behind an
+// uncompressed JSONLWriter) to io.WriteCloser without closing it, mirroring
+// io.NopCloser's read-side equivalent.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// zstdReadCloser adapts *zstd.Decoder's void Close to the io.ReadCloser
+// signature the other codecs return.
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (z zstdReadCloser) Close() error {
+	z.Decoder.Close()
+	return nil
+}
+
+// CountJSONLRecords streams path through codec's decompressor (gzip, zstd,
+// and xz all transparently read every concatenated frame/stream OpenAppend
+// wrote; see JSONLWriter.OpenAppend) and counts non-empty lines, without
+// loading the file into memory. Used on resume to confirm a compressed
+// output file actually holds as many records as Checkpoint.Completed
+// claims, since compressed formats can't be seeked into the way a plain
+// JSONL file's byte offset can.
+func CountJSONLRecords(path, codec string) (int, error) {
+	if !validCompression(codec) {
+		return 0, fmt.Errorf("unsupported compression codec: %s", codec)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r, err := newDecompressReader(codec, f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s decompressor: %w", codec, err)
+	}
+	defer r.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) > 0 {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+func TestDetectCompression(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"dataset.jsonl", CompressionNone},
+		{"dataset.jsonl.gz", CompressionGzip},
+		{"dataset.jsonl.zst", CompressionZstd},
+		{"dataset.jsonl.xz", CompressionXZ},
+		{"dataset.parquet", CompressionNone},
+	}
+
+	for _, tt := range tests {
+		if got := DetectCompression(tt.path); got != tt.want {
+			t.Errorf("DetectCompression(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestJSONLWriter_Compression_RoundTrip(t *testing.T) {
+	for _, codec := range []string{CompressionGzip, CompressionZstd, CompressionXZ} {
+		t.Run(codec, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			outPath := filepath.Join(tmpDir, "dataset.jsonl")
+
+			w := NewJSONLWriter(schema.NewInstructionSchema())
+			if err := w.SetCompression(codec); err != nil {
+				t.Fatalf("SetCompression failed: %v", err)
+			}
+			if err := w.Open(outPath); err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				if err := w.Write(&schema.Sample{Fields: map[string]any{"instruction": "Q", "output": "A"}}); err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			count, err := CountJSONLRecords(outPath, codec)
+			if err != nil {
+				t.Fatalf("CountJSONLRecords failed: %v", err)
+			}
+			if count != 3 {
+				t.Errorf("CountJSONLRecords = %d, want 3", count)
+			}
+		})
+	}
+}
+
+func TestJSONLWriter_Compression_AppendAcrossFrames(t *testing.T) {
+	// gzip, zstd, and xz all decode a concatenation of independently-
+	// compressed frames/streams as a single logical stream, so OpenAppend
+	// (which starts a new frame/stream rather than seeking into the
+	// existing one) works with CountJSONLRecords for all three codecs.
+	for _, codec := range []string{CompressionGzip, CompressionZstd, CompressionXZ} {
+		t.Run(codec, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			outPath := filepath.Join(tmpDir, "dataset.jsonl")
+
+			w := NewJSONLWriter(schema.NewInstructionSchema())
+			if err := w.SetCompression(codec); err != nil {
+				t.Fatalf("SetCompression failed: %v", err)
+			}
+			if err := w.Open(outPath); err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			for i := 0; i < 2; i++ {
+				if err := w.Write(&schema.Sample{Fields: map[string]any{"instruction": "Q", "output": "A"}}); err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			w2 := NewJSONLWriter(schema.NewInstructionSchema())
+			if err := w2.SetCompression(codec); err != nil {
+				t.Fatalf("SetCompression failed: %v", err)
+			}
+			if err := w2.OpenAppend(outPath); err != nil {
+				t.Fatalf("OpenAppend failed: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				if err := w2.Write(&schema.Sample{Fields: map[string]any{"instruction": "Q2", "output": "A2"}}); err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+			}
+			if err := w2.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			count, err := CountJSONLRecords(outPath, codec)
+			if err != nil {
+				t.Fatalf("CountJSONLRecords failed: %v", err)
+			}
+			if count != 5 {
+				t.Errorf("CountJSONLRecords = %d, want 5", count)
+			}
+		})
+	}
+}
+
+func TestJSONLWriter_SetCompression_InvalidCodec(t *testing.T) {
+	w := NewJSONLWriter(schema.NewInstructionSchema())
+	if err := w.SetCompression("brotli"); err == nil {
+		t.Fatal("expected an error for an unsupported codec")
+	}
+}
+
+func TestJSONLWriter_Open_DetectsCompressionFromExtension(t *testing.T) {
+	tmpDir := t.TempDir()
+	outPath := filepath.Join(tmpDir, "dataset.jsonl.gz")
+
+	w := NewJSONLWriter(schema.NewInstructionSchema())
+	if err := w.Open(outPath); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := w.Write(&schema.Sample{Fields: map[string]any{"instruction": "Q", "output": "A"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	count, err := CountJSONLRecords(outPath, CompressionGzip)
+	if err != nil {
+		t.Fatalf("CountJSONLRecords failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("CountJSONLRecords = %d, want 1", count)
+	}
+}
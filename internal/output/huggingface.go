@@ -0,0 +1,124 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// HuggingFaceWriter writes a directory dataset under train/ as one or more
+// sharded Parquet part files, via ParquetWriter, plus a dataset_info.json
+// summarizing the schema and row count. The directory is loadable in
+// Python with `datasets.load_dataset("parquet", data_dir=<dir>/"train")`.
+// It is not loadable with `datasets.load_from_disk`: that API expects
+// Arrow-backed shards written by `Dataset.save_to_disk`, a different
+// on-disk format than sharded Parquet, regardless of which library wrote
+// the Parquet files.
+type HuggingFaceWriter struct {
+	schema schema.Schema
+	dir    string
+	shards *ParquetWriter
+
+	mu    sync.Mutex
+	count int64
+}
+
+// NewHuggingFaceWriter creates a new HuggingFace dataset directory writer.
+func NewHuggingFaceWriter(sch schema.Schema) *HuggingFaceWriter {
+	return &HuggingFaceWriter{schema: sch}
+}
+
+func (w *HuggingFaceWriter) Format() string { return "huggingface" }
+
+// Open creates a fresh dataset directory at path.
+func (w *HuggingFaceWriter) Open(path string) error {
+	return w.open(path, false)
+}
+
+// OpenAppend resumes a dataset directory, appending a new shard after the
+// last committed one.
+func (w *HuggingFaceWriter) OpenAppend(path string) error {
+	return w.open(path, true)
+}
+
+func (w *HuggingFaceWriter) open(path string, appendMode bool) error {
+	w.dir = path
+	trainDir := filepath.Join(w.dir, "train")
+
+	w.shards = NewParquetWriter(w.schema)
+	var err error
+	if appendMode {
+		if err = w.shards.OpenAppend(trainDir); err == nil {
+			w.count = w.shards.state.RowsCommitted
+		}
+	} else {
+		err = w.shards.Open(trainDir)
+	}
+	if err != nil {
+		return err
+	}
+	return w.writeDatasetInfo()
+}
+
+// writeDatasetInfo (re)writes dataset_info.json, a summary of the
+// dataset's feature schema and row count for a human (or another kothaset
+// command) to read without opening a Parquet reader. It isn't consulted by
+// `datasets.load_dataset("parquet", ...)`, which infers schema and row
+// counts from the Parquet files themselves.
+func (w *HuggingFaceWriter) writeDatasetInfo() error {
+	info := map[string]any{
+		"dataset_name": "kothaset",
+		"splits": map[string]any{
+			"train": map[string]any{"name": "train", "num_examples": w.count},
+		},
+	}
+	if w.schema != nil {
+		info["features"] = w.schema.Fields()
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(w.dir, "dataset_info.json"), data, 0644)
+}
+
+func (w *HuggingFaceWriter) Write(sample *schema.Sample) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.shards.Write(sample); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+func (w *HuggingFaceWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.shards.Flush(); err != nil {
+		return err
+	}
+	return w.writeDatasetInfo()
+}
+
+func (w *HuggingFaceWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.shards.Sync(); err != nil {
+		return err
+	}
+	return w.writeDatasetInfo()
+}
+
+func (w *HuggingFaceWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.shards.Close(); err != nil {
+		return err
+	}
+	return w.writeDatasetInfo()
+}
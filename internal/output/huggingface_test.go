@@ -30,6 +30,9 @@ func TestHuggingFaceWriter(t *testing.T) {
 	if _, err := os.Stat(filepath.Join(outPath, "dataset_info.json")); err != nil {
 		t.Error("dataset_info.json missing")
 	}
+	if _, err := os.Stat(filepath.Join(outPath, "state.json")); err != nil {
+		t.Error("state.json missing")
+	}
 	if _, err := os.Stat(filepath.Join(outPath, "train", "data-00000-of-00001.jsonl")); err != nil {
 		t.Error("train data missing")
 	}
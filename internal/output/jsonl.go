@@ -3,6 +3,8 @@ package output
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"sync"
 
@@ -11,10 +13,19 @@ import (
 
 // JSONLWriter writes samples as JSON Lines format
 type JSONLWriter struct {
-	schema schema.Schema
-	file   *os.File
-	writer *bufio.Writer
-	mu     sync.Mutex
+	schema      schema.Schema
+	path        string
+	compression string
+	file        *os.File
+	compWriter  io.WriteCloser
+	writer      *bufio.Writer
+	mu          sync.Mutex
+
+	// rejectionFile/rejectionWriter back the optional sidecar opened by
+	// WriteRejection; nil until the first rejected sample is written, so
+	// a run with no validation failures never creates the file.
+	rejectionFile   *os.File
+	rejectionWriter *bufio.Writer
 }
 
 // NewJSONLWriter creates a new JSONL writer
@@ -26,13 +37,49 @@ func NewJSONLWriter(sch schema.Schema) *JSONLWriter {
 
 func (w *JSONLWriter) Format() string { return "jsonl" }
 
+// SetCompression implements CompressionWriter. codec must be "" or one of
+// CompressionGzip/CompressionZstd/CompressionXZ; an unrecognized value is
+// rejected up front rather than surfacing as a confusing error from Open.
+func (w *JSONLWriter) SetCompression(codec string) error {
+	if !validCompression(codec) {
+		return fmt.Errorf("jsonl writer: unsupported compression codec: %s", codec)
+	}
+	w.compression = codec
+	return nil
+}
+
+// effectiveCompression returns the codec Open/OpenAppend should use: the
+// codec SetCompression forced, or one implied by path's extension.
+func (w *JSONLWriter) effectiveCompression(path string) string {
+	if w.compression != "" {
+		return w.compression
+	}
+	return DetectCompression(path)
+}
+
 func (w *JSONLWriter) Open(path string) error {
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
+	return w.init(path, file, w.effectiveCompression(path))
+}
+
+// init wires file (already opened in truncate or append mode by Open/
+// OpenAppend) through codec's compressor, if any, and sets up the buffered
+// writer every Write call goes through.
+func (w *JSONLWriter) init(path string, file *os.File, codec string) error {
+	compWriter, err := newCompressWriter(codec, file)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("jsonl writer: %w", err)
+	}
+
+	w.path = path
+	w.compression = codec
 	w.file = file
-	w.writer = bufio.NewWriterSize(file, 64*1024) // 64KB buffer
+	w.compWriter = compWriter
+	w.writer = bufio.NewWriterSize(compWriter, 64*1024) // 64KB buffer
 	return nil
 }
 
@@ -40,19 +87,62 @@ func (w *JSONLWriter) Write(sample *schema.Sample) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if err := w.writeLine(sample); err != nil {
+		return err
+	}
+	// Flush to the OS immediately so data survives application crashes.
+	// For a compressed output (w.compWriter is a real codec, not
+	// nopWriteCloser) this only pushes bytes into the codec's own internal
+	// buffer, not onto disk - see Sync, which additionally flushes the
+	// codec layer where the codec supports it.
+	return w.writer.Flush()
+}
+
+// writeLine marshals sample and appends it to the buffered writer as one
+// JSONL record, without flushing - shared by Write and WriteBatch so the two
+// can't drift on the marshal/write/newline sequence. Caller must hold w.mu.
+func (w *JSONLWriter) writeLine(sample *schema.Sample) error {
 	data, err := json.Marshal(sample.Fields)
 	if err != nil {
 		return err
 	}
-
 	if _, err := w.writer.Write(data); err != nil {
 		return err
 	}
-	if _, err = w.writer.WriteString("\n"); err != nil {
-		return err
+	_, err = w.writer.WriteString("\n")
+	return err
+}
+
+// WriteBatch implements BatchWriter: it writes samples in order through the
+// same buffered writer Write uses, but flushes once at the end instead of
+// after every sample, amortizing the flush/syscall cost across the whole
+// group. It stops at the first sample that fails to marshal or write, but
+// still flushes whatever came before it so that prefix is actually durable
+// rather than left sitting in the buffer. If the flush itself fails,
+// bufio.Writer drops its buffered bytes rather than preserving them for
+// retry, so none of the batch - not even samples before a mid-batch failure
+// - can be assumed written, and WriteBatch reports 0 regardless of how far
+// the loop got.
+func (w *JSONLWriter) WriteBatch(samples []*schema.Sample) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(samples)
+	var writeErr error
+	for i, sample := range samples {
+		if err := w.writeLine(sample); err != nil {
+			n, writeErr = i, err
+			break
+		}
 	}
-	// Flush to OS immediately so data survives application crashes
-	return w.writer.Flush()
+
+	if err := w.writer.Flush(); err != nil {
+		if writeErr != nil {
+			return 0, fmt.Errorf("%w (after sample %d failed: %v)", err, n, writeErr)
+		}
+		return 0, err
+	}
+	return n, writeErr
 }
 
 func (w *JSONLWriter) Flush() error {
@@ -64,8 +154,11 @@ func (w *JSONLWriter) Flush() error {
 	return nil
 }
 
-// Sync flushes buffered data and fsyncs to physical storage.
-// Use at checkpoint boundaries for crash-safe durability.
+// Sync flushes buffered data - including the compression codec's own
+// internal buffer, for gzip/zstd (see flusher; xz has no equivalent, so a
+// compressed xz output is only durable once Close finalizes the stream) -
+// and fsyncs to physical storage. Use at checkpoint boundaries for
+// crash-safe durability.
 func (w *JSONLWriter) Sync() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -74,6 +167,11 @@ func (w *JSONLWriter) Sync() error {
 			return err
 		}
 	}
+	if f, ok := w.compWriter.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
 	if w.file != nil {
 		return w.file.Sync()
 	}
@@ -84,19 +182,77 @@ func (w *JSONLWriter) Close() error {
 	if err := w.Flush(); err != nil {
 		return err
 	}
+	if w.compWriter != nil {
+		// Finalizes the compressed frame/stream (a no-op for uncompressed
+		// output, see nopWriteCloser).
+		if err := w.compWriter.Close(); err != nil {
+			return fmt.Errorf("jsonl writer: failed to close compression stream: %w", err)
+		}
+	}
+	if w.rejectionWriter != nil {
+		if err := w.rejectionWriter.Flush(); err != nil {
+			return err
+		}
+	}
+	if w.rejectionFile != nil {
+		if err := w.rejectionFile.Close(); err != nil {
+			return err
+		}
+	}
 	if w.file != nil {
 		return w.file.Close()
 	}
 	return nil
 }
 
-// OpenAppend opens the file in append mode for resuming
+// OpenAppend opens the file in append mode for resuming. For a compressed
+// output, appending starts a brand new compressed frame/stream after
+// whatever the prior run already wrote rather than seeking into the
+// existing one: gzip, zstd, and xz all treat a file as the concatenation of
+// however many frames/streams it holds, decoding it as a single logical
+// stream (see CountJSONLRecords).
 func (w *JSONLWriter) OpenAppend(path string) error {
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return err
 	}
-	w.file = file
-	w.writer = bufio.NewWriterSize(file, 64*1024)
-	return nil
+	return w.init(path, file, w.effectiveCompression(path))
+}
+
+// WriteRejection implements output.RejectionWriter by appending sample
+// and report's errors to a "<path>.errors.jsonl" sidecar next to the
+// main output file, opened lazily on the first call so a run with no
+// validation failures never creates it.
+func (w *JSONLWriter) WriteRejection(sample *schema.Sample, report *schema.ValidationReport) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.rejectionWriter == nil {
+		if w.path == "" {
+			return fmt.Errorf("jsonl writer: not open, cannot derive a rejection sidecar path")
+		}
+		file, err := os.OpenFile(w.path+".errors.jsonl", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("jsonl writer: failed to open rejection sidecar: %w", err)
+		}
+		w.rejectionFile = file
+		w.rejectionWriter = bufio.NewWriterSize(file, 4*1024)
+	}
+
+	entry := struct {
+		Sample *schema.Sample        `json:"sample"`
+		Errors []*schema.SchemaError `json:"errors"`
+	}{Sample: sample, Errors: report.Errors()}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := w.rejectionWriter.Write(data); err != nil {
+		return err
+	}
+	if _, err := w.rejectionWriter.WriteString("\n"); err != nil {
+		return err
+	}
+	return w.rejectionWriter.Flush()
 }
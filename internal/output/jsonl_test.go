@@ -2,6 +2,7 @@ package output
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -53,6 +54,97 @@ func TestJSONLWriter(t *testing.T) {
 	}
 }
 
+func TestJSONLWriter_WriteBatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	outPath := filepath.Join(tmpDir, "batch.jsonl")
+
+	w := NewJSONLWriter(schema.NewInstructionSchema())
+	if err := w.Open(outPath); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	samples := []*schema.Sample{
+		{Fields: map[string]any{"instruction": "Q1", "output": "A1"}},
+		{Fields: map[string]any{"instruction": "Q2", "output": "A2"}},
+		{Fields: map[string]any{"instruction": "Q3", "output": "A3"}},
+	}
+	n, err := w.WriteBatch(samples)
+	if err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+	if n != len(samples) {
+		t.Fatalf("expected %d samples written, got %d", len(samples), n)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	content, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimRight(content, "\n"), []byte("\n"))
+	if len(lines) != len(samples) {
+		t.Fatalf("expected %d lines, got %d", len(samples), len(lines))
+	}
+	for i, line := range lines {
+		var data map[string]any
+		if err := json.Unmarshal(line, &data); err != nil {
+			t.Fatalf("line %d: Unmarshal failed: %v", i, err)
+		}
+		if data["instruction"] != samples[i].Fields["instruction"] {
+			t.Errorf("line %d: expected instruction %v, got %v", i, samples[i].Fields["instruction"], data["instruction"])
+		}
+	}
+}
+
+func TestJSONLWriter_WriteBatch_PartialFailureFlushesPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	outPath := filepath.Join(tmpDir, "partial.jsonl")
+
+	w := NewJSONLWriter(schema.NewInstructionSchema())
+	if err := w.Open(outPath); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	samples := []*schema.Sample{
+		{Fields: map[string]any{"instruction": "Q1", "output": "A1"}},
+		{Fields: map[string]any{"bad": make(chan int)}}, // unmarshalable: fails json.Marshal
+		{Fields: map[string]any{"instruction": "Q3", "output": "A3"}},
+	}
+	n, err := w.WriteBatch(samples)
+	if err == nil {
+		t.Fatal("expected WriteBatch to fail on the unmarshalable sample")
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 sample confirmed written before the failure, got %d", n)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// The one sample before the failure must already be on disk - WriteBatch
+	// is documented to flush that prefix even when it stops early.
+	content, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimRight(content, "\n"), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line flushed to disk, got %d: %q", len(lines), content)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(lines[0], &data); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if data["instruction"] != "Q1" {
+		t.Errorf("expected flushed line to be Q1, got %v", data["instruction"])
+	}
+}
+
 func TestJSONLWriter_OpenAppend(t *testing.T) {
 	tmpDir := t.TempDir()
 	outPath := filepath.Join(tmpDir, "append.jsonl")
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// kafkaBrokersEnv names the environment variable carrying a
+// comma-separated list of Kafka broker addresses, read at Writer
+// construction time since output_format has no room for broker config.
+const kafkaBrokersEnv = "KOTHASET_KAFKA_BROKERS"
+
+// kafkaBrokersFromEnv parses kafkaBrokersEnv into a broker address list.
+func kafkaBrokersFromEnv() []string {
+	raw := os.Getenv(kafkaBrokersEnv)
+	if raw == "" {
+		return nil
+	}
+	var brokers []string
+	for _, b := range strings.Split(raw, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	return brokers
+}
+
+// KafkaWriter publishes each sample as a JSON message to a Kafka topic
+// named after the schema, so downstream consumers can subscribe per
+// dataset style (instruction, chat, preference, ...) instead of tailing a
+// file. path is accepted for interface compatibility with Writer but
+// otherwise unused, since Kafka has no filesystem destination.
+type KafkaWriter struct {
+	schema  schema.Schema
+	brokers []string
+	topic   string
+	w       *kafka.Writer
+}
+
+// NewKafkaWriter creates a writer that publishes to the given brokers.
+func NewKafkaWriter(sch schema.Schema, brokers []string) *KafkaWriter {
+	return &KafkaWriter{schema: sch, brokers: brokers}
+}
+
+func (w *KafkaWriter) Format() string { return "kafka" }
+
+func (w *KafkaWriter) Open(path string) error       { return w.open() }
+func (w *KafkaWriter) OpenAppend(path string) error { return w.open() }
+
+func (w *KafkaWriter) open() error {
+	if len(w.brokers) == 0 {
+		return fmt.Errorf("kafka writer: no brokers configured (set %s)", kafkaBrokersEnv)
+	}
+
+	w.topic = w.schema.Name()
+	w.w = &kafka.Writer{
+		Addr:         kafka.TCP(w.brokers...),
+		Topic:        w.topic,
+		Balancer:     &kafka.LeastBytes{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	return nil
+}
+
+func (w *KafkaWriter) Write(sample *schema.Sample) error {
+	data, err := json.Marshal(sample.Fields)
+	if err != nil {
+		return fmt.Errorf("kafka writer: failed to encode sample: %w", err)
+	}
+	if err := w.w.WriteMessages(context.Background(), kafka.Message{Value: data}); err != nil {
+		return fmt.Errorf("kafka writer: failed to publish to topic %s: %w", w.topic, err)
+	}
+	return nil
+}
+
+// Flush is a no-op: WriteMessages already blocks until the broker accepts
+// each message.
+func (w *KafkaWriter) Flush() error { return nil }
+
+// Sync is a no-op beyond what Write already guarantees: with
+// RequiredAcks set to RequireAll, WriteMessages doesn't return until every
+// in-sync replica has acknowledged the message.
+func (w *KafkaWriter) Sync() error { return nil }
+
+func (w *KafkaWriter) Close() error {
+	if w.w == nil {
+		return nil
+	}
+	return w.w.Close()
+}
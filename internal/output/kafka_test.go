@@ -0,0 +1,29 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+func TestKafkaBrokersFromEnv(t *testing.T) {
+	t.Setenv(kafkaBrokersEnv, " broker1:9092, broker2:9092 ,")
+
+	got := kafkaBrokersFromEnv()
+	want := []string{"broker1:9092", "broker2:9092"}
+	if len(got) != len(want) {
+		t.Fatalf("kafkaBrokersFromEnv() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("kafkaBrokersFromEnv()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestKafkaWriter_OpenRequiresBrokers(t *testing.T) {
+	w := NewKafkaWriter(schema.NewInstructionSchema(), nil)
+	if err := w.Open("unused"); err == nil {
+		t.Fatal("expected an error when no brokers are configured")
+	}
+}
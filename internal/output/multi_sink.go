@@ -0,0 +1,119 @@
+package output
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// SinkErrorPolicy controls how MultiSink reacts when one of its sinks
+// returns an error.
+type SinkErrorPolicy int
+
+const (
+	// FailFast aborts the whole operation on the first sink error,
+	// matching MultiWriter's behavior.
+	FailFast SinkErrorPolicy = iota
+
+	// BestEffort records the error but continues writing to the
+	// remaining sinks, so a struggling sink (e.g. a flaky Kafka broker)
+	// doesn't take down an otherwise-healthy local JSONL/Parquet sink.
+	BestEffort
+)
+
+// sinkEntry pairs a Sink with the error policy to apply to it.
+type sinkEntry struct {
+	sink   Sink
+	policy SinkErrorPolicy
+	name   string
+}
+
+// MultiSink fans a single generation run's samples out to several Sinks,
+// each with its own error policy. Unlike MultiWriter (which always treats
+// every backend as fail-fast and requires full Writer/OpenAppend support),
+// MultiSink lets best-effort backends — typically remote ones not
+// essential to the run's durability guarantees — fail without aborting
+// the sinks the run actually depends on.
+type MultiSink struct {
+	entries []sinkEntry
+}
+
+// NewMultiSink creates a MultiSink with no sinks attached yet; add them
+// with Add.
+func NewMultiSink() *MultiSink {
+	return &MultiSink{}
+}
+
+// Add attaches sink to the fan-out with the given error policy. name
+// identifies the sink in wrapped errors and BestEffort log lines.
+func (m *MultiSink) Add(name string, sink Sink, policy SinkErrorPolicy) *MultiSink {
+	m.entries = append(m.entries, sinkEntry{sink: sink, policy: policy, name: name})
+	return m
+}
+
+func (m *MultiSink) Format() string {
+	names := make([]string, len(m.entries))
+	for i, e := range m.entries {
+		names[i] = e.name
+	}
+	return strings.Join(names, ",")
+}
+
+// Open opens every sink, deriving each one's destination from path via
+// formatPath.
+func (m *MultiSink) Open(path string) error {
+	return m.forEach(func(e sinkEntry) error {
+		return e.sink.Open(formatPath(path, e.sink.Format()))
+	})
+}
+
+func (m *MultiSink) Write(sample *schema.Sample) error {
+	return m.forEach(func(e sinkEntry) error {
+		return e.sink.Write(sample)
+	})
+}
+
+func (m *MultiSink) Flush() error {
+	return m.forEach(func(e sinkEntry) error {
+		return e.sink.Flush()
+	})
+}
+
+func (m *MultiSink) Sync() error {
+	return m.forEach(func(e sinkEntry) error {
+		return e.sink.Sync()
+	})
+}
+
+// Close closes every sink regardless of error policy, so a best-effort
+// sink's prior failures don't leak its resources. The first fail-fast
+// error encountered is returned.
+func (m *MultiSink) Close() error {
+	var firstErr error
+	for _, e := range m.entries {
+		if err := e.sink.Close(); err != nil {
+			wrapped := fmt.Errorf("%s sink: %w", e.name, err)
+			if e.policy == FailFast && firstErr == nil {
+				firstErr = wrapped
+			}
+		}
+	}
+	return firstErr
+}
+
+// forEach runs op against every sink. A FailFast sink's error aborts and
+// is returned immediately; a BestEffort sink's error is swallowed so the
+// remaining sinks still run.
+func (m *MultiSink) forEach(op func(sinkEntry) error) error {
+	for _, e := range m.entries {
+		if err := op(e); err != nil {
+			if e.policy == FailFast {
+				return fmt.Errorf("%s sink: %w", e.name, err)
+			}
+			log.Printf("output: best-effort sink %q failed: %v", e.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// fakeSink is an in-memory Sink used to exercise MultiSink's error
+// policies without a real backend.
+type fakeSink struct {
+	format string
+	failOn string // operation name that should return errFakeSink
+	writes int
+	closed bool
+}
+
+var errFakeSink = errors.New("fake sink failure")
+
+func (f *fakeSink) Format() string { return f.format }
+
+func (f *fakeSink) Open(path string) error {
+	if f.failOn == "open" {
+		return errFakeSink
+	}
+	return nil
+}
+
+func (f *fakeSink) Write(sample *schema.Sample) error {
+	if f.failOn == "write" {
+		return errFakeSink
+	}
+	f.writes++
+	return nil
+}
+
+func (f *fakeSink) Flush() error { return nil }
+func (f *fakeSink) Sync() error  { return nil }
+
+func (f *fakeSink) Close() error {
+	f.closed = true
+	if f.failOn == "close" {
+		return errFakeSink
+	}
+	return nil
+}
+
+func TestMultiSink_BestEffortContinuesOnFailure(t *testing.T) {
+	healthy := &fakeSink{format: "jsonl"}
+	flaky := &fakeSink{format: "kafka", failOn: "write"}
+
+	m := NewMultiSink().
+		Add("local", healthy, FailFast).
+		Add("remote", flaky, BestEffort)
+
+	sample := &schema.Sample{Fields: map[string]any{"a": 1}}
+	if err := m.Write(sample); err != nil {
+		t.Fatalf("expected best-effort failure to be swallowed, got: %v", err)
+	}
+	if healthy.writes != 1 {
+		t.Errorf("expected the healthy sink to receive the write, got %d writes", healthy.writes)
+	}
+}
+
+func TestMultiSink_FailFastAbortsOnFailure(t *testing.T) {
+	broken := &fakeSink{format: "jsonl", failOn: "write"}
+	after := &fakeSink{format: "parquet"}
+
+	m := NewMultiSink().
+		Add("broken", broken, FailFast).
+		Add("after", after, FailFast)
+
+	sample := &schema.Sample{Fields: map[string]any{"a": 1}}
+	if err := m.Write(sample); !errors.Is(err, errFakeSink) {
+		t.Fatalf("expected errFakeSink to propagate, got: %v", err)
+	}
+	if after.writes != 0 {
+		t.Errorf("expected the fail-fast abort to skip the remaining sink, got %d writes", after.writes)
+	}
+}
+
+func TestMultiSink_CloseClosesEverySink(t *testing.T) {
+	a := &fakeSink{format: "jsonl", failOn: "close"}
+	b := &fakeSink{format: "parquet"}
+
+	m := NewMultiSink().Add("a", a, BestEffort).Add("b", b, FailFast)
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("expected a best-effort close failure to be swallowed, got: %v", err)
+	}
+	if !a.closed || !b.closed {
+		t.Error("expected Close to be called on every sink regardless of policy")
+	}
+}
@@ -5,177 +5,448 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
 	"github.com/shantoislamdev/kothaset/internal/schema"
-	"github.com/xitongsys/parquet-go-source/local"
-	"github.com/xitongsys/parquet-go/parquet"
-	"github.com/xitongsys/parquet-go/writer"
 )
 
-// ParquetWriter writes samples in Parquet columnar format
+// parquetStateSuffix names the sidecar file that tracks committed
+// row-group segments for a logical dataset across append sessions.
+const parquetStateSuffix = ".parquet.state"
+
+// parquetState is persisted next to a Parquet dataset so OpenAppend can
+// resume into a fresh segment instead of re-reading the previous one.
+type parquetState struct {
+	// Segments are the part files written so far, in order.
+	Segments []string `json:"segments"`
+	// RowsCommitted is the total number of rows flushed across all segments.
+	RowsCommitted int64 `json:"rows_committed"`
+}
+
+// ParquetWriter streams samples into row-group-sized Parquet segments as
+// they arrive instead of buffering the whole dataset in memory, using
+// parquet-go/parquet-go. A logical dataset is a directory of numbered part
+// files (part-0001.parquet, ...) described by a sidecar .parquet.state
+// file, which ParquetDirWriter reads back as a single dataset.
 type ParquetWriter struct {
-	schema    schema.Schema
-	path      string
-	samples   []*schema.Sample
-	batchSize int
-	mu        sync.Mutex
-	useNative bool
+	schema   schema.Schema
+	pqSchema *parquet.Schema
+	dir      string
+
+	mu      sync.Mutex
+	f       *os.File
+	pw      *parquet.Writer
+	segment int // 1-based index of the current part file
+	state   parquetState
+
+	buffered      int   // samples written since the last row group flush
+	bufferedBytes int64 // approximate uncompressed bytes since the last flush
+	segmentRows   int   // rows committed to the current segment across any number of row groups
+
+	rowGroupSize int64 // target uncompressed bytes per row group
+	batchSize    int   // max samples buffered before a forced flush
+	compression  compress.Codec
 }
 
-// NewParquetWriter creates a new Parquet writer
+// NewParquetWriter creates a new streaming Parquet writer.
 func NewParquetWriter(sch schema.Schema) *ParquetWriter {
 	return &ParquetWriter{
-		schema:    sch,
-		samples:   make([]*schema.Sample, 0, 1000),
-		batchSize: 1000,
-		useNative: true, // Enable native Parquet by default
+		schema:       sch,
+		pqSchema:     buildParquetSchema(sch),
+		batchSize:    1000,
+		rowGroupSize: 128 * 1024 * 1024, // 128MB, matches common Parquet row-group defaults
+		compression:  &parquet.Snappy,
 	}
 }
 
 func (w *ParquetWriter) Format() string { return "parquet" }
 
+// SetRowGroupSize sets the target uncompressed byte size for a row group.
+// A row group is also flushed early once batchSize samples are buffered.
+func (w *ParquetWriter) SetRowGroupSize(bytes int64) {
+	w.rowGroupSize = bytes
+}
+
+// SetBatchSize sets the maximum number of buffered samples before a row
+// group is flushed, regardless of SetRowGroupSize.
+func (w *ParquetWriter) SetBatchSize(size int) {
+	w.batchSize = size
+}
+
+// SetCompression sets the Parquet compression codec. Supported values are
+// "snappy", "zstd", and "gzip"; unknown values fall back to snappy.
+func (w *ParquetWriter) SetCompression(codec string) {
+	switch strings.ToLower(codec) {
+	case "zstd":
+		w.compression = &parquet.Zstd
+	case "gzip":
+		w.compression = &parquet.Gzip
+	default:
+		w.compression = &parquet.Snappy
+	}
+}
+
+// Open creates a fresh dataset directory at path containing a single
+// part file.
 func (w *ParquetWriter) Open(path string) error {
-	w.path = path
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if dir != "" && dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return err
-		}
+	w.dir = path
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return err
 	}
-	return nil
+	w.state = parquetState{}
+	w.segment = 1
+	return w.openSegment()
 }
 
+// OpenAppend resumes a logical dataset. If a .parquet.state sidecar exists
+// it starts a new segment file after the last committed one; otherwise it
+// behaves like Open.
 func (w *ParquetWriter) OpenAppend(path string) error {
-	// Parquet doesn't support true append - we store samples in memory
-	// and rewrite on Close. Just set the path and continue from where we left off.
-	// The checkpoint system tracks how many samples were already completed.
-	w.path = path
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if dir != "" && dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return err
-		}
+	w.dir = path
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return err
 	}
-	return nil
+
+	state, err := loadParquetState(w.statePath())
+	if err != nil {
+		return fmt.Errorf("failed to read parquet state: %w", err)
+	}
+	w.state = state
+	w.segment = len(state.Segments) + 1
+	return w.openSegment()
 }
 
-func (w *ParquetWriter) Write(sample *schema.Sample) error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	w.samples = append(w.samples, sample)
-	return nil
+func (w *ParquetWriter) statePath() string {
+	return filepath.Join(w.dir, "dataset"+parquetStateSuffix)
 }
 
-func (w *ParquetWriter) Flush() error {
-	// No-op for batch writer, actual write happens on Close
+func (w *ParquetWriter) segmentName(n int) string {
+	return fmt.Sprintf("part-%04d.parquet", n)
+}
+
+func (w *ParquetWriter) openSegment() error {
+	segPath := filepath.Join(w.dir, w.segmentName(w.segment))
+	f, err := os.Create(segPath)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet segment %s: %w", segPath, err)
+	}
+
+	w.f = f
+	w.pw = parquet.NewWriter(f, w.pqSchema, parquet.Compression(w.compression))
+	w.buffered = 0
+	w.bufferedBytes = 0
+	w.segmentRows = 0
 	return nil
 }
 
-func (w *ParquetWriter) Close() error {
+// buildParquetSchema converts a schema.Schema's field definitions into a
+// parquet.Schema, so every registered schema (instruction, chat,
+// preference, classification) round-trips without a hardcoded record
+// struct.
+func buildParquetSchema(sch schema.Schema) *parquet.Schema {
+	group := parquet.Group{}
+	for _, f := range sch.Fields() {
+		node := parquetFieldNode(f.Type)
+		if !f.Required {
+			node = parquet.Optional(node)
+		}
+		group[f.Name] = node
+	}
+	return parquet.NewSchema(sch.Name(), group)
+}
+
+// parquetFieldNode maps a schema.FieldType to a Parquet leaf node. Lists,
+// objects, and messages are flattened to JSON-encoded strings since the
+// schema built here is deliberately flat.
+func parquetFieldNode(t schema.FieldType) parquet.Node {
+	switch t {
+	case schema.FieldTypeInt:
+		return parquet.Leaf(parquet.Int64Type)
+	case schema.FieldTypeFloat:
+		return parquet.Leaf(parquet.DoubleType)
+	case schema.FieldTypeBool:
+		return parquet.Leaf(parquet.BooleanType)
+	default:
+		return parquet.String()
+	}
+}
+
+// sampleRow renders a sample's fields to match the schema built by
+// buildParquetSchema: lists/objects are JSON-encoded strings, and a field
+// with no value (and no default) is left nil so it round-trips as a
+// missing optional value rather than a zero one.
+func sampleRow(sch schema.Schema, sample *schema.Sample) (map[string]any, error) {
+	row := make(map[string]any, len(sch.Fields()))
+	for _, f := range sch.Fields() {
+		val, ok := sample.Get(f.Name)
+		if !ok {
+			val = f.Default
+		}
+		if val == nil {
+			if f.Required {
+				return nil, fmt.Errorf("missing required field %q", f.Name)
+			}
+			row[f.Name] = nil
+			continue
+		}
+		switch f.Type {
+		case schema.FieldTypeInt:
+			row[f.Name] = toInt64(val)
+		case schema.FieldTypeFloat:
+			row[f.Name] = toFloat64(val)
+		case schema.FieldTypeBool:
+			b, _ := val.(bool)
+			row[f.Name] = b
+		case schema.FieldTypeString:
+			s, _ := val.(string)
+			row[f.Name] = s
+		default:
+			encoded, err := json.Marshal(val)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode field %q: %w", f.Name, err)
+			}
+			row[f.Name] = string(encoded)
+		}
+	}
+	return row, nil
+}
+
+// toInt64 coerces a field value decoded from JSON (float64) or set
+// directly (int, int64) to the int64 parquetFieldNode expects.
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+// toFloat64 coerces a field value set as a float64, int, or int64 to the
+// float64 parquetFieldNode expects.
+func toFloat64(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	default:
+		return 0
+	}
+}
+
+// approxRowSize estimates a row's encoded size for row-group flush
+// thresholds without re-encoding it: parquet.Writer.Write already did the
+// real encoding, so this only needs to be in the right ballpark.
+func approxRowSize(row map[string]any) int64 {
+	const scalarSize = 8
+	var total int64
+	for _, v := range row {
+		if s, ok := v.(string); ok {
+			total += int64(len(s))
+			continue
+		}
+		total += scalarSize
+	}
+	return total
+}
+
+func (w *ParquetWriter) Write(sample *schema.Sample) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if len(w.samples) == 0 {
-		return nil
+	row, err := sampleRow(w.schema, sample)
+	if err != nil {
+		return fmt.Errorf("failed to encode sample for parquet: %w", err)
 	}
-
-	// Use native Parquet if enabled
-	if w.useNative {
-		return w.writeParquetNative()
+	if err := w.pw.Write(row); err != nil {
+		return fmt.Errorf("failed to write parquet row: %w", err)
 	}
 
-	// Fallback to JSON placeholder
-	return w.writeJSONPlaceholder()
+	w.buffered++
+	w.bufferedBytes += approxRowSize(row)
+	if w.buffered >= w.batchSize || w.bufferedBytes >= w.rowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
 }
 
-// SetBatchSize sets the batch size for writes
-func (w *ParquetWriter) SetBatchSize(size int) {
-	w.batchSize = size
+// flushRowGroup commits the currently buffered rows as a Parquet row
+// group. Must be called with w.mu held.
+func (w *ParquetWriter) flushRowGroup() error {
+	if w.buffered == 0 {
+		return nil
+	}
+	if err := w.pw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush parquet row group: %w", err)
+	}
+	w.state.RowsCommitted += int64(w.buffered)
+	w.segmentRows += w.buffered
+	w.buffered = 0
+	w.bufferedBytes = 0
+	return nil
 }
 
-// SetUseNative controls whether to use native Parquet or JSON fallback
-func (w *ParquetWriter) SetUseNative(native bool) {
-	w.useNative = native
+func (w *ParquetWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushRowGroup()
 }
 
-// ParquetRecord is a generic struct for Parquet writing
-// Since parquet-go requires struct tags, we use a map-based approach
-type ParquetRecord struct {
-	ID          string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
-	Instruction string `parquet:"name=instruction, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	Input       string `parquet:"name=input, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	Output      string `parquet:"name=output, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+// Sync commits the currently buffered rows as a row group, then finalizes
+// the current segment (writes its footer, fsyncs it, and records it in the
+// state sidecar) and opens a fresh segment to continue into, same as
+// OpenAppend does across sessions. A Parquet file isn't valid until its
+// footer is written, so simply fsyncing a row group mid-segment wouldn't
+// give Generator.Run's checkpoint a segment any reader could open; rotating
+// segments on every Sync is what makes a crash right after a successful
+// Sync lose nothing already committed. Use at checkpoint boundaries for
+// crash-safe durability.
+func (w *ParquetWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.pw == nil {
+		return nil
+	}
+	if err := w.flushRowGroup(); err != nil {
+		return err
+	}
+	if w.segmentRows == 0 {
+		// Nothing has been committed to this segment yet; rotating would
+		// just leave an empty finalized file behind.
+		return nil
+	}
+	if err := w.finalizeSegment(); err != nil {
+		return err
+	}
+	w.segment++
+	return w.openSegment()
 }
 
-// writeParquetNative writes using parquet-go library
-func (w *ParquetWriter) writeParquetNative() error {
-	// Create local file writer
-	fw, err := local.NewLocalFileWriter(w.path)
-	if err != nil {
-		return fmt.Errorf("failed to create parquet file: %w", err)
+func (w *ParquetWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.pw == nil {
+		return nil
 	}
-	defer fw.Close()
 
-	// Create Parquet writer with the record schema
-	pw, err := writer.NewParquetWriter(fw, new(ParquetRecord), 4)
-	if err != nil {
-		return fmt.Errorf("failed to create parquet writer: %w", err)
+	if err := w.flushRowGroup(); err != nil {
+		return err
 	}
+	return w.finalizeSegment()
+}
 
-	// Set compression
-	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+// finalizeSegment writes the current segment's footer, records it in the
+// state sidecar, then fsyncs and closes its file. Must be called with w.mu
+// held, with w.pw non-nil.
+//
+// The state sidecar is recorded right after Close, before the fsync call,
+// not after: Close already leaves a structurally complete Parquet file
+// (footer written), so once it succeeds this segment must be in Segments
+// before OpenAppend can recompute the next segment number safely. If the
+// fsync (or Close) that follows fails, the caller only has a durability
+// warning to report, same as any other writer's Sync failure; but if the
+// state sidecar weren't updated until after, a later OpenAppend-based
+// resume would rederive the same segment number for this already-complete
+// file and os.Create would truncate it, silently losing rows that were
+// already durably counted in RowsCommitted.
+func (w *ParquetWriter) finalizeSegment() error {
+	if err := w.pw.Close(); err != nil {
+		w.f.Close()
+		return fmt.Errorf("failed to finalize parquet segment: %w", err)
+	}
 
-	// Write each sample
-	for _, sample := range w.samples {
-		record := ParquetRecord{
-			ID:          sample.ID,
-			Instruction: sample.GetString("instruction"),
-			Input:       sample.GetString("input"),
-			Output:      sample.GetString("output"),
-		}
-		if err := pw.Write(record); err != nil {
-			pw.WriteStop()
-			return fmt.Errorf("failed to write record: %w", err)
+	segName := w.segmentName(w.segment)
+	found := false
+	for _, s := range w.state.Segments {
+		if s == segName {
+			found = true
+			break
 		}
 	}
+	if !found {
+		w.state.Segments = append(w.state.Segments, segName)
+	}
+	if err := saveParquetState(w.statePath(), w.state); err != nil {
+		w.f.Close()
+		return err
+	}
 
-	// Finalize writing
-	if err := pw.WriteStop(); err != nil {
-		return fmt.Errorf("failed to finalize parquet: %w", err)
+	if err := w.f.Sync(); err != nil {
+		w.f.Close()
+		return fmt.Errorf("failed to sync parquet segment: %w", err)
 	}
+	return w.f.Close()
+}
 
-	return nil
+func loadParquetState(path string) (parquetState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return parquetState{}, nil
+	}
+	if err != nil {
+		return parquetState{}, err
+	}
+	var state parquetState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return parquetState{}, err
+	}
+	return state, nil
 }
 
-// writeJSONPlaceholder writes JSON-based placeholder format
-func (w *ParquetWriter) writeJSONPlaceholder() error {
-	file, err := os.Create(w.path)
+func saveParquetState(path string, state parquetState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return os.WriteFile(path, data, 0644)
+}
 
-	// Create columnar structure
-	columns := make(map[string][]any)
-	for _, sample := range w.samples {
-		for key, value := range sample.Fields {
-			columns[key] = append(columns[key], value)
-		}
-	}
+// ParquetDirWriter treats a directory of part-NNNN.parquet segments,
+// written over one or more ParquetWriter sessions, as a single logical
+// dataset. Readers (e.g. `validate dataset`) use it to enumerate every
+// segment belonging to a dataset produced with OpenAppend.
+type ParquetDirWriter struct {
+	dir string
+}
 
-	// Write metadata
-	metadata := map[string]any{
-		"format":   "parquet-placeholder",
-		"schema":   w.schema.Name(),
-		"num_rows": len(w.samples),
-		"columns":  columns,
-		"_note":    "Use SetUseNative(true) for native Parquet support",
+// NewParquetDirWriter creates a helper over a ParquetWriter dataset
+// directory.
+func NewParquetDirWriter(dir string) *ParquetDirWriter {
+	return &ParquetDirWriter{dir: dir}
+}
+
+// Segments returns the part files making up the dataset, in write order.
+func (d *ParquetDirWriter) Segments() ([]string, error) {
+	state, err := loadParquetState(filepath.Join(d.dir, "dataset"+parquetStateSuffix))
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(state.Segments))
+	for _, s := range state.Segments {
+		paths = append(paths, filepath.Join(d.dir, s))
 	}
+	return paths, nil
+}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(metadata)
+// RowsCommitted returns the total number of rows flushed across all
+// segments of the dataset.
+func (d *ParquetDirWriter) RowsCommitted() (int64, error) {
+	state, err := loadParquetState(filepath.Join(d.dir, "dataset"+parquetStateSuffix))
+	if err != nil {
+		return 0, err
+	}
+	return state.RowsCommitted, nil
 }
@@ -10,7 +10,7 @@ import (
 
 func TestParquetWriter_Native(t *testing.T) {
 	tmpDir := t.TempDir()
-	outPath := filepath.Join(tmpDir, "test.parquet")
+	outPath := filepath.Join(tmpDir, "dataset")
 
 	s := schema.NewInstructionSchema()
 	w := NewParquetWriter(s)
@@ -27,72 +27,202 @@ func TestParquetWriter_Native(t *testing.T) {
 			"output":      "print('Hello, World!')",
 		},
 	}
-	w.Write(sample)
+	if err := w.Write(sample); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
 
 	if err := w.Close(); err != nil {
 		t.Fatalf("Close failed: %v", err)
 	}
 
-	// Verify file exists and has content
-	info, err := os.Stat(outPath)
+	segPath := filepath.Join(outPath, "part-0001.parquet")
+	info, err := os.Stat(segPath)
 	if err != nil {
 		t.Fatalf("Stat failed: %v", err)
 	}
-
 	if info.Size() == 0 {
-		t.Error("Expected non-empty parquet file")
+		t.Error("Expected non-empty parquet segment")
 	}
 
 	// Parquet files start with "PAR1" magic bytes
-	content, err := os.ReadFile(outPath)
+	content, err := os.ReadFile(segPath)
 	if err != nil {
 		t.Fatalf("ReadFile failed: %v", err)
 	}
-
 	if len(content) < 4 || string(content[:4]) != "PAR1" {
 		t.Errorf("Expected Parquet magic bytes, got: %v", content[:4])
 	}
 }
 
-func TestParquetWriter_Fallback(t *testing.T) {
+func TestParquetWriter_RowGroupFlush(t *testing.T) {
 	tmpDir := t.TempDir()
-	outPath := filepath.Join(tmpDir, "test_fallback.parquet")
+	outPath := filepath.Join(tmpDir, "dataset")
 
 	s := schema.NewInstructionSchema()
 	w := NewParquetWriter(s)
-	w.SetUseNative(false) // Use JSON fallback
+	w.SetBatchSize(2)
 
 	if err := w.Open(outPath); err != nil {
 		t.Fatalf("Open failed: %v", err)
 	}
 
-	sample := &schema.Sample{
-		ID: "test-1",
-		Fields: map[string]any{
-			"instruction": "Test instruction",
-		},
+	for i := 0; i < 5; i++ {
+		sample := &schema.Sample{
+			Fields: map[string]any{
+				"instruction": "instruction",
+				"output":      "output",
+			},
+		}
+		if err := w.Write(sample); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
 	}
-	w.Write(sample)
 
 	if err := w.Close(); err != nil {
 		t.Fatalf("Close failed: %v", err)
 	}
 
-	// Verify file exists
-	content, err := os.ReadFile(outPath)
+	dir := NewParquetDirWriter(outPath)
+	rows, err := dir.RowsCommitted()
+	if err != nil {
+		t.Fatalf("RowsCommitted failed: %v", err)
+	}
+	if rows != 5 {
+		t.Errorf("expected 5 rows committed, got %d", rows)
+	}
+}
+
+// TestParquetWriter_SyncFinalizesReadableSegment confirms Sync leaves behind
+// a complete, valid Parquet segment (footer written, state sidecar updated)
+// rather than just an fsynced but unterminated file, since a process
+// crashing right after Sync must not lose rows Generator.Run's checkpoint
+// already considered durable.
+func TestParquetWriter_SyncFinalizesReadableSegment(t *testing.T) {
+	tmpDir := t.TempDir()
+	outPath := filepath.Join(tmpDir, "dataset")
+
+	s := schema.NewInstructionSchema()
+	w := NewParquetWriter(s)
+	w.SetBatchSize(100) // large enough that Write alone won't flush
+
+	if err := w.Open(outPath); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := w.Write(&schema.Sample{Fields: map[string]any{"instruction": "a", "output": "b"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Sync must have finalized and recorded the segment without needing
+	// Close, so a reader can already see it.
+	dir := NewParquetDirWriter(outPath)
+	segments, err := dir.Segments()
+	if err != nil {
+		t.Fatalf("Segments failed: %v", err)
+	}
+	if len(segments) != 1 {
+		t.Fatalf("expected 1 finalized segment after Sync, got %d", len(segments))
+	}
+	segContent, err := os.ReadFile(segments[0])
 	if err != nil {
 		t.Fatalf("ReadFile failed: %v", err)
 	}
+	if len(segContent) < 4 || string(segContent[len(segContent)-4:]) != "PAR1" {
+		t.Error("expected the synced segment to end with the Parquet footer magic bytes")
+	}
 
-	// Should contain JSON placeholder marker
-	if len(content) == 0 {
-		t.Error("Expected non-empty file")
+	// A second Write continues into a fresh segment.
+	if err := w.Write(&schema.Sample{Fields: map[string]any{"instruction": "c", "output": "d"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	rows, err := dir.RowsCommitted()
+	if err != nil {
+		t.Fatalf("RowsCommitted failed: %v", err)
+	}
+	if rows != 2 {
+		t.Errorf("expected 2 rows committed across both segments, got %d", rows)
+	}
+}
+
+// TestParquetWriter_SyncWithNoNewRowsIsNoop confirms a Sync call that finds
+// nothing newly committed to the current segment doesn't rotate into an
+// empty segment file.
+func TestParquetWriter_SyncWithNoNewRowsIsNoop(t *testing.T) {
+	tmpDir := t.TempDir()
+	outPath := filepath.Join(tmpDir, "dataset")
+
+	s := schema.NewInstructionSchema()
+	w := NewParquetWriter(s)
+
+	if err := w.Open(outPath); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("second Sync failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	dir := NewParquetDirWriter(outPath)
+	segments, err := dir.Segments()
+	if err != nil {
+		t.Fatalf("Segments failed: %v", err)
+	}
+	if len(segments) != 1 {
+		t.Errorf("expected a no-op Sync not to rotate into extra empty segments, got %d", len(segments))
+	}
+}
+
+func TestParquetWriter_AppendNewSegment(t *testing.T) {
+	tmpDir := t.TempDir()
+	outPath := filepath.Join(tmpDir, "dataset")
+
+	s := schema.NewInstructionSchema()
+	w := NewParquetWriter(s)
+	if err := w.Open(outPath); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	w.Write(&schema.Sample{Fields: map[string]any{"instruction": "a", "output": "b"}})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	w2 := NewParquetWriter(s)
+	if err := w2.OpenAppend(outPath); err != nil {
+		t.Fatalf("OpenAppend failed: %v", err)
+	}
+	w2.Write(&schema.Sample{Fields: map[string]any{"instruction": "c", "output": "d"}})
+	if err := w2.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	dir := NewParquetDirWriter(outPath)
+	segments, err := dir.Segments()
+	if err != nil {
+		t.Fatalf("Segments failed: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	if filepath.Base(segments[1]) != "part-0002.parquet" {
+		t.Errorf("expected second segment part-0002.parquet, got %s", filepath.Base(segments[1]))
 	}
 }
 
 func TestParquetWriter_EmptySamples(t *testing.T) {
 	tmpDir := t.TempDir()
-	outPath := filepath.Join(tmpDir, "empty.parquet")
+	outPath := filepath.Join(tmpDir, "empty")
 
 	s := schema.NewInstructionSchema()
 	w := NewParquetWriter(s)
@@ -107,9 +237,12 @@ func TestParquetWriter_EmptySamples(t *testing.T) {
 		t.Fatalf("Close failed: %v", err)
 	}
 
-	// File should not exist or be empty
-	_, err := os.Stat(outPath)
-	if err == nil {
-		t.Error("Expected no file for empty samples")
+	dir := NewParquetDirWriter(outPath)
+	rows, err := dir.RowsCommitted()
+	if err != nil {
+		t.Fatalf("RowsCommitted failed: %v", err)
+	}
+	if rows != 0 {
+		t.Errorf("expected 0 rows committed, got %d", rows)
 	}
 }
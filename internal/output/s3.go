@@ -0,0 +1,194 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/shantoislamdev/kothaset/internal/schema"
+)
+
+// defaultS3PartSize is the default multipart upload part size, matching
+// the AWS SDK's own default.
+const defaultS3PartSize = 5 * 1024 * 1024
+
+// S3Writer wraps an inner Writer that stages its output on local disk, and
+// uploads finished shards to s3://bucket/prefix/ as the inner writer
+// rotates or closes them. It composes with any local Writer (Open with
+// "parquet+s3" builds a ParquetWriter staged locally and mirrored to S3).
+type S3Writer struct {
+	inner Writer
+
+	bucket     string
+	prefix     string
+	stagingDir string
+
+	partSize int64
+	sse      types.ServerSideEncryption
+
+	mu       sync.Mutex
+	client   *s3.Client
+	uploader *manager.Uploader
+}
+
+// NewS3Writer creates a writer that uploads inner's output to S3 once
+// inner flushes or closes it.
+func NewS3Writer(inner Writer) *S3Writer {
+	return &S3Writer{inner: inner, partSize: defaultS3PartSize}
+}
+
+func (w *S3Writer) Format() string { return w.inner.Format() + "+s3" }
+
+// SetPartSize sets the multipart upload part size in bytes.
+func (w *S3Writer) SetPartSize(bytes int64) {
+	if bytes > 0 {
+		w.partSize = bytes
+	}
+}
+
+// SetServerSideEncryption sets the SSE mode applied to uploaded objects
+// (e.g. "AES256" or "aws:kms"). Empty disables SSE headers.
+func (w *S3Writer) SetServerSideEncryption(sse string) {
+	w.sse = types.ServerSideEncryption(sse)
+}
+
+// Open stages inner's output under a local temp directory and uploads it
+// to the s3://bucket/prefix destination named by path.
+func (w *S3Writer) Open(path string) error {
+	return w.open(path, false)
+}
+
+// OpenAppend resumes a prior run, staging inner's output in append mode.
+// Since S3 objects are immutable, previously uploaded shards are left in
+// place; only shards written during this session are (re-)uploaded.
+func (w *S3Writer) OpenAppend(path string) error {
+	return w.open(path, true)
+}
+
+func (w *S3Writer) open(rawURL string, appendMode bool) error {
+	bucket, prefix, err := parseS3URL(rawURL)
+	if err != nil {
+		return err
+	}
+	w.bucket, w.prefix = bucket, prefix
+
+	stagingDir, err := os.MkdirTemp("", "kothaset-s3-*")
+	if err != nil {
+		return fmt.Errorf("s3 writer: failed to create staging directory: %w", err)
+	}
+	w.stagingDir = stagingDir
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return fmt.Errorf("s3 writer: failed to load AWS config: %w", err)
+	}
+	w.client = s3.NewFromConfig(cfg)
+	w.uploader = manager.NewUploader(w.client, func(u *manager.Uploader) {
+		u.PartSize = w.partSize
+	})
+
+	stagedPath := filepath.Join(stagingDir, filepath.Base(strings.TrimRight(rawURL, "/")))
+	if appendMode {
+		return w.inner.OpenAppend(stagedPath)
+	}
+	return w.inner.Open(stagedPath)
+}
+
+func (w *S3Writer) Write(sample *schema.Sample) error {
+	return w.inner.Write(sample)
+}
+
+func (w *S3Writer) Flush() error {
+	return w.inner.Flush()
+}
+
+// Sync flushes the inner writer's buffered data, then uploads everything
+// staged so far, so data is durable in S3 at every checkpoint boundary.
+func (w *S3Writer) Sync() error {
+	if err := w.inner.Sync(); err != nil {
+		return err
+	}
+	return w.uploadStaged()
+}
+
+// Close closes the inner writer, uploads any remaining staged shards, and
+// removes the local staging directory.
+func (w *S3Writer) Close() error {
+	if err := w.inner.Close(); err != nil {
+		return err
+	}
+	if err := w.uploadStaged(); err != nil {
+		return err
+	}
+	return os.RemoveAll(w.stagingDir)
+}
+
+// uploadStaged walks the staging directory and uploads every file to its
+// corresponding key under prefix. Files are re-uploaded idempotently on
+// every call, which is wasteful for files that haven't changed since the
+// last Sync but keeps the implementation simple and always correct.
+func (w *S3Writer) uploadStaged() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return filepath.Walk(w.stagingDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("s3 writer: failed to open staged file %s: %w", path, err)
+		}
+		defer f.Close()
+
+		rel, err := filepath.Rel(w.stagingDir, path)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimPrefix(filepath.ToSlash(filepath.Join(w.prefix, rel)), "/")
+
+		input := &s3.PutObjectInput{
+			Bucket: aws.String(w.bucket),
+			Key:    aws.String(key),
+			Body:   f,
+		}
+		if w.sse != "" {
+			input.ServerSideEncryption = w.sse
+		}
+
+		if _, err := w.uploader.Upload(context.Background(), input); err != nil {
+			return fmt.Errorf("s3 writer: failed to upload s3://%s/%s: %w", w.bucket, key, err)
+		}
+		return nil
+	})
+}
+
+// parseS3URL splits an "s3://bucket/prefix" destination into its bucket
+// and key prefix.
+func parseS3URL(raw string) (bucket, prefix string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("s3 writer: invalid destination %q: %w", raw, err)
+	}
+	if u.Scheme != "s3" {
+		return "", "", fmt.Errorf("s3 writer: expected an s3:// destination, got %q", raw)
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("s3 writer: destination %q is missing a bucket name", raw)
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
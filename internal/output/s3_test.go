@@ -0,0 +1,40 @@
+package output
+
+import "testing"
+
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantBucket string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{name: "bucket and prefix", raw: "s3://my-bucket/datasets/train", wantBucket: "my-bucket", wantPrefix: "datasets/train"},
+		{name: "bucket only", raw: "s3://my-bucket", wantBucket: "my-bucket", wantPrefix: ""},
+		{name: "wrong scheme", raw: "https://my-bucket/datasets", wantErr: true},
+		{name: "missing bucket", raw: "s3:///datasets", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, prefix, err := parseS3URL(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseS3URL(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if bucket != tt.wantBucket || prefix != tt.wantPrefix {
+				t.Errorf("parseS3URL(%q) = (%q, %q), want (%q, %q)", tt.raw, bucket, prefix, tt.wantBucket, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestS3Writer_Format(t *testing.T) {
+	w := NewS3Writer(NewJSONLWriter(nil))
+	if got, want := w.Format(), "jsonl+s3"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
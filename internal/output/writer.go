@@ -3,47 +3,303 @@ package output
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/shantoislamdev/kothaset/internal/schema"
 )
 
-// Writer defines the interface for dataset output writers
-type Writer interface {
-	// Open initializes the writer for the given path
+// Sink is the minimal contract a single output backend implements. It
+// covers backends that don't have a meaningful local "resume by appending
+// to a path" notion of their own (S3, Kafka) as well as ones that do, via
+// Writer below. Sync must do something a crash-safety checkpoint can rely
+// on: a local file syncs to disk, Parquet closes the current row group,
+// S3 uploads the current part, Kafka waits for broker acks.
+type Sink interface {
+	// Open initializes the sink for the given destination (a file path,
+	// directory, or scheme-prefixed URL such as s3://bucket/prefix,
+	// depending on the backend).
 	Open(path string) error
 
-	// OpenAppend opens the writer in append mode for resuming
-	// This preserves existing data instead of truncating
-	OpenAppend(path string) error
-
 	// Write writes a single sample to the output
 	Write(sample *schema.Sample) error
 
 	// Flush flushes any buffered data to the OS
 	Flush() error
 
-	// Sync flushes buffered data and fsyncs to physical storage.
-	// Use at checkpoint boundaries for crash-safe durability.
+	// Sync flushes buffered data to physical storage or an equivalent
+	// durability boundary for the backend. Use at checkpoint boundaries
+	// for crash-safe durability.
 	Sync() error
 
-	// Close closes the writer and releases resources
+	// Close closes the sink and releases resources
 	Close() error
 
 	// Format returns the output format name
 	Format() string
 }
 
-// NewWriter creates a new writer for the given format
+// Writer is a Sink that also supports resuming a prior run by appending to
+// the same destination instead of truncating it. Every built-in local
+// backend (JSONL, Parquet, HuggingFace) implements this; remote-only sinks
+// composed on top of them (S3Writer, KafkaWriter) implement it by
+// delegating to their inner Writer.
+type Writer interface {
+	Sink
+
+	// OpenAppend opens the writer in append mode for resuming.
+	// This preserves existing data instead of truncating.
+	OpenAppend(path string) error
+}
+
+// RejectionWriter is implemented by writers that can additionally record
+// samples that failed schema validation, alongside the
+// *schema.ValidationReport explaining why. It's checked with an optional
+// type assertion (see generator.Generator.Run) rather than folded into
+// Writer, since most backends (S3, Kafka, a MultiWriter/MultiSink over
+// them) have no natural place to put a rejection sidecar.
+type RejectionWriter interface {
+	WriteRejection(sample *schema.Sample, report *schema.ValidationReport) error
+}
+
+// BatchWriter is implemented by writers that can write a group of samples in
+// one call without flushing to the underlying stream/OS after each one,
+// amortizing that cost across the whole group instead of paying it per
+// sample (see generator.Generator's write-batching, gated by
+// Config.BatchSize/BatchMaxBytes). It's checked with an optional type
+// assertion rather than folded into Writer, since the generator falls back
+// to one Write call per sample for any backend that doesn't implement it.
+type BatchWriter interface {
+	// WriteBatch writes samples in order, stopping at the first one that
+	// fails to marshal/write, then flushes whatever came before it so that
+	// prefix is actually durable. It returns how many samples are confirmed
+	// written alongside the error, if any; everything from that count
+	// onward is not written. If the trailing flush itself fails, none of
+	// the batch can be assumed durable, so the count is 0 regardless of how
+	// far the write loop got.
+	WriteBatch(samples []*schema.Sample) (int, error)
+}
+
+// NewWriter creates a new writer for the given format. format may name:
+//   - a single backend ("parquet")
+//   - a "+"-joined chain of wrappers around one base backend
+//     ("parquet+s3"), composed inside-out so the last name wraps the rest
+//   - a ","-joined list of independent backends ("jsonl,parquet"), in
+//     which case the returned Writer is a MultiWriter that fans out to
+//     every named chain
 func NewWriter(format string, sch schema.Schema) (Writer, error) {
-	switch format {
+	formats := splitFormats(format)
+	if len(formats) == 1 {
+		return newChainWriter(formats[0], sch)
+	}
+
+	writers := make([]Writer, 0, len(formats))
+	for _, f := range formats {
+		w, err := newChainWriter(f, sch)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, w)
+	}
+	return NewMultiWriter(writers...), nil
+}
+
+// newChainWriter builds a single "+"-joined backend chain, e.g.
+// "parquet+s3" builds a ParquetWriter and wraps it in an S3Writer.
+func newChainWriter(format string, sch schema.Schema) (Writer, error) {
+	names := strings.Split(format, "+")
+
+	w, err := newBaseWriter(strings.TrimSpace(names[0]), sch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wrap := range names[1:] {
+		w, err = wrapWriter(strings.TrimSpace(wrap), w)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func newBaseWriter(format string, sch schema.Schema) (Writer, error) {
+	switch normalizeFormat(format) {
 	case "jsonl", "":
 		return NewJSONLWriter(sch), nil
+	case "parquet":
+		return NewParquetWriter(sch), nil
+	case "huggingface":
+		return NewHuggingFaceWriter(sch), nil
+	case "kafka":
+		brokers := kafkaBrokersFromEnv()
+		if len(brokers) == 0 {
+			return nil, fmt.Errorf("kafka output format requires the KOTHASET_KAFKA_BROKERS environment variable (comma-separated host:port list)")
+		}
+		return NewKafkaWriter(sch, brokers), nil
 	default:
-		return nil, fmt.Errorf("unsupported output format: %s (supported: jsonl)", format)
+		return nil, fmt.Errorf("unsupported output format: %s (supported: %s)", format, strings.Join(SupportedFormats(), ", "))
 	}
 }
 
+// wrapWriter wraps a base writer in a remote-upload layer named by wrap,
+// e.g. "s3" in "parquet+s3".
+func wrapWriter(wrap string, inner Writer) (Writer, error) {
+	switch normalizeFormat(wrap) {
+	case "s3":
+		return NewS3Writer(inner), nil
+	default:
+		return nil, fmt.Errorf("unsupported output wrapper: %s (supported: s3)", wrap)
+	}
+}
+
+// normalizeFormat lower-cases format and resolves aliases ("hf" ->
+// "huggingface") to the canonical name used by Writer.Format().
+func normalizeFormat(format string) string {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "hf", "huggingface":
+		return "huggingface"
+	default:
+		return strings.ToLower(strings.TrimSpace(format))
+	}
+}
+
+// splitFormats splits a comma-separated output_format value into its
+// individual backend names, e.g. "jsonl, parquet" -> ["jsonl", "parquet"].
+func splitFormats(format string) []string {
+	var out []string
+	for _, f := range strings.Split(format, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 {
+		out = []string{""}
+	}
+	return out
+}
+
 // SupportedFormats returns a list of supported output formats
 func SupportedFormats() []string {
-	return []string{"jsonl"}
+	return []string{"jsonl", "parquet", "huggingface", "hf", "kafka"}
+}
+
+// IsSupportedFormat reports whether every comma-separated, "+"-chained
+// backend named in format is a format NewWriter can construct.
+func IsSupportedFormat(format string) bool {
+	for _, chain := range splitFormats(format) {
+		names := strings.Split(chain, "+")
+		switch normalizeFormat(strings.TrimSpace(names[0])) {
+		case "", "jsonl", "parquet", "huggingface", "kafka":
+		default:
+			return false
+		}
+		for _, wrap := range names[1:] {
+			if normalizeFormat(strings.TrimSpace(wrap)) != "s3" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MultiWriter fans a single generation run's samples out to several
+// backend writers at once, e.g. output_format: "jsonl,parquet" streams a
+// tailable JSONL log alongside a columnar Parquet file for analytics
+// without generating the dataset twice. A write is only considered
+// successful once every inner writer accepts it.
+type MultiWriter struct {
+	writers []Writer
+}
+
+// NewMultiWriter creates a writer that fans out to every given writer.
+func NewMultiWriter(writers ...Writer) *MultiWriter {
+	return &MultiWriter{writers: writers}
+}
+
+func (w *MultiWriter) Format() string {
+	names := make([]string, len(w.writers))
+	for i, inner := range w.writers {
+		names[i] = inner.Format()
+	}
+	return strings.Join(names, ",")
+}
+
+// Open opens every inner writer, deriving each one's path from path via
+// formatPath so a jsonl writer and a parquet writer sharing a base path
+// don't collide (jsonl keeps the file, parquet/huggingface get a sibling
+// directory named after the format).
+func (w *MultiWriter) Open(path string) error {
+	for _, inner := range w.writers {
+		if err := inner.Open(formatPath(path, inner.Format())); err != nil {
+			return fmt.Errorf("%s writer: %w", inner.Format(), err)
+		}
+	}
+	return nil
+}
+
+func (w *MultiWriter) OpenAppend(path string) error {
+	for _, inner := range w.writers {
+		if err := inner.OpenAppend(formatPath(path, inner.Format())); err != nil {
+			return fmt.Errorf("%s writer: %w", inner.Format(), err)
+		}
+	}
+	return nil
+}
+
+func (w *MultiWriter) Write(sample *schema.Sample) error {
+	for _, inner := range w.writers {
+		if err := inner.Write(sample); err != nil {
+			return fmt.Errorf("%s writer: %w", inner.Format(), err)
+		}
+	}
+	return nil
+}
+
+func (w *MultiWriter) Flush() error {
+	for _, inner := range w.writers {
+		if err := inner.Flush(); err != nil {
+			return fmt.Errorf("%s writer: %w", inner.Format(), err)
+		}
+	}
+	return nil
+}
+
+func (w *MultiWriter) Sync() error {
+	for _, inner := range w.writers {
+		if err := inner.Sync(); err != nil {
+			return fmt.Errorf("%s writer: %w", inner.Format(), err)
+		}
+	}
+	return nil
+}
+
+// Close closes every inner writer even if one fails, so a failure in one
+// backend doesn't leak file handles held by the others. The first error
+// encountered is returned.
+func (w *MultiWriter) Close() error {
+	var firstErr error
+	for _, inner := range w.writers {
+		if err := inner.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s writer: %w", inner.Format(), err)
+		}
+	}
+	return firstErr
+}
+
+// formatPath derives the path an inner writer of a MultiWriter should
+// open, from the base path the caller asked for. The jsonl writer keeps
+// (or gains) a ".jsonl" extension; directory-based formats (parquet,
+// huggingface) get a sibling directory suffixed with their format name.
+func formatPath(base, format string) string {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	if format == "jsonl" {
+		if ext == ".jsonl" {
+			return base
+		}
+		return stem + ".jsonl"
+	}
+	return stem + "_" + format
 }
@@ -0,0 +1,611 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropicDefaultBaseURL is the Anthropic Messages API endpoint.
+const anthropicDefaultBaseURL = "https://api.anthropic.com"
+
+// anthropicVersion is the API version header Anthropic requires on every
+// request; Config.Headers can override it for accounts pinned to a
+// different version.
+const anthropicVersion = "2023-06-01"
+
+// AnthropicProvider implements the Provider interface for Anthropic's
+// Messages API.
+type AnthropicProvider struct {
+	name    string
+	model   string
+	apiKey  string
+	baseURL string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewAnthropicProvider creates a new Anthropic provider.
+func NewAnthropicProvider(cfg *Config) (Provider, error) {
+	if cfg.APIKey == "" {
+		return nil, NewAuthError("API key is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+
+	return &AnthropicProvider{
+		name:    cfg.Name,
+		model:   cfg.Model,
+		apiKey:  cfg.APIKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// anthropicMessage is a single turn in the Messages API request body.
+// Anthropic only accepts "user" and "assistant" roles here; a system
+// prompt is sent via the separate top-level System field.
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model         string               `json:"model"`
+	Messages      []anthropicMessage   `json:"messages"`
+	System        string               `json:"system,omitempty"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Temperature   float64              `json:"temperature,omitempty"`
+	TopP          float64              `json:"top_p,omitempty"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Tools         []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+// anthropicStructuredOutputTool is the name of the single forced tool used
+// to get schema-constrained output out of the Messages API, which has no
+// native response_format/json_schema parameter of its own (see Generate's
+// req.ResponseSchema handling).
+const anthropicStructuredOutputTool = "emit_sample"
+
+// anthropicTool describes a tool in the Messages API's "tools" parameter.
+// Generate uses it only to smuggle a JSON Schema through forced tool-use,
+// never for genuine tool calling.
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// anthropicToolChoice forces the model to call a specific tool rather than
+// choosing freely (or not at all).
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Model      string                  `json:"model"`
+	StopReason string                  `json:"stop_reason"`
+	Content    []anthropicContentBlock `json:"content"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type anthropicErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// buildMessages folds req.Messages into Anthropic's user/assistant-only
+// shape, concatenating any "system" role messages (and req.SystemPrompt)
+// into the top-level system field.
+func (p *AnthropicProvider) buildMessages(req GenerationRequest) (system string, messages []anthropicMessage) {
+	var systemParts []string
+	if req.SystemPrompt != "" {
+		systemParts = append(systemParts, req.SystemPrompt)
+	}
+
+	for _, msg := range req.Messages {
+		switch strings.ToLower(msg.Role) {
+		case "system":
+			systemParts = append(systemParts, msg.Content)
+		case "assistant", "ai", "bot":
+			messages = append(messages, anthropicMessage{Role: "assistant", Content: msg.Content})
+		default:
+			messages = append(messages, anthropicMessage{Role: "user", Content: msg.Content})
+		}
+	}
+
+	return strings.Join(systemParts, "\n\n"), messages
+}
+
+// Generate implements Provider.Generate
+func (p *AnthropicProvider) Generate(ctx context.Context, req GenerationRequest) (*GenerationResponse, error) {
+	start := time.Now()
+
+	system, messages := p.buildMessages(req)
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 4096
+	}
+
+	body := anthropicRequest{
+		Model:         p.model,
+		Messages:      messages,
+		System:        system,
+		MaxTokens:     maxTokens,
+		Temperature:   req.Temperature,
+		TopP:          req.TopP,
+		StopSequences: req.StopSequences,
+	}
+
+	if len(req.ResponseSchema) > 0 {
+		body.Tools = []anthropicTool{{
+			Name:        anthropicStructuredOutputTool,
+			Description: "Emit the generated sample matching the required schema.",
+			InputSchema: json.RawMessage(req.ResponseSchema),
+		}}
+		body.ToolChoice = &anthropicToolChoice{Type: "tool", Name: anthropicStructuredOutputTool}
+	}
+
+	respBody, requestID, err := p.doRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// A forced structured-output tool call returns its JSON in a tool_use
+	// block's input instead of a text block; fold it into Content so
+	// ParseResponse sees the same raw-JSON shape either way.
+	var content strings.Builder
+	for _, block := range respBody.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			if block.Name == anthropicStructuredOutputTool && len(block.Input) > 0 {
+				content.Write(block.Input)
+			}
+		}
+	}
+
+	return &GenerationResponse{
+		Content:      content.String(),
+		FinishReason: respBody.StopReason,
+		Usage: TokenUsage{
+			PromptTokens:     respBody.Usage.InputTokens,
+			CompletionTokens: respBody.Usage.OutputTokens,
+			TotalTokens:      respBody.Usage.InputTokens + respBody.Usage.OutputTokens,
+		},
+		Model:     respBody.Model,
+		RequestID: requestID,
+		Latency:   time.Since(start),
+	}, nil
+}
+
+// doRequest POSTs body to /v1/messages, applying auth headers and then
+// Config.Headers on top so deployment-specific overrides (e.g.
+// anthropic-beta feature flags) win over the built-in ones.
+func (p *AnthropicProvider) doRequest(ctx context.Context, body anthropicRequest) (*anthropicResponse, string, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", err
+	}
+	httpReq.Header.Set("content-type", "application/json")
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	for k, v := range p.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, "", p.convertNetworkError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read anthropic response: %w", err)
+	}
+
+	requestID := resp.Header.Get("request-id")
+	if resp.StatusCode != http.StatusOK {
+		return nil, requestID, p.convertStatusError(resp.StatusCode, data, resp.Header.Get("retry-after"))
+	}
+
+	var out anthropicResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, requestID, fmt.Errorf("failed to decode anthropic response: %w", err)
+	}
+	return &out, requestID, nil
+}
+
+// GenerateStream implements Provider.GenerateStream. Streaming isn't
+// implemented yet; callers should check SupportsStreaming before using it.
+func (p *AnthropicProvider) GenerateStream(ctx context.Context, req GenerationRequest) (<-chan StreamChunk, error) {
+	return nil, NewProviderError(ErrKindValidation, "anthropic provider does not support streaming yet", nil)
+}
+
+// Name implements Provider.Name
+func (p *AnthropicProvider) Name() string { return p.name }
+
+// Type implements Provider.Type
+func (p *AnthropicProvider) Type() string { return "anthropic" }
+
+// Model implements Provider.Model
+func (p *AnthropicProvider) Model() string { return p.model }
+
+// SupportedModels implements Provider.SupportedModels
+func (p *AnthropicProvider) SupportedModels() []string {
+	return []string{
+		"claude-opus-4-6", "claude-sonnet-4-6", "claude-haiku-4-6",
+	}
+}
+
+// SupportsStreaming implements Provider.SupportsStreaming
+func (p *AnthropicProvider) SupportsStreaming() bool { return false }
+
+// SupportsStructuredOutput implements Provider.SupportsStructuredOutput.
+// Generate enforces it via a forced tool-use call (see
+// anthropicStructuredOutputTool), not a native response_format parameter.
+func (p *AnthropicProvider) SupportsStructuredOutput() bool { return true }
+
+// Validate implements Provider.Validate
+func (p *AnthropicProvider) Validate() error {
+	if p.apiKey == "" {
+		return NewValidationError("API key is required")
+	}
+	if p.model == "" {
+		return NewValidationError("model is required")
+	}
+	return nil
+}
+
+// HealthCheck implements Provider.HealthCheck
+func (p *AnthropicProvider) HealthCheck(ctx context.Context) error {
+	_, err := p.Generate(ctx, GenerationRequest{
+		Messages:  []Message{{Role: "user", Content: "ping"}},
+		MaxTokens: 1,
+	})
+	return err
+}
+
+// Close implements Provider.Close
+func (p *AnthropicProvider) Close() error {
+	return nil
+}
+
+// anthropicBatchRequestEntry is one request bundled into a Message Batches
+// API job, using the same request shape Generate sends to /v1/messages.
+type anthropicBatchRequestEntry struct {
+	CustomID string           `json:"custom_id"`
+	Params   anthropicRequest `json:"params"`
+}
+
+type anthropicBatchCreateRequest struct {
+	Requests []anthropicBatchRequestEntry `json:"requests"`
+}
+
+// anthropicBatchResponse is the Message Batches API's job representation,
+// returned by both creating and polling a batch.
+type anthropicBatchResponse struct {
+	ID               string `json:"id"`
+	ProcessingStatus string `json:"processing_status"` // in_progress, canceling, ended
+	RequestCounts    struct {
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+		Errored    int `json:"errored"`
+		Canceled   int `json:"canceled"`
+		Expired    int `json:"expired"`
+	} `json:"request_counts"`
+	// ResultsURL is set once ProcessingStatus == "ended"; it points to a
+	// JSONL stream of anthropicBatchResultLine, one per submitted request.
+	ResultsURL *string `json:"results_url"`
+}
+
+// anthropicBatchResultLine is one line of the JSONL stream at
+// anthropicBatchResponse.ResultsURL.
+type anthropicBatchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string                  `json:"type"` // succeeded, errored, canceled, expired
+		Message *anthropicResponse      `json:"message,omitempty"`
+		Error   *anthropicErrorResponse `json:"error,omitempty"`
+	} `json:"result"`
+}
+
+// SubmitBatch implements BatchProvider, creating a single Message Batches
+// API job covering every entry in requests. Unlike OpenAI's Batch API,
+// Anthropic's takes the requests inline in the creation call rather than
+// via a separately uploaded file.
+func (p *AnthropicProvider) SubmitBatch(ctx context.Context, requests []BatchRequest) (string, error) {
+	entries := make([]anthropicBatchRequestEntry, 0, len(requests))
+	for _, r := range requests {
+		system, messages := p.buildMessages(r.Request)
+		maxTokens := r.Request.MaxTokens
+		if maxTokens <= 0 {
+			maxTokens = 4096
+		}
+
+		params := anthropicRequest{
+			Model:         p.model,
+			Messages:      messages,
+			System:        system,
+			MaxTokens:     maxTokens,
+			Temperature:   r.Request.Temperature,
+			TopP:          r.Request.TopP,
+			StopSequences: r.Request.StopSequences,
+		}
+		if len(r.Request.ResponseSchema) > 0 {
+			params.Tools = []anthropicTool{{
+				Name:        anthropicStructuredOutputTool,
+				Description: "Emit the generated sample matching the required schema.",
+				InputSchema: json.RawMessage(r.Request.ResponseSchema),
+			}}
+			params.ToolChoice = &anthropicToolChoice{Type: "tool", Name: anthropicStructuredOutputTool}
+		}
+		entries = append(entries, anthropicBatchRequestEntry{CustomID: r.CustomID, Params: params})
+	}
+
+	payload, err := json.Marshal(anthropicBatchCreateRequest{Requests: entries})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal anthropic batch request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/messages/batches", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	p.setBatchHeaders(httpReq)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return "", p.convertNetworkError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read anthropic batch response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", p.convertStatusError(resp.StatusCode, data, resp.Header.Get("retry-after"))
+	}
+
+	var out anthropicBatchResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("failed to decode anthropic batch response: %w", err)
+	}
+	return out.ID, nil
+}
+
+// PollBatch implements BatchProvider.
+func (p *AnthropicProvider) PollBatch(ctx context.Context, jobID string) (BatchJobStatus, error) {
+	out, err := p.getBatch(ctx, jobID)
+	if err != nil {
+		return BatchJobStatus{}, err
+	}
+
+	counts := out.RequestCounts
+	status := BatchJobStatus{
+		CompletedCount: counts.Succeeded,
+		FailedCount:    counts.Errored + counts.Canceled + counts.Expired,
+		TotalCount:     counts.Processing + counts.Succeeded + counts.Errored + counts.Canceled + counts.Expired,
+	}
+	switch out.ProcessingStatus {
+	case "ended":
+		// Anthropic's batch lifecycle has no separate terminal "cancelled"
+		// status: a cancelled batch still finishes by transitioning to
+		// "ended" once every in-flight request drains, with results_url
+		// populated for whatever completed beforehand. Report it as
+		// BatchStateCompleted either way so FetchBatchResults still runs;
+		// counts.Canceled in FailedCount already reflects what didn't make
+		// it.
+		status.State = BatchStateCompleted
+	default:
+		// "in_progress" and the transient "canceling" (still draining
+		// in-flight requests after a cancel request) both mean "not done
+		// yet" - keep polling until "ended".
+		status.State = BatchStateInProgress
+	}
+	return status, nil
+}
+
+// FetchBatchResults implements BatchProvider, following the job's
+// ResultsURL (only set once it has ended) and decoding its JSONL stream.
+func (p *AnthropicProvider) FetchBatchResults(ctx context.Context, jobID string) ([]BatchResult, error) {
+	out, err := p.getBatch(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if out.ResultsURL == nil || *out.ResultsURL == "" {
+		return nil, fmt.Errorf("anthropic batch %s has no results yet", jobID)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, *out.ResultsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.setBatchHeaders(httpReq)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, p.convertNetworkError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anthropic batch results: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.convertStatusError(resp.StatusCode, data, resp.Header.Get("retry-after"))
+	}
+
+	var results []BatchResult
+	for _, line := range SplitJSONLLines(data) {
+		var rl anthropicBatchResultLine
+		if err := json.Unmarshal(line, &rl); err != nil {
+			return nil, fmt.Errorf("failed to decode anthropic batch result line: %w", err)
+		}
+		results = append(results, anthropicBatchResult(rl))
+	}
+	return results, nil
+}
+
+// anthropicBatchResult converts one decoded result line into a BatchResult.
+func anthropicBatchResult(rl anthropicBatchResultLine) BatchResult {
+	if rl.Result.Type != "succeeded" || rl.Result.Message == nil {
+		msg := rl.Result.Type
+		if rl.Result.Error != nil {
+			msg = rl.Result.Error.Error.Message
+		}
+		return BatchResult{CustomID: rl.CustomID, Err: fmt.Errorf("batch request %s: %s", rl.Result.Type, msg)}
+	}
+
+	respBody := rl.Result.Message
+	var content strings.Builder
+	for _, block := range respBody.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			if block.Name == anthropicStructuredOutputTool && len(block.Input) > 0 {
+				content.Write(block.Input)
+			}
+		}
+	}
+	return BatchResult{
+		CustomID: rl.CustomID,
+		Response: &GenerationResponse{
+			Content:      content.String(),
+			FinishReason: respBody.StopReason,
+			Usage: TokenUsage{
+				PromptTokens:     respBody.Usage.InputTokens,
+				CompletionTokens: respBody.Usage.OutputTokens,
+				TotalTokens:      respBody.Usage.InputTokens + respBody.Usage.OutputTokens,
+			},
+			Model: respBody.Model,
+		},
+	}
+}
+
+// getBatch fetches a batch job's current representation, shared by
+// PollBatch and FetchBatchResults (the latter needs it for ResultsURL).
+func (p *AnthropicProvider) getBatch(ctx context.Context, jobID string) (*anthropicBatchResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/v1/messages/batches/"+jobID, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.setBatchHeaders(httpReq)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, p.convertNetworkError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anthropic batch status: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.convertStatusError(resp.StatusCode, data, resp.Header.Get("retry-after"))
+	}
+
+	var out anthropicBatchResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode anthropic batch status: %w", err)
+	}
+	return &out, nil
+}
+
+// setBatchHeaders applies the same auth/version/override headers doRequest
+// uses, for the batch endpoints' requests that don't go through it (they
+// have no JSON request body to build alongside the headers, or in
+// FetchBatchResults's case target an entirely different host).
+func (p *AnthropicProvider) setBatchHeaders(req *http.Request) {
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// convertNetworkError converts a transport-level failure to a ProviderError.
+func (p *AnthropicProvider) convertNetworkError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &ProviderError{Kind: ErrKindTimeout, Message: "request timed out", Cause: err, Retryable: true}
+	}
+	if errors.Is(err, context.Canceled) {
+		return &ProviderError{Kind: ErrKindNetwork, Message: "request canceled", Cause: err, Retryable: false}
+	}
+	return &ProviderError{
+		Kind:      ErrKindNetwork,
+		Message:   fmt.Sprintf("network error: %v", err),
+		Cause:     err,
+		Retryable: true,
+	}
+}
+
+// convertStatusError converts a non-2xx Anthropic response to a
+// ProviderError. retryAfter is the response's Retry-After header, used
+// verbatim (as whole seconds) for a 429 when present; "" or an
+// unparseable value falls back to a 60s guess.
+func (p *AnthropicProvider) convertStatusError(statusCode int, body []byte, retryAfter string) error {
+	var errResp anthropicErrorResponse
+	message := string(body)
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+		message = errResp.Error.Message
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return NewAuthError(message)
+	case http.StatusTooManyRequests:
+		return NewRateLimitError(message, parseRetryAfter(retryAfter, 60))
+	case http.StatusBadRequest:
+		if strings.Contains(message, "context") && strings.Contains(message, "token") {
+			return &ProviderError{Kind: ErrKindContextLength, Message: message, StatusCode: statusCode}
+		}
+		return NewValidationError(message)
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return &ProviderError{Kind: ErrKindServer, Message: message, Retryable: true, StatusCode: statusCode}
+	default:
+		return NewProviderError(ErrKindUnknown, message, nil)
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+// deepseekDefaultBaseURL is DeepSeek's OpenAI-compatible chat completions
+// endpoint.
+const deepseekDefaultBaseURL = "https://api.deepseek.com"
+
+// DeepSeekProvider is a thin wrapper around OpenAIProvider: DeepSeek's
+// /v1/chat/completions API is OpenAI-compatible, so generation, streaming,
+// error handling, and token-usage reporting are all reused as-is. Only the
+// default base URL, Type(), and SupportedModels() differ.
+type DeepSeekProvider struct {
+	*OpenAIProvider
+}
+
+// NewDeepSeekProvider creates a new DeepSeek provider.
+func NewDeepSeekProvider(cfg *Config) (Provider, error) {
+	deepSeekCfg := *cfg
+	if deepSeekCfg.BaseURL == "" {
+		deepSeekCfg.BaseURL = deepseekDefaultBaseURL
+	}
+
+	inner, err := NewOpenAIProvider(&deepSeekCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	oaiProvider, ok := inner.(*OpenAIProvider)
+	if !ok {
+		return nil, NewProviderError(ErrKindUnknown, "internal: openai provider factory returned unexpected type", nil)
+	}
+	return &DeepSeekProvider{OpenAIProvider: oaiProvider}, nil
+}
+
+// Type implements Provider.Type
+func (p *DeepSeekProvider) Type() string { return "deepseek" }
+
+// SupportedModels implements Provider.SupportedModels
+func (p *DeepSeekProvider) SupportedModels() []string {
+	return []string{"deepseek-chat-3.2", "deepseek-chat", "deepseek-reasoner"}
+}
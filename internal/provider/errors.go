@@ -3,6 +3,7 @@ package provider
 import (
 	"errors"
 	"fmt"
+	"strconv"
 )
 
 // ErrorKind categorizes provider errors
@@ -107,6 +108,15 @@ func IsAuthError(err error) bool {
 	return false
 }
 
+// IsServerError checks if an error is a provider-side server error (5xx)
+func IsServerError(err error) bool {
+	var pe *ProviderError
+	if errors.As(err, &pe) {
+		return pe.Kind == ErrKindServer
+	}
+	return false
+}
+
 // IsRetryableError checks if an error can be retried
 func IsRetryableError(err error) bool {
 	var pe *ProviderError
@@ -124,3 +134,18 @@ func GetRetryAfter(err error) int {
 	}
 	return 0
 }
+
+// parseRetryAfter parses a Retry-After header value (whole seconds; the
+// HTTP-date form isn't handled, since no provider in this package sends
+// it) and falls back to def when header is empty or not a valid
+// non-negative integer.
+func parseRetryAfter(header string, def int) int {
+	if header == "" {
+		return def
+	}
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return def
+	}
+	return seconds
+}
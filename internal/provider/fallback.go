@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/multierr"
+)
+
+// failoverKinds are the ErrorKinds a FallbackProvider treats as grounds to
+// retry against the next provider in line rather than surfacing the error:
+// all four represent the current provider being unable to serve this
+// request right now (rate-limited, down, too slow, or the request doesn't
+// fit its context window), where another provider might still succeed.
+// Anything else (auth, validation, content filter, ...) is assumed to
+// affect every provider identically, so it's returned immediately.
+//
+// ErrKindNetwork isn't in this set because convertError also uses it for
+// context.Canceled (the caller gave up, not the provider) with no separate
+// marker to tell the two apart by Kind alone; isFailoverable special-cases
+// it below instead of via this map.
+var failoverKinds = map[ErrorKind]bool{
+	ErrKindRateLimit:     true,
+	ErrKindServer:        true,
+	ErrKindTimeout:       true,
+	ErrKindContextLength: true,
+}
+
+// isFailoverable reports whether err is a *ProviderError worth retrying
+// against the next provider: one of failoverKinds, or an ErrKindNetwork
+// error that isn't just the request's context being canceled.
+func isFailoverable(err error) bool {
+	var pe *ProviderError
+	if !errors.As(err, &pe) {
+		return false
+	}
+	if pe.Kind == ErrKindNetwork {
+		return !errors.Is(err, context.Canceled)
+	}
+	return failoverKinds[pe.Kind]
+}
+
+// AttemptFunc is called once per provider a FallbackProvider tries for a
+// given request, in order, including the final attempt whether it
+// succeeded or not. err is nil on success. Callers use this to record
+// provenance (which provider actually produced a sample) alongside the
+// dataset it's generating.
+type AttemptFunc func(providerName string, err error)
+
+// FallbackProvider wraps an ordered list of Providers and presents them as
+// a single Provider: Generate and GenerateStream try providers in list
+// order, moving on to the next one whenever the current one fails with an
+// error isFailoverable classifies as transient to that provider specifically
+// (see failoverKinds), and returning immediately on any other error or once
+// the last provider in the list has been tried.
+type FallbackProvider struct {
+	providers []Provider
+	onAttempt AttemptFunc
+}
+
+// NewFallbackProvider returns a FallbackProvider trying providers in the
+// given order. onAttempt may be nil. At least one provider is required.
+func NewFallbackProvider(providers []Provider, onAttempt AttemptFunc) (*FallbackProvider, error) {
+	if len(providers) == 0 {
+		return nil, NewValidationError("FallbackProvider requires at least one provider")
+	}
+	return &FallbackProvider{providers: providers, onAttempt: onAttempt}, nil
+}
+
+func (f *FallbackProvider) recordAttempt(name string, err error) {
+	if f.onAttempt != nil {
+		f.onAttempt(name, err)
+	}
+}
+
+// Generate implements Provider.Generate
+func (f *FallbackProvider) Generate(ctx context.Context, req GenerationRequest) (*GenerationResponse, error) {
+	var lastErr error
+	for i, p := range f.providers {
+		resp, err := p.Generate(ctx, req)
+		f.recordAttempt(p.Name(), err)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if i == len(f.providers)-1 || !isFailoverable(err) {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+// GenerateStream implements Provider.GenerateStream. Each attempt's chunks
+// are buffered (not forwarded to the caller) until that attempt either
+// finishes cleanly or fails: a clean finish flushes the whole buffer to the
+// output channel in order, so the caller never sees one provider's partial
+// output followed by another provider's full restart. On a failoverable
+// failure, the buffered chunks (the partial assistant turn) are discarded
+// rather than folded into the request, so the next provider generates the
+// complete response from scratch instead of continuing a conversation with
+// an incomplete assistant turn it never produced itself.
+func (f *FallbackProvider) GenerateStream(ctx context.Context, req GenerationRequest) (<-chan StreamChunk, error) {
+	out := make(chan StreamChunk, 100)
+
+	go func() {
+		defer close(out)
+
+		var lastErr error
+		for i, p := range f.providers {
+			chunks, err := p.GenerateStream(ctx, req)
+			if err != nil {
+				f.recordAttempt(p.Name(), err)
+				lastErr = err
+				if i == len(f.providers)-1 || !isFailoverable(err) {
+					out <- StreamChunk{Done: true, Error: err}
+					return
+				}
+				continue
+			}
+
+			buffered, streamErr := drainStream(chunks)
+			f.recordAttempt(p.Name(), streamErr)
+			if streamErr == nil {
+				for _, c := range buffered {
+					out <- c
+				}
+				return
+			}
+			lastErr = streamErr
+			if i == len(f.providers)-1 || !isFailoverable(streamErr) {
+				out <- StreamChunk{Done: true, Error: streamErr}
+				return
+			}
+		}
+		out <- StreamChunk{Done: true, Error: lastErr}
+	}()
+
+	return out, nil
+}
+
+// drainStream collects every chunk from ch, stopping (per the Provider.
+// GenerateStream contract) at whichever comes first: a chunk carrying
+// Error, whose error is returned instead of the partial chunk list, or the
+// channel closing after a Done chunk, whose chunks are returned in full.
+func drainStream(ch <-chan StreamChunk) ([]StreamChunk, error) {
+	var buffered []StreamChunk
+	for c := range ch {
+		if c.Error != nil {
+			return nil, c.Error
+		}
+		buffered = append(buffered, c)
+	}
+	return buffered, nil
+}
+
+// Name implements Provider.Name
+func (f *FallbackProvider) Name() string {
+	return f.providers[0].Name()
+}
+
+// Type implements Provider.Type
+func (f *FallbackProvider) Type() string {
+	return "fallback"
+}
+
+// Model implements Provider.Model, returning the primary (first) provider's
+// model; callers that need to know which model actually served a given
+// request should use the AttemptFunc hook instead.
+func (f *FallbackProvider) Model() string {
+	return f.providers[0].Model()
+}
+
+// SupportsStreaming implements Provider.SupportsStreaming, true only if
+// every wrapped provider supports it, since a mid-list provider without
+// streaming support would silently break failover partway through.
+func (f *FallbackProvider) SupportsStreaming() bool {
+	for _, p := range f.providers {
+		if !p.SupportsStreaming() {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsStructuredOutput implements Provider.SupportsStructuredOutput,
+// true only if every wrapped provider supports it, for the same reason as
+// SupportsStreaming.
+func (f *FallbackProvider) SupportsStructuredOutput() bool {
+	for _, p := range f.providers {
+		if !p.SupportsStructuredOutput() {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate implements Provider.Validate, accumulating every wrapped
+// provider's validation errors instead of stopping at the first one.
+func (f *FallbackProvider) Validate() error {
+	var errs error
+	for _, p := range f.providers {
+		errs = multierr.Append(errs, p.Validate())
+	}
+	return errs
+}
+
+// HealthCheck implements Provider.HealthCheck, succeeding as soon as one
+// wrapped provider does, since that's enough for Generate/GenerateStream to
+// eventually succeed. Returns the accumulated errors only if every provider
+// is unhealthy.
+func (f *FallbackProvider) HealthCheck(ctx context.Context) error {
+	var errs error
+	for _, p := range f.providers {
+		err := p.HealthCheck(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = multierr.Append(errs, err)
+	}
+	return errs
+}
+
+// Close implements Provider.Close, closing every wrapped provider and
+// joining any errors.
+func (f *FallbackProvider) Close() error {
+	var errs error
+	for _, p := range f.providers {
+		errs = multierr.Append(errs, p.Close())
+	}
+	return errs
+}
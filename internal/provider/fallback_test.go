@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// scriptedFallbackProvider is a minimal Provider used to drive
+// FallbackProvider's retry logic: Generate/GenerateStream return genErr
+// (or, for streaming, emit a chunk carrying streamErr) when set, otherwise
+// a fixed successful response/stream.
+type scriptedFallbackProvider struct {
+	name      string
+	genErr    error
+	streamErr error
+	healthErr error
+	calls     int
+}
+
+func (p *scriptedFallbackProvider) Generate(ctx context.Context, req GenerationRequest) (*GenerationResponse, error) {
+	p.calls++
+	if p.genErr != nil {
+		return nil, p.genErr
+	}
+	return &GenerationResponse{Content: "from " + p.name}, nil
+}
+
+func (p *scriptedFallbackProvider) GenerateStream(ctx context.Context, req GenerationRequest) (<-chan StreamChunk, error) {
+	p.calls++
+	out := make(chan StreamChunk, 4)
+	go func() {
+		defer close(out)
+		out <- StreamChunk{Content: "partial from " + p.name}
+		if p.streamErr != nil {
+			out <- StreamChunk{Error: p.streamErr}
+			return
+		}
+		out <- StreamChunk{Done: true, FinishReason: "stop"}
+	}()
+	return out, nil
+}
+
+func (p *scriptedFallbackProvider) Name() string                   { return p.name }
+func (p *scriptedFallbackProvider) Type() string                   { return "scripted" }
+func (p *scriptedFallbackProvider) Model() string                  { return "scripted-model" }
+func (p *scriptedFallbackProvider) SupportsStreaming() bool        { return true }
+func (p *scriptedFallbackProvider) SupportsStructuredOutput() bool { return true }
+func (p *scriptedFallbackProvider) Validate() error                { return nil }
+func (p *scriptedFallbackProvider) HealthCheck(ctx context.Context) error {
+	return p.healthErr
+}
+func (p *scriptedFallbackProvider) Close() error { return nil }
+
+func TestFallbackProvider_Generate_FailsOverOnRetryableError(t *testing.T) {
+	first := &scriptedFallbackProvider{name: "first", genErr: NewRateLimitError("busy", 1)}
+	second := &scriptedFallbackProvider{name: "second"}
+
+	var attempts []string
+	fp, err := NewFallbackProvider([]Provider{first, second}, func(name string, err error) {
+		attempts = append(attempts, name)
+	})
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+
+	resp, err := fp.Generate(context.Background(), GenerationRequest{})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if resp.Content != "from second" {
+		t.Errorf("Content = %q, want %q", resp.Content, "from second")
+	}
+	if len(attempts) != 2 || attempts[0] != "first" || attempts[1] != "second" {
+		t.Errorf("attempts = %v, want [first second]", attempts)
+	}
+}
+
+func TestFallbackProvider_Generate_FailsOverOnNetworkError(t *testing.T) {
+	first := &scriptedFallbackProvider{name: "first", genErr: NewProviderError(ErrKindNetwork, "connection refused", errors.New("dial tcp: connection refused"))}
+	second := &scriptedFallbackProvider{name: "second"}
+
+	fp, err := NewFallbackProvider([]Provider{first, second}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+
+	resp, err := fp.Generate(context.Background(), GenerationRequest{})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if resp.Content != "from second" {
+		t.Errorf("Content = %q, want %q", resp.Content, "from second")
+	}
+}
+
+func TestFallbackProvider_Generate_DoesNotFailOverOnContextCanceled(t *testing.T) {
+	first := &scriptedFallbackProvider{name: "first", genErr: &ProviderError{Kind: ErrKindNetwork, Message: "request canceled", Cause: context.Canceled}}
+	second := &scriptedFallbackProvider{name: "second"}
+
+	fp, err := NewFallbackProvider([]Provider{first, second}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+
+	if _, err := fp.Generate(context.Background(), GenerationRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Generate error = %v, want context.Canceled", err)
+	}
+	if second.calls != 0 {
+		t.Errorf("second.calls = %d, want 0 (a canceled context should not trigger failover)", second.calls)
+	}
+}
+
+func TestFallbackProvider_Generate_DoesNotFailOverOnNonFailoverableError(t *testing.T) {
+	first := &scriptedFallbackProvider{name: "first", genErr: NewAuthError("bad key")}
+	second := &scriptedFallbackProvider{name: "second"}
+
+	fp, err := NewFallbackProvider([]Provider{first, second}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+
+	if _, err := fp.Generate(context.Background(), GenerationRequest{}); !IsAuthError(err) {
+		t.Errorf("Generate error = %v, want an auth error", err)
+	}
+	if second.calls != 0 {
+		t.Errorf("second.calls = %d, want 0 (should not be tried for a non-failoverable error)", second.calls)
+	}
+}
+
+func TestFallbackProvider_Generate_ReturnsLastErrorWhenAllFail(t *testing.T) {
+	first := &scriptedFallbackProvider{name: "first", genErr: NewProviderError(ErrKindServer, "down", nil)}
+	last := NewProviderError(ErrKindServer, "also down", nil)
+	second := &scriptedFallbackProvider{name: "second", genErr: last}
+
+	fp, err := NewFallbackProvider([]Provider{first, second}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+
+	if _, err := fp.Generate(context.Background(), GenerationRequest{}); !errors.Is(err, last) {
+		t.Errorf("Generate error = %v, want %v", err, last)
+	}
+}
+
+func TestFallbackProvider_GenerateStream_FailsOverAndFlushesOnlyWinningAttempt(t *testing.T) {
+	first := &scriptedFallbackProvider{name: "first", streamErr: NewProviderError(ErrKindTimeout, "timed out", nil)}
+	second := &scriptedFallbackProvider{name: "second"}
+
+	fp, err := NewFallbackProvider([]Provider{first, second}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+
+	ch, err := fp.GenerateStream(context.Background(), GenerationRequest{})
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+
+	var content string
+	var sawDone bool
+	for c := range ch {
+		if c.Error != nil {
+			t.Fatalf("unexpected chunk error: %v", c.Error)
+		}
+		content += c.Content
+		if c.Done {
+			sawDone = true
+		}
+	}
+	if !sawDone {
+		t.Error("stream never sent a Done chunk")
+	}
+	if content != "partial from second" {
+		t.Errorf("content = %q, want only the winning attempt's content, got the failed attempt's too", content)
+	}
+}
+
+func TestFallbackProvider_SupportsStreaming_RequiresAllProviders(t *testing.T) {
+	streaming := &scriptedFallbackProvider{name: "streaming"}
+	fp, err := NewFallbackProvider([]Provider{streaming}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+	if !fp.SupportsStreaming() {
+		t.Error("SupportsStreaming() = false, want true when every provider supports it")
+	}
+}
+
+func TestFallbackProvider_HealthCheck_SucceedsIfAnyProviderIsHealthy(t *testing.T) {
+	unhealthy := &scriptedFallbackProvider{name: "unhealthy", healthErr: errors.New("down")}
+	healthy := &scriptedFallbackProvider{name: "healthy"}
+
+	fp, err := NewFallbackProvider([]Provider{unhealthy, healthy}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+	if err := fp.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() = %v, want nil since one provider is healthy", err)
+	}
+}
+
+func TestFallbackProvider_HealthCheck_FailsIfEveryProviderIsUnhealthy(t *testing.T) {
+	a := &scriptedFallbackProvider{name: "a", healthErr: errors.New("down a")}
+	b := &scriptedFallbackProvider{name: "b", healthErr: errors.New("down b")}
+
+	fp, err := NewFallbackProvider([]Provider{a, b}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackProvider failed: %v", err)
+	}
+	if err := fp.HealthCheck(context.Background()); err == nil {
+		t.Error("HealthCheck() = nil, want an error since every provider is unhealthy")
+	}
+}
+
+func TestNewFallbackProvider_RequiresAtLeastOneProvider(t *testing.T) {
+	if _, err := NewFallbackProvider(nil, nil); err == nil {
+		t.Error("NewFallbackProvider(nil, nil) = nil error, want a validation error")
+	}
+}
@@ -2,11 +2,14 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,12 +18,42 @@ import (
 	"github.com/openai/openai-go/v3/shared"
 )
 
+// SystemRoleMode configures how system content (GenerationRequest.SystemPrompt
+// and any Role == "system" Messages) is sent to a model, since some models
+// (OpenAI's o1 family) reject the system role entirely.
+type SystemRoleMode string
+
+const (
+	// SystemRoleAuto picks a mode based on the configured model: o1-family
+	// models (which reject "system") get SystemRoleUserPrefix, everything
+	// else gets SystemRoleSystem. The default when unset.
+	SystemRoleAuto SystemRoleMode = "auto"
+
+	// SystemRoleSystem sends system content as a "system" role message,
+	// unchanged.
+	SystemRoleSystem SystemRoleMode = "system"
+
+	// SystemRoleUserPrefix prepends system content to the first user
+	// message's content instead of sending a separate message, matching
+	// the "-u/--user-instead-of-system" workaround some tools use against
+	// models that reject the system role.
+	SystemRoleUserPrefix SystemRoleMode = "user-prefix"
+
+	// SystemRoleDeveloper sends system content as the newer "developer"
+	// role, OpenAI's replacement for "system" on reasoning models.
+	SystemRoleDeveloper SystemRoleMode = "developer"
+
+	// SystemRoleDrop omits system content entirely.
+	SystemRoleDrop SystemRoleMode = "drop"
+)
+
 // OpenAIProvider implements the Provider interface for OpenAI and compatible APIs
 type OpenAIProvider struct {
-	name   string
-	model  string
-	apiKey string
-	client *openai.Client
+	name           string
+	model          string
+	apiKey         string
+	systemRoleMode SystemRoleMode
+	client         *openai.Client
 }
 
 // NewOpenAIProvider creates a new OpenAI-compatible provider
@@ -44,43 +77,104 @@ func NewOpenAIProvider(cfg *Config) (Provider, error) {
 		opts = append(opts, option.WithHTTPClient(httpClient))
 	}
 
+	// Config.Headers is applied last so it can override the provider's
+	// built-in auth/version headers (e.g. a custom "OpenAI-Organization").
+	for k, v := range cfg.Headers {
+		opts = append(opts, option.WithHeader(k, v))
+	}
+
 	client := openai.NewClient(opts...)
 
 	return &OpenAIProvider{
-		name:   cfg.Name,
-		model:  cfg.Model,
-		apiKey: cfg.APIKey,
-		client: &client,
+		name:           cfg.Name,
+		model:          cfg.Model,
+		apiKey:         cfg.APIKey,
+		systemRoleMode: cfg.SystemRoleMode,
+		client:         &client,
 	}, nil
 }
 
-// Generate implements Provider.Generate
-func (p *OpenAIProvider) Generate(ctx context.Context, req GenerationRequest) (*GenerationResponse, error) {
-	start := time.Now()
-
-	// Convert messages
-	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(req.Messages)+1)
+// resolvedSystemRoleMode returns p.systemRoleMode, resolving SystemRoleAuto
+// (and the unset zero value, which defaults to it) against p.model: the o1
+// family rejects the system role outright, so it gets SystemRoleUserPrefix;
+// everything else keeps SystemRoleSystem.
+func (p *OpenAIProvider) resolvedSystemRoleMode() SystemRoleMode {
+	mode := p.systemRoleMode
+	if mode == "" {
+		mode = SystemRoleAuto
+	}
+	if mode != SystemRoleAuto {
+		return mode
+	}
+	model := strings.ToLower(p.model)
+	if strings.HasPrefix(model, "o1") {
+		return SystemRoleUserPrefix
+	}
+	return SystemRoleSystem
+}
 
-	// Add system prompt if provided
+// buildMessages converts req into the OpenAI SDK's message list, applying
+// mode to req.SystemPrompt and any Role == "system" entries in req.Messages
+// before they reach convertMessage. This is the one place both Generate and
+// GenerateStream route system content through, so the role-conversion table
+// isn't a switch buried in two places.
+func buildMessages(req GenerationRequest, mode SystemRoleMode) []openai.ChatCompletionMessageParamUnion {
+	// Collect system content, draining Role == "system" messages out of
+	// the conversation list in the process (the block below already
+	// emits them, so convertMessage shouldn't see them twice).
+	var systemParts []string
 	if req.SystemPrompt != "" {
-		messages = append(messages, openai.SystemMessage(req.SystemPrompt))
+		systemParts = append(systemParts, req.SystemPrompt)
 	}
-
-	// Add conversation messages
+	rest := make([]Message, 0, len(req.Messages))
 	for _, msg := range req.Messages {
-		role := strings.ToLower(msg.Role)
-		switch role {
-		case "system":
-			messages = append(messages, openai.SystemMessage(msg.Content))
-		case "user", "human":
-			messages = append(messages, openai.UserMessage(msg.Content))
-		case "assistant", "ai", "bot":
-			messages = append(messages, openai.AssistantMessage(msg.Content))
+		if strings.EqualFold(msg.Role, "system") {
+			if msg.Content != "" {
+				systemParts = append(systemParts, msg.Content)
+			}
+			continue
+		}
+		rest = append(rest, msg)
+	}
+	systemContent := strings.Join(systemParts, "\n\n")
+
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(rest)+1)
+
+	if systemContent != "" && mode != SystemRoleDrop && mode != SystemRoleUserPrefix {
+		switch mode {
+		case SystemRoleDeveloper:
+			messages = append(messages, openai.DeveloperMessage(systemContent))
 		default:
-			messages = append(messages, openai.UserMessage(msg.Content))
+			messages = append(messages, openai.SystemMessage(systemContent))
 		}
 	}
 
+	userPrefixed := false
+	for _, msg := range rest {
+		if systemContent != "" && mode == SystemRoleUserPrefix && !userPrefixed && strings.EqualFold(msg.Role, "user") {
+			msg.Content = systemContent + "\n\n" + msg.Content
+			userPrefixed = true
+		}
+		messages = append(messages, convertMessage(msg))
+	}
+
+	// No user message to prefix onto (e.g. a system-only request): fall
+	// back to sending it as its own user message rather than dropping it.
+	if systemContent != "" && mode == SystemRoleUserPrefix && !userPrefixed {
+		messages = append(messages, openai.UserMessage(systemContent))
+	}
+
+	return messages
+}
+
+// Generate implements Provider.Generate
+func (p *OpenAIProvider) Generate(ctx context.Context, req GenerationRequest) (*GenerationResponse, error) {
+	start := time.Now()
+
+	// Convert messages, routing system content through the configured
+	// SystemRoleMode.
+	messages := buildMessages(req, p.resolvedSystemRoleMode())
+
 	// Build request parameters
 	params := openai.ChatCompletionNewParams{
 		Model:       openai.ChatModel(p.model),
@@ -103,14 +197,20 @@ func (p *OpenAIProvider) Generate(ctx context.Context, req GenerationRequest) (*
 	if req.PresencePenalty != 0 {
 		params.PresencePenalty = openai.Float(req.PresencePenalty)
 	}
-	if req.ResponseFormat == "json" {
-		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
-			OfJSONObject: &shared.ResponseFormatJSONObjectParam{Type: "json_object"},
+	if len(req.Tools) > 0 {
+		params.Tools = convertTools(req.Tools)
+		if choice, ok := convertToolChoice(req.ToolChoice); ok {
+			params.ToolChoice = choice
 		}
 	}
+	if format, ok := buildResponseFormat(req); ok {
+		params.ResponseFormat = format
+	}
 
-	// Make request
-	resp, err := p.client.Chat.Completions.New(ctx, params)
+	// Make request, capturing the raw HTTP response so we can also read
+	// the x-ratelimit-* headers off it.
+	var httpResp *http.Response
+	resp, err := p.client.Chat.Completions.New(ctx, params, option.WithResponseInto(&httpResp))
 	if err != nil {
 		return nil, p.convertError(err)
 	}
@@ -132,31 +232,171 @@ func (p *OpenAIProvider) Generate(ctx context.Context, req GenerationRequest) (*
 		Model:     resp.Model,
 		RequestID: resp.ID,
 		Latency:   time.Since(start),
+		ToolCalls: convertResponseToolCalls(choice.Message.ToolCalls),
+		RateLimit: parseRateLimitHeaders(httpResp),
 	}, nil
 }
 
-// GenerateStream implements Provider.GenerateStream
-func (p *OpenAIProvider) GenerateStream(ctx context.Context, req GenerationRequest) (<-chan StreamChunk, error) {
-	// Convert messages
-	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(req.Messages)+1)
+// buildResponseFormat derives the chat completion's response_format from
+// req, preferring a strict JSON Schema (req.ResponseSchema) over the plain
+// "json_object" mode (req.ResponseFormat == "json") when both are set,
+// since a schema is strictly more constrained. ok is false if neither
+// applies, or ResponseSchema is set but isn't valid JSON, in which case
+// callers should leave response_format unset rather than send a malformed
+// request.
+func buildResponseFormat(req GenerationRequest) (openai.ChatCompletionNewParamsResponseFormatUnion, bool) {
+	if len(req.ResponseSchema) > 0 {
+		var parsedSchema map[string]any
+		if err := json.Unmarshal(req.ResponseSchema, &parsedSchema); err != nil {
+			return openai.ChatCompletionNewParamsResponseFormatUnion{}, false
+		}
+		return openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				Type: "json_schema",
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "kothaset_sample",
+					Strict: openai.Bool(true),
+					Schema: parsedSchema,
+				},
+			},
+		}, true
+	}
+	if req.ResponseFormat == "json" {
+		return openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONObject: &shared.ResponseFormatJSONObjectParam{Type: "json_object"},
+		}, true
+	}
+	return openai.ChatCompletionNewParamsResponseFormatUnion{}, false
+}
 
-	if req.SystemPrompt != "" {
-		messages = append(messages, openai.SystemMessage(req.SystemPrompt))
+// parseRateLimitHeaders reads OpenAI's x-ratelimit-* response headers into
+// a RateLimitInfo, surfacing the provider's rate-limit headroom alongside
+// every response so a scheduler could eventually back off before hitting a
+// 429 instead of only reacting to one in convertError (not wired up yet).
+// Returns nil if resp is nil or none of the headers are present (e.g. a
+// compatible API that doesn't send them).
+func parseRateLimitHeaders(resp *http.Response) *RateLimitInfo {
+	if resp == nil {
+		return nil
 	}
 
-	for _, msg := range req.Messages {
-		role := strings.ToLower(msg.Role)
-		switch role {
-		case "system":
-			messages = append(messages, openai.SystemMessage(msg.Content))
-		case "user", "human":
-			messages = append(messages, openai.UserMessage(msg.Content))
-		case "assistant", "ai", "bot":
-			messages = append(messages, openai.AssistantMessage(msg.Content))
-		default:
-			messages = append(messages, openai.UserMessage(msg.Content))
+	info := RateLimitInfo{
+		RemainingRequests: parseRateLimitInt(resp.Header.Get("x-ratelimit-remaining-requests")),
+		RemainingTokens:   parseRateLimitInt(resp.Header.Get("x-ratelimit-remaining-tokens")),
+		ResetRequests:     resp.Header.Get("x-ratelimit-reset-requests"),
+		ResetTokens:       resp.Header.Get("x-ratelimit-reset-tokens"),
+	}
+	if info == (RateLimitInfo{}) {
+		return nil
+	}
+	return &info
+}
+
+func parseRateLimitInt(v string) int {
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+// convertMessage maps a provider-neutral Message to the OpenAI SDK's
+// role-specific message param union, replaying ToolCalls/ToolCallID so a
+// multi-turn request can include a prior function-calling exchange.
+func convertMessage(msg Message) openai.ChatCompletionMessageParamUnion {
+	switch role := strings.ToLower(msg.Role); role {
+	case "system":
+		return openai.SystemMessage(msg.Content)
+	case "user", "human":
+		return openai.UserMessage(msg.Content)
+	case "assistant", "ai", "bot":
+		if len(msg.ToolCalls) == 0 {
+			return openai.AssistantMessage(msg.Content)
+		}
+		param := openai.ChatCompletionMessageParamUnion{OfAssistant: &openai.ChatCompletionAssistantMessageParam{}}
+		if msg.Content != "" {
+			param.OfAssistant.Content.OfString = openai.String(msg.Content)
+		}
+		for _, call := range msg.ToolCalls {
+			param.OfAssistant.ToolCalls = append(param.OfAssistant.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+				OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+					ID: call.ID,
+					Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+						Name:      call.Name,
+						Arguments: call.Arguments,
+					},
+				},
+			})
+		}
+		return param
+	case "tool":
+		return openai.ToolMessage(msg.Content, msg.ToolCallID)
+	default:
+		return openai.UserMessage(msg.Content)
+	}
+}
+
+// convertTools maps ToolDefinition to the OpenAI SDK's function-tool
+// union, passing Parameters through as a raw JSON Schema object.
+func convertTools(tools []ToolDefinition) []openai.ChatCompletionToolUnionParam {
+	out := make([]openai.ChatCompletionToolUnionParam, 0, len(tools))
+	for _, t := range tools {
+		def := shared.FunctionDefinitionParam{Name: t.Name}
+		if t.Description != "" {
+			def.Description = openai.String(t.Description)
+		}
+		if len(t.Parameters) > 0 {
+			var params map[string]any
+			if err := json.Unmarshal(t.Parameters, &params); err == nil {
+				def.Parameters = params
+			}
+		}
+		out = append(out, openai.ChatCompletionFunctionTool(def))
+	}
+	return out
+}
+
+// convertToolChoice maps GenerationRequest.ToolChoice to the SDK's
+// tool_choice union. ok is false for the empty string, which leaves
+// tool_choice unset so the API applies its own default ("auto").
+func convertToolChoice(choice string) (openai.ChatCompletionToolChoiceOptionUnionParam, bool) {
+	switch choice {
+	case "":
+		return openai.ChatCompletionToolChoiceOptionUnionParam{}, false
+	case "auto", "none", "required":
+		return openai.ChatCompletionToolChoiceOptionUnionParam{
+			OfAuto: openai.String(choice),
+		}, true
+	default:
+		return openai.ToolChoiceOptionFunctionToolChoice(openai.ChatCompletionNamedToolChoiceFunctionParam{
+			Name: choice,
+		}), true
+	}
+}
+
+// convertResponseToolCalls maps the OpenAI SDK's tool call union to the
+// provider-neutral ToolCall shape; custom (non-function) tool calls are
+// skipped since GenerationRequest only ever offers function tools.
+func convertResponseToolCalls(calls []openai.ChatCompletionMessageToolCallUnion) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		if c.Type != "function" {
+			continue
 		}
+		out = append(out, ToolCall{
+			ID:        c.ID,
+			Name:      c.Function.Name,
+			Arguments: c.Function.Arguments,
+		})
 	}
+	return out
+}
+
+// GenerateStream implements Provider.GenerateStream
+func (p *OpenAIProvider) GenerateStream(ctx context.Context, req GenerationRequest) (<-chan StreamChunk, error) {
+	// Convert messages, routing system content through the configured
+	// SystemRoleMode.
+	messages := buildMessages(req, p.resolvedSystemRoleMode())
 
 	// Build request parameters
 	params := openai.ChatCompletionNewParams{
@@ -165,9 +405,25 @@ func (p *OpenAIProvider) GenerateStream(ctx context.Context, req GenerationReque
 		MaxTokens:   openai.Int(int64(req.MaxTokens)),
 		Temperature: openai.Float(req.Temperature),
 	}
+	if len(req.Tools) > 0 {
+		params.Tools = convertTools(req.Tools)
+		if choice, ok := convertToolChoice(req.ToolChoice); ok {
+			params.ToolChoice = choice
+		}
+	}
+	if format, ok := buildResponseFormat(req); ok {
+		params.ResponseFormat = format
+	}
+	// Ask for a final usage-only chunk so streaming callers get the same
+	// token accounting Generate gets, instead of discarding usage entirely.
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
 
-	// Create stream
-	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	// Create stream, capturing the raw HTTP response for its
+	// x-ratelimit-* headers the same way Generate does.
+	var httpResp *http.Response
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params, option.WithResponseInto(&httpResp))
 
 	// Create output channel
 	out := make(chan StreamChunk, 100)
@@ -175,30 +431,46 @@ func (p *OpenAIProvider) GenerateStream(ctx context.Context, req GenerationReque
 	go func() {
 		defer close(out)
 
+		var usage *TokenUsage
+		finishReason := "stop"
 		for stream.Next() {
 			evt := stream.Current()
+			if evt.Usage.TotalTokens > 0 {
+				usage = &TokenUsage{
+					PromptTokens:     int(evt.Usage.PromptTokens),
+					CompletionTokens: int(evt.Usage.CompletionTokens),
+					TotalTokens:      int(evt.Usage.TotalTokens),
+				}
+			}
 			if len(evt.Choices) > 0 {
 				choice := evt.Choices[0]
-				chunk := StreamChunk{
-					Content: choice.Delta.Content,
+				if choice.Delta.Content != "" {
+					out <- StreamChunk{Content: choice.Delta.Content}
 				}
 				if choice.FinishReason != "" {
-					chunk.Done = true
-					chunk.FinishReason = string(choice.FinishReason)
+					finishReason = string(choice.FinishReason)
+				}
+				for _, tc := range choice.Delta.ToolCalls {
+					out <- StreamChunk{
+						ToolCallDelta: &ToolCallDelta{
+							Index:     int(tc.Index),
+							ID:        tc.ID,
+							Name:      tc.Function.Name,
+							Arguments: tc.Function.Arguments,
+						},
+					}
 				}
-				out <- chunk
 			}
 		}
 
-		if err := stream.Err(); err != nil {
-			if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
-				out <- StreamChunk{Done: true, FinishReason: "stop"}
-			} else {
-				out <- StreamChunk{Done: true, Error: p.convertError(err)}
-			}
-		} else {
-			out <- StreamChunk{Done: true, FinishReason: "stop"}
+		// Exactly one terminal chunk carries Done, so a consumer that
+		// stops reading as soon as it sees Done (e.g. the gRPC plugin
+		// client) doesn't miss Usage/RateLimit on a second one.
+		if err := stream.Err(); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+			out <- StreamChunk{Done: true, Error: p.convertError(err)}
+			return
 		}
+		out <- StreamChunk{Done: true, FinishReason: finishReason, Usage: usage, RateLimit: parseRateLimitHeaders(httpResp)}
 	}()
 
 	return out, nil
@@ -234,9 +506,14 @@ func (p *OpenAIProvider) SupportsStreaming() bool {
 	return true
 }
 
+// SupportsStructuredOutput implements Provider.SupportsStructuredOutput
+func (p *OpenAIProvider) SupportsStructuredOutput() bool {
+	return true
+}
+
 // SupportsBatching implements Provider.SupportsBatching
 func (p *OpenAIProvider) SupportsBatching() bool {
-	return false // OpenAI batch API has different semantics
+	return true // see BatchProvider below
 }
 
 // Validate implements Provider.Validate
@@ -266,6 +543,229 @@ func (p *OpenAIProvider) Close() error {
 	return nil
 }
 
+// openAIBatchLine is one line of the JSONL file OpenAI's Batch API expects
+// as its input file: a CustomID the output can be matched back to, plus
+// the same request body Generate would send to /v1/chat/completions.
+type openAIBatchLine struct {
+	CustomID string                         `json:"custom_id"`
+	Method   string                         `json:"method"`
+	URL      string                         `json:"url"`
+	Body     openai.ChatCompletionNewParams `json:"body"`
+}
+
+// SubmitBatch implements BatchProvider. It uploads requests as a single
+// JSONL input file and creates a batch job against it, mirroring Generate's
+// request construction (minus streaming, which the Batch API has no
+// concept of).
+func (p *OpenAIProvider) SubmitBatch(ctx context.Context, requests []BatchRequest) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	mode := p.resolvedSystemRoleMode()
+	for _, r := range requests {
+		params := openai.ChatCompletionNewParams{
+			Model:       openai.ChatModel(p.model),
+			Messages:    buildMessages(r.Request, mode),
+			MaxTokens:   openai.Int(int64(r.Request.MaxTokens)),
+			Temperature: openai.Float(r.Request.Temperature),
+		}
+		if r.Request.TopP > 0 {
+			params.TopP = openai.Float(r.Request.TopP)
+		}
+		if len(r.Request.StopSequences) > 0 {
+			params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: r.Request.StopSequences}
+		}
+		if r.Request.Seed != nil {
+			params.Seed = openai.Int(*r.Request.Seed)
+		}
+		if format, ok := buildResponseFormat(r.Request); ok {
+			params.ResponseFormat = format
+		}
+		if err := enc.Encode(openAIBatchLine{
+			CustomID: r.CustomID,
+			Method:   http.MethodPost,
+			URL:      string(openai.BatchNewParamsEndpointV1ChatCompletions),
+			Body:     params,
+		}); err != nil {
+			return "", fmt.Errorf("failed to encode batch request %q: %w", r.CustomID, err)
+		}
+	}
+
+	file, err := p.client.Files.New(ctx, openai.FileNewParams{
+		File:    &buf,
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		return "", p.convertError(err)
+	}
+
+	batch, err := p.client.Batches.New(ctx, openai.BatchNewParams{
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		InputFileID:      file.ID,
+	})
+	if err != nil {
+		return "", p.convertError(err)
+	}
+	return batch.ID, nil
+}
+
+// PollBatch implements BatchProvider.
+func (p *OpenAIProvider) PollBatch(ctx context.Context, jobID string) (BatchJobStatus, error) {
+	batch, err := p.client.Batches.Get(ctx, jobID)
+	if err != nil {
+		return BatchJobStatus{}, p.convertError(err)
+	}
+
+	status := BatchJobStatus{
+		CompletedCount: int(batch.RequestCounts.Completed),
+		FailedCount:    int(batch.RequestCounts.Failed),
+		TotalCount:     int(batch.RequestCounts.Total),
+	}
+	switch batch.Status {
+	case openai.BatchStatusCompleted:
+		status.State = BatchStateCompleted
+	case openai.BatchStatusFailed:
+		status.State = BatchStateFailed
+	case openai.BatchStatusExpired:
+		status.State = BatchStateExpired
+	case openai.BatchStatusCancelled, openai.BatchStatusCancelling:
+		status.State = BatchStateCancelled
+	default:
+		// validating, in_progress, finalizing
+		status.State = BatchStateInProgress
+	}
+	return status, nil
+}
+
+// openAIBatchResultLine is one line of the JSONL output/error files OpenAI
+// writes the completed batch's results to.
+type openAIBatchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int            `json:"status_code"`
+		Body       ChatCompletion `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ChatCompletion mirrors the OpenAI SDK's response shape closely enough to
+// decode a batch output line's body; defined locally (rather than reusing
+// openai.ChatCompletion directly) to avoid depending on its JSON field
+// metadata, which isn't meant for direct unmarshaling outside the SDK's own
+// request plumbing.
+type ChatCompletion struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		FinishReason string `json:"finish_reason"`
+		Message      struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// FetchBatchResults implements BatchProvider, downloading the job's output
+// file (successful requests) and error file (failed ones) and decoding both
+// into BatchResults keyed by CustomID.
+func (p *OpenAIProvider) FetchBatchResults(ctx context.Context, jobID string) ([]BatchResult, error) {
+	batch, err := p.client.Batches.Get(ctx, jobID)
+	if err != nil {
+		return nil, p.convertError(err)
+	}
+
+	var results []BatchResult
+	if batch.OutputFileID != "" {
+		lines, err := p.downloadBatchFile(ctx, batch.OutputFileID)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			var rl openAIBatchResultLine
+			if err := json.Unmarshal(line, &rl); err != nil {
+				return nil, fmt.Errorf("failed to decode batch output line: %w", err)
+			}
+			results = append(results, p.batchResultFromLine(rl))
+		}
+	}
+	if batch.ErrorFileID != "" {
+		lines, err := p.downloadBatchFile(ctx, batch.ErrorFileID)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			var rl openAIBatchResultLine
+			if err := json.Unmarshal(line, &rl); err != nil {
+				return nil, fmt.Errorf("failed to decode batch error line: %w", err)
+			}
+			results = append(results, p.batchResultFromLine(rl))
+		}
+	}
+	return results, nil
+}
+
+// batchResultFromLine converts one decoded JSONL line (from either the
+// output or the error file) into a BatchResult.
+func (p *OpenAIProvider) batchResultFromLine(rl openAIBatchResultLine) BatchResult {
+	if rl.Error != nil {
+		return BatchResult{CustomID: rl.CustomID, Err: fmt.Errorf("batch request failed: %s: %s", rl.Error.Code, rl.Error.Message)}
+	}
+	if rl.Response == nil || rl.Response.StatusCode != http.StatusOK || len(rl.Response.Body.Choices) == 0 {
+		return BatchResult{CustomID: rl.CustomID, Err: fmt.Errorf("batch request returned no usable response (status %d)", statusCodeOf(rl.Response))}
+	}
+
+	choice := rl.Response.Body.Choices[0]
+	body := rl.Response.Body
+	return BatchResult{
+		CustomID: rl.CustomID,
+		Response: &GenerationResponse{
+			Content:      choice.Message.Content,
+			FinishReason: choice.FinishReason,
+			Usage: TokenUsage{
+				PromptTokens:     body.Usage.PromptTokens,
+				CompletionTokens: body.Usage.CompletionTokens,
+				TotalTokens:      body.Usage.TotalTokens,
+			},
+			Model: body.Model,
+		},
+	}
+}
+
+// statusCodeOf reads the status code off an openAIBatchResultLine.Response
+// that may be nil (e.g. a line that only carried an error).
+func statusCodeOf(resp *struct {
+	StatusCode int            `json:"status_code"`
+	Body       ChatCompletion `json:"body"`
+}) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// downloadBatchFile fetches a batch input/output/error file's content and
+// splits it into its JSONL lines.
+func (p *OpenAIProvider) downloadBatchFile(ctx context.Context, fileID string) ([][]byte, error) {
+	resp, err := p.client.Files.Content(ctx, fileID)
+	if err != nil {
+		return nil, p.convertError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch file %s: %w", fileID, err)
+	}
+
+	return SplitJSONLLines(data), nil
+}
+
 // convertError converts OpenAI SDK errors to ProviderError
 func (p *OpenAIProvider) convertError(err error) error {
 	if err == nil {
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestBuildResponseFormat(t *testing.T) {
+	t.Run("no schema or format leaves response_format unset", func(t *testing.T) {
+		_, ok := buildResponseFormat(GenerationRequest{})
+		if ok {
+			t.Error("ok = true, want false")
+		}
+	})
+
+	t.Run("json format requests json_object", func(t *testing.T) {
+		format, ok := buildResponseFormat(GenerationRequest{ResponseFormat: "json"})
+		if !ok {
+			t.Fatal("ok = false, want true")
+		}
+		if format.OfJSONObject == nil {
+			t.Error("expected OfJSONObject to be set")
+		}
+	})
+
+	t.Run("response schema requests a strict json_schema", func(t *testing.T) {
+		format, ok := buildResponseFormat(GenerationRequest{ResponseSchema: []byte(`{"type":"object"}`)})
+		if !ok {
+			t.Fatal("ok = false, want true")
+		}
+		if format.OfJSONSchema == nil {
+			t.Fatal("expected OfJSONSchema to be set")
+		}
+		if !format.OfJSONSchema.JSONSchema.Strict.Value {
+			t.Error("expected Strict to be true")
+		}
+	})
+
+	t.Run("response schema takes priority over json format", func(t *testing.T) {
+		format, ok := buildResponseFormat(GenerationRequest{
+			ResponseSchema: []byte(`{"type":"object"}`),
+			ResponseFormat: "json",
+		})
+		if !ok {
+			t.Fatal("ok = false, want true")
+		}
+		if format.OfJSONSchema == nil {
+			t.Error("expected OfJSONSchema to be set, not OfJSONObject")
+		}
+	})
+
+	t.Run("malformed response schema leaves response_format unset", func(t *testing.T) {
+		_, ok := buildResponseFormat(GenerationRequest{ResponseSchema: []byte(`not json`)})
+		if ok {
+			t.Error("ok = true, want false")
+		}
+	})
+}
+
+func TestOpenAIProvider_ResolvedSystemRoleMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		mode  SystemRoleMode
+		want  SystemRoleMode
+	}{
+		{"explicit mode wins", "gpt-4o", SystemRoleDeveloper, SystemRoleDeveloper},
+		{"unset defaults to auto then system", "gpt-4o", "", SystemRoleSystem},
+		{"auto on ordinary model resolves to system", "gpt-4o", SystemRoleAuto, SystemRoleSystem},
+		{"auto on o1 resolves to user-prefix", "o1", SystemRoleAuto, SystemRoleUserPrefix},
+		{"auto on o1-mini resolves to user-prefix", "o1-mini", SystemRoleAuto, SystemRoleUserPrefix},
+		{"auto on o1-preview resolves to user-prefix", "o1-preview", SystemRoleAuto, SystemRoleUserPrefix},
+		{"unset on o1 resolves to user-prefix", "o1", "", SystemRoleUserPrefix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &OpenAIProvider{model: tt.model, systemRoleMode: tt.mode}
+			if got := p.resolvedSystemRoleMode(); got != tt.want {
+				t.Errorf("resolvedSystemRoleMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildMessages(t *testing.T) {
+	req := GenerationRequest{
+		SystemPrompt: "Be concise.",
+		Messages: []Message{
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	t.Run("system mode sends a system message", func(t *testing.T) {
+		got := buildMessages(req, SystemRoleSystem)
+		if len(got) != 2 {
+			t.Fatalf("len(messages) = %d, want 2", len(got))
+		}
+		if got[0].OfSystem == nil || got[0].OfSystem.Content.OfString.Value != "Be concise." {
+			t.Errorf("messages[0] = %+v, want a system message with %q", got[0], "Be concise.")
+		}
+		if got[1].OfUser == nil || got[1].OfUser.Content.OfString.Value != "Hello" {
+			t.Errorf("messages[1] = %+v, want a user message with %q", got[1], "Hello")
+		}
+	})
+
+	t.Run("developer mode sends a developer message", func(t *testing.T) {
+		got := buildMessages(req, SystemRoleDeveloper)
+		if len(got) != 2 {
+			t.Fatalf("len(messages) = %d, want 2", len(got))
+		}
+		if got[0].OfDeveloper == nil || got[0].OfDeveloper.Content.OfString.Value != "Be concise." {
+			t.Errorf("messages[0] = %+v, want a developer message with %q", got[0], "Be concise.")
+		}
+	})
+
+	t.Run("user-prefix mode folds system content into the first user message", func(t *testing.T) {
+		got := buildMessages(req, SystemRoleUserPrefix)
+		if len(got) != 1 {
+			t.Fatalf("len(messages) = %d, want 1", len(got))
+		}
+		want := "Be concise.\n\nHello"
+		if got[0].OfUser == nil || got[0].OfUser.Content.OfString.Value != want {
+			t.Errorf("messages[0] = %+v, want a user message with %q", got[0], want)
+		}
+	})
+
+	t.Run("user-prefix mode with no user message falls back to its own user message", func(t *testing.T) {
+		got := buildMessages(GenerationRequest{SystemPrompt: "Be concise."}, SystemRoleUserPrefix)
+		if len(got) != 1 {
+			t.Fatalf("len(messages) = %d, want 1", len(got))
+		}
+		if got[0].OfUser == nil || got[0].OfUser.Content.OfString.Value != "Be concise." {
+			t.Errorf("messages[0] = %+v, want a user message with %q", got[0], "Be concise.")
+		}
+	})
+
+	t.Run("drop mode omits system content entirely", func(t *testing.T) {
+		got := buildMessages(req, SystemRoleDrop)
+		if len(got) != 1 {
+			t.Fatalf("len(messages) = %d, want 1", len(got))
+		}
+		if got[0].OfUser == nil || got[0].OfUser.Content.OfString.Value != "Hello" {
+			t.Errorf("messages[0] = %+v, want a user message with %q", got[0], "Hello")
+		}
+	})
+
+	t.Run("system-role Message entries are folded in alongside SystemPrompt", func(t *testing.T) {
+		got := buildMessages(GenerationRequest{
+			Messages: []Message{
+				{Role: "system", Content: "Rule one."},
+				{Role: "user", Content: "Hi"},
+			},
+		}, SystemRoleSystem)
+		if len(got) != 2 {
+			t.Fatalf("len(messages) = %d, want 2", len(got))
+		}
+		if got[0].OfSystem == nil || got[0].OfSystem.Content.OfString.Value != "Rule one." {
+			t.Errorf("messages[0] = %+v, want a system message with %q", got[0], "Rule one.")
+		}
+	})
+}
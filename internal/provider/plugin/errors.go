@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"errors"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// wireError is a gob-encodable mirror of provider.ProviderError. A
+// *provider.ProviderError can't cross the RPC boundary directly: its
+// Cause field is an error interface, and gob refuses to encode interface
+// values whose concrete type it hasn't been told about. wireError
+// flattens Cause down to a string and is reconstituted into a
+// *provider.ProviderError on the other side, preserving Kind and
+// RetryAfter so callers can still branch on provider.IsRetryableError /
+// provider.GetRetryAfter after a round trip through the plugin.
+type wireError struct {
+	Kind       provider.ErrorKind
+	Message    string
+	CauseMsg   string
+	Retryable  bool
+	RetryAfter int
+	StatusCode int
+	RequestID  string
+}
+
+// toWireError converts err into its wire form, or nil if err is nil. A
+// non-ProviderError is wrapped as ErrKindUnknown so the plugin boundary
+// never silently drops an error's message.
+func toWireError(err error) *wireError {
+	if err == nil {
+		return nil
+	}
+	var pe *provider.ProviderError
+	if errors.As(err, &pe) {
+		we := &wireError{
+			Kind:       pe.Kind,
+			Message:    pe.Message,
+			Retryable:  pe.Retryable,
+			RetryAfter: pe.RetryAfter,
+			StatusCode: pe.StatusCode,
+			RequestID:  pe.RequestID,
+		}
+		if pe.Cause != nil {
+			we.CauseMsg = pe.Cause.Error()
+		}
+		return we
+	}
+	return &wireError{Kind: provider.ErrKindUnknown, Message: err.Error()}
+}
+
+// toProviderError reconstructs a *provider.ProviderError from its wire
+// form. The original Cause can't be recovered as a typed error (it never
+// left the plugin process), so it's folded into Message instead of lost.
+func (w *wireError) toProviderError() error {
+	if w == nil {
+		return nil
+	}
+	msg := w.Message
+	if w.CauseMsg != "" {
+		msg = msg + ": " + w.CauseMsg
+	}
+	return &provider.ProviderError{
+		Kind:       w.Kind,
+		Message:    msg,
+		Retryable:  w.Retryable,
+		RetryAfter: w.RetryAfter,
+		StatusCode: w.StatusCode,
+		RequestID:  w.RequestID,
+	}
+}
@@ -0,0 +1,45 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+func TestWireErrorRoundTrip(t *testing.T) {
+	original := provider.NewRateLimitError("slow down", 30)
+
+	we := toWireError(original)
+	if we == nil {
+		t.Fatal("toWireError returned nil for a non-nil error")
+	}
+
+	restored := we.toProviderError()
+	if !provider.IsRateLimitError(restored) {
+		t.Error("expected the restored error to still be a rate limit error")
+	}
+	if got := provider.GetRetryAfter(restored); got != 30 {
+		t.Errorf("GetRetryAfter(restored) = %d, want 30", got)
+	}
+}
+
+func TestWireErrorNil(t *testing.T) {
+	if toWireError(nil) != nil {
+		t.Error("toWireError(nil) should be nil")
+	}
+	var w *wireError
+	if w.toProviderError() != nil {
+		t.Error("(*wireError)(nil).toProviderError() should be nil")
+	}
+}
+
+func TestWireErrorWrapsPlainError(t *testing.T) {
+	we := toWireError(errors.New("boom"))
+	if we.Kind != provider.ErrKindUnknown {
+		t.Errorf("Kind = %q, want %q", we.Kind, provider.ErrKindUnknown)
+	}
+	if we.Message != "boom" {
+		t.Errorf("Message = %q, want %q", we.Message, "boom")
+	}
+}
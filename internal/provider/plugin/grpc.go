@@ -0,0 +1,484 @@
+// grpc.go bridges a provider.Provider to a real gRPC service so
+// GenerateStream can be delivered as a server-streaming RPC — something
+// rpc.go's net/rpc bridge can't carry (see its GenerateStream, which
+// always reports unsupported). See provider.proto for the canonical
+// schema these types and the service descriptor below stand in for.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	hplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// gobCodecName is the gRPC content-subtype the bridge registers its codec
+// under; every call sets it explicitly via grpc.CallContentSubtype so the
+// default proto codec is never invoked.
+const gobCodecName = "kothaset-gob"
+
+// gobCodec round-trips the bridge's plain Go structs with gob instead of
+// protobuf, since none of them implement proto.Message. See provider.proto's
+// header comment for why.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, fmt.Errorf("plugin: gob marshal failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return fmt.Errorf("plugin: gob unmarshal failed: %w", err)
+	}
+	return nil
+}
+
+func (gobCodec) Name() string { return gobCodecName }
+
+func init() {
+	encoding.RegisterCodec(gobCodec{})
+}
+
+const providerServiceName = "kothaset.provider.v1.Provider"
+
+func fullMethod(name string) string { return "/" + providerServiceName + "/" + name }
+
+// providerServiceDesc is the hand-written stand-in for the ServiceDesc
+// protoc-gen-go-grpc would generate from provider.proto.
+var providerServiceDesc = grpc.ServiceDesc{
+	ServiceName: providerServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Generate", Handler: generateUnaryHandler},
+		{MethodName: "Describe", Handler: describeUnaryHandler},
+		{MethodName: "Validate", Handler: validateUnaryHandler},
+		{MethodName: "HealthCheck", Handler: healthCheckUnaryHandler},
+		{MethodName: "Close", Handler: closeUnaryHandler},
+		{MethodName: "Cancel", Handler: cancelUnaryHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "GenerateStream", Handler: generateStreamHandler, ServerStreams: true},
+	},
+	Metadata: "provider.proto",
+}
+
+func generateUnaryHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req generateRequestMsg
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return srv.(*grpcServer).generate(ctx, &req), nil
+}
+
+func describeUnaryHandler(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req emptyMsg
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return srv.(*grpcServer).describe(), nil
+}
+
+func validateUnaryHandler(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req emptyMsg
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return &errorMsg{Err: toWireError(srv.(*grpcServer).impl.Validate())}, nil
+}
+
+func healthCheckUnaryHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req emptyMsg
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return &errorMsg{Err: toWireError(srv.(*grpcServer).impl.HealthCheck(ctx))}, nil
+}
+
+func closeUnaryHandler(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req emptyMsg
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return &errorMsg{Err: toWireError(srv.(*grpcServer).impl.Close())}, nil
+}
+
+func cancelUnaryHandler(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req cancelRequestMsg
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	srv.(*grpcServer).cancel(req.CallID)
+	return &emptyMsg{}, nil
+}
+
+func generateStreamHandler(srv interface{}, stream grpc.ServerStream) error {
+	var req generateRequestMsg
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	return srv.(*grpcServer).generateStream(stream.Context(), &req, stream)
+}
+
+// grpcServer runs in the plugin process and dispatches gRPC calls to the
+// wrapped provider.Provider implementation. Unlike rpcServer, it tracks
+// in-flight calls by CallID so an explicit Cancel RPC (see provider.proto)
+// can tear one down even if the originating stream has already gone away.
+type grpcServer struct {
+	impl provider.Provider
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newGRPCServer(impl provider.Provider) *grpcServer {
+	return &grpcServer{impl: impl, cancels: make(map[string]context.CancelFunc)}
+}
+
+func (s *grpcServer) track(callID string, ctx context.Context) (context.Context, func()) {
+	if callID == "" {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancels[callID] = cancel
+	s.mu.Unlock()
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.cancels, callID)
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+func (s *grpcServer) cancel(callID string) {
+	s.mu.Lock()
+	cancel, ok := s.cancels[callID]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (s *grpcServer) generate(ctx context.Context, req *generateRequestMsg) *generateResponseMsg {
+	ctx, done := s.track(req.CallID, ctx)
+	defer done()
+
+	resp, err := s.impl.Generate(ctx, req.Req)
+	return &generateResponseMsg{Resp: resp, Err: toWireError(err)}
+}
+
+func (s *grpcServer) describe() *describeResponseMsg {
+	return &describeResponseMsg{
+		Name:                     s.impl.Name(),
+		Type:                     s.impl.Type(),
+		Model:                    s.impl.Model(),
+		SupportsStreaming:        s.impl.SupportsStreaming(),
+		SupportsStructuredOutput: s.impl.SupportsStructuredOutput(),
+	}
+}
+
+func (s *grpcServer) generateStream(ctx context.Context, req *generateRequestMsg, stream grpc.ServerStream) error {
+	ctx, done := s.track(req.CallID, ctx)
+	defer done()
+
+	ch, err := s.impl.GenerateStream(ctx, req.Req)
+	if err != nil {
+		return stream.SendMsg(&streamChunkMsg{Done: true, Err: toWireError(err)})
+	}
+
+	for chunk := range ch {
+		msg := &streamChunkMsg{
+			Content:      chunk.Content,
+			Done:         chunk.Done,
+			FinishReason: chunk.FinishReason,
+			Usage:        chunk.Usage,
+			Err:          toWireError(chunk.Error),
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grpcClient runs in the host process and implements provider.Provider by
+// invoking the gRPC service exposed by grpcServer.
+type grpcClient struct {
+	conn *grpc.ClientConn
+
+	mu      sync.Mutex
+	callSeq int
+	closed  bool
+}
+
+func newGRPCClient(conn *grpc.ClientConn) *grpcClient {
+	return &grpcClient{conn: conn}
+}
+
+// nextCallID generates a per-connection unique ID so Cancel can target a
+// specific in-flight call.
+func (c *grpcClient) nextCallID() string {
+	c.mu.Lock()
+	c.callSeq++
+	id := c.callSeq
+	c.mu.Unlock()
+	return fmt.Sprintf("call-%d", id)
+}
+
+func (c *grpcClient) invoke(ctx context.Context, method string, req, reply interface{}) error {
+	return c.conn.Invoke(ctx, fullMethod(method), req, reply, grpc.CallContentSubtype(gobCodecName))
+}
+
+// cancelOnDone sends an explicit Cancel RPC once ctx is done, forwarding
+// the host's context cancellation to the plugin process as a CancelRequest
+// message in addition to the stream/call-level cancellation gRPC already
+// performs at the transport layer.
+func (c *grpcClient) cancelOnDone(ctx context.Context, callID string) {
+	go func() {
+		<-ctx.Done()
+		var reply emptyMsg
+		_ = c.conn.Invoke(context.Background(), fullMethod("Cancel"), &cancelRequestMsg{CallID: callID}, &reply, grpc.CallContentSubtype(gobCodecName))
+	}()
+}
+
+func (c *grpcClient) Generate(ctx context.Context, req provider.GenerationRequest) (*provider.GenerationResponse, error) {
+	callID := c.nextCallID()
+	c.cancelOnDone(ctx, callID)
+
+	var reply generateResponseMsg
+	if err := c.invoke(ctx, "Generate", &generateRequestMsg{CallID: callID, Req: req}, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Err != nil {
+		return nil, reply.Err.toProviderError()
+	}
+	return reply.Resp, nil
+}
+
+func (c *grpcClient) GenerateStream(ctx context.Context, req provider.GenerationRequest) (<-chan provider.StreamChunk, error) {
+	callID := c.nextCallID()
+	c.cancelOnDone(ctx, callID)
+
+	stream, err := c.conn.NewStream(ctx, &providerServiceDesc.Streams[0], fullMethod("GenerateStream"), grpc.CallContentSubtype(gobCodecName))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&generateRequestMsg{CallID: callID, Req: req}); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan provider.StreamChunk, 16)
+	go func() {
+		defer close(ch)
+		for {
+			var msg streamChunkMsg
+			err := stream.RecvMsg(&msg)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				ch <- provider.StreamChunk{Done: true, Error: err}
+				return
+			}
+			ch <- provider.StreamChunk{
+				Content:      msg.Content,
+				Done:         msg.Done,
+				FinishReason: msg.FinishReason,
+				Usage:        msg.Usage,
+				Error:        msg.Err.toProviderError(),
+			}
+			if msg.Done {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (c *grpcClient) Name() string {
+	var reply describeResponseMsg
+	if c.invoke(context.Background(), "Describe", &emptyMsg{}, &reply) != nil {
+		return ""
+	}
+	return reply.Name
+}
+
+func (c *grpcClient) Type() string {
+	var reply describeResponseMsg
+	if c.invoke(context.Background(), "Describe", &emptyMsg{}, &reply) != nil {
+		return ""
+	}
+	return reply.Type
+}
+
+func (c *grpcClient) Model() string {
+	var reply describeResponseMsg
+	if c.invoke(context.Background(), "Describe", &emptyMsg{}, &reply) != nil {
+		return ""
+	}
+	return reply.Model
+}
+
+func (c *grpcClient) SupportsStreaming() bool {
+	var reply describeResponseMsg
+	if c.invoke(context.Background(), "Describe", &emptyMsg{}, &reply) != nil {
+		return false
+	}
+	return reply.SupportsStreaming
+}
+
+func (c *grpcClient) SupportsStructuredOutput() bool {
+	var reply describeResponseMsg
+	if c.invoke(context.Background(), "Describe", &emptyMsg{}, &reply) != nil {
+		return false
+	}
+	return reply.SupportsStructuredOutput
+}
+
+func (c *grpcClient) Validate() error {
+	var reply errorMsg
+	if err := c.invoke(context.Background(), "Validate", &emptyMsg{}, &reply); err != nil {
+		return err
+	}
+	return reply.Err.toProviderError()
+}
+
+func (c *grpcClient) HealthCheck(ctx context.Context) error {
+	var reply errorMsg
+	if err := c.invoke(ctx, "HealthCheck", &emptyMsg{}, &reply); err != nil {
+		return err
+	}
+	return reply.Err.toProviderError()
+}
+
+func (c *grpcClient) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	var reply errorMsg
+	callErr := c.invoke(context.Background(), "Close", &emptyMsg{}, &reply)
+	closeErr := c.conn.Close()
+	if callErr != nil {
+		return callErr
+	}
+	if reply.Err != nil {
+		return reply.Err.toProviderError()
+	}
+	return closeErr
+}
+
+// grpcProviderPlugin is the gRPC counterpart of providerPlugin: it
+// registers impl as a real gRPC service instead of exposing it over
+// go-plugin's net/rpc bridge, so GenerateStream works as a genuine
+// server-streaming RPC.
+type grpcProviderPlugin struct {
+	hplugin.NetRPCUnsupportedPlugin
+	impl provider.Provider
+}
+
+func grpcPluginMap(impl provider.Provider) map[string]hplugin.Plugin {
+	return map[string]hplugin.Plugin{
+		providerPluginName: &grpcProviderPlugin{impl: impl},
+	}
+}
+
+func (p *grpcProviderPlugin) GRPCServer(_ *hplugin.GRPCBroker, s *grpc.Server) error {
+	s.RegisterService(&providerServiceDesc, newGRPCServer(p.impl))
+	return nil
+}
+
+func (p *grpcProviderPlugin) GRPCClient(_ context.Context, _ *hplugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return newGRPCClient(conn), nil
+}
+
+// LaunchGRPC starts path as a gRPC provider plugin, the same way Launch
+// starts a net/rpc one, but negotiates go-plugin's gRPC protocol so
+// GenerateStream is a true server-streaming RPC instead of reporting
+// unsupported. args are shell-split the same way a cmd: secret reference
+// is (see resolveCmdSecret).
+func LaunchGRPC(path string, args ...string) (*PluginProvider, error) {
+	client := hplugin.NewClient(&hplugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          grpcPluginMap(nil),
+		Cmd:              exec.Command(path, args...),
+		AllowedProtocols: []hplugin.Protocol{hplugin.ProtocolGRPC},
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("plugin: failed to start %s: %w", path, err)
+	}
+
+	raw, err := rpcClient.Dispense(providerPluginName)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("plugin: failed to dispense provider from %s: %w", path, err)
+	}
+
+	impl, ok := raw.(provider.Provider)
+	if !ok {
+		client.Kill()
+		return nil, fmt.Errorf("plugin: %s did not return a provider.Provider", path)
+	}
+
+	return &PluginProvider{Provider: impl, client: client, path: path}, nil
+}
+
+// DialGRPC connects directly to an already-running gRPC provider at addr
+// (e.g. "unix:///run/kothaset-provider.sock" or "10.0.0.5:50051"),
+// skipping subprocess management entirely — for a provider gateway that
+// runs as its own long-lived service rather than a binary KothaSet
+// launches itself.
+func DialGRPC(addr string) (provider.Provider, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("plugin: failed to dial %s: %w", addr, err)
+	}
+	return newGRPCClient(conn), nil
+}
+
+// NewGRPCFactory is a provider.Factory for Type: "grpc" providers,
+// registered via provider.RegisterFactory. Exactly one of cfg.Command or
+// cfg.Socket should be set.
+func NewGRPCFactory(cfg *provider.Config) (provider.Provider, error) {
+	switch {
+	case cfg.Socket != "":
+		return DialGRPC(cfg.Socket)
+	case cfg.Command != "":
+		fields := strings.Fields(cfg.Command)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("plugin: empty grpc command for provider %s", cfg.Name)
+		}
+		proxy, err := LaunchGRPC(fields[0], fields[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		return proxy, nil
+	default:
+		return nil, fmt.Errorf("plugin: grpc provider %s needs a command or socket", cfg.Name)
+	}
+}
@@ -0,0 +1,42 @@
+package plugin
+
+import "github.com/shantoislamdev/kothaset/internal/provider"
+
+// These mirror provider.proto's messages 1:1; see that file's header
+// comment for why they're hand-written instead of protoc-generated.
+
+type generateRequestMsg struct {
+	CallID string
+	Req    provider.GenerationRequest
+}
+
+type generateResponseMsg struct {
+	Resp *provider.GenerationResponse
+	Err  *wireError
+}
+
+type streamChunkMsg struct {
+	Content      string
+	Done         bool
+	FinishReason string
+	Usage        *provider.TokenUsage
+	Err          *wireError
+}
+
+type describeResponseMsg struct {
+	Name                     string
+	Type                     string
+	Model                    string
+	SupportsStreaming        bool
+	SupportsStructuredOutput bool
+}
+
+type cancelRequestMsg struct {
+	CallID string
+}
+
+type errorMsg struct {
+	Err *wireError
+}
+
+type emptyMsg struct{}
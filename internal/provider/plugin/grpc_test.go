@@ -0,0 +1,37 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+func TestGobCodecRoundTrip(t *testing.T) {
+	var codec gobCodec
+
+	original := &generateRequestMsg{
+		CallID: "call-1",
+		Req:    provider.GenerationRequest{SystemPrompt: "hello"},
+	}
+
+	data, err := codec.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var restored generateRequestMsg
+	if err := codec.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if restored.CallID != original.CallID || restored.Req.SystemPrompt != original.Req.SystemPrompt {
+		t.Errorf("round trip = %+v, want %+v", restored, original)
+	}
+}
+
+func TestGobCodecName(t *testing.T) {
+	var codec gobCodec
+	if codec.Name() != gobCodecName {
+		t.Errorf("Name() = %q, want %q", codec.Name(), gobCodecName)
+	}
+}
@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	hplugin "github.com/hashicorp/go-plugin"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// DefaultDir is the conventional location plugin binaries are discovered
+// from when no explicit path is configured, mirroring how kubectl and
+// Terraform look in a well-known user directory for plugin executables.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kothaset", "plugins")
+}
+
+// Discover lists executable files directly inside dir. It returns nil
+// (not an error) if dir doesn't exist, since a missing plugin directory
+// just means no plugins are installed.
+func Discover(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugin: failed to read plugin directory %s: %w", dir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	return paths, nil
+}
+
+// PluginProvider is a host-side provider.Provider backed by an
+// out-of-process plugin binary. It embeds the RPC proxy returned by
+// Launch so every Provider method forwards over the wire, except Close,
+// which also tears down the plugin subprocess.
+type PluginProvider struct {
+	provider.Provider
+	client *hplugin.Client
+	path   string
+}
+
+// Launch starts the plugin binary at path, performs the handshake, and
+// returns a provider.Provider proxy for it. args are passed through to
+// the plugin process unchanged (e.g. a config file flag some plugins
+// support).
+func Launch(path string, args ...string) (*PluginProvider, error) {
+	client := hplugin.NewClient(&hplugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          pluginMap(nil),
+		Cmd:              exec.Command(path, args...),
+		AllowedProtocols: []hplugin.Protocol{hplugin.ProtocolNetRPC},
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("plugin: failed to start %s: %w", path, err)
+	}
+
+	raw, err := rpcClient.Dispense(providerPluginName)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("plugin: failed to dispense provider from %s: %w", path, err)
+	}
+
+	impl, ok := raw.(provider.Provider)
+	if !ok {
+		client.Kill()
+		return nil, fmt.Errorf("plugin: %s did not return a provider.Provider", path)
+	}
+
+	return &PluginProvider{Provider: impl, client: client, path: path}, nil
+}
+
+// Path returns the plugin binary this provider was launched from, for
+// diagnostics (e.g. `kothaset provider list`).
+func (p *PluginProvider) Path() string {
+	return p.path
+}
+
+// Close shuts down the plugin's provider cleanly and then kills the
+// subprocess, so a plugin that ignores Close (or hangs) doesn't leak a
+// process KothaSet is responsible for.
+func (p *PluginProvider) Close() error {
+	err := p.Provider.Close()
+	p.client.Kill()
+	return err
+}
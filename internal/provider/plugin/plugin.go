@@ -0,0 +1,59 @@
+// Package plugin lets a provider.Provider implementation live in a
+// separate, out-of-process binary instead of being compiled into
+// KothaSet itself — the same shape as Teleport's external audit log
+// plugins or Terraform's provider binaries. The host launches the plugin
+// executable, speaks go-plugin's net/rpc transport to it over a
+// handshake-negotiated pipe, and the result satisfies provider.Provider
+// just like a built-in OpenAI/Anthropic/DeepSeek provider.
+package plugin
+
+import (
+	"net/rpc"
+
+	hplugin "github.com/hashicorp/go-plugin"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// Handshake is shared by host and plugin process so both agree on the
+// wire protocol version and refuse to talk to a binary that isn't a
+// KothaSet provider plugin (e.g. one launched by accident, or a plugin
+// built for an incompatible KothaSet release).
+var Handshake = hplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "KOTHASET_PLUGIN",
+	MagicCookieValue: "provider",
+}
+
+// providerPluginName is the key both sides register the Provider
+// implementation under in go-plugin's plugin map.
+const providerPluginName = "provider"
+
+// pluginMap is the map of plugins exchanged over the connection; both
+// Serve (plugin side) and Launch (host side) use the same map shape so
+// the key always matches.
+func pluginMap(impl provider.Provider) map[string]hplugin.Plugin {
+	return map[string]hplugin.Plugin{
+		providerPluginName: &providerPlugin{impl: impl},
+	}
+}
+
+// providerPlugin adapts a provider.Provider to go-plugin's net/rpc
+// Plugin interface. impl is only set on the plugin-author side (Serve);
+// the host side (Launch) leaves it nil since it only ever dispenses a
+// Client.
+type providerPlugin struct {
+	impl provider.Provider
+}
+
+// Server implements plugin.Plugin on the plugin-author side: it exposes
+// impl over net/rpc.
+func (p *providerPlugin) Server(*hplugin.MuxBroker) (interface{}, error) {
+	return &rpcServer{impl: p.impl}, nil
+}
+
+// Client implements plugin.Plugin on the host side: it wraps the RPC
+// connection in a provider.Provider-shaped proxy.
+func (p *providerPlugin) Client(_ *hplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &rpcClient{client: c}, nil
+}
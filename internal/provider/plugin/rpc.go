@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"context"
+	"net/rpc"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// generateArgs/generateReply, healthCheckArgs and friends exist because
+// net/rpc requires every method to take exactly one argument and one
+// reply pointer; the Provider interface's zero- and single-argument
+// methods are wrapped in these trivial structs purely to satisfy that
+// shape.
+
+type generateArgs struct {
+	Req provider.GenerationRequest
+}
+
+type generateReply struct {
+	Resp *provider.GenerationResponse
+	Err  *wireError
+}
+
+type healthCheckReply struct {
+	Err *wireError
+}
+
+type stringReply struct {
+	Value string
+}
+
+type boolReply struct {
+	Value bool
+}
+
+type errReply struct {
+	Err *wireError
+}
+
+// rpcServer runs in the plugin process and dispatches net/rpc calls to
+// the wrapped provider.Provider implementation.
+type rpcServer struct {
+	impl provider.Provider
+}
+
+func (s *rpcServer) Generate(args generateArgs, reply *generateReply) error {
+	resp, err := s.impl.Generate(context.Background(), args.Req)
+	reply.Resp = resp
+	reply.Err = toWireError(err)
+	return nil
+}
+
+// GenerateStream is not exposed over net/rpc: streaming requires a
+// server-push channel that net/rpc's one-request/one-reply shape can't
+// carry, so the client side always reports it unsupported instead of
+// wiring a call through. A plugin author needing streaming should expose
+// it via a side-channel of their own rather than through this bridge.
+func (s *rpcServer) GenerateStream(_ struct{}, reply *errReply) error {
+	reply.Err = toWireError(provider.NewProviderError(provider.ErrKindValidation, "plugin providers do not support streaming over the net/rpc bridge", nil))
+	return nil
+}
+
+func (s *rpcServer) Name(_ struct{}, reply *stringReply) error {
+	reply.Value = s.impl.Name()
+	return nil
+}
+
+func (s *rpcServer) Type(_ struct{}, reply *stringReply) error {
+	reply.Value = s.impl.Type()
+	return nil
+}
+
+func (s *rpcServer) Model(_ struct{}, reply *stringReply) error {
+	reply.Value = s.impl.Model()
+	return nil
+}
+
+func (s *rpcServer) SupportsStreaming(_ struct{}, reply *boolReply) error {
+	reply.Value = s.impl.SupportsStreaming()
+	return nil
+}
+
+func (s *rpcServer) SupportsStructuredOutput(_ struct{}, reply *boolReply) error {
+	reply.Value = s.impl.SupportsStructuredOutput()
+	return nil
+}
+
+func (s *rpcServer) Validate(_ struct{}, reply *errReply) error {
+	reply.Err = toWireError(s.impl.Validate())
+	return nil
+}
+
+func (s *rpcServer) HealthCheck(_ struct{}, reply *healthCheckReply) error {
+	reply.Err = toWireError(s.impl.HealthCheck(context.Background()))
+	return nil
+}
+
+func (s *rpcServer) Close(_ struct{}, reply *errReply) error {
+	reply.Err = toWireError(s.impl.Close())
+	return nil
+}
+
+// rpcClient runs in the host process and implements provider.Provider by
+// forwarding every call over the net/rpc connection to the plugin.
+type rpcClient struct {
+	client *rpc.Client
+}
+
+func (c *rpcClient) Generate(_ context.Context, req provider.GenerationRequest) (*provider.GenerationResponse, error) {
+	var reply generateReply
+	if err := c.client.Call("Plugin.Generate", generateArgs{Req: req}, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Err != nil {
+		return nil, reply.Err.toProviderError()
+	}
+	return reply.Resp, nil
+}
+
+// GenerateStream always reports streaming unsupported; see rpcServer's
+// GenerateStream for why this bridge can't carry it.
+func (c *rpcClient) GenerateStream(_ context.Context, _ provider.GenerationRequest) (<-chan provider.StreamChunk, error) {
+	var reply errReply
+	if err := c.client.Call("Plugin.GenerateStream", struct{}{}, &reply); err != nil {
+		return nil, err
+	}
+	return nil, reply.Err.toProviderError()
+}
+
+func (c *rpcClient) Name() string {
+	var reply stringReply
+	if err := c.client.Call("Plugin.Name", struct{}{}, &reply); err != nil {
+		return ""
+	}
+	return reply.Value
+}
+
+func (c *rpcClient) Type() string {
+	var reply stringReply
+	if err := c.client.Call("Plugin.Type", struct{}{}, &reply); err != nil {
+		return ""
+	}
+	return reply.Value
+}
+
+func (c *rpcClient) Model() string {
+	var reply stringReply
+	if err := c.client.Call("Plugin.Model", struct{}{}, &reply); err != nil {
+		return ""
+	}
+	return reply.Value
+}
+
+func (c *rpcClient) SupportsStreaming() bool {
+	var reply boolReply
+	if err := c.client.Call("Plugin.SupportsStreaming", struct{}{}, &reply); err != nil {
+		return false
+	}
+	return reply.Value
+}
+
+func (c *rpcClient) SupportsStructuredOutput() bool {
+	var reply boolReply
+	if err := c.client.Call("Plugin.SupportsStructuredOutput", struct{}{}, &reply); err != nil {
+		return false
+	}
+	return reply.Value
+}
+
+func (c *rpcClient) Validate() error {
+	var reply errReply
+	if err := c.client.Call("Plugin.Validate", struct{}{}, &reply); err != nil {
+		return err
+	}
+	if reply.Err != nil {
+		return reply.Err.toProviderError()
+	}
+	return nil
+}
+
+func (c *rpcClient) HealthCheck(_ context.Context) error {
+	var reply healthCheckReply
+	if err := c.client.Call("Plugin.HealthCheck", struct{}{}, &reply); err != nil {
+		return err
+	}
+	if reply.Err != nil {
+		return reply.Err.toProviderError()
+	}
+	return nil
+}
+
+func (c *rpcClient) Close() error {
+	var reply errReply
+	if err := c.client.Call("Plugin.Close", struct{}{}, &reply); err != nil {
+		return err
+	}
+	if reply.Err != nil {
+		return reply.Err.toProviderError()
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	hplugin "github.com/hashicorp/go-plugin"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// Serve runs impl as a KothaSet provider plugin. A plugin author wraps a
+// provider.Provider implementation (or an adapter around a third-party
+// SDK) and calls Serve from main, e.g.:
+//
+//	func main() {
+//	    plugin.Serve(myprovider.New())
+//	}
+//
+// Serve blocks until the host process disconnects; it never returns
+// under normal operation.
+func Serve(impl provider.Provider) {
+	hplugin.Serve(&hplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         pluginMap(impl),
+	})
+}
+
+// ServeGRPC is Serve's gRPC counterpart: impl is exposed over the
+// service described in provider.proto instead of go-plugin's net/rpc
+// bridge, so a host calling LaunchGRPC gets real streaming out of
+// GenerateStream rather than the "unsupported" Serve always returns.
+// A plugin author picks one of Serve or ServeGRPC depending on whether
+// LaunchGRPC or Launch will be used to start it; it never returns under
+// normal operation.
+func ServeGRPC(impl provider.Provider) {
+	hplugin.Serve(&hplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         grpcPluginMap(impl),
+		GRPCServer:      hplugin.DefaultGRPCServer,
+	})
+}
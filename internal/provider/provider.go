@@ -2,7 +2,9 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"time"
 )
 
@@ -11,12 +13,26 @@ type Provider interface {
 	// Generate creates a completion for the given request
 	Generate(ctx context.Context, req GenerationRequest) (*GenerationResponse, error)
 
+	// GenerateStream creates a completion the same way as Generate, but
+	// delivers it incrementally as a channel of StreamChunks instead of
+	// waiting for the full response. The channel is closed after a chunk
+	// with Done set to true (or a chunk carrying Error) is sent. Callers
+	// should check SupportsStreaming before using it.
+	GenerateStream(ctx context.Context, req GenerationRequest) (<-chan StreamChunk, error)
+
 	// Metadata
 	Name() string
 	Type() string
 	Model() string
 	SupportsStreaming() bool
 
+	// SupportsStructuredOutput reports whether this provider can take
+	// GenerationRequest.ResponseSchema and constrain decoding to it (e.g.
+	// OpenAI's response_format json_schema, Anthropic forced tool-use).
+	// Callers should fall back to prompt-only "respond with only JSON"
+	// instructions when it returns false.
+	SupportsStructuredOutput() bool
+
 	// Lifecycle
 	Validate() error
 	HealthCheck(ctx context.Context) error
@@ -25,9 +41,35 @@ type Provider interface {
 
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"`           // system, user, assistant
+	Role    string `json:"role"`           // system, user, assistant, tool
 	Content string `json:"content"`        // message content
 	Name    string `json:"name,omitempty"` // optional name for multi-agent
+
+	// ToolCalls replays the function calls an earlier Role == "assistant"
+	// message made, so a multi-turn request can include them in history.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// ToolCallID identifies which ToolCalls entry a Role == "tool" message
+	// is the result of.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ToolDefinition describes a function the model may call, in OpenAI's
+// tools/function-calling format. Parameters is the function's JSON Schema
+// parameter object, passed through to the provider verbatim.
+type ToolDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall is a model-issued request to invoke one of GenerationRequest's
+// Tools, surfaced on GenerationResponse.ToolCalls and (to replay it in a
+// later turn's history) Message.ToolCalls.
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON-encoded arguments
 }
 
 // GenerationRequest contains all parameters for a generation request
@@ -62,6 +104,22 @@ type GenerationRequest struct {
 	// ResponseFormat for structured output (e.g., "json")
 	ResponseFormat string `json:"response_format,omitempty"`
 
+	// ResponseSchema, when set, is a JSON Schema describing the expected
+	// response shape (see schema.Schema.ResponseJSONSchema). Providers
+	// that report SupportsStructuredOutput use it to constrain decoding
+	// instead of relying on ResponseFormat's free-form "json" hint;
+	// others ignore it.
+	ResponseSchema []byte `json:"response_schema,omitempty"`
+
+	// Tools lists the functions the model may call. Empty disables
+	// function-calling for this request.
+	Tools []ToolDefinition `json:"tools,omitempty"`
+
+	// ToolChoice controls whether/which tool the model must call: "auto"
+	// (the default when Tools is set), "none", "required", or a specific
+	// tool's Name to force that one. Ignored when Tools is empty.
+	ToolChoice string `json:"tool_choice,omitempty"`
+
 	// Metadata for tracking/logging
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -88,6 +146,32 @@ type GenerationResponse struct {
 
 	// Cached indicates if this was a cached response
 	Cached bool `json:"cached,omitempty"`
+
+	// ToolCalls are the function calls the model made instead of (or
+	// alongside) Content; empty when the model didn't call a tool.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// RateLimit carries the provider's rate-limit headroom as of this
+	// response, if the provider sends it. Nil when unavailable (e.g. a
+	// compatible API that doesn't send rate-limit headers).
+	RateLimit *RateLimitInfo `json:"rate_limit,omitempty"`
+}
+
+// RateLimitInfo is a provider's reported rate-limit headroom, parsed from
+// its response headers (OpenAI's x-ratelimit-remaining-requests/tokens and
+// x-ratelimit-reset-requests/tokens). It lets a scheduler back off ahead of
+// a 429 instead of only reacting to one after the fact.
+type RateLimitInfo struct {
+	// RemainingRequests/RemainingTokens are how many requests/tokens are
+	// left in the current window.
+	RemainingRequests int `json:"remaining_requests"`
+	RemainingTokens   int `json:"remaining_tokens"`
+
+	// ResetRequests/ResetTokens are the provider's own duration strings
+	// (e.g. "1s", "6m0s") until each window resets, kept as-is rather than
+	// parsed into a time.Duration since providers format them differently.
+	ResetRequests string `json:"reset_requests,omitempty"`
+	ResetTokens   string `json:"reset_tokens,omitempty"`
 }
 
 // TokenUsage contains token consumption information
@@ -111,6 +195,100 @@ type StreamChunk struct {
 	// Usage when Done is true
 	Usage *TokenUsage `json:"usage,omitempty"`
 
+	// RateLimit when Done is true, the same as GenerationResponse.RateLimit.
+	RateLimit *RateLimitInfo `json:"rate_limit,omitempty"`
+
+	// ToolCallDelta carries an incremental tool-call fragment the same
+	// way Content carries a text fragment. A single call's Arguments JSON
+	// typically arrives split across many chunks; callers accumulate by
+	// Index the way the OpenAI SDK does.
+	ToolCallDelta *ToolCallDelta `json:"tool_call_delta,omitempty"`
+
 	// Error if something went wrong
 	Error error `json:"error,omitempty"`
 }
+
+// ToolCallDelta is one incremental fragment of a streamed tool call. ID
+// and Name are only set on the fragment that starts a given Index;
+// Arguments should be concatenated across fragments sharing an Index to
+// reconstruct the full JSON.
+type ToolCallDelta struct {
+	Index     int    `json:"index"`
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// BatchProvider is an optional capability for asynchronous, discounted
+// batch generation (OpenAI's Batch API, Anthropic's Message Batches API)
+// instead of the live Generate/GenerateStream path. Callers should
+// type-assert a Provider for it rather than relying on a boolean
+// capability flag, since submitting/polling/fetching has no uniform
+// request shape to expose on Provider itself.
+type BatchProvider interface {
+	// SubmitBatch uploads/creates a batch job covering every entry in
+	// requests and returns the provider's job ID for it.
+	SubmitBatch(ctx context.Context, requests []BatchRequest) (jobID string, err error)
+
+	// PollBatch reports a previously submitted job's current progress.
+	PollBatch(ctx context.Context, jobID string) (BatchJobStatus, error)
+
+	// FetchBatchResults downloads a job's results, one BatchResult per
+	// BatchRequest submitted under it (matched by CustomID). Only valid
+	// once PollBatch reports BatchJobStatus.State == BatchStateCompleted.
+	FetchBatchResults(ctx context.Context, jobID string) ([]BatchResult, error)
+}
+
+// BatchRequest is one request bundled into a batch job. CustomID lets the
+// caller match a BatchResult back to the request that produced it, since a
+// batch's results can come back in a different order than submitted (or,
+// for BatchStateFailed entries, not at all).
+type BatchRequest struct {
+	CustomID string
+	Request  GenerationRequest
+}
+
+// BatchState is the lifecycle stage of a submitted batch job, normalized
+// across providers' own job-status vocabularies.
+type BatchState string
+
+const (
+	BatchStateInProgress BatchState = "in_progress"
+	BatchStateCompleted  BatchState = "completed"
+	BatchStateFailed     BatchState = "failed"
+	BatchStateExpired    BatchState = "expired"
+	BatchStateCancelled  BatchState = "cancelled"
+)
+
+// BatchJobStatus reports a submitted batch job's progress, as returned by
+// BatchProvider.PollBatch.
+type BatchJobStatus struct {
+	State          BatchState
+	CompletedCount int
+	FailedCount    int
+	TotalCount     int
+}
+
+// BatchResult is one request's outcome from BatchProvider.FetchBatchResults,
+// matched back to its BatchRequest by CustomID. Exactly one of
+// Response/Err is set.
+type BatchResult struct {
+	CustomID string
+	Response *GenerationResponse
+	Err      error
+}
+
+// SplitJSONLLines splits data (an OpenAI batch output/error file or an
+// Anthropic batch results_url body) into its non-blank JSONL lines, shared
+// by both providers' FetchBatchResults so a fix to line-splitting (e.g.
+// trailing-newline/CRLF handling) only needs to be made once.
+func SplitJSONLLines(data []byte) [][]byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -18,6 +18,20 @@ type Config struct {
 	Timeout    time.Duration
 	RateLimit  int // requests per minute
 	Headers    map[string]string
+
+	// Command and Socket configure the "grpc" provider type (see
+	// provider/plugin.NewGRPCFactory). Exactly one should be set: Command
+	// is a binary (plus args) to launch as a subprocess; Socket dials an
+	// already-running gRPC provider directly (e.g. "unix:///run/p.sock"
+	// or "10.0.0.5:50051"), skipping subprocess management entirely.
+	Command string
+	Socket  string
+
+	// SystemRoleMode configures how the "openai" provider type sends
+	// system content to models that don't accept a system role (OpenAI's
+	// o1 family). See SystemRoleMode's constants; empty defaults to
+	// SystemRoleAuto.
+	SystemRoleMode SystemRoleMode
 }
 
 // Registry manages provider instances
@@ -41,6 +55,8 @@ func NewRegistry() *Registry {
 	}
 	// Register built-in factories
 	r.RegisterFactory("openai", NewOpenAIProvider)
+	r.RegisterFactory("anthropic", NewAnthropicProvider)
+	r.RegisterFactory("deepseek", NewDeepSeekProvider)
 	return r
 }
 
@@ -111,6 +127,41 @@ func (r *Registry) GetOrCreate(cfg *Config) (Provider, error) {
 	return provider, nil
 }
 
+// Replace swaps the provider registered under cfg.Name for a freshly
+// constructed instance built from cfg. It is used for hot-reloading
+// provider configuration (e.g. a rotated API key or changed BaseURL)
+// without disrupting in-flight generation goroutines, which hold their
+// own reference to the Provider returned by GetOrCreate and are
+// unaffected by a later Replace.
+func (r *Registry) Replace(name string, cfg *Config) error {
+	factory, ok := func() (Factory, bool) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		f, ok := r.factories[cfg.Type]
+		return f, ok
+	}()
+	if !ok {
+		return fmt.Errorf("unknown provider type: %s", cfg.Type)
+	}
+
+	next, err := factory(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider %s: %w", name, err)
+	}
+
+	r.mu.Lock()
+	old, existed := r.providers[name]
+	r.providers[name] = next
+	r.mu.Unlock()
+
+	if existed {
+		if err := old.Close(); err != nil {
+			return fmt.Errorf("replaced provider %s but failed to close old instance: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // List returns all registered provider names
 func (r *Registry) List() []string {
 	r.mu.RLock()
@@ -170,3 +221,9 @@ func List() []string {
 func CloseAll() error {
 	return globalRegistry.Close()
 }
+
+// Replace swaps the named provider in the global registry for a freshly
+// constructed instance built from cfg.
+func Replace(name string, cfg *Config) error {
+	return globalRegistry.Replace(name, cfg)
+}
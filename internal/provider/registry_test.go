@@ -21,6 +21,7 @@ func (m *MockProvider) Type() string                          { return "mock" }
 func (m *MockProvider) Model() string                         { return "mock-model" }
 func (m *MockProvider) SupportedModels() []string             { return []string{"mock-model"} }
 func (m *MockProvider) SupportsStreaming() bool               { return false }
+func (m *MockProvider) SupportsStructuredOutput() bool        { return false }
 func (m *MockProvider) SupportsBatching() bool                { return false }
 func (m *MockProvider) Validate() error                       { return nil }
 func (m *MockProvider) HealthCheck(ctx context.Context) error { return nil }
@@ -5,12 +5,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"go.uber.org/multierr"
 )
 
 // ChatMessage represents a message in a conversation
 type ChatMessage struct {
-	Role    string `json:"role"`    // system, user, assistant
+	Role    string `json:"role"`    // system, user, assistant, tool
 	Content string `json:"content"` // message content
+
+	// ToolCalls carries the function calls an assistant turn made instead
+	// of (or alongside) replying directly, mirroring OpenAI's tool_calls /
+	// the Hermes function-call trace format. Only meaningful when
+	// Role == "assistant"; nil for an ordinary text turn.
+	ToolCalls []ChatToolCall `json:"tool_calls,omitempty"`
+
+	// ToolCallID identifies which ToolCalls entry a Role == "tool" message
+	// is the result of.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ChatToolCall is one function call an assistant turn made mid-conversation.
+type ChatToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON-encoded arguments
 }
 
 // ChatSchema implements the ShareGPT-style multi-turn conversation format
@@ -90,7 +109,11 @@ Requirements:
 - The conversation should be coherent and natural
 - Assistant responses should be helpful, accurate, and engaging
 - User messages can include questions, requests, or follow-ups
-- Vary the conversation style and complexity`)
+- Vary the conversation style and complexity
+- Optionally, an assistant turn may call a tool instead of replying directly:
+  {"role": "assistant", "content": "", "tool_calls": [{"id": "call_1", "name": "tool_name", "arguments": "{\"key\": \"value\"}"}]}
+  followed by a "tool" turn with the result:
+  {"role": "tool", "tool_call_id": "call_1", "content": "tool output"}`)
 
 	// Inject user instructions (from context.yaml)
 	if opts.UserInstruction != "" {
@@ -103,6 +126,53 @@ Requirements:
 	return sb.String(), nil
 }
 
+// ResponseJSONSchema implements Schema.ResponseJSONSchema.
+func (s *ChatSchema) ResponseJSONSchema() []byte {
+	return buildResponseJSONSchema(s.Name(), s.Fields(), s.RequiredFields())
+}
+
+// chatMessageJSONSchema describes one ChatMessage turn as a nested JSON
+// Schema object, for JSONSchema's "conversations" property - role is
+// constrained to the roles ValidateSample accepts, and tool_calls mirrors
+// ChatToolCall's fields for an assistant turn that calls a tool instead of
+// replying directly.
+func chatMessageJSONSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"role":    map[string]any{"type": "string", "enum": []string{"system", "user", "assistant", "tool"}},
+			"content": map[string]any{"type": "string"},
+			"tool_calls": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id":        map[string]any{"type": "string"},
+						"name":      map[string]any{"type": "string"},
+						"arguments": map[string]any{"type": "string"},
+					},
+					"required": []string{"id", "name", "arguments"},
+				},
+			},
+			"tool_call_id": map[string]any{"type": "string"},
+		},
+		"required": []string{"role", "content"},
+	}
+}
+
+// JSONSchema implements Schema.JSONSchema, with conversations described as
+// an array of chatMessageJSONSchema objects (rather than buildJSONSchema's
+// default array-of-strings) and a minItems mirroring ValidateSample's "at
+// least 2 messages" check.
+func (s *ChatSchema) JSONSchema() ([]byte, error) {
+	return buildJSONSchema(s.Name(), s.Description(), s.Fields(), s.RequiredFields(), map[string]map[string]any{
+		"conversations": {
+			"items":    chatMessageJSONSchema(),
+			"minItems": 2,
+		},
+	})
+}
+
 func (s *ChatSchema) ParseResponse(raw string) (*Sample, error) {
 	raw = strings.TrimSpace(raw)
 
@@ -136,6 +206,24 @@ func (s *ChatSchema) ParseResponse(raw string) (*Sample, error) {
 	return sample, nil
 }
 
+// stringField renders a decoded JSON field as a string for the []any
+// fallback path: nil becomes "", a string passes through unchanged, and
+// any other JSON value (object, array, number) is re-encoded to JSON
+// rather than fmt.Sprint's Go-syntax rendering, since fields like
+// ToolCall.Arguments are expected to hold JSON text.
+func stringField(v any) string {
+	if v == nil {
+		return ""
+	}
+	if str, ok := v.(string); ok {
+		return str
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
 func (s *ChatSchema) ValidateSample(sample *Sample) error {
 	convs, ok := sample.Fields["conversations"]
 	if !ok {
@@ -150,8 +238,20 @@ func (s *ChatSchema) ValidateSample(sample *Sample) error {
 			for _, item := range rawList {
 				if m, ok := item.(map[string]any); ok {
 					cm := ChatMessage{
-						Role:    fmt.Sprint(m["role"]),
-						Content: fmt.Sprint(m["content"]),
+						Role:       stringField(m["role"]),
+						Content:    stringField(m["content"]),
+						ToolCallID: stringField(m["tool_call_id"]),
+					}
+					if calls, ok := m["tool_calls"].([]any); ok {
+						for _, c := range calls {
+							if cm2, ok := c.(map[string]any); ok {
+								cm.ToolCalls = append(cm.ToolCalls, ChatToolCall{
+									ID:        stringField(cm2["id"]),
+									Name:      stringField(cm2["name"]),
+									Arguments: stringField(cm2["arguments"]),
+								})
+							}
+						}
 					}
 					convList = append(convList, cm)
 				}
@@ -161,21 +261,35 @@ func (s *ChatSchema) ValidateSample(sample *Sample) error {
 		}
 	}
 
+	// Unlike the structural checks above, the remaining checks are
+	// independent of each other, so they're accumulated with multierr
+	// instead of returning on the first failure — a rejected sample can
+	// have a bad role AND empty content AND too few messages, and a
+	// caller triaging rejections wants all of it in one pass.
+	var errs error
 	if len(convList) < 2 {
-		return NewSchemaError(s.Name(), "conversations", "at least 2 messages required")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "conversations", "at least 2 messages required"))
 	}
 
-	// Validate alternating roles
 	for i, msg := range convList {
-		if msg.Role != "user" && msg.Role != "assistant" && msg.Role != "system" {
-			return NewSchemaError(s.Name(), "conversations", fmt.Sprintf("invalid role at index %d: %s", i, msg.Role))
+		switch msg.Role {
+		case "user", "assistant", "system":
+		case "tool":
+			if msg.ToolCallID == "" {
+				errs = multierr.Append(errs, NewSchemaError(s.Name(), "conversations", fmt.Sprintf("tool message at index %d missing tool_call_id", i)))
+			}
+		default:
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "conversations", fmt.Sprintf("invalid role at index %d: %s", i, msg.Role)))
 		}
-		if msg.Content == "" {
-			return NewSchemaError(s.Name(), "conversations", fmt.Sprintf("empty content at index %d", i))
+
+		// An assistant turn that calls a tool instead of replying
+		// directly is allowed to leave Content empty.
+		if msg.Content == "" && !(msg.Role == "assistant" && len(msg.ToolCalls) > 0) {
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "conversations", fmt.Sprintf("empty content at index %d", i)))
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func (s *ChatSchema) ToJSON(sample *Sample) ([]byte, error) {
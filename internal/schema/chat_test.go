@@ -66,6 +66,77 @@ func TestChatSchema_ParseResponse(t *testing.T) {
 	}
 }
 
+func TestChatSchema_ParseResponse_ToolCalls(t *testing.T) {
+	s := NewChatSchema()
+
+	raw := `{
+		"conversations": [
+			{"role": "user", "content": "What's the weather in Dhaka?"},
+			{"role": "assistant", "content": "", "tool_calls": [{"id": "call_1", "name": "get_weather", "arguments": "{\"city\": \"Dhaka\"}"}]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "28C, humid"},
+			{"role": "assistant", "content": "It's 28C and humid in Dhaka."}
+		]
+	}`
+
+	sample, err := s.ParseResponse(raw)
+	if err != nil {
+		t.Fatalf("ParseResponse failed: %v", err)
+	}
+
+	convs := sample.Fields["conversations"].([]ChatMessage)
+	if len(convs) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(convs))
+	}
+	if len(convs[1].ToolCalls) != 1 || convs[1].ToolCalls[0].Name != "get_weather" {
+		t.Errorf("expected a get_weather tool call, got %+v", convs[1].ToolCalls)
+	}
+	if convs[2].ToolCallID != "call_1" {
+		t.Errorf("expected tool message to reference call_1, got %q", convs[2].ToolCallID)
+	}
+}
+
+func TestChatSchema_ValidateSample_ToolCalls(t *testing.T) {
+	s := NewChatSchema()
+
+	valid := &Sample{
+		Fields: map[string]any{
+			"conversations": []ChatMessage{
+				{Role: "user", Content: "What's the weather?"},
+				{Role: "assistant", ToolCalls: []ChatToolCall{{ID: "call_1", Name: "get_weather", Arguments: "{}"}}},
+				{Role: "tool", ToolCallID: "call_1", Content: "sunny"},
+				{Role: "assistant", Content: "It's sunny."},
+			},
+		},
+	}
+	if err := s.ValidateSample(valid); err != nil {
+		t.Errorf("ValidateSample failed for valid tool-call sample: %v", err)
+	}
+
+	missingID := &Sample{
+		Fields: map[string]any{
+			"conversations": []ChatMessage{
+				{Role: "user", Content: "hi"},
+				{Role: "tool", Content: "sunny"},
+			},
+		},
+	}
+	if err := s.ValidateSample(missingID); err == nil {
+		t.Error("ValidateSample should fail for a tool message missing tool_call_id")
+	}
+
+	emptyAssistantContent := &Sample{
+		Fields: map[string]any{
+			"conversations": []ChatMessage{
+				{Role: "user", Content: "hi"},
+				{Role: "assistant", Content: ""},
+			},
+		},
+	}
+	if err := s.ValidateSample(emptyAssistantContent); err == nil {
+		t.Error("ValidateSample should fail for empty assistant content with no tool calls")
+	}
+}
+
 func TestChatSchema_ValidateSample(t *testing.T) {
 	s := NewChatSchema()
 
@@ -5,14 +5,71 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"go.uber.org/multierr"
 )
 
+// ClassificationMode selects the output shape GeneratePrompt/ValidateSample
+// use for a ClassificationSchema.
+type ClassificationMode string
+
+const (
+	// ClassificationModeSingle assigns one label per sample. This is the
+	// default and matches the schema's original behavior.
+	ClassificationModeSingle ClassificationMode = "single"
+
+	// ClassificationModeMulti assigns zero or more labels per sample, plus
+	// a primary_label naming whichever is most central.
+	ClassificationModeMulti ClassificationMode = "multi"
+
+	// ClassificationModeHierarchical assigns a root-to-leaf path through a
+	// taxonomy per sample.
+	ClassificationModeHierarchical ClassificationMode = "hierarchical"
+)
+
+// ClassificationConfig configures a ClassificationSchema instance. The zero
+// value is ClassificationModeSingle with no label restrictions, so the
+// built-in registry entry (constructed with ClassificationConfig{}) keeps
+// today's unrestricted single-label behavior.
+//
+// Mode, Labels, LabelDefinitions and MaxLabels can all be overridden per
+// call via PromptOptions.Variables (keys "mode", "labels",
+// "label_definitions", "taxonomy", "max_labels") for prompt generation;
+// ValidateSample, which has no access to per-call options, always enforces
+// against this instance's own Config.
+type ClassificationConfig struct {
+	// Mode selects the output shape. Defaults to ClassificationModeSingle.
+	Mode ClassificationMode
+
+	// Labels restricts which labels ValidateSample accepts for
+	// ClassificationModeSingle/ClassificationModeMulti. Unrestricted when
+	// empty.
+	Labels []string
+
+	// LabelDefinitions gives a short description per label, included in
+	// the prompt to reduce ambiguity about what each label means.
+	LabelDefinitions map[string]string
+
+	// MaxLabels caps how many labels ClassificationModeMulti accepts.
+	// <= 0 means unlimited.
+	MaxLabels int
+
+	// Taxonomy lists every allowed path for ClassificationModeHierarchical,
+	// root first (e.g. {"electronics", "phones", "smartphones"}).
+	// ValidateSample accepts any sample path that's a prefix of one of
+	// these entries. Unrestricted when empty.
+	Taxonomy [][]string
+}
+
 // ClassificationSchema implements text classification format
-type ClassificationSchema struct{}
+type ClassificationSchema struct {
+	cfg ClassificationConfig
+}
 
-// NewClassificationSchema creates a new classification schema
-func NewClassificationSchema() *ClassificationSchema {
-	return &ClassificationSchema{}
+// NewClassificationSchema creates a new classification schema configured
+// by cfg.
+func NewClassificationSchema(cfg ClassificationConfig) *ClassificationSchema {
+	return &ClassificationSchema{cfg: cfg}
 }
 
 func (s *ClassificationSchema) Name() string        { return "classification" }
@@ -23,8 +80,15 @@ func (s *ClassificationSchema) Description() string {
 	return "Text classification with labels for training classifiers"
 }
 
+func (s *ClassificationSchema) mode() ClassificationMode {
+	if s.cfg.Mode == "" {
+		return ClassificationModeSingle
+	}
+	return s.cfg.Mode
+}
+
 func (s *ClassificationSchema) Fields() []FieldDefinition {
-	return []FieldDefinition{
+	fields := []FieldDefinition{
 		{
 			Name:        "text",
 			Type:        FieldTypeString,
@@ -34,15 +98,27 @@ func (s *ClassificationSchema) Fields() []FieldDefinition {
 		{
 			Name:        "label",
 			Type:        FieldTypeString,
-			Description: "The classification label",
-			Required:    true,
+			Description: "The classification label (single mode)",
+			Required:    s.mode() == ClassificationModeSingle,
 		},
 		{
 			Name:        "labels",
 			Type:        FieldTypeList,
-			Description: "Multiple labels for multi-label classification",
+			Description: "Assigned labels (multi-label mode)",
+			Required:    s.mode() == ClassificationModeMulti,
+		},
+		{
+			Name:        "primary_label",
+			Type:        FieldTypeString,
+			Description: "The most central label among labels (multi-label mode)",
 			Required:    false,
 		},
+		{
+			Name:        "path",
+			Type:        FieldTypeList,
+			Description: "Root-to-leaf taxonomy path (hierarchical mode)",
+			Required:    s.mode() == ClassificationModeHierarchical,
+		},
 		{
 			Name:        "confidence",
 			Type:        FieldTypeFloat,
@@ -50,10 +126,18 @@ func (s *ClassificationSchema) Fields() []FieldDefinition {
 			Required:    false,
 		},
 	}
+	return fields
 }
 
 func (s *ClassificationSchema) RequiredFields() []string {
-	return []string{"text", "label"}
+	switch s.mode() {
+	case ClassificationModeMulti:
+		return []string{"text", "labels"}
+	case ClassificationModeHierarchical:
+		return []string{"text", "path"}
+	default:
+		return []string{"text", "label"}
+	}
 }
 
 func (s *ClassificationSchema) GeneratePrompt(ctx context.Context, opts PromptOptions) (string, error) {
@@ -65,26 +149,61 @@ func (s *ClassificationSchema) GeneratePrompt(ctx context.Context, opts PromptOp
 		sb.WriteString(fmt.Sprintf("Category/Domain: %s\n", opts.Topic))
 	}
 
-	// Get labels from variables if provided
-	var labels []string
+	mode := s.mode()
+	labels := s.cfg.Labels
+	labelDefs := s.cfg.LabelDefinitions
+	taxonomy := s.cfg.Taxonomy
+	maxLabels := s.cfg.MaxLabels
+
 	if opts.Variables != nil {
+		if m, ok := opts.Variables["mode"].(string); ok && m != "" {
+			mode = ClassificationMode(m)
+		}
 		if labelList, ok := opts.Variables["labels"].([]string); ok {
 			labels = labelList
 		}
+		if defs, ok := opts.Variables["label_definitions"].(map[string]string); ok {
+			labelDefs = defs
+		}
+		if tax, ok := opts.Variables["taxonomy"].([][]string); ok {
+			taxonomy = tax
+		}
+		if max, ok := opts.Variables["max_labels"].(int); ok && max > 0 {
+			maxLabels = max
+		}
 	}
 
 	sb.WriteString("\n")
 
+	switch mode {
+	case ClassificationModeMulti:
+		s.writeMultiPrompt(&sb, labels, labelDefs, maxLabels)
+	case ClassificationModeHierarchical:
+		s.writeHierarchicalPrompt(&sb, taxonomy)
+	default:
+		s.writeSinglePrompt(&sb, labels, labelDefs)
+	}
+
+	sb.WriteString("\n\nRespond with ONLY the JSON object, no additional text.")
+
+	return sb.String(), nil
+}
+
+func (s *ClassificationSchema) writeSinglePrompt(sb *strings.Builder, labels []string, defs map[string]string) {
 	if len(labels) > 0 {
-		sb.WriteString(fmt.Sprintf("Available labels: %s\n\n", strings.Join(labels, ", ")))
-		sb.WriteString(`Generate a text sample and assign the most appropriate label:
+		sb.WriteString(fmt.Sprintf("Available labels: %s\n", strings.Join(labels, ", ")))
+		writeLabelDefinitions(sb, labels, defs)
+		sb.WriteString(`
+Generate a text sample and assign the most appropriate label:
 
 {
   "text": "The text content to classify",
   "label": "one_of_the_available_labels"
 }`)
-	} else {
-		sb.WriteString(`Generate a text classification example with an appropriate label:
+		return
+	}
+
+	sb.WriteString(`Generate a text classification example with an appropriate label:
 
 {
   "text": "The text content to classify",
@@ -97,11 +216,114 @@ Common classification types:
 - Intent: question, request, complaint, feedback, etc.
 - Toxicity: toxic, non-toxic
 - Language: en, es, fr, de, etc.`)
+}
+
+func (s *ClassificationSchema) writeMultiPrompt(sb *strings.Builder, labels []string, defs map[string]string, maxLabels int) {
+	if len(labels) > 0 {
+		sb.WriteString(fmt.Sprintf("Available labels: %s\n", strings.Join(labels, ", ")))
+		writeLabelDefinitions(sb, labels, defs)
 	}
 
-	sb.WriteString("\n\nRespond with ONLY the JSON object, no additional text.")
+	constraint := "Assign one or more labels that apply."
+	if maxLabels > 0 {
+		constraint = fmt.Sprintf("Assign one to %d labels that apply.", maxLabels)
+	}
 
-	return sb.String(), nil
+	sb.WriteString(fmt.Sprintf(`
+Generate a text sample for multi-label classification. %s Set primary_label to whichever assigned label is most central to the text:
+
+{
+  "text": "The text content to classify",
+  "labels": ["label_one", "label_two"],
+  "primary_label": "label_one"
+}`, constraint))
+}
+
+func (s *ClassificationSchema) writeHierarchicalPrompt(sb *strings.Builder, taxonomy [][]string) {
+	if len(taxonomy) > 0 {
+		sb.WriteString("Available taxonomy paths (root to leaf):\n")
+		for _, entry := range taxonomy {
+			sb.WriteString(fmt.Sprintf("  - %s\n", strings.Join(entry, " > ")))
+		}
+	}
+
+	sb.WriteString(`
+Generate a text sample and classify it against the taxonomy, from root category down to the most specific leaf that applies:
+
+{
+  "text": "The text content to classify",
+  "path": ["root_category", "sub_category", "leaf_category"]
+}`)
+}
+
+// writeLabelDefinitions appends a short description line per label that
+// has one in defs, to reduce ambiguity about what each label means.
+func writeLabelDefinitions(sb *strings.Builder, labels []string, defs map[string]string) {
+	if len(defs) == 0 {
+		return
+	}
+	for _, l := range labels {
+		if desc, ok := defs[l]; ok && desc != "" {
+			sb.WriteString(fmt.Sprintf("  - %s: %s\n", l, desc))
+		}
+	}
+}
+
+// ResponseJSONSchema implements Schema.ResponseJSONSchema, additionally
+// constraining the label/labels field to cfg.Labels's enum when one is
+// configured, so a structured-output-capable provider can't return a label
+// outside the allowed set. Like buildResponseJSONSchema, every field is
+// listed in "required" (optional fields nullable instead of absent) and
+// "additionalProperties" is false, for OpenAI strict-mode compatibility.
+func (s *ClassificationSchema) ResponseJSONSchema() []byte {
+	fields := s.Fields()
+	properties := make(map[string]any, len(fields))
+	allFields := make([]string, 0, len(fields))
+	for _, f := range fields {
+		prop := map[string]any{
+			"type":        jsonSchemaTypeOrNullable(f.Type, f.Required),
+			"description": f.Description,
+		}
+		if len(s.cfg.Labels) > 0 {
+			switch f.Name {
+			case "label":
+				// label's type is nullable whenever the field isn't required
+				// (e.g. multi-label/hierarchical mode); the enum has to admit
+				// null too; or a strict-mode provider could never satisfy
+				// both constraints for an unused label field.
+				enum := make([]any, 0, len(s.cfg.Labels)+1)
+				for _, l := range s.cfg.Labels {
+					enum = append(enum, l)
+				}
+				if !f.Required {
+					enum = append(enum, nil)
+				}
+				prop["enum"] = enum
+			case "labels":
+				prop["items"] = map[string]any{"type": "string", "enum": s.cfg.Labels}
+			}
+		}
+		properties[f.Name] = prop
+		allFields = append(allFields, f.Name)
+	}
+
+	return assembleResponseJSONSchema(s.Name(), properties, allFields)
+}
+
+// JSONSchema implements Schema.JSONSchema: text gets a minLength mirroring
+// ValidateSample's "too short" check, and label/labels are constrained to
+// cfg.Labels's enum when one is configured, same as ResponseJSONSchema -
+// but, unlike ResponseJSONSchema, optional fields are simply absent from
+// "required" rather than nullable, so the enum never needs to admit null.
+func (s *ClassificationSchema) JSONSchema() ([]byte, error) {
+	overrides := map[string]map[string]any{
+		"text": {"minLength": 5},
+	}
+	if len(s.cfg.Labels) > 0 {
+		overrides["label"] = map[string]any{"enum": s.cfg.Labels}
+		overrides["labels"] = map[string]any{"items": map[string]any{"type": "string", "enum": s.cfg.Labels}}
+	}
+	return buildJSONSchema(s.Name(), s.Description(), s.Fields(), s.RequiredFields(), overrides)
 }
 
 func (s *ClassificationSchema) ParseResponse(raw string) (*Sample, error) {
@@ -118,10 +340,12 @@ func (s *ClassificationSchema) ParseResponse(raw string) (*Sample, error) {
 	}
 
 	var data struct {
-		Text       string   `json:"text"`
-		Label      string   `json:"label"`
-		Labels     []string `json:"labels,omitempty"`
-		Confidence float64  `json:"confidence,omitempty"`
+		Text         string   `json:"text"`
+		Label        string   `json:"label"`
+		Labels       []string `json:"labels,omitempty"`
+		PrimaryLabel string   `json:"primary_label,omitempty"`
+		Path         []string `json:"path,omitempty"`
+		Confidence   float64  `json:"confidence,omitempty"`
 	}
 
 	if err := json.Unmarshal([]byte(raw), &data); err != nil {
@@ -135,6 +359,12 @@ func (s *ClassificationSchema) ParseResponse(raw string) (*Sample, error) {
 	if len(data.Labels) > 0 {
 		fields["labels"] = data.Labels
 	}
+	if data.PrimaryLabel != "" {
+		fields["primary_label"] = data.PrimaryLabel
+	}
+	if len(data.Path) > 0 {
+		fields["path"] = data.Path
+	}
 	if data.Confidence > 0 {
 		fields["confidence"] = data.Confidence
 	}
@@ -147,21 +377,118 @@ func (s *ClassificationSchema) ParseResponse(raw string) (*Sample, error) {
 }
 
 func (s *ClassificationSchema) ValidateSample(sample *Sample) error {
+	var errs error
+
 	text := sample.GetString("text")
 	if text == "" {
-		return NewSchemaError(s.Name(), "text", "text is required")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "text", "text is required"))
+	} else if len(text) < 5 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "text", "text is too short"))
 	}
 
+	switch s.mode() {
+	case ClassificationModeMulti:
+		errs = multierr.Append(errs, s.validateMulti(sample))
+	case ClassificationModeHierarchical:
+		errs = multierr.Append(errs, s.validateHierarchical(sample))
+	default:
+		errs = multierr.Append(errs, s.validateSingle(sample))
+	}
+
+	return errs
+}
+
+func (s *ClassificationSchema) validateSingle(sample *Sample) error {
+	var errs error
+
 	label := sample.GetString("label")
 	if label == "" {
-		return NewSchemaError(s.Name(), "label", "label is required")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "label", "label is required"))
+	} else if len(s.cfg.Labels) > 0 && !containsString(s.cfg.Labels, label) {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "label", fmt.Sprintf("label %q is not in the allowed set", label)))
+	}
+
+	return errs
+}
+
+func (s *ClassificationSchema) validateMulti(sample *Sample) error {
+	var errs error
+
+	labels := sample.GetStrings("labels")
+	if len(labels) == 0 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "labels", "at least one label is required"))
+	}
+	if s.cfg.MaxLabels > 0 && len(labels) > s.cfg.MaxLabels {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "labels", fmt.Sprintf("at most %d labels are allowed, got %d", s.cfg.MaxLabels, len(labels))))
+	}
+	for _, l := range labels {
+		if l == "" {
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "labels", "labels must not be empty"))
+			continue
+		}
+		if len(s.cfg.Labels) > 0 && !containsString(s.cfg.Labels, l) {
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "labels", fmt.Sprintf("label %q is not in the allowed set", l)))
+		}
 	}
 
-	if len(text) < 5 {
-		return NewSchemaError(s.Name(), "text", "text is too short")
+	if primary := sample.GetString("primary_label"); primary != "" && len(labels) > 0 && !containsString(labels, primary) {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "primary_label", "primary_label must be one of labels"))
 	}
 
-	return nil
+	return errs
+}
+
+func (s *ClassificationSchema) validateHierarchical(sample *Sample) error {
+	var errs error
+
+	path := sample.GetStrings("path")
+	if len(path) == 0 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "path", "path is required"))
+		return errs
+	}
+
+	for _, seg := range path {
+		if seg == "" {
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "path", "path segments must not be empty"))
+		}
+	}
+
+	if len(s.cfg.Taxonomy) > 0 && !taxonomyAllows(s.cfg.Taxonomy, path) {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "path", fmt.Sprintf("path %v is not a prefix of any taxonomy entry", path)))
+	}
+
+	return errs
+}
+
+// taxonomyAllows reports whether path is a prefix of (or equal to) at
+// least one full taxonomy entry, so an intermediate, non-leaf path is
+// still considered valid.
+func taxonomyAllows(taxonomy [][]string, path []string) bool {
+	for _, entry := range taxonomy {
+		if len(path) > len(entry) {
+			continue
+		}
+		match := true
+		for i, seg := range path {
+			if entry[i] != seg {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *ClassificationSchema) ToJSON(sample *Sample) ([]byte, error) {
@@ -7,7 +7,7 @@ import (
 )
 
 func TestClassificationSchema_GeneratePrompt(t *testing.T) {
-	s := NewClassificationSchema()
+	s := NewClassificationSchema(ClassificationConfig{})
 	ctx := context.Background()
 
 	opts := PromptOptions{
@@ -31,7 +31,7 @@ func TestClassificationSchema_GeneratePrompt(t *testing.T) {
 }
 
 func TestClassificationSchema_ParseResponse(t *testing.T) {
-	s := NewClassificationSchema()
+	s := NewClassificationSchema(ClassificationConfig{})
 
 	validJSON := `{
 		"text": "This movie was great!",
@@ -58,7 +58,7 @@ func TestClassificationSchema_ParseResponse(t *testing.T) {
 }
 
 func TestClassificationSchema_ValidateSample(t *testing.T) {
-	s := NewClassificationSchema()
+	s := NewClassificationSchema(ClassificationConfig{})
 
 	tests := []struct {
 		name    string
@@ -114,3 +114,132 @@ func TestClassificationSchema_ValidateSample(t *testing.T) {
 		})
 	}
 }
+
+func TestClassificationSchema_MultiMode(t *testing.T) {
+	s := NewClassificationSchema(ClassificationConfig{
+		Mode:      ClassificationModeMulti,
+		Labels:    []string{"billing", "technical_issue", "spam"},
+		MaxLabels: 2,
+	})
+	ctx := context.Background()
+
+	prompt, err := s.GeneratePrompt(ctx, PromptOptions{})
+	if err != nil {
+		t.Fatalf("GeneratePrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, `"labels"`) || !strings.Contains(prompt, `"primary_label"`) {
+		t.Error("prompt missing multi-label JSON shape")
+	}
+	if !strings.Contains(prompt, "one to 2 labels") {
+		t.Error("prompt missing MaxLabels constraint")
+	}
+
+	tests := []struct {
+		name    string
+		sample  *Sample
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			sample: &Sample{Fields: map[string]any{
+				"text": "valid text content", "labels": []string{"billing", "spam"}, "primary_label": "billing",
+			}},
+			wantErr: false,
+		},
+		{
+			name: "no labels",
+			sample: &Sample{Fields: map[string]any{
+				"text": "valid text content",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "too many labels",
+			sample: &Sample{Fields: map[string]any{
+				"text": "valid text content", "labels": []string{"billing", "spam", "technical_issue"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "label not in allowed set",
+			sample: &Sample{Fields: map[string]any{
+				"text": "valid text content", "labels": []string{"unknown"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "primary_label not among labels",
+			sample: &Sample{Fields: map[string]any{
+				"text": "valid text content", "labels": []string{"billing"}, "primary_label": "spam",
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateSample(tt.sample)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSample() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClassificationSchema_HierarchicalMode(t *testing.T) {
+	s := NewClassificationSchema(ClassificationConfig{
+		Mode: ClassificationModeHierarchical,
+		Taxonomy: [][]string{
+			{"electronics", "phones", "smartphones"},
+			{"electronics", "phones"},
+		},
+	})
+	ctx := context.Background()
+
+	prompt, err := s.GeneratePrompt(ctx, PromptOptions{})
+	if err != nil {
+		t.Fatalf("GeneratePrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, `"path"`) {
+		t.Error("prompt missing hierarchical JSON shape")
+	}
+	if !strings.Contains(prompt, "electronics > phones > smartphones") {
+		t.Error("prompt missing rendered taxonomy path")
+	}
+
+	tests := []struct {
+		name    string
+		sample  *Sample
+		wantErr bool
+	}{
+		{
+			name:    "valid leaf path",
+			sample:  &Sample{Fields: map[string]any{"text": "valid text content", "path": []string{"electronics", "phones", "smartphones"}}},
+			wantErr: false,
+		},
+		{
+			name:    "valid intermediate path",
+			sample:  &Sample{Fields: map[string]any{"text": "valid text content", "path": []string{"electronics", "phones"}}},
+			wantErr: false,
+		},
+		{
+			name:    "missing path",
+			sample:  &Sample{Fields: map[string]any{"text": "valid text content"}},
+			wantErr: true,
+		},
+		{
+			name:    "path not in taxonomy",
+			sample:  &Sample{Fields: map[string]any{"text": "valid text content", "path": []string{"books", "fiction"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateSample(tt.sample)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSample() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// conformanceFixture describes one schema's conformance cases, loaded from
+// a testdata/*.yaml file. See TestSchemas_Conformance.
+type conformanceFixture struct {
+	Schema      string                     `yaml:"schema"`
+	Valid       []conformanceSample        `yaml:"valid"`
+	Invalid     []conformanceInvalidSample `yaml:"invalid"`
+	ParseInputs []conformanceParseInput    `yaml:"parse_inputs"`
+}
+
+type conformanceSample struct {
+	Name   string         `yaml:"name"`
+	Fields map[string]any `yaml:"fields"`
+}
+
+type conformanceInvalidSample struct {
+	Name             string         `yaml:"name"`
+	Fields           map[string]any `yaml:"fields"`
+	ExpectErrorField string         `yaml:"expect_error_field"`
+}
+
+type conformanceParseInput struct {
+	Name         string         `yaml:"name"`
+	Raw          string         `yaml:"raw"`
+	ExpectError  bool           `yaml:"expect_error"`
+	ExpectFields map[string]any `yaml:"expect_fields"`
+}
+
+// TestSchemas_Conformance runs every fixture in testdata/*.yaml against the
+// schema it names. Each fixture lists valid and invalid field sets to run
+// through ValidateSample, plus optional raw model responses to round-trip
+// through ParseResponse. This lets contributors add coverage for a new
+// schema, or an edge case on an existing one, by adding a YAML file instead
+// of writing Go.
+func TestSchemas_Conformance(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.yaml")
+	if err != nil {
+		t.Fatalf("failed to list fixtures: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no fixtures found in testdata/")
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			var fixture conformanceFixture
+			if err := yaml.Unmarshal(data, &fixture); err != nil {
+				t.Fatalf("failed to parse fixture: %v", err)
+			}
+
+			s, err := Get(fixture.Schema)
+			if err != nil {
+				t.Fatalf("schema %q not registered: %v", fixture.Schema, err)
+			}
+
+			for i, c := range fixture.Valid {
+				c := c
+				t.Run(fixtureCaseName("valid", i, c.Name), func(t *testing.T) {
+					sample := &Sample{Fields: c.Fields}
+					if err := s.ValidateSample(sample); err != nil {
+						t.Errorf("expected valid sample to pass, got: %v", err)
+					}
+				})
+			}
+
+			for i, c := range fixture.Invalid {
+				c := c
+				t.Run(fixtureCaseName("invalid", i, c.Name), func(t *testing.T) {
+					sample := &Sample{Fields: c.Fields}
+					err := s.ValidateSample(sample)
+					if err == nil {
+						t.Fatal("expected invalid sample to fail validation")
+					}
+					if c.ExpectErrorField == "" {
+						return
+					}
+					var schemaErr *SchemaError
+					if !errors.As(err, &schemaErr) {
+						t.Fatalf("expected a *SchemaError, got %T: %v", err, err)
+					}
+					if schemaErr.Field != c.ExpectErrorField {
+						t.Errorf("expected error field %q, got %q", c.ExpectErrorField, schemaErr.Field)
+					}
+				})
+			}
+
+			for i, c := range fixture.ParseInputs {
+				c := c
+				t.Run(fixtureCaseName("parse", i, c.Name), func(t *testing.T) {
+					sample, err := s.ParseResponse(c.Raw)
+					if c.ExpectError {
+						if err == nil {
+							t.Fatal("expected ParseResponse to fail")
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("ParseResponse failed: %v", err)
+					}
+					for field, want := range c.ExpectFields {
+						got, ok := sample.Get(field)
+						if !ok {
+							t.Errorf("expected field %q to be set", field)
+							continue
+						}
+						if got != want {
+							t.Errorf("field %q: expected %v, got %v", field, want, got)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+func fixtureCaseName(kind string, index int, name string) string {
+	if name != "" {
+		return kind + "/" + name
+	}
+	return fmt.Sprintf("%s/%d", kind, index)
+}
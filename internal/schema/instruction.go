@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"go.uber.org/multierr"
 )
 
 // InstructionSchema implements the Alpaca-style instruction-response format
@@ -120,6 +122,20 @@ Requirements:
 	return sb.String(), nil
 }
 
+// ResponseJSONSchema implements Schema.ResponseJSONSchema.
+func (s *InstructionSchema) ResponseJSONSchema() []byte {
+	return buildResponseJSONSchema(s.Name(), s.Fields(), s.RequiredFields())
+}
+
+// JSONSchema implements Schema.JSONSchema, with minLength on instruction and
+// output mirroring ValidateSample's "too short" checks.
+func (s *InstructionSchema) JSONSchema() ([]byte, error) {
+	return buildJSONSchema(s.Name(), s.Description(), s.Fields(), s.RequiredFields(), map[string]map[string]any{
+		"instruction": {"minLength": 10},
+		"output":      {"minLength": 10},
+	})
+}
+
 func (s *InstructionSchema) ParseResponse(raw string) (*Sample, error) {
 	raw = StripCodeBlock(raw)
 
@@ -147,25 +163,23 @@ func (s *InstructionSchema) ParseResponse(raw string) (*Sample, error) {
 }
 
 func (s *InstructionSchema) ValidateSample(sample *Sample) error {
+	var errs error
+
 	instruction := sample.GetString("instruction")
 	if instruction == "" {
-		return NewSchemaError(s.Name(), "instruction", "instruction is required")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "instruction", "instruction is required"))
+	} else if len(instruction) < 10 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "instruction", "instruction is too short"))
 	}
 
 	output := sample.GetString("output")
 	if output == "" {
-		return NewSchemaError(s.Name(), "output", "output is required")
-	}
-
-	// Quality checks
-	if len(instruction) < 10 {
-		return NewSchemaError(s.Name(), "instruction", "instruction is too short")
-	}
-	if len(output) < 10 {
-		return NewSchemaError(s.Name(), "output", "output is too short")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "output", "output is required"))
+	} else if len(output) < 10 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "output", "output is too short"))
 	}
 
-	return nil
+	return errs
 }
 
 func (s *InstructionSchema) ToJSON(sample *Sample) ([]byte, error) {
@@ -0,0 +1,205 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// KTOSchema implements the Kahneman-Tversky Optimization format: unpaired
+// (prompt, completion, label) records where label is a simple
+// desirable/undesirable binary judgment, rather than a chosen/rejected pair
+// (see PreferenceSchema). This is what TRL's KTOTrainer expects.
+type KTOSchema struct{}
+
+// NewKTOSchema creates a new KTO schema
+func NewKTOSchema() *KTOSchema {
+	return &KTOSchema{}
+}
+
+func (s *KTOSchema) Name() string        { return "kto" }
+func (s *KTOSchema) Style() DatasetStyle { return StylePreference }
+func (s *KTOSchema) Version() string     { return "1.0" }
+
+func (s *KTOSchema) Description() string {
+	return "KTO unpaired prompt/completion/label records for Kahneman-Tversky Optimization"
+}
+
+func (s *KTOSchema) Fields() []FieldDefinition {
+	return []FieldDefinition{
+		{
+			Name:        "prompt",
+			Type:        FieldTypeString,
+			Description: "The instruction or question",
+			Required:    true,
+		},
+		{
+			Name:        "completion",
+			Type:        FieldTypeString,
+			Description: "A single candidate response to the prompt",
+			Required:    true,
+		},
+		{
+			Name:        "label",
+			Type:        FieldTypeBool,
+			Description: "true if completion is desirable, false if undesirable",
+			Required:    true,
+		},
+		{
+			Name:        "rationale",
+			Type:        FieldTypeString,
+			Description: "Why the completion was judged desirable or undesirable",
+			Required:    false,
+		},
+	}
+}
+
+func (s *KTOSchema) RequiredFields() []string {
+	return []string{"prompt", "completion", "label"}
+}
+
+func (s *KTOSchema) GeneratePrompt(ctx context.Context, opts PromptOptions) (string, error) {
+	var sb strings.Builder
+
+	// Inject user context first (from context.yaml)
+	if opts.UserContext != "" {
+		sb.WriteString(opts.UserContext)
+		sb.WriteString("\n\n")
+	} else {
+		// Default context if none provided
+		sb.WriteString("Generate a single prompt/completion pair with a desirability judgment for KTO training.\n\n")
+	}
+
+	if opts.Topic != "" {
+		sb.WriteString(fmt.Sprintf("Topic: %s\n", opts.Topic))
+	}
+	if opts.Category != "" {
+		sb.WriteString(fmt.Sprintf("Category: %s\n", opts.Category))
+	}
+
+	sb.WriteString("\n")
+
+	sb.WriteString(`Generate a prompt and one candidate response, then judge whether that
+response is desirable (helpful, accurate, safe, well-written) or
+undesirable (unhelpful, inaccurate, unsafe, or poorly written):
+
+{
+  "prompt": "A clear question or instruction",
+  "completion": "A single candidate response to the prompt",
+  "label": true,
+  "rationale": "A short explanation of why the completion was judged desirable or not"
+}
+
+Unlike a chosen/rejected pair, this is a single unpaired judgment: about
+half the samples you generate across a batch should have label true
+(desirable) and about half should have label false (undesirable), so the
+dataset carries both signals.`)
+
+	// Inject user instructions (from context.yaml)
+	if opts.UserInstruction != "" {
+		sb.WriteString("\n\nAdditional Instructions:\n")
+		sb.WriteString(opts.UserInstruction)
+	}
+
+	sb.WriteString("\n\nRespond with ONLY the JSON object, no additional text.")
+
+	return sb.String(), nil
+}
+
+// ResponseJSONSchema implements Schema.ResponseJSONSchema.
+func (s *KTOSchema) ResponseJSONSchema() []byte {
+	return buildResponseJSONSchema(s.Name(), s.Fields(), s.RequiredFields())
+}
+
+// JSONSchema implements Schema.JSONSchema, with minLength on prompt
+// mirroring ValidateSample's "too short" check.
+func (s *KTOSchema) JSONSchema() ([]byte, error) {
+	return buildJSONSchema(s.Name(), s.Description(), s.Fields(), s.RequiredFields(), map[string]map[string]any{
+		"prompt": {"minLength": 10},
+	})
+}
+
+func (s *KTOSchema) ParseResponse(raw string) (*Sample, error) {
+	raw = strings.TrimSpace(raw)
+
+	if strings.HasPrefix(raw, "```json") {
+		raw = strings.TrimPrefix(raw, "```json")
+		raw = strings.TrimSuffix(raw, "```")
+		raw = strings.TrimSpace(raw)
+	} else if strings.HasPrefix(raw, "```") {
+		raw = strings.TrimPrefix(raw, "```")
+		raw = strings.TrimSuffix(raw, "```")
+		raw = strings.TrimSpace(raw)
+	}
+
+	var data struct {
+		Prompt     string `json:"prompt"`
+		Completion string `json:"completion"`
+		Label      *bool  `json:"label"`
+		Rationale  string `json:"rationale,omitempty"`
+	}
+
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fields := map[string]any{
+		"prompt":     data.Prompt,
+		"completion": data.Completion,
+	}
+	// Label is a *bool so a response that omits "label" entirely is
+	// distinguishable from an explicit false - otherwise it would decode
+	// to Go's bool zero value and ValidateSample's "label is required"
+	// check could never fire for a malformed response.
+	if data.Label != nil {
+		fields["label"] = *data.Label
+	}
+	if data.Rationale != "" {
+		fields["rationale"] = data.Rationale
+	}
+
+	sample := &Sample{
+		Fields: fields,
+	}
+
+	return sample, nil
+}
+
+func (s *KTOSchema) ValidateSample(sample *Sample) error {
+	var errs error
+
+	prompt := sample.GetString("prompt")
+	if prompt == "" {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "prompt", "prompt is required"))
+	} else if len(prompt) < 10 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "prompt", "prompt is too short"))
+	}
+
+	completion := sample.GetString("completion")
+	if completion == "" {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "completion", "completion is required"))
+	}
+
+	if label, ok := sample.Get("label"); !ok {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "label", "label is required"))
+	} else if _, ok := label.(bool); !ok {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "label", "label must be a boolean"))
+	}
+
+	return errs
+}
+
+func (s *KTOSchema) ToJSON(sample *Sample) ([]byte, error) {
+	return json.MarshalIndent(sample.Fields, "", "  ")
+}
+
+func (s *KTOSchema) ToJSONL(sample *Sample) ([]byte, error) {
+	data, err := json.Marshal(sample.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestKTOSchema_GeneratePrompt(t *testing.T) {
+	s := NewKTOSchema()
+	ctx := context.Background()
+
+	opts := PromptOptions{Topic: "Cooking"}
+
+	prompt, err := s.GeneratePrompt(ctx, opts)
+	if err != nil {
+		t.Fatalf("GeneratePrompt failed: %v", err)
+	}
+
+	if !strings.Contains(prompt, "Topic: Cooking") {
+		t.Error("Prompt missing topic")
+	}
+	if !strings.Contains(prompt, "\"label\"") {
+		t.Error("Prompt missing label field")
+	}
+}
+
+func TestKTOSchema_ParseResponse(t *testing.T) {
+	s := NewKTOSchema()
+
+	validJSON := `{"prompt": "Explain gravity", "completion": "Gravity pulls objects together.", "label": true}`
+
+	sample, err := s.ParseResponse(validJSON)
+	if err != nil {
+		t.Fatalf("ParseResponse failed: %v", err)
+	}
+
+	if sample.GetString("completion") != "Gravity pulls objects together." {
+		t.Error("Incorrect completion parsed")
+	}
+	label, _ := sample.Get("label")
+	if label != true {
+		t.Errorf("label = %v, want true", label)
+	}
+}
+
+func TestKTOSchema_ParseResponse_MissingLabelFailsValidation(t *testing.T) {
+	s := NewKTOSchema()
+
+	// A response that omits "label" entirely must not be indistinguishable
+	// from an explicit false: ParseResponse should leave the field unset
+	// so ValidateSample's "label is required" check actually fires.
+	missingLabelJSON := `{"prompt": "Explain gravity", "completion": "Gravity pulls objects together."}`
+
+	sample, err := s.ParseResponse(missingLabelJSON)
+	if err != nil {
+		t.Fatalf("ParseResponse failed: %v", err)
+	}
+
+	if _, ok := sample.Get("label"); ok {
+		t.Error("expected label to be absent when omitted from the response")
+	}
+
+	if err := s.ValidateSample(sample); err == nil {
+		t.Error("expected ValidateSample to reject a sample with no label")
+	}
+}
+
+func TestKTOSchema_ValidateSample(t *testing.T) {
+	s := NewKTOSchema()
+
+	tests := []struct {
+		name    string
+		sample  *Sample
+		wantErr bool
+	}{
+		{
+			name: "valid desirable sample",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":     "Explain how gravity works",
+					"completion": "Gravity pulls objects with mass toward each other.",
+					"label":      true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid undesirable sample",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":     "Explain how gravity works",
+					"completion": "Gravity is magic.",
+					"label":      false,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing label",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":     "Explain how gravity works",
+					"completion": "Gravity pulls objects together.",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "label not a boolean",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":     "Explain how gravity works",
+					"completion": "Gravity pulls objects together.",
+					"label":      "true",
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateSample(tt.sample)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSample() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"go.uber.org/multierr"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
 )
 
 // PreferenceSchema implements the DPO/RLHF preference format
@@ -43,6 +47,24 @@ func (s *PreferenceSchema) Fields() []FieldDefinition {
 			Description: "The less preferred/worse response",
 			Required:    true,
 		},
+		{
+			Name:        "chosen_score",
+			Type:        FieldTypeFloat,
+			Description: "Quality score for chosen (0-1)",
+			Required:    false,
+		},
+		{
+			Name:        "rejected_score",
+			Type:        FieldTypeFloat,
+			Description: "Quality score for rejected (0-1)",
+			Required:    false,
+		},
+		{
+			Name:        "rationale",
+			Type:        FieldTypeString,
+			Description: "Why chosen is preferred over rejected",
+			Required:    false,
+		},
 	}
 }
 
@@ -76,7 +98,10 @@ func (s *PreferenceSchema) GeneratePrompt(ctx context.Context, opts PromptOption
 {
   "prompt": "A clear question or instruction",
   "chosen": "The preferred response - helpful, accurate, safe, and well-written",
-  "rejected": "A less preferred response - could be less helpful, less accurate, less safe, or lower quality"
+  "rejected": "A less preferred response - could be less helpful, less accurate, less safe, or lower quality",
+  "chosen_score": 0.9,
+  "rejected_score": 0.3,
+  "rationale": "A short explanation of why chosen is preferred over rejected"
 }
 
 The difference between chosen and rejected should represent clear quality distinctions:
@@ -84,7 +109,10 @@ The difference between chosen and rejected should represent clear quality distin
 - Helpfulness: chosen directly addresses the need, rejected is vague
 - Safety: chosen avoids harmful content, rejected may be borderline
 - Clarity: chosen is well-organized, rejected is confusing
-- Completeness: chosen is thorough, rejected is incomplete`)
+- Completeness: chosen is thorough, rejected is incomplete
+
+chosen_score and rejected_score are each 0-1 quality scores; rationale
+explains the preference in a sentence or two.`)
 
 	// Inject user instructions (from context.yaml)
 	if opts.UserInstruction != "" {
@@ -97,6 +125,19 @@ The difference between chosen and rejected should represent clear quality distin
 	return sb.String(), nil
 }
 
+// ResponseJSONSchema implements Schema.ResponseJSONSchema.
+func (s *PreferenceSchema) ResponseJSONSchema() []byte {
+	return buildResponseJSONSchema(s.Name(), s.Fields(), s.RequiredFields())
+}
+
+// JSONSchema implements Schema.JSONSchema, with minLength on prompt
+// mirroring ValidateSample's "too short" check.
+func (s *PreferenceSchema) JSONSchema() ([]byte, error) {
+	return buildJSONSchema(s.Name(), s.Description(), s.Fields(), s.RequiredFields(), map[string]map[string]any{
+		"prompt": {"minLength": 10},
+	})
+}
+
 func (s *PreferenceSchema) ParseResponse(raw string) (*Sample, error) {
 	raw = strings.TrimSpace(raw)
 
@@ -111,51 +152,75 @@ func (s *PreferenceSchema) ParseResponse(raw string) (*Sample, error) {
 	}
 
 	var data struct {
-		Prompt   string `json:"prompt"`
-		Chosen   string `json:"chosen"`
-		Rejected string `json:"rejected"`
+		Prompt        string  `json:"prompt"`
+		Chosen        string  `json:"chosen"`
+		Rejected      string  `json:"rejected"`
+		ChosenScore   float64 `json:"chosen_score,omitempty"`
+		RejectedScore float64 `json:"rejected_score,omitempty"`
+		Rationale     string  `json:"rationale,omitempty"`
 	}
 
 	if err := json.Unmarshal([]byte(raw), &data); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	fields := map[string]any{
+		"prompt":   data.Prompt,
+		"chosen":   data.Chosen,
+		"rejected": data.Rejected,
+	}
+	if data.ChosenScore > 0 {
+		fields["chosen_score"] = data.ChosenScore
+	}
+	if data.RejectedScore > 0 {
+		fields["rejected_score"] = data.RejectedScore
+	}
+	if data.Rationale != "" {
+		fields["rationale"] = data.Rationale
+	}
+
 	sample := &Sample{
-		Fields: map[string]any{
-			"prompt":   data.Prompt,
-			"chosen":   data.Chosen,
-			"rejected": data.Rejected,
-		},
+		Fields: fields,
 	}
 
 	return sample, nil
 }
 
 func (s *PreferenceSchema) ValidateSample(sample *Sample) error {
+	var errs error
+
 	prompt := sample.GetString("prompt")
 	if prompt == "" {
-		return NewSchemaError(s.Name(), "prompt", "prompt is required")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "prompt", "prompt is required"))
+	} else if len(prompt) < 10 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "prompt", "prompt is too short"))
 	}
 
 	chosen := sample.GetString("chosen")
 	if chosen == "" {
-		return NewSchemaError(s.Name(), "chosen", "chosen is required")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "chosen", "chosen is required"))
 	}
 
 	rejected := sample.GetString("rejected")
 	if rejected == "" {
-		return NewSchemaError(s.Name(), "rejected", "rejected is required")
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "rejected", "rejected is required"))
 	}
 
-	// Quality checks
-	if len(prompt) < 10 {
-		return NewSchemaError(s.Name(), "prompt", "prompt is too short")
+	if chosen != "" && rejected != "" && chosen == rejected {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "chosen", "chosen and rejected should be different"))
 	}
-	if chosen == rejected {
-		return NewSchemaError(s.Name(), "chosen", "chosen and rejected should be different")
+
+	chosenScore, hasChosenScore := sample.Get("chosen_score")
+	rejectedScore, hasRejectedScore := sample.Get("rejected_score")
+	if hasChosenScore && hasRejectedScore {
+		cs, csOK := chosenScore.(float64)
+		rs, rsOK := rejectedScore.(float64)
+		if csOK && rsOK && cs <= rs {
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "chosen_score", "chosen_score should be greater than rejected_score"))
+		}
 	}
 
-	return nil
+	return errs
 }
 
 func (s *PreferenceSchema) ToJSON(sample *Sample) ([]byte, error) {
@@ -169,3 +234,146 @@ func (s *PreferenceSchema) ToJSONL(sample *Sample) ([]byte, error) {
 	}
 	return append(data, '\n'), nil
 }
+
+// PairGenerationOptions configures GenerateTwoPassPair's two-completion +
+// judge strategy.
+type PairGenerationOptions struct {
+	// Prompt is the instruction/question both completions answer.
+	Prompt string
+
+	// SystemPromptA/SystemPromptB are the system prompts for the two
+	// completions. SystemPromptB falls back to SystemPromptA when empty,
+	// so TemperatureA/TemperatureB alone can drive the two responses
+	// apart without needing a second, differently-worded prompt.
+	SystemPromptA string
+	SystemPromptB string
+
+	// TemperatureA/TemperatureB are the sampling temperatures for the two
+	// completions.
+	TemperatureA float64
+	TemperatureB float64
+
+	// MaxTokens limits both completions.
+	MaxTokens int
+}
+
+// preferenceJudgeVerdict is the shape the judge provider is asked to
+// return when picking between the two completions GenerateTwoPassPair
+// produces.
+type preferenceJudgeVerdict struct {
+	Preferred string `json:"preferred"` // "a" or "b"
+	Rationale string `json:"rationale"`
+}
+
+// GenerateTwoPassPair drives the contrastive-prompt strategy from
+// GeneratePrompt as an actual two-generation + judge loop instead: it asks
+// gen for two independent completions of opts.Prompt (at different
+// temperatures/system prompts), then asks judge which one is better, and
+// assembles the result into a chosen/rejected Sample. gen and judge are
+// providers already registered on a Generator; passing the same Provider
+// for both is fine. Keeping this as a schema-level method (rather than the
+// CLI driving two Generate calls itself) lets any caller get a preference
+// pair with one call.
+func (s *PreferenceSchema) GenerateTwoPassPair(ctx context.Context, gen provider.Provider, judge provider.Provider, opts PairGenerationOptions) (*Sample, error) {
+	systemB := opts.SystemPromptB
+	if systemB == "" {
+		systemB = opts.SystemPromptA
+	}
+
+	// The two completions are independent, so generate them concurrently
+	// rather than paying for two sequential round trips.
+	type genResult struct {
+		content string
+		err     error
+	}
+	resultsA := make(chan genResult, 1)
+	resultsB := make(chan genResult, 1)
+
+	go func() {
+		resp, err := gen.Generate(ctx, provider.GenerationRequest{
+			Messages:     []provider.Message{{Role: "user", Content: opts.Prompt}},
+			SystemPrompt: opts.SystemPromptA,
+			Temperature:  opts.TemperatureA,
+			MaxTokens:    opts.MaxTokens,
+		})
+		if err != nil {
+			resultsA <- genResult{err: fmt.Errorf("generating first completion: %w", err)}
+			return
+		}
+		resultsA <- genResult{content: resp.Content}
+	}()
+	go func() {
+		resp, err := gen.Generate(ctx, provider.GenerationRequest{
+			Messages:     []provider.Message{{Role: "user", Content: opts.Prompt}},
+			SystemPrompt: systemB,
+			Temperature:  opts.TemperatureB,
+			MaxTokens:    opts.MaxTokens,
+		})
+		if err != nil {
+			resultsB <- genResult{err: fmt.Errorf("generating second completion: %w", err)}
+			return
+		}
+		resultsB <- genResult{content: resp.Content}
+	}()
+
+	resA, resB := <-resultsA, <-resultsB
+	if resA.err != nil {
+		return nil, resA.err
+	}
+	if resB.err != nil {
+		return nil, resB.err
+	}
+
+	if resA.content == resB.content {
+		return nil, fmt.Errorf("both completions were identical, nothing to prefer between")
+	}
+
+	chosen, rejected, rationale, err := judgePair(ctx, judge, opts.Prompt, resA.content, resB.content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sample{
+		Fields: map[string]any{
+			"prompt":    opts.Prompt,
+			"chosen":    chosen,
+			"rejected":  rejected,
+			"rationale": rationale,
+		},
+	}, nil
+}
+
+// judgePair asks judge to pick the better of responseA/responseB for
+// prompt, returning (chosen, rejected, rationale). It defaults to
+// preferring A if the judge's verdict can't be parsed as "a" or "b".
+func judgePair(ctx context.Context, judge provider.Provider, prompt, responseA, responseB string) (chosen, rejected, rationale string, err error) {
+	var sb strings.Builder
+	sb.WriteString("Given the prompt and two candidate responses, decide which response is better.\n\n")
+	fmt.Fprintf(&sb, "Prompt:\n%s\n\n", prompt)
+	fmt.Fprintf(&sb, "Response A:\n%s\n\n", responseA)
+	fmt.Fprintf(&sb, "Response B:\n%s\n\n", responseB)
+	sb.WriteString(`Respond with ONLY a JSON object: {"preferred": "a" or "b", "rationale": "a short explanation of why"}`)
+
+	resp, err := judge.Generate(ctx, provider.GenerationRequest{
+		Messages: []provider.Message{{Role: "user", Content: sb.String()}},
+	})
+	if err != nil {
+		return "", "", "", fmt.Errorf("judge provider call failed: %w", err)
+	}
+
+	raw := strings.TrimSpace(resp.Content)
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(raw, "```")
+	raw = strings.TrimSpace(raw)
+
+	var verdict preferenceJudgeVerdict
+	if err := json.Unmarshal([]byte(raw), &verdict); err != nil {
+		return "", "", "", fmt.Errorf("failed to parse judge response: %w", err)
+	}
+
+	if strings.EqualFold(strings.TrimSpace(verdict.Preferred), "b") {
+		return responseB, responseA, verdict.Rationale, nil
+	}
+	return responseA, responseB, verdict.Rationale, nil
+}
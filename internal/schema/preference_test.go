@@ -3,8 +3,96 @@ package schema
 import (
 	"context"
 	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
 )
 
+// scriptedProvider implements provider.Provider with one response per call,
+// cycling back to the last response once the script runs out.
+type scriptedProvider struct {
+	responses []string
+	calls     int
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, req provider.GenerationRequest) (*provider.GenerationResponse, error) {
+	i := p.calls
+	if i >= len(p.responses) {
+		i = len(p.responses) - 1
+	}
+	p.calls++
+	return &provider.GenerationResponse{Content: p.responses[i]}, nil
+}
+
+func (p *scriptedProvider) GenerateStream(ctx context.Context, req provider.GenerationRequest) (<-chan provider.StreamChunk, error) {
+	return nil, nil
+}
+func (p *scriptedProvider) Name() string                          { return "scripted" }
+func (p *scriptedProvider) Type() string                          { return "scripted" }
+func (p *scriptedProvider) Model() string                         { return "scripted-model" }
+func (p *scriptedProvider) SupportsStreaming() bool               { return false }
+func (p *scriptedProvider) SupportsStructuredOutput() bool        { return false }
+func (p *scriptedProvider) Validate() error                       { return nil }
+func (p *scriptedProvider) HealthCheck(ctx context.Context) error { return nil }
+func (p *scriptedProvider) Close() error                          { return nil }
+
+func TestPreferenceSchema_GenerateTwoPassPair(t *testing.T) {
+	s := NewPreferenceSchema()
+	gen := &scriptedProvider{responses: []string{"The careful answer", "A sloppier answer"}}
+	judgeProvider := &scriptedProvider{responses: []string{`{"preferred": "a", "rationale": "more accurate"}`}}
+
+	sample, err := s.GenerateTwoPassPair(context.Background(), gen, judgeProvider, PairGenerationOptions{
+		Prompt:        "Explain gravity",
+		TemperatureA:  0.2,
+		TemperatureB:  1.0,
+		SystemPromptA: "Answer carefully and precisely.",
+	})
+	if err != nil {
+		t.Fatalf("GenerateTwoPassPair failed: %v", err)
+	}
+
+	if sample.GetString("chosen") != "The careful answer" {
+		t.Errorf("chosen = %q, want %q", sample.GetString("chosen"), "The careful answer")
+	}
+	if sample.GetString("rejected") != "A sloppier answer" {
+		t.Errorf("rejected = %q, want %q", sample.GetString("rejected"), "A sloppier answer")
+	}
+	if sample.GetString("rationale") != "more accurate" {
+		t.Errorf("rationale = %q, want %q", sample.GetString("rationale"), "more accurate")
+	}
+
+	if err := s.ValidateSample(sample); err != nil {
+		t.Errorf("ValidateSample rejected generated pair: %v", err)
+	}
+}
+
+func TestPreferenceSchema_GenerateTwoPassPair_JudgePrefersB(t *testing.T) {
+	s := NewPreferenceSchema()
+	gen := &scriptedProvider{responses: []string{"Response A", "Response B"}}
+	judgeProvider := &scriptedProvider{responses: []string{`{"preferred": "b", "rationale": "B is clearer"}`}}
+
+	sample, err := s.GenerateTwoPassPair(context.Background(), gen, judgeProvider, PairGenerationOptions{Prompt: "Explain tides"})
+	if err != nil {
+		t.Fatalf("GenerateTwoPassPair failed: %v", err)
+	}
+
+	if sample.GetString("chosen") != "Response B" || sample.GetString("rejected") != "Response A" {
+		t.Errorf("chosen/rejected = %q/%q, want Response B/Response A", sample.GetString("chosen"), sample.GetString("rejected"))
+	}
+}
+
+func TestPreferenceSchema_GenerateTwoPassPair_RejectsIdenticalCompletions(t *testing.T) {
+	s := NewPreferenceSchema()
+	gen := &scriptedProvider{responses: []string{"Same answer", "Same answer"}}
+	judgeProvider := &scriptedProvider{}
+
+	if _, err := s.GenerateTwoPassPair(context.Background(), gen, judgeProvider, PairGenerationOptions{Prompt: "Explain tides"}); err == nil {
+		t.Error("GenerateTwoPassPair should fail when both completions are identical")
+	}
+	if judgeProvider.calls != 0 {
+		t.Errorf("judge should not be called for identical completions, got %d calls", judgeProvider.calls)
+	}
+}
+
 func TestPreferenceSchema_ValidateSample(t *testing.T) {
 	s := NewPreferenceSchema()
 
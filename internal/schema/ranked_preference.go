@@ -0,0 +1,259 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// RankedPreferenceSchema implements list-wise preference records -
+// {prompt, responses, ranking} - for list-wise RLHF and ORPO-style
+// training, rather than a single chosen/rejected pair (see
+// PreferenceSchema) or an unpaired binary judgment (see KTOSchema).
+type RankedPreferenceSchema struct{}
+
+// NewRankedPreferenceSchema creates a new ranked preference schema
+func NewRankedPreferenceSchema() *RankedPreferenceSchema {
+	return &RankedPreferenceSchema{}
+}
+
+func (s *RankedPreferenceSchema) Name() string        { return "ranked" }
+func (s *RankedPreferenceSchema) Style() DatasetStyle { return StylePreference }
+func (s *RankedPreferenceSchema) Version() string     { return "1.0" }
+
+func (s *RankedPreferenceSchema) Description() string {
+	return "List-wise ranked responses for RLHF/ORPO-style training"
+}
+
+func (s *RankedPreferenceSchema) Fields() []FieldDefinition {
+	return []FieldDefinition{
+		{
+			Name:        "prompt",
+			Type:        FieldTypeString,
+			Description: "The instruction or question",
+			Required:    true,
+		},
+		{
+			Name:        "responses",
+			Type:        FieldTypeList,
+			Description: "Candidate responses to the prompt, in generation order",
+			Required:    true,
+		},
+		{
+			Name:        "ranking",
+			Type:        FieldTypeList,
+			Description: "Indices into responses, best first (a permutation of 0..len(responses)-1)",
+			Required:    true,
+		},
+		{
+			Name:        "rationale",
+			Type:        FieldTypeString,
+			Description: "Why the responses were ranked in this order",
+			Required:    false,
+		},
+	}
+}
+
+func (s *RankedPreferenceSchema) RequiredFields() []string {
+	return []string{"prompt", "responses", "ranking"}
+}
+
+func (s *RankedPreferenceSchema) GeneratePrompt(ctx context.Context, opts PromptOptions) (string, error) {
+	var sb strings.Builder
+
+	// Inject user context first (from context.yaml)
+	if opts.UserContext != "" {
+		sb.WriteString(opts.UserContext)
+		sb.WriteString("\n\n")
+	} else {
+		// Default context if none provided
+		sb.WriteString("Generate a prompt with several candidate responses, ranked best to worst, for list-wise preference training.\n\n")
+	}
+
+	if opts.Topic != "" {
+		sb.WriteString(fmt.Sprintf("Topic: %s\n", opts.Topic))
+	}
+	if opts.Category != "" {
+		sb.WriteString(fmt.Sprintf("Category: %s\n", opts.Category))
+	}
+
+	sb.WriteString("\n")
+
+	sb.WriteString(`Generate a prompt with 3-5 candidate responses of varying quality, then
+rank them from best to worst:
+
+{
+  "prompt": "A clear question or instruction",
+  "responses": [
+    "The best response - helpful, accurate, safe, and well-written",
+    "A mediocre response - adequate but with room for improvement",
+    "The weakest response - could be less helpful, less accurate, or lower quality"
+  ],
+  "ranking": [0, 1, 2],
+  "rationale": "A short explanation of why the responses were ranked in this order"
+}
+
+ranking is a permutation of the indices into responses, listed best
+first: ranking[0] is the index of the best response, ranking[len-1] is
+the index of the worst. It must contain every index in responses
+exactly once.`)
+
+	// Inject user instructions (from context.yaml)
+	if opts.UserInstruction != "" {
+		sb.WriteString("\n\nAdditional Instructions:\n")
+		sb.WriteString(opts.UserInstruction)
+	}
+
+	sb.WriteString("\n\nRespond with ONLY the JSON object, no additional text.")
+
+	return sb.String(), nil
+}
+
+// ResponseJSONSchema implements Schema.ResponseJSONSchema.
+func (s *RankedPreferenceSchema) ResponseJSONSchema() []byte {
+	return buildResponseJSONSchema(s.Name(), s.Fields(), s.RequiredFields())
+}
+
+// JSONSchema implements Schema.JSONSchema: prompt gets a minLength
+// mirroring ValidateSample's "too short" check, responses gets a minItems
+// mirroring its "at least two candidates" check, and ranking's items are
+// overridden to integer since (unlike every other list field) it holds
+// indices, not strings.
+func (s *RankedPreferenceSchema) JSONSchema() ([]byte, error) {
+	return buildJSONSchema(s.Name(), s.Description(), s.Fields(), s.RequiredFields(), map[string]map[string]any{
+		"prompt":    {"minLength": 10},
+		"responses": {"minItems": 2},
+		"ranking":   {"items": map[string]any{"type": "integer"}},
+	})
+}
+
+func (s *RankedPreferenceSchema) ParseResponse(raw string) (*Sample, error) {
+	raw = strings.TrimSpace(raw)
+
+	if strings.HasPrefix(raw, "```json") {
+		raw = strings.TrimPrefix(raw, "```json")
+		raw = strings.TrimSuffix(raw, "```")
+		raw = strings.TrimSpace(raw)
+	} else if strings.HasPrefix(raw, "```") {
+		raw = strings.TrimPrefix(raw, "```")
+		raw = strings.TrimSuffix(raw, "```")
+		raw = strings.TrimSpace(raw)
+	}
+
+	var data struct {
+		Prompt    string   `json:"prompt"`
+		Responses []string `json:"responses"`
+		Ranking   []int    `json:"ranking"`
+		Rationale string   `json:"rationale,omitempty"`
+	}
+
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fields := map[string]any{
+		"prompt":    data.Prompt,
+		"responses": data.Responses,
+		"ranking":   data.Ranking,
+	}
+	if data.Rationale != "" {
+		fields["rationale"] = data.Rationale
+	}
+
+	sample := &Sample{
+		Fields: fields,
+	}
+
+	return sample, nil
+}
+
+func (s *RankedPreferenceSchema) ValidateSample(sample *Sample) error {
+	var errs error
+
+	prompt := sample.GetString("prompt")
+	if prompt == "" {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "prompt", "prompt is required"))
+	} else if len(prompt) < 10 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "prompt", "prompt is too short"))
+	}
+
+	responses := sample.GetStrings("responses")
+	if len(responses) == 0 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "responses", "responses is required"))
+	} else if len(responses) < 2 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "responses", "responses must contain at least two candidates to rank"))
+	}
+
+	ranking := rankingInts(sample)
+	if len(ranking) == 0 {
+		errs = multierr.Append(errs, NewSchemaError(s.Name(), "ranking", "ranking is required"))
+	} else if len(responses) > 0 {
+		if len(ranking) != len(responses) {
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "ranking", "ranking must have the same length as responses"))
+		} else if !isPermutation(ranking, len(responses)) {
+			errs = multierr.Append(errs, NewSchemaError(s.Name(), "ranking", "ranking must be a permutation of 0..len(responses)-1 with no duplicates"))
+		}
+	}
+
+	return errs
+}
+
+// rankingInts retrieves the "ranking" field as a []int, tolerating both the
+// []int ParseResponse produces and the []any a JSON-decoded fixture or
+// round-tripped sample yields (float64 indices).
+func rankingInts(sample *Sample) []int {
+	val, ok := sample.Get("ranking")
+	if !ok {
+		return nil
+	}
+	switch v := val.(type) {
+	case []int:
+		return v
+	case []any:
+		out := make([]int, 0, len(v))
+		for _, item := range v {
+			switch n := item.(type) {
+			case int:
+				out = append(out, n)
+			case float64:
+				out = append(out, int(n))
+			default:
+				return nil
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// isPermutation reports whether ranking contains every index in
+// [0, n) exactly once.
+func isPermutation(ranking []int, n int) bool {
+	if len(ranking) != n {
+		return false
+	}
+	seen := make(map[int]bool, n)
+	for _, idx := range ranking {
+		if idx < 0 || idx >= n || seen[idx] {
+			return false
+		}
+		seen[idx] = true
+	}
+	return true
+}
+
+func (s *RankedPreferenceSchema) ToJSON(sample *Sample) ([]byte, error) {
+	return json.MarshalIndent(sample.Fields, "", "  ")
+}
+
+func (s *RankedPreferenceSchema) ToJSONL(sample *Sample) ([]byte, error) {
+	data, err := json.Marshal(sample.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
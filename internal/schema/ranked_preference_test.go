@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRankedPreferenceSchema_GeneratePrompt(t *testing.T) {
+	s := NewRankedPreferenceSchema()
+	ctx := context.Background()
+
+	opts := PromptOptions{Topic: "Cooking"}
+
+	prompt, err := s.GeneratePrompt(ctx, opts)
+	if err != nil {
+		t.Fatalf("GeneratePrompt failed: %v", err)
+	}
+
+	if !strings.Contains(prompt, "Topic: Cooking") {
+		t.Error("Prompt missing topic")
+	}
+	if !strings.Contains(prompt, "\"ranking\"") {
+		t.Error("Prompt missing ranking field")
+	}
+}
+
+func TestRankedPreferenceSchema_ParseResponse(t *testing.T) {
+	s := NewRankedPreferenceSchema()
+
+	validJSON := `{"prompt": "Explain gravity", "responses": ["Good answer", "Bad answer"], "ranking": [0, 1]}`
+
+	sample, err := s.ParseResponse(validJSON)
+	if err != nil {
+		t.Fatalf("ParseResponse failed: %v", err)
+	}
+
+	responses := sample.GetStrings("responses")
+	if len(responses) != 2 || responses[0] != "Good answer" {
+		t.Errorf("responses = %v, want [Good answer Bad answer]", responses)
+	}
+}
+
+func TestRankedPreferenceSchema_ValidateSample(t *testing.T) {
+	s := NewRankedPreferenceSchema()
+
+	tests := []struct {
+		name    string
+		sample  *Sample
+		wantErr bool
+	}{
+		{
+			name: "valid ranking",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":    "Explain how gravity works",
+					"responses": []string{"Best", "Middle", "Worst"},
+					"ranking":   []int{0, 1, 2},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid ranking with any-typed ranking (as from a decoded response)",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":    "Explain how gravity works",
+					"responses": []any{"Best", "Worst"},
+					"ranking":   []any{float64(1), float64(0)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "ranking length mismatch",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":    "Explain how gravity works",
+					"responses": []string{"A", "B", "C"},
+					"ranking":   []int{0, 1},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ranking has duplicate index",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":    "Explain how gravity works",
+					"responses": []string{"A", "B"},
+					"ranking":   []int{0, 0},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ranking has out-of-range index",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":    "Explain how gravity works",
+					"responses": []string{"A", "B"},
+					"ranking":   []int{0, 5},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "only one response",
+			sample: &Sample{
+				Fields: map[string]any{
+					"prompt":    "Explain how gravity works",
+					"responses": []string{"A"},
+					"ranking":   []int{0},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateSample(tt.sample)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSample() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
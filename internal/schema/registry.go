@@ -30,7 +30,9 @@ func (r *Registry) registerBuiltins() {
 		NewInstructionSchema(),
 		NewChatSchema(),
 		NewPreferenceSchema(),
-		NewClassificationSchema(),
+		NewKTOSchema(),
+		NewRankedPreferenceSchema(),
+		NewClassificationSchema(ClassificationConfig{}),
 	}
 	for _, s := range builtins {
 		if err := r.Register(s); err != nil {
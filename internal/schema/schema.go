@@ -35,6 +35,24 @@ type Schema interface {
 	GeneratePrompt(ctx context.Context, opts PromptOptions) (string, error)
 	ParseResponse(raw string) (*Sample, error)
 
+	// ResponseJSONSchema returns a JSON Schema document describing this
+	// schema's expected response shape, for providers that support
+	// structured/constrained decoding (see provider.Provider.
+	// SupportsStructuredOutput). Returns nil if no JSON Schema could be
+	// built, in which case callers should fall back to prompt-only decoding.
+	ResponseJSONSchema() []byte
+
+	// JSONSchema returns a Draft-07 JSON Schema document describing this
+	// schema's on-disk sample shape, for downstream tools (dataset viewers,
+	// HF `datasets` feature inference, external validators) to treat as the
+	// single source of truth for what a valid sample looks like - the same
+	// contract ValidateSample enforces internally, including constraints
+	// like minLength on fields ValidateSample rejects as "too short".
+	// Unlike ResponseJSONSchema, optional fields are simply absent from
+	// "required" rather than represented as nullable (there's no
+	// strict-mode provider to satisfy here).
+	JSONSchema() ([]byte, error)
+
 	// Validation
 	ValidateSample(sample *Sample) error
 
@@ -90,6 +108,14 @@ type PromptOptions struct {
 
 	// Custom system prompt override
 	SystemPrompt string
+
+	// UserContext is injected at the start of the prompt, ahead of the
+	// schema's own default framing (see context.yaml).
+	UserContext string
+
+	// UserInstruction is appended after the schema's own prompt body, as
+	// an "Additional Instructions" section (see context.yaml).
+	UserInstruction string
 }
 
 // Sample represents a single dataset sample
@@ -191,6 +217,133 @@ func (s *Sample) ToJSONL() ([]byte, error) {
 	return append(data, '\n'), nil
 }
 
+// jsonSchemaType maps a FieldType to the corresponding JSON Schema
+// primitive type name.
+func jsonSchemaType(t FieldType) string {
+	switch t {
+	case FieldTypeInt:
+		return "integer"
+	case FieldTypeFloat:
+		return "number"
+	case FieldTypeBool:
+		return "boolean"
+	case FieldTypeList, FieldTypeMessage:
+		return "array"
+	case FieldTypeObject:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// jsonSchemaTypeOrNullable returns jsonSchemaType(t), or a ["type", "null"]
+// union when required is false: OpenAI's strict structured-output mode
+// requires every property to be listed in "required", so an optional field
+// is represented as a required-but-nullable one instead of being omitted
+// (see buildResponseJSONSchema and ClassificationSchema.ResponseJSONSchema).
+func jsonSchemaTypeOrNullable(t FieldType, required bool) any {
+	typ := jsonSchemaType(t)
+	if required {
+		return typ
+	}
+	return []string{typ, "null"}
+}
+
+// buildResponseJSONSchema renders fields/required into a JSON Schema object
+// describing name's expected response shape, in the strict-mode-compatible
+// form OpenAI's structured outputs require: every field appears in
+// "required" (optional fields are nullable instead of absent) and
+// "additionalProperties" is false. This is the shared implementation
+// behind ResponseJSONSchema for schemas that don't need per-field
+// constraints beyond type/required; ClassificationSchema builds its
+// properties itself (to also constrain label(s) to its configured enum) but
+// still assembles the final document via assembleResponseJSONSchema.
+func buildResponseJSONSchema(name string, fields []FieldDefinition, required []string) []byte {
+	requiredSet := make(map[string]bool, len(required))
+	for _, r := range required {
+		requiredSet[r] = true
+	}
+
+	properties := make(map[string]any, len(fields))
+	allFields := make([]string, 0, len(fields))
+	for _, f := range fields {
+		properties[f.Name] = map[string]any{
+			"type":        jsonSchemaTypeOrNullable(f.Type, requiredSet[f.Name]),
+			"description": f.Description,
+		}
+		allFields = append(allFields, f.Name)
+	}
+
+	return assembleResponseJSONSchema(name, properties, allFields)
+}
+
+// assembleResponseJSONSchema wraps properties/allFields into the
+// strict-mode-compatible JSON Schema envelope OpenAI's structured outputs
+// require (every field in "required", "additionalProperties": false),
+// shared by buildResponseJSONSchema and ClassificationSchema.
+// ResponseJSONSchema so both stay in sync on the envelope's shape.
+func assembleResponseJSONSchema(name string, properties map[string]any, allFields []string) []byte {
+	out, err := json.Marshal(map[string]any{
+		"type":                 "object",
+		"title":                name,
+		"properties":           properties,
+		"required":             allFields,
+		"additionalProperties": false,
+	})
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// jsonSchemaDraft7URI identifies the JSON Schema draft JSONSchema emits,
+// chosen for its broad support across external validators and downstream
+// tooling (e.g. HF `datasets` feature inference).
+const jsonSchemaDraft7URI = "http://json-schema.org/draft-07/schema#"
+
+// buildJSONSchema renders fields/required into a Draft-07 JSON Schema
+// document describing name's on-disk sample shape. Every field gets a
+// type/description property; a FieldTypeList field additionally gets a
+// default "items": {"type": "string"}, since that's the shape every
+// built-in list field except RankedPreferenceSchema's "ranking" takes.
+// overrides[fieldName] is merged into that field's property map afterward,
+// letting a caller add a minLength (mirroring a ValidateSample "too short"
+// check), replace "items" for a non-string list, or replace the whole
+// property for a nested shape (see ChatSchema.JSONSchema's "conversations").
+func buildJSONSchema(name, description string, fields []FieldDefinition, required []string, overrides map[string]map[string]any) ([]byte, error) {
+	properties := make(map[string]any, len(fields))
+	for _, f := range fields {
+		prop := map[string]any{
+			"type":        jsonSchemaType(f.Type),
+			"description": f.Description,
+		}
+		if f.Type == FieldTypeList {
+			prop["items"] = map[string]any{"type": "string"}
+		}
+		for k, v := range overrides[f.Name] {
+			prop[k] = v
+		}
+		properties[f.Name] = prop
+	}
+
+	return assembleJSONSchema(name, description, properties, required)
+}
+
+// assembleJSONSchema wraps properties/required into the Draft-07 envelope
+// JSONSchema returns, shared by buildJSONSchema and schemas (Classification,
+// Chat) that build their properties directly instead of going through it.
+func assembleJSONSchema(name, description string, properties map[string]any, required []string) ([]byte, error) {
+	return json.MarshalIndent(map[string]any{
+		"$schema":              jsonSchemaDraft7URI,
+		"title":                name,
+		"description":          description,
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, "", "  ")
+}
+
 // SchemaError represents a schema-related error
 type SchemaError struct {
 	Schema  string
@@ -1,6 +1,8 @@
 package schema
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -108,3 +110,347 @@ func TestStripCodeBlock_Nested(t *testing.T) {
 		t.Fatalf("expected JSON object end, got: %q", got)
 	}
 }
+
+func TestSchema_ResponseJSONSchema(t *testing.T) {
+	schemas := []Schema{
+		NewInstructionSchema(),
+		NewChatSchema(),
+		NewPreferenceSchema(),
+		NewClassificationSchema(ClassificationConfig{}),
+	}
+
+	for _, s := range schemas {
+		s := s
+		t.Run(s.Name(), func(t *testing.T) {
+			raw := s.ResponseJSONSchema()
+			if len(raw) == 0 {
+				t.Fatal("expected a non-empty JSON Schema")
+			}
+
+			var doc struct {
+				Type       string                    `json:"type"`
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				t.Fatalf("expected valid JSON, got error: %v", err)
+			}
+			if doc.Type != "object" {
+				t.Errorf("expected type object, got %q", doc.Type)
+			}
+			for _, field := range s.RequiredFields() {
+				if _, ok := doc.Properties[field]; !ok {
+					t.Errorf("expected required field %q to have a property entry", field)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_ResponseJSONSchema_StrictModeShape(t *testing.T) {
+	// OpenAI's strict structured-output mode requires every property to be
+	// listed in "required" and "additionalProperties" to be false; optional
+	// fields are represented as a nullable type union instead of being
+	// omitted from "required". Verify every schema's output satisfies this,
+	// since InstructionSchema and ClassificationSchema both have fields that
+	// are optional depending on configuration/mode.
+	schemas := []Schema{
+		NewInstructionSchema(),
+		NewChatSchema(),
+		NewPreferenceSchema(),
+		NewClassificationSchema(ClassificationConfig{}),
+	}
+
+	for _, s := range schemas {
+		s := s
+		t.Run(s.Name(), func(t *testing.T) {
+			var doc struct {
+				Properties           map[string]map[string]any `json:"properties"`
+				Required             []string                  `json:"required"`
+				AdditionalProperties bool                      `json:"additionalProperties"`
+			}
+			if err := json.Unmarshal(s.ResponseJSONSchema(), &doc); err != nil {
+				t.Fatalf("expected valid JSON, got error: %v", err)
+			}
+			if doc.AdditionalProperties {
+				t.Error("expected additionalProperties to be false")
+			}
+
+			required := make(map[string]bool, len(doc.Required))
+			for _, r := range doc.Required {
+				required[r] = true
+			}
+			for name := range doc.Properties {
+				if !required[name] {
+					t.Errorf("expected field %q to be listed in required", name)
+				}
+			}
+
+			requiredFields := make(map[string]bool, len(s.RequiredFields()))
+			for _, r := range s.RequiredFields() {
+				requiredFields[r] = true
+			}
+			for name, prop := range doc.Properties {
+				_, isUnion := prop["type"].([]any)
+				if requiredFields[name] && isUnion {
+					t.Errorf("expected required field %q to have a plain type, got a nullable union", name)
+				}
+				if !requiredFields[name] && !isUnion {
+					t.Errorf("expected optional field %q to have a nullable type union, got %v", name, prop["type"])
+				}
+			}
+		})
+	}
+}
+
+func TestClassificationSchema_ResponseJSONSchema_ConstrainsLabelEnum(t *testing.T) {
+	s := NewClassificationSchema(ClassificationConfig{Labels: []string{"positive", "negative"}})
+
+	var doc struct {
+		Properties map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(s.ResponseJSONSchema(), &doc); err != nil {
+		t.Fatalf("expected valid JSON, got error: %v", err)
+	}
+
+	label, ok := doc.Properties["label"]
+	if !ok {
+		t.Fatal("expected a label property")
+	}
+	if len(label.Enum) != 2 || label.Enum[0] != "positive" || label.Enum[1] != "negative" {
+		t.Errorf("expected label enum to be the configured labels, got %v", label.Enum)
+	}
+}
+
+func TestSchema_JSONSchema(t *testing.T) {
+	schemas := []Schema{
+		NewInstructionSchema(),
+		NewChatSchema(),
+		NewPreferenceSchema(),
+		NewKTOSchema(),
+		NewRankedPreferenceSchema(),
+		NewClassificationSchema(ClassificationConfig{}),
+	}
+
+	for _, s := range schemas {
+		s := s
+		t.Run(s.Name(), func(t *testing.T) {
+			raw, err := s.JSONSchema()
+			if err != nil {
+				t.Fatalf("JSONSchema failed: %v", err)
+			}
+			if len(raw) == 0 {
+				t.Fatal("expected a non-empty JSON Schema")
+			}
+
+			var doc struct {
+				Schema     string                    `json:"$schema"`
+				Type       string                    `json:"type"`
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				t.Fatalf("expected valid JSON, got error: %v", err)
+			}
+			if doc.Schema != jsonSchemaDraft7URI {
+				t.Errorf("expected $schema %q, got %q", jsonSchemaDraft7URI, doc.Schema)
+			}
+			if doc.Type != "object" {
+				t.Errorf("expected type object, got %q", doc.Type)
+			}
+			for _, field := range s.Fields() {
+				if _, ok := doc.Properties[field.Name]; !ok {
+					t.Errorf("expected field %q to have a property entry", field.Name)
+				}
+			}
+			required := make(map[string]bool, len(doc.Required))
+			for _, r := range doc.Required {
+				required[r] = true
+			}
+			for _, field := range s.RequiredFields() {
+				if !required[field] {
+					t.Errorf("expected required field %q to be listed in required", field)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_JSONSchema_MinLengthMirrorsValidation(t *testing.T) {
+	// A sample one char below the configured minLength should fail
+	// ValidateSample's "too short" check, confirming JSONSchema's minLength
+	// actually matches the internal constraint rather than drifting from it.
+	cases := []struct {
+		schema Schema
+		field  string
+	}{
+		{NewInstructionSchema(), "instruction"},
+		{NewInstructionSchema(), "output"},
+		{NewPreferenceSchema(), "prompt"},
+		{NewKTOSchema(), "prompt"},
+		{NewRankedPreferenceSchema(), "prompt"},
+		{NewClassificationSchema(ClassificationConfig{}), "text"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.schema.Name()+"/"+c.field, func(t *testing.T) {
+			var doc struct {
+				Properties map[string]struct {
+					MinLength int `json:"minLength"`
+				} `json:"properties"`
+			}
+			raw, err := c.schema.JSONSchema()
+			if err != nil {
+				t.Fatalf("JSONSchema failed: %v", err)
+			}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				t.Fatalf("expected valid JSON, got error: %v", err)
+			}
+			minLength := doc.Properties[c.field].MinLength
+			if minLength == 0 {
+				t.Fatalf("expected field %q to have a minLength", c.field)
+			}
+
+			tooShort := make(map[string]any)
+			for _, f := range c.schema.Fields() {
+				if f.Required {
+					tooShort[f.Name] = strings.Repeat("x", 50)
+				}
+			}
+			tooShort[c.field] = strings.Repeat("x", minLength-1)
+			if c.schema.Name() == "kto" {
+				tooShort["label"] = true
+			}
+			if c.schema.Name() == "ranked" {
+				tooShort["responses"] = []string{"a", "b"}
+				tooShort["ranking"] = []int{0, 1}
+			}
+
+			if err := c.schema.ValidateSample(&Sample{Fields: tooShort}); err == nil {
+				t.Errorf("expected a sample with %q one char below minLength %d to fail ValidateSample", c.field, minLength)
+			}
+		})
+	}
+}
+
+func TestChatSchema_JSONSchema_NestedMessageObject(t *testing.T) {
+	s := NewChatSchema()
+
+	var doc struct {
+		Properties map[string]struct {
+			Items struct {
+				Type       string                    `json:"type"`
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			} `json:"items"`
+			MinItems int `json:"minItems"`
+		} `json:"properties"`
+	}
+	raw, err := s.JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("expected valid JSON, got error: %v", err)
+	}
+
+	conv, ok := doc.Properties["conversations"]
+	if !ok {
+		t.Fatal("expected a conversations property")
+	}
+	if conv.MinItems != 2 {
+		t.Errorf("expected conversations minItems 2, got %d", conv.MinItems)
+	}
+	if conv.Items.Type != "object" {
+		t.Errorf("expected conversations items to be an object, got %q", conv.Items.Type)
+	}
+	for _, field := range []string{"role", "content", "tool_calls", "tool_call_id"} {
+		if _, ok := conv.Items.Properties[field]; !ok {
+			t.Errorf("expected message object to have a %q property", field)
+		}
+	}
+}
+
+func TestClassificationSchema_JSONSchema_ConstrainsLabelEnum(t *testing.T) {
+	s := NewClassificationSchema(ClassificationConfig{Labels: []string{"positive", "negative"}})
+
+	var doc struct {
+		Properties map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}
+	raw, err := s.JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("expected valid JSON, got error: %v", err)
+	}
+
+	label, ok := doc.Properties["label"]
+	if !ok {
+		t.Fatal("expected a label property")
+	}
+	if len(label.Enum) != 2 || label.Enum[0] != "positive" || label.Enum[1] != "negative" {
+		t.Errorf("expected label enum to be the configured labels, got %v", label.Enum)
+	}
+}
+
+func TestRankedPreferenceSchema_JSONSchema_RankingItemsAreIntegers(t *testing.T) {
+	s := NewRankedPreferenceSchema()
+
+	var doc struct {
+		Properties map[string]struct {
+			Items struct {
+				Type string `json:"type"`
+			} `json:"items"`
+		} `json:"properties"`
+	}
+	raw, err := s.JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("expected valid JSON, got error: %v", err)
+	}
+
+	if got := doc.Properties["ranking"].Items.Type; got != "integer" {
+		t.Errorf("expected ranking items type integer, got %q", got)
+	}
+	if got := doc.Properties["responses"].Items.Type; got != "string" {
+		t.Errorf("expected responses items type string, got %q", got)
+	}
+}
+
+func TestClassificationSchema_ResponseJSONSchema_LabelEnumAdmitsNullWhenOptional(t *testing.T) {
+	// In multi-label mode, "label" is unused (its type is the nullable union
+	// ["string","null"]); its enum must admit null too, or a strict-mode
+	// provider could never satisfy both constraints for that field.
+	s := NewClassificationSchema(ClassificationConfig{Mode: ClassificationModeMulti, Labels: []string{"a", "b"}})
+
+	var doc struct {
+		Properties map[string]struct {
+			Enum []any `json:"enum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(s.ResponseJSONSchema(), &doc); err != nil {
+		t.Fatalf("expected valid JSON, got error: %v", err)
+	}
+
+	label, ok := doc.Properties["label"]
+	if !ok {
+		t.Fatal("expected a label property")
+	}
+	sawNull := false
+	for _, v := range label.Enum {
+		if v == nil {
+			sawNull = true
+		}
+	}
+	if !sawNull {
+		t.Errorf("expected label enum to admit null in multi-label mode, got %v", label.Enum)
+	}
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"errors"
+
+	"go.uber.org/multierr"
+)
+
+// ValidationReport wraps the error returned by Schema.ValidateSample —
+// typically several *SchemaError values combined with multierr.Append —
+// so callers can inspect every failing field in one pass instead of only
+// the first, e.g. to log a rejected sample with its full list of reasons
+// or triage a batch of failures by field name.
+type ValidationReport struct {
+	err error
+}
+
+// NewValidationReport wraps err, which may be nil, a single *SchemaError,
+// or a multierr-combined error, into a ValidationReport.
+func NewValidationReport(err error) *ValidationReport {
+	return &ValidationReport{err: err}
+}
+
+// Errors returns every *SchemaError bundled into the report, in the
+// order ValidateSample appended them. A wrapped error that isn't a
+// *SchemaError is skipped rather than reported as a zero-value one.
+func (r *ValidationReport) Errors() []*SchemaError {
+	if r == nil || r.err == nil {
+		return nil
+	}
+	errs := multierr.Errors(r.err)
+	out := make([]*SchemaError, 0, len(errs))
+	for _, err := range errs {
+		var se *SchemaError
+		if errors.As(err, &se) {
+			out = append(out, se)
+		}
+	}
+	return out
+}
+
+// Fields returns the distinct field names that failed validation, in
+// first-seen order.
+func (r *ValidationReport) Fields() []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, se := range r.Errors() {
+		if se.Field == "" || seen[se.Field] {
+			continue
+		}
+		seen[se.Field] = true
+		fields = append(fields, se.Field)
+	}
+	return fields
+}
+
+// HasField reports whether name is among the fields that failed
+// validation.
+func (r *ValidationReport) HasField(name string) bool {
+	for _, f := range r.Fields() {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package schema
+
+import "testing"
+
+func TestValidationReport_AccumulatesAllFieldErrors(t *testing.T) {
+	s := NewInstructionSchema()
+	sample := &Sample{Fields: map[string]any{
+		"instruction": "short",
+		// output is missing entirely
+	}}
+
+	report := NewValidationReport(s.ValidateSample(sample))
+
+	errs := report.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("Errors() = %d errors, want 2: %v", len(errs), errs)
+	}
+
+	if !report.HasField("instruction") {
+		t.Error("expected report to include the instruction field")
+	}
+	if !report.HasField("output") {
+		t.Error("expected report to include the output field")
+	}
+	if report.HasField("label") {
+		t.Error("did not expect an unrelated field to be reported")
+	}
+
+	fields := report.Fields()
+	if len(fields) != 2 {
+		t.Errorf("Fields() = %v, want 2 distinct field names", fields)
+	}
+}
+
+func TestValidationReport_NilAndValid(t *testing.T) {
+	if got := NewValidationReport(nil).Errors(); got != nil {
+		t.Errorf("Errors() on a nil error = %v, want nil", got)
+	}
+
+	var report *ValidationReport
+	if got := report.Errors(); got != nil {
+		t.Errorf("Errors() on a nil *ValidationReport = %v, want nil", got)
+	}
+}
@@ -0,0 +1,203 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// instruments are the kothaset.* OTel metrics recorded by the generator and
+// provider layers, rebuilt against the current Meter() whenever Init (or
+// Shutdown, falling back to the no-op meter) replaces the meter provider.
+var (
+	instrumentsMu sync.Mutex
+
+	requestLatency metric.Float64Histogram
+	sampleTokens   metric.Int64Histogram
+	successCount   metric.Int64Counter
+	failureCount   metric.Int64Counter
+	duplicateCount metric.Int64Counter
+	cacheHitCount  metric.Int64Counter
+
+	rateLimiterGauges   = map[string]metric.Registration{}
+	rateLimiterCallback metric.Int64ObservableGauge
+)
+
+// initInstruments (re)creates every kothaset.* instrument against the
+// meter Init just installed. Called with instrumentsMu unlocked by Init,
+// which itself only runs during setup (no concurrent generation yet).
+func initInstruments() error {
+	instrumentsMu.Lock()
+	defer instrumentsMu.Unlock()
+
+	meter := Meter()
+
+	var err error
+	if requestLatency, err = meter.Float64Histogram("kothaset.request.duration",
+		metric.WithDescription("Duration of provider generation requests, in seconds."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return err
+	}
+	if sampleTokens, err = meter.Int64Histogram("kothaset.sample.tokens",
+		metric.WithDescription("Total tokens billed per generated sample."),
+	); err != nil {
+		return err
+	}
+	if successCount, err = meter.Int64Counter("kothaset.samples.success",
+		metric.WithDescription("Samples that passed schema validation and were written to output."),
+	); err != nil {
+		return err
+	}
+	if failureCount, err = meter.Int64Counter("kothaset.samples.failure",
+		metric.WithDescription("Samples that failed generation, parsing, or validation."),
+	); err != nil {
+		return err
+	}
+	if duplicateCount, err = meter.Int64Counter("kothaset.samples.duplicate",
+		metric.WithDescription("Samples rejected by the near-duplicate deduper."),
+	); err != nil {
+		return err
+	}
+	if cacheHitCount, err = meter.Int64Counter("kothaset.samples.cache_hit",
+		metric.WithDescription("Samples served from the prompt/response cache instead of a live provider call."),
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// resetInstruments drops every instrument handle and rate-limiter gauge
+// registration so Shutdown leaves nothing pointing at a closed meter
+// provider; the next Init call rebuilds them from scratch.
+func resetInstruments() {
+	instrumentsMu.Lock()
+	defer instrumentsMu.Unlock()
+
+	requestLatency, sampleTokens = nil, nil
+	successCount, failureCount, duplicateCount, cacheHitCount = nil, nil, nil, nil
+	for name, reg := range rateLimiterGauges {
+		reg.Unregister()
+		delete(rateLimiterGauges, name)
+	}
+	rateLimiterCallback = nil
+}
+
+// RecordRequestLatency records one provider generation request's wall-clock
+// duration, labeled by provider name.
+func RecordRequestLatency(ctx context.Context, providerName string, d time.Duration) {
+	instrumentsMu.Lock()
+	h := requestLatency
+	instrumentsMu.Unlock()
+	if h == nil {
+		return
+	}
+	h.Record(ctx, d.Seconds(), metric.WithAttributes(attribute.String("provider", providerName)))
+}
+
+// RecordSampleTokens records one generated sample's total billed tokens,
+// labeled by schema.
+func RecordSampleTokens(ctx context.Context, schemaName string, tokens int) {
+	instrumentsMu.Lock()
+	h := sampleTokens
+	instrumentsMu.Unlock()
+	if h == nil {
+		return
+	}
+	h.Record(ctx, int64(tokens), metric.WithAttributes(attribute.String("schema", schemaName)))
+}
+
+// attrs builds the common schema/provider attribute set the outcome
+// counters below are all labeled with.
+func attrs(schemaName, providerName string) metric.MeasurementOption {
+	return metric.WithAttributes(
+		attribute.String("schema", schemaName),
+		attribute.String("provider", providerName),
+	)
+}
+
+// IncSuccess counts one sample written to output.
+func IncSuccess(ctx context.Context, schemaName, providerName string) {
+	instrumentsMu.Lock()
+	c := successCount
+	instrumentsMu.Unlock()
+	if c == nil {
+		return
+	}
+	c.Add(ctx, 1, attrs(schemaName, providerName))
+}
+
+// IncFailure counts one sample that failed generation or validation.
+func IncFailure(ctx context.Context, schemaName, providerName string) {
+	instrumentsMu.Lock()
+	c := failureCount
+	instrumentsMu.Unlock()
+	if c == nil {
+		return
+	}
+	c.Add(ctx, 1, attrs(schemaName, providerName))
+}
+
+// IncDuplicate counts one sample rejected as a near-duplicate.
+func IncDuplicate(ctx context.Context, schemaName, providerName string) {
+	instrumentsMu.Lock()
+	c := duplicateCount
+	instrumentsMu.Unlock()
+	if c == nil {
+		return
+	}
+	c.Add(ctx, 1, attrs(schemaName, providerName))
+}
+
+// IncCacheHit counts one sample served from cache.
+func IncCacheHit(ctx context.Context, schemaName, providerName string) {
+	instrumentsMu.Lock()
+	c := cacheHitCount
+	instrumentsMu.Unlock()
+	if c == nil {
+		return
+	}
+	c.Add(ctx, 1, attrs(schemaName, providerName))
+}
+
+// RegisterRateLimiterGauge registers an observable gauge reporting name's
+// (a provider name) rate limiter headroom as of each export, sampled via
+// statsFn (e.g. a closure over (*generator.RateLimiter).Stats - this
+// package can't import internal/generator directly, since generator
+// imports telemetry to emit spans/metrics). Calling it again for a name
+// already registered (e.g. Generator.Run rebuilding its router on every
+// call) replaces the previous registration rather than reporting both.
+// A no-op meter provider (observability disabled, the default) tolerates
+// the gauge/callback registration below without ever exporting it.
+func RegisterRateLimiterGauge(name string, statsFn func() (available, capacity float64)) error {
+	instrumentsMu.Lock()
+	defer instrumentsMu.Unlock()
+
+	if rateLimiterCallback == nil {
+		gauge, err := Meter().Int64ObservableGauge("kothaset.ratelimiter.available_tokens",
+			metric.WithDescription("Rate limiter tokens currently available, per configured provider."),
+		)
+		if err != nil {
+			return err
+		}
+		rateLimiterCallback = gauge
+	}
+
+	if prev, ok := rateLimiterGauges[name]; ok {
+		prev.Unregister()
+	}
+
+	reg, err := Meter().RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		available, _ := statsFn()
+		o.ObserveInt64(rateLimiterCallback, int64(available), metric.WithAttributes(attribute.String("provider", name)))
+		return nil
+	}, rateLimiterCallback)
+	if err != nil {
+		return err
+	}
+	rateLimiterGauges[name] = reg
+	return nil
+}
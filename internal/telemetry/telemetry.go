@@ -0,0 +1,141 @@
+// Package telemetry wires KothaSet's generation pipeline into OpenTelemetry:
+// a root span per Generator.Run, per-sample spans with retry events, and a
+// set of kothaset.* metrics (request latency, tokens per sample, outcome
+// counters, and rate-limiter headroom gauges). It's a thin layer alongside
+// internal/metrics' existing Prometheus instrumentation rather than a
+// replacement for it - Prometheus metrics stay scoped to a scrape endpoint,
+// while this package ships both traces and metrics to an OTLP collector
+// (Tempo/Jaeger, or an OTLP-to-Prometheus bridge) per config.ObservabilityConfig.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shantoislamdev/kothaset/internal/config"
+)
+
+const defaultServiceName = "kothaset"
+
+// state holds the live providers Init set up, so Shutdown can flush and
+// close them and so a disabled (or not-yet-initialized) process falls back
+// to OTel's no-op implementations instead of a nil pointer panic.
+var (
+	tracerProvider trace.TracerProvider = trace.NewNoopTracerProvider()
+	meterProvider  metric.MeterProvider = noop.NewMeterProvider()
+	shutdownFuncs  []func(context.Context) error
+)
+
+// Init configures the process-wide tracer/meter providers from cfg. A zero
+// ObservabilityConfig (the default) leaves the no-op providers in place, so
+// every Tracer()/Meter() call site stays safe to use unconditionally
+// whether or not observability is enabled. Init is idempotent: calling it
+// again (e.g. after a config hot-reload) shuts down the previous providers
+// first.
+func Init(ctx context.Context, cfg config.ObservabilityConfig) error {
+	if err := Shutdown(ctx); err != nil {
+		return fmt.Errorf("telemetry: failed to shut down previous providers: %w", err)
+	}
+
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.OTLPEndpoint == "" {
+		return errors.New("telemetry: observability.otlp_endpoint is required when observability.enabled is true")
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName(cfg))),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return fmt.Errorf("telemetry: failed to build resource: %w", err)
+	}
+
+	sampleRate := cfg.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 1
+	}
+
+	traceExporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint),
+		otlptracegrpc.WithHeaders(cfg.OTLPHeaders),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return fmt.Errorf("telemetry: failed to create trace exporter: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRate))),
+	)
+
+	metricExporter, err := otlpmetricgrpc.New(ctx,
+		otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint),
+		otlpmetricgrpc.WithHeaders(cfg.OTLPHeaders),
+		otlpmetricgrpc.WithInsecure(),
+	)
+	if err != nil {
+		return fmt.Errorf("telemetry: failed to create metric exporter: %w", err)
+	}
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+
+	tracerProvider = tp
+	meterProvider = mp
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+	shutdownFuncs = []func(context.Context) error{tp.Shutdown, mp.Shutdown}
+
+	return initInstruments()
+}
+
+// Shutdown flushes and closes whatever providers Init set up, restoring
+// the no-op providers so Tracer()/Meter() stay safe to call afterward.
+// Safe to call when Init was never called or observability is disabled.
+func Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, fn := range shutdownFuncs {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	shutdownFuncs = nil
+	tracerProvider = trace.NewNoopTracerProvider()
+	meterProvider = noop.NewMeterProvider()
+	resetInstruments()
+	return errors.Join(errs...)
+}
+
+// Tracer returns the tracer every kothaset span is started from.
+func Tracer() trace.Tracer {
+	return tracerProvider.Tracer(defaultServiceName)
+}
+
+// Meter returns the meter every kothaset instrument is recorded against.
+func Meter() metric.Meter {
+	return meterProvider.Meter(defaultServiceName)
+}
+
+func serviceName(cfg config.ObservabilityConfig) string {
+	if cfg.ServiceName != "" {
+		return cfg.ServiceName
+	}
+	return defaultServiceName
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/shantoislamdev/kothaset/internal/config"
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"provider error", provider.NewRateLimitError("slow down", 1), string(provider.ErrKindRateLimit)},
+		{"wrapped provider error", fmt.Errorf("request failed: %w", provider.NewAuthError("bad key")), string(provider.ErrKindAuth)},
+		{"plain error", errors.New("boom"), "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorClass(tt.err); got != tt.want {
+				t.Errorf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInit_DisabledIsNoop(t *testing.T) {
+	if err := Init(context.Background(), config.ObservabilityConfig{}); err != nil {
+		t.Fatalf("Init() with Enabled=false returned error: %v", err)
+	}
+	if Tracer() == nil {
+		t.Fatal("Tracer() returned nil")
+	}
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestInit_EnabledRequiresEndpoint(t *testing.T) {
+	err := Init(context.Background(), config.ObservabilityConfig{Enabled: true})
+	if err == nil {
+		t.Fatal("Init() with Enabled=true and no OTLPEndpoint should error")
+	}
+}
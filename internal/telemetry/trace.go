@@ -0,0 +1,23 @@
+package telemetry
+
+import (
+	"errors"
+
+	"github.com/shantoislamdev/kothaset/internal/provider"
+)
+
+// ErrorClass classifies err for a span event/attribute, mirroring
+// provider.IsRetryableError's own classification instead of inventing a
+// second taxonomy: a *provider.ProviderError reports its Kind, anything
+// else (a context error, a local failure before a provider was even
+// reached) is "unknown".
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	var pe *provider.ProviderError
+	if errors.As(err, &pe) {
+		return string(pe.Kind)
+	}
+	return "unknown"
+}
@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FetchRemote downloads a gzipped tarball from url, verifies its
+// SHA-256 digest matches the hex-encoded checksum (skipped if checksum
+// is empty), and extracts every regular file it contains into destDir.
+// Used by `kothaset init --template-url` so a team can share an internal
+// template without the CLI having to trust the transport alone.
+func FetchRemote(url, checksum, destDir string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch template %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch template %s: status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read template archive: %w", err)
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(body)
+		got := hex.EncodeToString(sum[:])
+		if !strings.EqualFold(got, checksum) {
+			return fmt.Errorf("template checksum mismatch: got %s, want %s", got, checksum)
+		}
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to open template archive: %w", err)
+	}
+	defer gz.Close()
+
+	return extractTar(gz, destDir)
+}
+
+// extractTar writes every regular file in the tar stream r to destDir,
+// rejecting any entry whose path would escape destDir (a zip-slip style
+// archive entry such as "../../etc/passwd").
+func extractTar(r io.Reader, destDir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read template archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target := filepath.Join(destDir, filepath.Clean(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(destDir)+string(filepath.Separator)) {
+			return fmt.Errorf("template archive entry %q escapes the destination directory", hdr.Name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %q: %w", hdr.Name, err)
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write template file %q: %w", hdr.Name, err)
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write template file %q: %w", hdr.Name, err)
+		}
+		f.Close()
+	}
+}
@@ -0,0 +1,89 @@
+// Package templates provides `kothaset init`'s schema-specific starter
+// kits: a tailored kothaset.yaml plus starter input files (topics.txt,
+// labels.yaml, personas.yaml, ...) appropriate for a given dataset
+// schema, embedded directly into the binary.
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed files
+var filesFS embed.FS
+
+// Template describes one built-in starter kit.
+type Template struct {
+	// Name is the value passed to `kothaset init --template`.
+	Name string
+
+	// Description is a one-line summary shown by --list-templates.
+	Description string
+
+	// Schema is the dataset schema this template's kothaset.yaml
+	// defaults global.schema to.
+	Schema string
+}
+
+// registry lists every built-in template. "instruction" matches the
+// content `kothaset init` wrote before templates existed, so it stays
+// the default when --template is omitted.
+var registry = []Template{
+	{Name: "instruction", Description: "Single-turn instruction/response pairs (default)", Schema: "instruction"},
+	{Name: "chat", Description: "Multi-turn conversational dialogues", Schema: "chat"},
+	{Name: "dpo-preference", Description: "Chosen/rejected preference pairs for DPO/RLHF", Schema: "preference"},
+	{Name: "classification-multilabel", Description: "Text classification with one or more labels", Schema: "classification"},
+	{Name: "rag-qa", Description: "Question answering grounded in retrieved reference material", Schema: "instruction"},
+	{Name: "function-calling", Description: "Tool/function-calling conversations", Schema: "chat"},
+}
+
+// List returns every built-in template, in registration order.
+func List() []Template {
+	return append([]Template(nil), registry...)
+}
+
+// Get returns the named built-in template's metadata.
+func Get(name string) (Template, error) {
+	for _, t := range registry {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return Template{}, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(names(), ", "))
+}
+
+// Files returns every file belonging to template name, keyed by its
+// path relative to the project root (e.g. "kothaset.yaml", "topics.txt").
+func Files(name string) (map[string][]byte, error) {
+	if _, err := Get(name); err != nil {
+		return nil, err
+	}
+
+	dir := "files/" + name
+	entries, err := filesFS.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded template %q: %w", name, err)
+	}
+
+	out := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := filesFS.ReadFile(dir + "/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded template file %q: %w", entry.Name(), err)
+		}
+		out[entry.Name()] = data
+	}
+	return out, nil
+}
+
+func names() []string {
+	out := make([]string, len(registry))
+	for i, t := range registry {
+		out[i] = t.Name
+	}
+	return out
+}
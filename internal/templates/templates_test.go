@@ -0,0 +1,84 @@
+package templates
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestList_IncludesInstructionDefault(t *testing.T) {
+	found := false
+	for _, tmpl := range List() {
+		if tmpl.Name == "instruction" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("List() does not include the default \"instruction\" template")
+	}
+}
+
+func TestGet_UnknownTemplate(t *testing.T) {
+	if _, err := Get("does-not-exist"); err == nil {
+		t.Error("Get() error = nil, want an error for an unregistered template")
+	}
+}
+
+func TestFiles_EveryRegisteredTemplateHasAKothasetYAML(t *testing.T) {
+	for _, tmpl := range List() {
+		files, err := Files(tmpl.Name)
+		if err != nil {
+			t.Fatalf("Files(%q) error = %v", tmpl.Name, err)
+		}
+		if _, ok := files["kothaset.yaml"]; !ok {
+			t.Errorf("template %q has no kothaset.yaml", tmpl.Name)
+		}
+	}
+}
+
+func buildTar(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("WriteHeader: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return &buf
+}
+
+func TestExtractTar_WritesRegularFiles(t *testing.T) {
+	dir := t.TempDir()
+	archive := buildTar(t, map[string]string{"kothaset.yaml": "version: \"1.0\"\n"})
+
+	if err := extractTar(archive, dir); err != nil {
+		t.Fatalf("extractTar() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "kothaset.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "version: \"1.0\"\n" {
+		t.Errorf("extracted content = %q, want %q", data, "version: \"1.0\"\n")
+	}
+}
+
+func TestExtractTar_RejectsPathEscape(t *testing.T) {
+	dir := t.TempDir()
+	archive := buildTar(t, map[string]string{"../escape.txt": "nope"})
+
+	if err := extractTar(archive, dir); err == nil {
+		t.Error("extractTar() error = nil, want an error for a path escaping destDir")
+	}
+}